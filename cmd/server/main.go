@@ -20,10 +20,22 @@ import (
 	"naviger/internal/api"
 	"naviger/internal/backup"
 	"naviger/internal/config"
+	"naviger/internal/domain"
+	"naviger/internal/incidents"
 	"naviger/internal/jvm"
+	"naviger/internal/logarchive"
+	"naviger/internal/metrics"
+	"naviger/internal/network"
+	"naviger/internal/notifications"
+	"naviger/internal/players"
 	"naviger/internal/runner"
+	"naviger/internal/scheduler"
 	"naviger/internal/server"
+	"naviger/internal/sessions"
+	"naviger/internal/sftpd"
+	"naviger/internal/statshistory"
 	"naviger/internal/storage"
+	"naviger/internal/tps"
 	"naviger/internal/updater"
 	"naviger/internal/ws"
 
@@ -208,7 +220,7 @@ func startDaemonService(ctx context.Context) {
 		return
 	}
 
-	for _, path := range []string{cfg.ServersPath, cfg.BackupsPath, cfg.RuntimesPath} {
+	for _, path := range []string{cfg.ServersPath, cfg.BackupsPath, cfg.RuntimesPath, cfg.TemplatesPath, cfg.LogsPath} {
 		_ = os.MkdirAll(path, 0755)
 	}
 
@@ -218,8 +230,20 @@ func startDaemonService(ctx context.Context) {
 		return
 	}
 
+	var serverUsers *runner.ServerUsers
+	if cfg.Hardened {
+		serverUsers = &runner.ServerUsers{FirstUID: cfg.FirstUID, Count: cfg.UIDCount}
+		private := []string{cfg.DatabasePath, cfg.BackupsPath, cfg.LogsPath, cfg.TemplatesPath, filepath.Join(configDir, "config.json")}
+		if err := runner.CheckServerUsers(*serverUsers, cfg.ServersPath, cfg.RuntimesPath, private...); err != nil {
+			log.Printf("Hardened mode is enabled but cannot be used: %v", err)
+			return
+		}
+	}
+
 	jvmMgr := jvm.NewManager(cfg.RuntimesPath)
-	srvMgr := server.NewManager(cfg.ServersPath, store)
+	jvmMgr.Registry = store
+	srvMgr := server.NewManager(cfg.ServersPath, cfg.TemplatesPath, store)
+	srvMgr.JVM = jvmMgr
 	bufferSize := cfg.LogBufferSize
 	if val, err := store.GetSetting("log_buffer_size"); err == nil {
 		if n, err := strconv.Atoi(val); err == nil && n > 0 {
@@ -228,13 +252,55 @@ func startDaemonService(ctx context.Context) {
 	}
 	hubManager := ws.NewHubManager(bufferSize)
 	supervisor := runner.NewSupervisor(store, jvmMgr, hubManager, cfg.ServersPath)
+	supervisor.Users = serverUsers
+	supervisor.SetBackend(domain.RunModeContainer, runner.NewContainerBackend(cfg.Engine, cfg.EngineImage))
+	diskSampler := server.NewDiskSampler(cfg.ServersPath, store)
+	supervisor.Disk = diskSampler
+	go diskSampler.Run(ctx)
 	backupManager := backup.NewManager(cfg.ServersPath, cfg.BackupsPath, store)
+	networkManager := network.NewManager(store, supervisor, cfg.ServersPath)
+	playerManager := players.NewManager(store, supervisor, cfg.ServersPath, players.NewMojangResolver())
+	sessionTracker := sessions.NewTracker(store)
+	supervisor.AddObserver(sessionTracker)
+	logArchive := logarchive.NewArchive(cfg.LogsPath, int64(cfg.LogMaxSizeMB)*1024*1024, cfg.LogMaxFiles)
+	supervisor.AddObserver(logArchive)
+	incidentManager := incidents.NewManager(store, cfg.ServersPath)
+	supervisor.AddObserver(incidentManager)
+	notifier := notifications.NewNotifier(store)
+	supervisor.AddObserver(notifier)
+	incidentManager.OnExit = notifier.ReportExit
+	collector := metrics.NewCollector(store, supervisor, hubManager, sessionTracker, diskSampler)
+	backupManager.OnBackup = func(serverID string, result backup.JobResult) {
+		notifier.ReportBackup(serverID, result.Name, result.Err)
+		collector.ObserveBackup(serverID, result.Duration, result.Size, result.Err)
+	}
+	defer notifier.Close()
+	go notifier.WatchUpdates(ctx, notifications.UpdateCheckInterval)
+	go notifier.WatchDisk(ctx, cfg.ServersPath, cfg.DiskLowMB, notifications.DiskCheckInterval)
+	defer logArchive.Close()
+	if cfg.TPSInterval > 0 {
+		prober := tps.NewProber(store, supervisor, time.Duration(cfg.TPSInterval)*time.Second)
+		prober.Threshold = max(cfg.TPSAlertBelow, 0)
+		prober.AlertAfter = time.Duration(cfg.TPSAlertAfter) * time.Minute
+		prober.OnLow = notifier.ReportLowTPS
+		supervisor.AddObserver(prober)
+		supervisor.Ticks = prober
+		go prober.Run(ctx)
+	}
+	statsHistory := statshistory.NewRecorder(store, supervisor, time.Duration(cfg.StatsInterval)*time.Second)
+	go statsHistory.Run(ctx)
+	taskScheduler := scheduler.NewScheduler(store, supervisor, backupManager)
+	go taskScheduler.Run(ctx)
 
 	if err := supervisor.ResetRunningStates(); err != nil {
 		log.Printf("Warning resetting states: %v", err)
 	}
+	if err := sessionTracker.CloseStale(); err != nil {
+		log.Printf("Warning closing player sessions: %v", err)
+	}
+	go srvMgr.RecordJavaVersions()
 
-	apiServer := api.NewAPIServer(srvMgr, supervisor, store, hubManager, backupManager, cfg)
+	apiServer := api.NewAPIServer(srvMgr, supervisor, store, hubManager, backupManager, networkManager, playerManager, sessionTracker, logArchive, incidentManager, taskScheduler, notifier, collector, statsHistory, cfg)
 	listenAddr := fmt.Sprintf(":%d", config.GetPort())
 
 	httpServer := apiServer.CreateHTTPServer(listenAddr)
@@ -246,6 +312,22 @@ func startDaemonService(ctx context.Context) {
 		}
 	}()
 
+	if cfg.SFTPPort > 0 {
+		hostKey, err := sftpd.LoadOrGenerateHostKey(filepath.Join(configDir, "ssh_host_ed25519_key"))
+		if err != nil {
+			log.Printf("SFTP disabled, cannot load host key: %v", err)
+		} else {
+			sftpServer := sftpd.NewServer(store, srvMgr, hostKey)
+			sftpAddr := fmt.Sprintf(":%d", cfg.SFTPPort)
+			go func() {
+				log.Printf("SFTP Listening on %s", sftpAddr)
+				if err := sftpServer.ListenAndServe(ctx, sftpAddr); err != nil {
+					log.Printf("SFTP Server Error: %v", err)
+				}
+			}()
+		}
+	}
+
 	<-ctx.Done()
 
 	log.Println("Shutting down HTTP server...")
@@ -49,6 +49,12 @@ if [ "$INSTALL_MODE" != "1" ] && [ "$INSTALL_MODE" != "2" ]; then
     exit 1
 fi
 
+# Hardened mode runs every Minecraft server as its own system user (Linux headless only)
+HARDENED="n"
+if [ "$INSTALL_MODE" = "1" ] && [ "$OS_TYPE" = "linux" ]; then
+    read -p "Run each server as its own unprivileged system user (hardened mode)? [y/N]: " HARDENED
+fi
+
 # Check for dependencies
 command -v curl >/dev/null 2>&1 || { echo >&2 "curl is required but not installed. Aborting."; exit 1; }
 command -v unzip >/dev/null 2>&1 || { echo >&2 "unzip is required but not installed. Aborting."; exit 1; }
@@ -168,6 +174,39 @@ if [ "$INSTALL_MODE" = "1" ]; then
         SERVICE_FILE="/etc/systemd/system/naviger.service"
         echo "Setting up systemd service..."
 
+        SERVICE_EXTRA=""
+        if [ "$HARDENED" = "y" ] || [ "$HARDENED" = "Y" ]; then
+            echo "Configuring hardened mode..."
+            # Lets the daemon start servers under their own UID and still manage their files.
+            SERVICE_EXTRA="AmbientCapabilities=CAP_SETUID CAP_SETGID CAP_CHOWN CAP_DAC_OVERRIDE CAP_FOWNER"
+
+            # Server users cannot reach folders inside a home directory, so keep servers and Java runtimes in /var/lib.
+            DATA_DIR="/var/lib/naviger"
+            sudo mkdir -p "${DATA_DIR}/servers" "${DATA_DIR}/runtimes"
+            sudo chown "$REAL_USER" "${DATA_DIR}" "${DATA_DIR}/servers" "${DATA_DIR}/runtimes"
+            sudo chmod 755 "${DATA_DIR}" "${DATA_DIR}/servers" "${DATA_DIR}/runtimes"
+
+            REAL_HOME=$(getent passwd "$REAL_USER" | cut -d: -f6)
+            CONFIG_DIR="${REAL_HOME}/.config/naviger"
+            CONFIG_FILE="${CONFIG_DIR}/config.json"
+            if [ ! -f "$CONFIG_FILE" ]; then
+                sudo -u "$REAL_USER" mkdir -p "$CONFIG_DIR"
+                sudo -u "$REAL_USER" bash -c "cat > '${CONFIG_FILE}'" <<EOF
+{
+  "servers_path": "${DATA_DIR}/servers",
+  "runtimes_path": "${DATA_DIR}/runtimes",
+  "hardened": true
+}
+EOF
+            elif grep -q '"hardened"' "$CONFIG_FILE"; then
+                sudo sed -i 's/"hardened": *false/"hardened": true/' "$CONFIG_FILE"
+            else
+                sudo sed -i '0,/{/s//{\n  "hardened": true,/' "$CONFIG_FILE"
+            fi
+            echo "Hardened mode enabled in ${CONFIG_FILE}."
+            echo "Existing servers keep their folders; Naviger refuses to start if server users cannot reach them."
+        fi
+
         sudo bash -c "cat > ${SERVICE_FILE}" <<EOF
 [Unit]
 Description=Naviger Server Daemon
@@ -180,6 +219,9 @@ ExecStart=${INSTALL_DIR}/naviger-server --headless
 WorkingDirectory=${INSTALL_DIR}
 Restart=on-failure
 RestartSec=5s
+# Lets Naviger create a cgroup per server to enforce resource limits.
+Delegate=yes
+${SERVICE_EXTRA}
 
 [Install]
 WantedBy=multi-user.target
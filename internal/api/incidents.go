@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"naviger/internal/domain"
+)
+
+func (api *Server) handleListIncidents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	incidents, err := api.Incidents.List(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(incidents)
+}
+
+func (api *Server) handleGetIncident(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	incident, err := api.Incidents.Get(id, r.PathValue("incidentId"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if incident == nil {
+		http.Error(w, "Incident not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(incident)
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"naviger/internal/domain"
+	"naviger/internal/jvm"
+	"naviger/internal/runner"
+
+	"github.com/google/uuid"
+)
+
+// javaRuntimeInfo is a runtime along with the servers that start with it.
+type javaRuntimeInfo struct {
+	jvm.Runtime
+	Servers []string `json:"servers"`
+}
+
+// javaRuntimeUsers maps runtime IDs to the servers that use them: their
+// pinned runtime, or the managed one for the Java version they need.
+func (api *Server) javaRuntimeUsers() (map[string][]domain.Server, error) {
+	servers, err := api.Store.ListServers()
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[string][]domain.Server)
+	for _, srv := range servers {
+		id := srv.JavaRuntime
+		if id == "" {
+			id = jvm.ManagedID(runner.RequiredJava(&srv))
+		}
+		users[id] = append(users[id], srv)
+	}
+	return users, nil
+}
+
+func (api *Server) handleListJavaRuntimes(w http.ResponseWriter, r *http.Request) {
+	runtimes, err := api.Supervisor.JVM.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	users, err := api.javaRuntimeUsers()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	infos := make([]javaRuntimeInfo, 0, len(runtimes))
+	for _, rt := range runtimes {
+		info := javaRuntimeInfo{Runtime: rt, Servers: []string{}}
+		for _, srv := range users[rt.ID] {
+			info.Servers = append(info.Servers, srv.ID)
+		}
+		infos = append(infos, info)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(infos)
+}
+
+func (api *Server) handleInstallJavaRuntime(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Version   int    `json:"version"`
+		RequestID string `json:"requestId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Version < 8 {
+		http.Error(w, "version must be a Java major version of 8 or newer", http.StatusBadRequest)
+		return
+	}
+
+	requestID := req.RequestID
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	hub := api.HubManager.GetHub(requestID)
+
+	progressChan := make(chan domain.ProgressEvent)
+	go func() {
+		for event := range progressChan {
+			jsonBytes, _ := json.Marshal(event)
+			hub.Broadcast(jsonBytes)
+		}
+	}()
+
+	go func() {
+		defer close(progressChan)
+		rt, err := api.Supervisor.JVM.Install(req.Version, progressChan)
+		if err != nil {
+			progressChan <- domain.ProgressEvent{
+				ServerID: "error",
+				Message:  fmt.Sprintf("Error: %v", err),
+			}
+			return
+		}
+		progressChan <- domain.ProgressEvent{
+			ServerID: rt.ID,
+			Message:  fmt.Sprintf("Java %s installed", rt.Version),
+			Progress: 100,
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": "installing",
+		"id":     requestID,
+	})
+}
+
+func (api *Server) handleRegisterJavaRuntime(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Path string `json:"path"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Path == "" {
+		http.Error(w, "path is required", http.StatusBadRequest)
+		return
+	}
+
+	rt, err := api.Supervisor.JVM.Register(req.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(javaRuntimeInfo{Runtime: rt, Servers: []string{}})
+}
+
+// handleDeleteJavaRuntime refuses to remove a runtime a server is pinned to
+// or a running server uses. Stopped servers that use a managed runtime get
+// it downloaded again on their next start.
+func (api *Server) handleDeleteJavaRuntime(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := api.Supervisor.JVM.Runtime(id); err != nil {
+		if errors.Is(err, jvm.ErrRuntimeNotFound) {
+			http.Error(w, "Java runtime not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	users, err := api.javaRuntimeUsers()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, srv := range users[id] {
+		if srv.JavaRuntime == id {
+			http.Error(w, fmt.Sprintf("Server %s is pinned to this runtime", srv.Name), http.StatusConflict)
+			return
+		}
+		if api.Supervisor.IsRunning(srv.ID) {
+			http.Error(w, fmt.Sprintf("Server %s is running with this runtime", srv.Name), http.StatusConflict)
+			return
+		}
+	}
+
+	if err := api.Supervisor.JVM.Remove(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
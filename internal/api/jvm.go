@@ -0,0 +1,53 @@
+package api
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+
+	"naviger/internal/domain"
+	"naviger/internal/jvmflags"
+)
+
+// commandPreview is the command line a server would start with.
+type commandPreview struct {
+	RunMode     string   `json:"runMode"`
+	JVMProfile  string   `json:"jvmProfile"`
+	JavaVersion int      `json:"javaVersion"`
+	Args        []string `json:"args"`
+	CommandLine string   `json:"commandLine"`
+}
+
+func (api *Server) handleListJVMProfiles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jvmflags.List())
+}
+
+func (api *Server) handlePreviewCommand(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	srv, err := api.Store.GetServerByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if srv == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	args, javaVersion, err := api.Supervisor.PreviewCommand(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	preview := commandPreview{
+		RunMode:     cmp.Or(srv.RunMode, domain.RunModeProcess),
+		JVMProfile:  cmp.Or(srv.JVMProfile, jvmflags.DefaultProfile),
+		JavaVersion: javaVersion,
+		Args:        args,
+		CommandLine: jvmflags.QuoteArgs(args),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
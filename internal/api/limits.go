@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"naviger/internal/domain"
+	"naviger/internal/runner"
+)
+
+// limitsResponse shows the stored limits next to the ones a start would
+// apply, and whether this host can enforce them at all.
+type limitsResponse struct {
+	Limits    domain.ResourceLimits `json:"limits"`
+	Effective domain.ResourceLimits `json:"effective"`
+	Enforced  bool                  `json:"enforced"`
+	Usage     *domain.CgroupUsage   `json:"usage,omitempty"`
+}
+
+func (api *Server) handleGetServerLimits(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	srv, err := api.Store.GetServerByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if srv == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	api.writeLimits(w, srv)
+}
+
+func (api *Server) handleUpdateServerLimits(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var limits domain.ResourceLimits
+	if err := json.NewDecoder(r.Body).Decode(&limits); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	srv, err := api.Store.GetServerByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if srv == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+	if err := runner.ValidateLimits(srv.RAM, limits); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := api.Store.UpdateServerLimits(id, limits); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	srv.Limits = limits
+
+	api.writeLimits(w, srv)
+}
+
+func (api *Server) writeLimits(w http.ResponseWriter, srv *domain.Server) {
+	resp := limitsResponse{
+		Limits:    srv.Limits,
+		Effective: runner.EffectiveLimits(srv),
+		Enforced:  srv.RunMode == domain.RunModeContainer || api.Supervisor.CgroupsAvailable(),
+	}
+	if stats, err := api.Supervisor.GetServerStats(srv.ID); err == nil {
+		resp.Usage = stats.Cgroup
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
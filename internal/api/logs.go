@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"naviger/internal/domain"
+	"naviger/internal/logarchive"
+)
+
+const (
+	defaultLogPageSize = 200
+	maxLogPageSize     = 5000
+)
+
+func (api *Server) handleSearchLogs(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	srv, err := api.Manager.GetServer(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if srv == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	query, err := parseLogQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := api.LogArchive.Search(id, query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func parseLogQuery(r *http.Request) (logarchive.Query, error) {
+	values := r.URL.Query()
+	query := logarchive.Query{
+		Limit: defaultLogPageSize,
+		Tail:  values.Get("tail") == "true",
+	}
+
+	for param, target := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if raw := values.Get(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s: must be an RFC 3339 timestamp", param)
+			}
+			*target = t
+		}
+	}
+
+	if raw := values.Get("q"); raw != "" {
+		pattern, err := regexp.Compile(raw)
+		if err != nil {
+			return query, fmt.Errorf("invalid q: %w", err)
+		}
+		query.Pattern = pattern
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxLogPageSize {
+			return query, fmt.Errorf("invalid limit: must be between 1 and %d", maxLogPageSize)
+		}
+		query.Limit = limit
+	}
+
+	if raw := values.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return query, fmt.Errorf("invalid offset: must be a non-negative integer")
+		}
+		query.Offset = offset
+	}
+
+	return query, nil
+}
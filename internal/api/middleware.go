@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"strings"
@@ -99,3 +100,18 @@ func (api *Server) AuthMiddleware(next http.Handler, requiredRole string, secret
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// metricsAuth serves /metrics straight away to scrapers presenting the
+// configured metrics token and hands every other request to next.
+func (api *Server) metricsAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := api.Config.MetricsToken; token != "" {
+			auth := r.Header.Get("Authorization")
+			if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) == 1 {
+				api.Metrics.ServeHTTP(w, r)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
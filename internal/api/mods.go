@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"naviger/internal/domain"
+)
+
+func (api *Server) handleListMods(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	mods, err := api.Manager.ListMods(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mods)
+}
+
+func (api *Server) handleInstallMod(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL  string `json:"url"`
+		Name string `json:"name"`
+		SHA1 string `json:"sha1"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.URL == "" {
+		http.Error(w, "url required", http.StatusBadRequest)
+		return
+	}
+
+	mod, err := api.Manager.InstallMod(r.PathValue("id"), req.URL, req.Name, req.SHA1)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(mod)
+}
+
+func (api *Server) handleDeleteMod(w http.ResponseWriter, r *http.Request) {
+	if err := api.Manager.DeleteMod(r.PathValue("id"), r.PathValue("name")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
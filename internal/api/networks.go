@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"naviger/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func (api *Server) handleListNetworks(w http.ResponseWriter, r *http.Request) {
+	networks, err := api.NetworkManager.ListNetworks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if networks == nil {
+		networks = []domain.Network{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(networks)
+}
+
+func (api *Server) handleGetNetwork(w http.ResponseWriter, r *http.Request) {
+	network, err := api.NetworkManager.GetNetwork(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if network == nil {
+		http.Error(w, "Network not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(network)
+}
+
+func (api *Server) handleCreateNetwork(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name    string `json:"name"`
+		ProxyID string `json:"proxyId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.ProxyID == "" {
+		http.Error(w, "proxyId required", http.StatusBadRequest)
+		return
+	}
+
+	network, err := api.NetworkManager.CreateNetwork(req.Name, req.ProxyID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(network)
+}
+
+func (api *Server) handleDeleteNetwork(w http.ResponseWriter, r *http.Request) {
+	if err := api.NetworkManager.DeleteNetwork(r.PathValue("id")); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *Server) handleAttachNetworkServer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ServerID string `json:"serverId"`
+		Alias    string `json:"alias"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.ServerID == "" {
+		http.Error(w, "serverId required", http.StatusBadRequest)
+		return
+	}
+
+	warnings, err := api.NetworkManager.AttachServer(r.PathValue("id"), req.ServerID, req.Alias)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"warnings": warnings})
+}
+
+func (api *Server) handleDetachNetworkServer(w http.ResponseWriter, r *http.Request) {
+	warnings, err := api.NetworkManager.DetachServer(r.PathValue("id"), r.PathValue("serverId"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"warnings": warnings})
+}
+
+func (api *Server) handleApplyNetwork(w http.ResponseWriter, r *http.Request) {
+	warnings, err := api.NetworkManager.ApplyConfig(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"warnings": warnings})
+}
+
+func (api *Server) handleStartNetwork(w http.ResponseWriter, r *http.Request) {
+	api.runNetworkJob(w, r, "starting", api.NetworkManager.StartNetworkJob)
+}
+
+func (api *Server) handleStopNetwork(w http.ResponseWriter, r *http.Request) {
+	api.runNetworkJob(w, r, "stopping", api.NetworkManager.StopNetworkJob)
+}
+
+func (api *Server) runNetworkJob(w http.ResponseWriter, r *http.Request, status string, job func(string, chan<- domain.ProgressEvent)) {
+	id := r.PathValue("id")
+	network, err := api.NetworkManager.GetNetwork(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if network == nil {
+		http.Error(w, "Network not found", http.StatusNotFound)
+		return
+	}
+
+	requestID := r.URL.Query().Get("requestId")
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	hub := api.HubManager.GetHub(requestID)
+
+	progressChan := make(chan domain.ProgressEvent)
+	go func() {
+		for event := range progressChan {
+			jsonBytes, _ := json.Marshal(event)
+			hub.Broadcast(jsonBytes)
+		}
+	}()
+
+	job(id, progressChan)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": status,
+		"id":     requestID,
+	})
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"naviger/internal/notifications"
+)
+
+const defaultDeadLetterLimit = 100
+
+func (api *Server) handleListNotificationEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notifications.Events)
+}
+
+func (api *Server) handleListNotificationSinks(w http.ResponseWriter, r *http.Request) {
+	sinks, err := api.Notifier.ListSinks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sinks)
+}
+
+func (api *Server) handleCreateNotificationSink(w http.ResponseWriter, r *http.Request) {
+	var req notifications.SinkInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	sink, err := api.Notifier.CreateSink(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sink)
+}
+
+func (api *Server) handleUpdateNotificationSink(w http.ResponseWriter, r *http.Request) {
+	var req notifications.SinkInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	sink, err := api.Notifier.UpdateSink(r.PathValue("id"), req)
+	if errors.Is(err, notifications.ErrSinkNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sink)
+}
+
+func (api *Server) handleDeleteNotificationSink(w http.ResponseWriter, r *http.Request) {
+	if err := api.Notifier.DeleteSink(r.PathValue("id")); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleTestNotificationSink sends a test event and reports the delivery
+// error, so a misconfigured sink can be fixed right away.
+func (api *Server) handleTestNotificationSink(w http.ResponseWriter, r *http.Request) {
+	err := api.Notifier.Test(r.PathValue("id"))
+	if errors.Is(err, notifications.ErrSinkNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (api *Server) handleListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit := defaultDeadLetterLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	letters, err := api.Notifier.DeadLetters(limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(letters)
+}
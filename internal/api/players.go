@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"naviger/internal/domain"
+	"naviger/internal/players"
+)
+
+func (api *Server) canViewPlayers(w http.ResponseWriter, r *http.Request) bool {
+	if !api.checkPermission(r, r.PathValue("id"), func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func writePlayersResult(w http.ResponseWriter, result interface{}, err error) {
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, players.ErrProfileNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (api *Server) handleListWhitelist(w http.ResponseWriter, r *http.Request) {
+	if !api.canViewPlayers(w, r) {
+		return
+	}
+	entries, err := api.PlayerManager.ListWhitelist(r.PathValue("id"))
+	writePlayersResult(w, entries, err)
+}
+
+func (api *Server) handleAddWhitelist(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	result, err := api.PlayerManager.AddToWhitelist(r.PathValue("id"), req.Name)
+	writePlayersResult(w, result, err)
+}
+
+func (api *Server) handleRemoveWhitelist(w http.ResponseWriter, r *http.Request) {
+	result, err := api.PlayerManager.RemoveFromWhitelist(r.PathValue("id"), r.PathValue("player"))
+	writePlayersResult(w, result, err)
+}
+
+func (api *Server) handleListOps(w http.ResponseWriter, r *http.Request) {
+	if !api.canViewPlayers(w, r) {
+		return
+	}
+	entries, err := api.PlayerManager.ListOps(r.PathValue("id"))
+	writePlayersResult(w, entries, err)
+}
+
+func (api *Server) handleAddOp(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name                string `json:"name"`
+		Level               int    `json:"level"`
+		BypassesPlayerLimit bool   `json:"bypassesPlayerLimit"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	result, err := api.PlayerManager.AddOp(r.PathValue("id"), req.Name, req.Level, req.BypassesPlayerLimit)
+	writePlayersResult(w, result, err)
+}
+
+func (api *Server) handleRemoveOp(w http.ResponseWriter, r *http.Request) {
+	result, err := api.PlayerManager.RemoveOp(r.PathValue("id"), r.PathValue("player"))
+	writePlayersResult(w, result, err)
+}
+
+func (api *Server) handleListBans(w http.ResponseWriter, r *http.Request) {
+	if !api.canViewPlayers(w, r) {
+		return
+	}
+	entries, err := api.PlayerManager.ListBans(r.PathValue("id"))
+	writePlayersResult(w, entries, err)
+}
+
+func (api *Server) handleBanPlayer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name   string `json:"name"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	result, err := api.PlayerManager.BanPlayer(r.PathValue("id"), req.Name, req.Reason)
+	writePlayersResult(w, result, err)
+}
+
+func (api *Server) handlePardonPlayer(w http.ResponseWriter, r *http.Request) {
+	result, err := api.PlayerManager.PardonPlayer(r.PathValue("id"), r.PathValue("player"))
+	writePlayersResult(w, result, err)
+}
+
+func (api *Server) handleListIPBans(w http.ResponseWriter, r *http.Request) {
+	if !api.canViewPlayers(w, r) {
+		return
+	}
+	entries, err := api.PlayerManager.ListIPBans(r.PathValue("id"))
+	writePlayersResult(w, entries, err)
+}
+
+func (api *Server) handleBanIP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IP     string `json:"ip"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	result, err := api.PlayerManager.BanIP(r.PathValue("id"), req.IP, req.Reason)
+	writePlayersResult(w, result, err)
+}
+
+func (api *Server) handlePardonIP(w http.ResponseWriter, r *http.Request) {
+	result, err := api.PlayerManager.PardonIP(r.PathValue("id"), r.PathValue("ip"))
+	writePlayersResult(w, result, err)
+}
+
+func (api *Server) handleOnlinePlayers(w http.ResponseWriter, r *http.Request) {
+	if !api.canViewPlayers(w, r) {
+		return
+	}
+	sessions, err := api.SessionTracker.Online(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+func (api *Server) handlePlayerHistory(w http.ResponseWriter, r *http.Request) {
+	if !api.canViewPlayers(w, r) {
+		return
+	}
+	summaries, err := api.SessionTracker.Summaries(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaries)
+}
+
+func (api *Server) handlePlayerHistoryDetail(w http.ResponseWriter, r *http.Request) {
+	if !api.canViewPlayers(w, r) {
+		return
+	}
+	summary, sessions, err := api.SessionTracker.Summary(r.PathValue("id"), r.PathValue("player"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if summary == nil {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"summary":  summary,
+		"sessions": sessions,
+	})
+}
@@ -6,12 +6,23 @@ import (
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"naviger/internal/backup"
 	"naviger/internal/config"
 	"naviger/internal/domain"
+	"naviger/internal/incidents"
+	"naviger/internal/jvmflags"
 	"naviger/internal/loader"
+	"naviger/internal/logarchive"
+	"naviger/internal/metrics"
+	"naviger/internal/network"
+	"naviger/internal/notifications"
+	"naviger/internal/players"
 	"naviger/internal/runner"
+	"naviger/internal/scheduler"
 	"naviger/internal/server"
+	"naviger/internal/sessions"
+	"naviger/internal/statshistory"
 	"naviger/internal/storage"
 	"naviger/internal/updater"
 	"naviger/internal/ws"
@@ -19,15 +30,25 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 type Server struct {
-	Manager       *server.Manager
-	Supervisor    *runner.Supervisor
-	Store         *storage.GormStore
-	HubManager    *ws.HubManager
-	BackupManager *backup.Manager
-	Config        *config.Config
+	Manager        *server.Manager
+	Supervisor     *runner.Supervisor
+	Store          *storage.GormStore
+	HubManager     *ws.HubManager
+	BackupManager  *backup.Manager
+	NetworkManager *network.Manager
+	PlayerManager  *players.Manager
+	SessionTracker *sessions.Tracker
+	LogArchive     *logarchive.Archive
+	Incidents      *incidents.Manager
+	Scheduler      *scheduler.Scheduler
+	Notifier       *notifications.Notifier
+	Metrics        *metrics.Collector
+	StatsHistory   *statshistory.Recorder
+	Config         *config.Config
 }
 
 func NewAPIServer(
@@ -36,15 +57,33 @@ func NewAPIServer(
 	store *storage.GormStore,
 	hubManager *ws.HubManager,
 	backupManager *backup.Manager,
+	networkManager *network.Manager,
+	playerManager *players.Manager,
+	sessionTracker *sessions.Tracker,
+	logArchive *logarchive.Archive,
+	incidentManager *incidents.Manager,
+	taskScheduler *scheduler.Scheduler,
+	notifier *notifications.Notifier,
+	collector *metrics.Collector,
+	statsHistory *statshistory.Recorder,
 	cfg *config.Config,
 ) *Server {
 	return &Server{
-		Manager:       manager,
-		Supervisor:    supervisor,
-		Store:         store,
-		HubManager:    hubManager,
-		BackupManager: backupManager,
-		Config:        cfg,
+		Manager:        manager,
+		Supervisor:     supervisor,
+		Store:          store,
+		HubManager:     hubManager,
+		BackupManager:  backupManager,
+		NetworkManager: networkManager,
+		PlayerManager:  playerManager,
+		SessionTracker: sessionTracker,
+		LogArchive:     logArchive,
+		Incidents:      incidentManager,
+		Scheduler:      taskScheduler,
+		Notifier:       notifier,
+		Metrics:        collector,
+		StatsHistory:   statsHistory,
+		Config:         cfg,
 	}
 }
 
@@ -94,20 +133,81 @@ func (api *Server) CreateHTTPServer(listenAddr string) *http.Server {
 	}
 
 	mux.Handle("GET /auth/me", protect(api.handleMe, ""))
+	mux.Handle("GET /metrics", api.metricsAuth(protect(api.Metrics.ServeHTTP, "admin")))
 
 	mux.Handle("GET /loaders", protect(api.handleGetLoaders, ""))
 	mux.Handle("GET /loaders/{name}/versions", protect(api.handleGetLoaderVersions, ""))
+	mux.Handle("GET /jvm-profiles", protect(api.handleListJVMProfiles, ""))
 
 	mux.Handle("GET /servers", protect(api.handleListServers, ""))
 	mux.Handle("GET /servers-stats", protect(api.handleGetAllServerStats, ""))
 	mux.Handle("POST /servers", protect(api.handleCreateServer, "admin"))
+	mux.Handle("POST /servers/custom", protect(api.handleCreateCustomServer, "admin"))
+	mux.Handle("POST /servers/import-modpack", protect(api.handleImportModpack, "admin"))
 
 	mux.Handle("GET /servers/{id}", protect(api.handleGetServer, ""))
 	mux.Handle("GET /servers/{id}/stats", protect(api.handleGetServerStats, ""))
+	mux.Handle("GET /servers/{id}/stats/history", protect(api.handleGetStatsHistory, ""))
+	mux.Handle("GET /servers/{id}/command", protect(api.handlePreviewCommand, "admin"))
+	mux.Handle("GET /servers/{id}/limits", protect(api.handleGetServerLimits, ""))
+	mux.Handle("PUT /servers/{id}/limits", protect(api.handleUpdateServerLimits, "admin"))
 	mux.HandleFunc("GET /servers/{id}/icon", api.handleGetServerIcon)
 	mux.Handle("POST /servers/{id}/icon", protect(api.handleUploadServerIcon, "admin"))
 	mux.Handle("PUT /servers/{id}", protect(api.handleUpdateServer, "admin"))
 	mux.Handle("DELETE /servers/{id}", protect(api.handleDeleteServer, "admin"))
+	mux.Handle("POST /servers/{id}/clone", protect(api.handleCloneServer, "admin"))
+	mux.Handle("GET /servers/{id}/properties", protect(api.handleGetServerProperties, ""))
+	mux.Handle("PATCH /servers/{id}/properties", protect(api.handlePatchServerProperties, "admin"))
+	mux.Handle("GET /properties/schema", protect(api.handleGetPropertiesSchema, ""))
+	mux.Handle("GET /servers/{id}/mods", protect(api.handleListMods, ""))
+	mux.Handle("POST /servers/{id}/mods", protect(api.handleInstallMod, "admin"))
+	mux.Handle("DELETE /servers/{id}/mods/{name}", protect(api.handleDeleteMod, "admin"))
+	mux.Handle("GET /servers/{id}/worlds", protect(api.handleListWorlds, ""))
+	mux.Handle("POST /servers/{id}/worlds", protect(api.handleUploadWorld, "admin"))
+	mux.Handle("PUT /servers/{id}/worlds/active", protect(api.handleSetActiveWorld, "admin"))
+	mux.Handle("GET /servers/{id}/worlds/{name}/download", protect(api.handleDownloadWorld, ""))
+	mux.Handle("POST /servers/{id}/worlds/{name}/reset", protect(api.handleResetWorld, "admin"))
+	mux.Handle("POST /servers/{id}/worlds/{name}/pregenerate", protect(api.handlePregenerateWorld, "admin"))
+	mux.Handle("GET /servers/{id}/worlds/{name}/level", protect(api.handleGetLevelData, ""))
+	mux.Handle("GET /servers/{id}/worlds/{name}/chunks/{x}/{z}", protect(api.handleGetChunk, ""))
+	mux.Handle("GET /servers/{id}/worlds/{name}/playerdata", protect(api.handleListPlayerData, ""))
+	mux.Handle("GET /servers/{id}/worlds/{name}/playerdata/{uuid}", protect(api.handleGetPlayerData, ""))
+	mux.Handle("PATCH /servers/{id}/worlds/{name}/playerdata/{uuid}", protect(api.handlePatchPlayerData, "admin"))
+
+	mux.Handle("GET /servers/{id}/players/whitelist", protect(api.handleListWhitelist, ""))
+	mux.Handle("POST /servers/{id}/players/whitelist", protect(api.handleAddWhitelist, "admin"))
+	mux.Handle("DELETE /servers/{id}/players/whitelist/{player}", protect(api.handleRemoveWhitelist, "admin"))
+	mux.Handle("GET /servers/{id}/players/ops", protect(api.handleListOps, ""))
+	mux.Handle("POST /servers/{id}/players/ops", protect(api.handleAddOp, "admin"))
+	mux.Handle("DELETE /servers/{id}/players/ops/{player}", protect(api.handleRemoveOp, "admin"))
+	mux.Handle("GET /servers/{id}/players/bans", protect(api.handleListBans, ""))
+	mux.Handle("POST /servers/{id}/players/bans", protect(api.handleBanPlayer, "admin"))
+	mux.Handle("DELETE /servers/{id}/players/bans/{player}", protect(api.handlePardonPlayer, "admin"))
+	mux.Handle("GET /servers/{id}/players/ip-bans", protect(api.handleListIPBans, ""))
+	mux.Handle("POST /servers/{id}/players/ip-bans", protect(api.handleBanIP, "admin"))
+	mux.Handle("DELETE /servers/{id}/players/ip-bans/{ip}", protect(api.handlePardonIP, "admin"))
+	mux.Handle("GET /servers/{id}/logs", protect(api.handleSearchLogs, ""))
+	mux.Handle("GET /servers/{id}/players/online", protect(api.handleOnlinePlayers, ""))
+	mux.Handle("GET /servers/{id}/players/history", protect(api.handlePlayerHistory, ""))
+	mux.Handle("GET /servers/{id}/players/history/{player}", protect(api.handlePlayerHistoryDetail, ""))
+	mux.Handle("GET /servers/{id}/incidents", protect(api.handleListIncidents, ""))
+	mux.Handle("GET /servers/{id}/incidents/{incidentId}", protect(api.handleGetIncident, ""))
+	mux.Handle("GET /servers/{id}/schedules", protect(api.handleListSchedules, ""))
+	mux.Handle("POST /servers/{id}/schedules", protect(api.handleCreateSchedule, "admin"))
+	mux.Handle("DELETE /servers/{id}/schedules/{scheduleId}", protect(api.handleDeleteSchedule, "admin"))
+
+	mux.Handle("GET /notifications/events", protect(api.handleListNotificationEvents, "admin"))
+	mux.Handle("GET /notifications/sinks", protect(api.handleListNotificationSinks, "admin"))
+	mux.Handle("POST /notifications/sinks", protect(api.handleCreateNotificationSink, "admin"))
+	mux.Handle("PUT /notifications/sinks/{id}", protect(api.handleUpdateNotificationSink, "admin"))
+	mux.Handle("DELETE /notifications/sinks/{id}", protect(api.handleDeleteNotificationSink, "admin"))
+	mux.Handle("POST /notifications/sinks/{id}/test", protect(api.handleTestNotificationSink, "admin"))
+	mux.Handle("GET /notifications/dead-letters", protect(api.handleListDeadLetters, "admin"))
+
+	mux.Handle("GET /templates", protect(api.handleListTemplates, "admin"))
+	mux.Handle("POST /templates", protect(api.handleCreateTemplate, "admin"))
+	mux.Handle("GET /templates/{id}", protect(api.handleGetTemplate, "admin"))
+	mux.Handle("DELETE /templates/{id}", protect(api.handleDeleteTemplate, "admin"))
 
 	mux.Handle("GET /servers/{id}/files", protect(api.handleListFiles, ""))
 	mux.Handle("GET /servers/{id}/files/content", protect(api.handleGetFileContent, ""))
@@ -126,6 +226,17 @@ func (api *Server) CreateHTTPServer(listenAddr string) *http.Server {
 	mux.Handle("DELETE /backups/{name}", protect(api.handleDeleteBackup, "admin"))
 	mux.Handle("DELETE /backups/progress/{id}", protect(api.handleCancelBackup, "admin"))
 	mux.Handle("POST /backups/{name}/restore", protect(api.handleRestoreBackup, "admin"))
+	mux.Handle("POST /backups/{name}/convert", protect(api.handleConvertBackup, "admin"))
+
+	mux.Handle("GET /networks", protect(api.handleListNetworks, "admin"))
+	mux.Handle("POST /networks", protect(api.handleCreateNetwork, "admin"))
+	mux.Handle("GET /networks/{id}", protect(api.handleGetNetwork, "admin"))
+	mux.Handle("DELETE /networks/{id}", protect(api.handleDeleteNetwork, "admin"))
+	mux.Handle("POST /networks/{id}/servers", protect(api.handleAttachNetworkServer, "admin"))
+	mux.Handle("DELETE /networks/{id}/servers/{serverId}", protect(api.handleDetachNetworkServer, "admin"))
+	mux.Handle("POST /networks/{id}/apply", protect(api.handleApplyNetwork, "admin"))
+	mux.Handle("POST /networks/{id}/start", protect(api.handleStartNetwork, "admin"))
+	mux.Handle("POST /networks/{id}/stop", protect(api.handleStopNetwork, "admin"))
 
 	mux.Handle("GET /settings/port-range", protect(api.handleGetPortRange, "admin"))
 	mux.Handle("PUT /settings/port-range", protect(api.handleSetPortRange, "admin"))
@@ -133,6 +244,10 @@ func (api *Server) CreateHTTPServer(listenAddr string) *http.Server {
 	mux.Handle("PUT /settings/log-buffer-size", protect(api.handleSetLogBufferSize, "admin"))
 
 	mux.Handle("POST /system/restart", protect(api.handleRestartDaemon, "admin"))
+	mux.Handle("GET /system/java", protect(api.handleListJavaRuntimes, "admin"))
+	mux.Handle("POST /system/java", protect(api.handleInstallJavaRuntime, "admin"))
+	mux.Handle("POST /system/java/register", protect(api.handleRegisterJavaRuntime, "admin"))
+	mux.Handle("DELETE /system/java/{id}", protect(api.handleDeleteJavaRuntime, "admin"))
 	mux.Handle("GET /updates", protect(api.handleCheckUpdates, "admin"))
 
 	mux.Handle("GET /ws/servers/{id}/console", protect(api.handleConsole, ""))
@@ -144,10 +259,13 @@ func (api *Server) CreateHTTPServer(listenAddr string) *http.Server {
 	mux.Handle("GET /users/{id}/permissions", protect(api.handleGetPermissions, "admin"))
 	mux.Handle("DELETE /users/{id}", protect(api.handleDeleteUser, "admin"))
 	mux.Handle("PUT /users/{id}/password", protect(api.handleUpdatePassword, ""))
+	mux.Handle("GET /users/{id}/ssh-keys", protect(api.handleListSSHKeys, ""))
+	mux.Handle("POST /users/{id}/ssh-keys", protect(api.handleAddSSHKey, ""))
+	mux.Handle("DELETE /users/{id}/ssh-keys/{keyId}", protect(api.handleDeleteSSHKey, ""))
 
 	mux.Handle("POST /public-links", protect(api.handleCreatePublicLink, "admin"))
 
-	handler := api.corsMiddleware(mux)
+	handler := api.Metrics.Middleware(api.corsMiddleware(mux))
 
 	return &http.Server{
 		Addr:    listenAddr,
@@ -355,16 +473,63 @@ func (api *Server) handleUpdateServer(w http.ResponseWriter, r *http.Request) {
 		Name       *string `json:"name"`
 		RAM        *int    `json:"ram"`
 		CustomArgs *string `json:"customArgs"`
+		RunMode    *string `json:"runMode"`
+		JVMProfile *string `json:"jvmProfile"`
+		Runtime    *string `json:"javaRuntime"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	if err := api.Store.UpdateServer(id, req.Name, req.RAM, req.CustomArgs); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if req.RunMode != nil && *req.RunMode != domain.RunModeProcess && *req.RunMode != domain.RunModeContainer {
+		http.Error(w, "runMode must be process or container", http.StatusBadRequest)
 		return
 	}
+	if req.JVMProfile != nil {
+		if _, ok := jvmflags.Get(*req.JVMProfile); !ok {
+			http.Error(w, "Unknown JVM profile", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.CustomArgs != nil {
+		if _, err := jvmflags.ParseArgs(*req.CustomArgs); err != nil {
+			http.Error(w, "Invalid customArgs: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Runtime != nil && *req.Runtime != "" {
+		if _, err := api.Supervisor.JVM.Runtime(*req.Runtime); err != nil {
+			http.Error(w, "Unknown Java runtime", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.RunMode != nil {
+		if err := api.Store.UpdateServerRunMode(id, *req.RunMode); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if req.JVMProfile != nil {
+		if err := api.Store.UpdateServerJVMProfile(id, *req.JVMProfile); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if req.Runtime != nil {
+		if err := api.Store.UpdateServerJavaRuntime(id, *req.Runtime); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if req.Name != nil || req.RAM != nil || req.CustomArgs != nil || (req.RunMode == nil && req.JVMProfile == nil && req.Runtime == nil) {
+		if err := api.Store.UpdateServer(id, req.Name, req.RAM, req.CustomArgs); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -382,6 +547,7 @@ func (api *Server) handleDeleteServer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	api.HubManager.RemoveHub(id)
+	_ = api.LogArchive.Remove(id)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -437,15 +603,201 @@ func (api *Server) handleListServers(w http.ResponseWriter, r *http.Request) {
 }
 
 func (api *Server) handleCreateServer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name        string `json:"name"`
+		Version     string `json:"version"`
+		Loader      string `json:"loader"`
+		RAM         int    `json:"ram"`
+		RequestID   string `json:"requestId"`
+		JarURL      string `json:"jarUrl"`
+		JavaVersion int    `json:"javaVersion"`
+		TemplateID  string `json:"templateId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Loader == "custom" && (req.JarURL == "" || req.JavaVersion <= 0) {
+		http.Error(w, "Custom servers require jarUrl and javaVersion", http.StatusBadRequest)
+		return
+	}
+
+	progressChan := make(chan domain.ProgressEvent)
+	hubID := "progress"
+	if req.RequestID != "" {
+		hubID = req.RequestID
+	}
+	hub := api.HubManager.GetHub(hubID)
+
+	go func() {
+		for event := range progressChan {
+			if event.ServerID == "" {
+				event.ServerID = "new-server"
+			}
+			jsonBytes, _ := json.Marshal(event)
+			hub.Broadcast(jsonBytes)
+		}
+	}()
+
+	if req.TemplateID != "" {
+		api.Manager.StartCreateFromTemplateJob(req.Name, req.TemplateID, req.RAM, progressChan)
+	} else if req.Loader == "custom" {
+		version := req.Version
+		if version == "" {
+			version = "custom"
+		}
+		source := loader.NewCustomLoader(req.JarURL, "")
+		api.Manager.StartCreateCustomServerJob(req.Name, version, req.RAM, req.JavaVersion, source, progressChan)
+	} else {
+		api.Manager.StartCreateServerJob(req.Name, req.Loader, req.Version, req.RAM, progressChan)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+
+	response := map[string]string{
+		"status": "creating",
+		"id":     req.RequestID,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (api *Server) handleCreateCustomServer(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 512<<20)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		http.Error(w, "File too large", http.StatusBadRequest)
+		return
+	}
+
+	name := r.FormValue("name")
+	version := r.FormValue("version")
+	if version == "" {
+		version = "custom"
+	}
+	requestID := r.FormValue("requestId")
+
+	ram, err := strconv.Atoi(r.FormValue("ram"))
+	if err != nil || ram <= 0 {
+		http.Error(w, "Invalid ram", http.StatusBadRequest)
+		return
+	}
+	javaVersion, err := strconv.Atoi(r.FormValue("javaVersion"))
+	if err != nil || javaVersion <= 0 {
+		http.Error(w, "Invalid javaVersion", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("jar")
+	if err != nil {
+		http.Error(w, "Invalid file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	tmpFile, err := os.CreateTemp("", "naviger-upload-*.jar")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tmpPath := tmpFile.Name()
+	_, copyErr := io.Copy(tmpFile, file)
+	closeErr := tmpFile.Close()
+	if copyErr != nil || closeErr != nil {
+		os.Remove(tmpPath)
+		http.Error(w, "Failed to store uploaded jar", http.StatusInternalServerError)
+		return
+	}
+
+	progressChan := make(chan domain.ProgressEvent)
+	hubID := "progress"
+	if requestID != "" {
+		hubID = requestID
+	}
+	hub := api.HubManager.GetHub(hubID)
+
+	go func() {
+		defer os.Remove(tmpPath)
+		for event := range progressChan {
+			if event.ServerID == "" {
+				event.ServerID = "new-server"
+			}
+			jsonBytes, _ := json.Marshal(event)
+			hub.Broadcast(jsonBytes)
+		}
+	}()
+
+	source := loader.NewCustomLoader("", tmpPath)
+	api.Manager.StartCreateCustomServerJob(name, version, ram, javaVersion, source, progressChan)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+
+	response := map[string]string{
+		"status": "creating",
+		"id":     requestID,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (api *Server) handleImportModpack(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Name      string `json:"name"`
-		Version   string `json:"version"`
-		Loader    string `json:"loader"`
 		RAM       int    `json:"ram"`
+		URL       string `json:"url"`
 		RequestID string `json:"requestId"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	var archivePath string
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		if req.URL == "" {
+			http.Error(w, "url required", http.StatusBadRequest)
+			return
+		}
+	} else {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<30)
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			http.Error(w, "File too large", http.StatusBadRequest)
+			return
+		}
+		req.Name = r.FormValue("name")
+		req.URL = r.FormValue("url")
+		req.RequestID = r.FormValue("requestId")
+		req.RAM, _ = strconv.Atoi(r.FormValue("ram"))
+
+		if req.URL == "" {
+			file, _, err := r.FormFile("pack")
+			if err != nil {
+				http.Error(w, "Either a pack file or url is required", http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+
+			tmpFile, err := os.CreateTemp("", "naviger-modpack-*.zip")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			archivePath = tmpFile.Name()
+			_, copyErr := io.Copy(tmpFile, file)
+			closeErr := tmpFile.Close()
+			if copyErr != nil || closeErr != nil {
+				os.Remove(archivePath)
+				http.Error(w, "Failed to store uploaded modpack", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	if req.RAM <= 0 {
+		if archivePath != "" {
+			os.Remove(archivePath)
+		}
+		http.Error(w, "Invalid ram", http.StatusBadRequest)
 		return
 	}
 
@@ -466,7 +818,7 @@ func (api *Server) handleCreateServer(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	api.Manager.StartCreateServerJob(req.Name, req.Loader, req.Version, req.RAM, progressChan)
+	api.Manager.StartImportModpackJob(req.Name, req.RAM, archivePath, req.URL, progressChan)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
@@ -555,6 +907,7 @@ func (api *Server) handleBackupServer(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Name      string `json:"name,omitempty"`
 		RequestID string `json:"requestId"`
+		Snapshot  bool   `json:"snapshot"`
 	}
 	_ = json.NewDecoder(r.Body).Decode(&req)
 
@@ -575,7 +928,7 @@ func (api *Server) handleBackupServer(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	api.BackupManager.StartBackupJob(id, req.Name, req.RequestID, progressChan)
+	api.BackupManager.StartBackupJob(id, req.Name, req.RequestID, req.Snapshot, progressChan)
 
 	response := map[string]string{
 		"status": "creating",
@@ -683,6 +1036,45 @@ func (api *Server) handleProgress(w http.ResponseWriter, r *http.Request) {
 	hub.ServeWs(w, r)
 }
 
+// handleConvertBackup writes a zip backup of a snapshot, reporting progress
+// on the requestId hub.
+func (api *Server) handleConvertBackup(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !strings.HasSuffix(name, backup.SnapshotSuffix) {
+		http.Error(w, "Only snapshots can be converted", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		RequestID string `json:"requestId"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	progressChan := make(chan domain.ProgressEvent)
+	hubID := req.RequestID
+	if hubID == "" {
+		hubID = "backup-" + name
+	}
+	hub := api.HubManager.GetHub(hubID)
+
+	go func() {
+		for event := range progressChan {
+			jsonBytes, _ := json.Marshal(event)
+			hub.Broadcast(jsonBytes)
+		}
+	}()
+
+	api.BackupManager.StartConvertJob(name, req.RequestID, progressChan)
+
+	response := map[string]string{
+		"status": "converting",
+		"id":     req.RequestID,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
 func (api *Server) handleCancelBackup(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if id == "" {
@@ -702,7 +1094,7 @@ func (api *Server) corsMiddleware(next http.Handler) http.Handler {
 		if origin != "" {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 		}
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS, DELETE")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, OPTIONS, DELETE")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
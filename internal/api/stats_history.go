@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"naviger/internal/domain"
+)
+
+const (
+	defaultHistoryRange = time.Hour
+	// maxHistoryPoints bounds the number of buckets a single request asks
+	// for.
+	maxHistoryPoints = 5000
+)
+
+func (api *Server) handleGetStatsHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query()
+	rng := defaultHistoryRange
+	if value := query.Get("range"); value != "" {
+		d, err := parseSpan(value)
+		if err != nil {
+			http.Error(w, "invalid range", http.StatusBadRequest)
+			return
+		}
+		rng = d
+	}
+
+	step := rng / 120
+	if value := query.Get("step"); value != "" {
+		d, err := parseSpan(value)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid step", http.StatusBadRequest)
+			return
+		}
+		step = d
+	}
+	if rng/max(step, time.Second) > maxHistoryPoints {
+		http.Error(w, fmt.Sprintf("step too small, at most %d points per request", maxHistoryPoints), http.StatusBadRequest)
+		return
+	}
+
+	history, err := api.StatsHistory.History(id, rng, max(step, time.Second))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// parseSpan parses a Go duration, also accepting whole days such as "7d".
+func parseSpan(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
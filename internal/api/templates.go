@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"naviger/internal/domain"
+)
+
+func (api *Server) handleListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := api.Manager.ListTemplates()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+func (api *Server) handleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	template, err := api.Manager.GetTemplate(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if template == nil {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+func (api *Server) handleCreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ServerID string `json:"serverId"`
+		Name     string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.ServerID == "" {
+		http.Error(w, "serverId required", http.StatusBadRequest)
+		return
+	}
+
+	template, err := api.Manager.CreateTemplate(req.ServerID, req.Name, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+func (api *Server) handleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := api.Manager.DeleteTemplate(r.PathValue("id")); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *Server) handleCloneServer(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Missing ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Name          string `json:"name"`
+		IncludeWorlds bool   `json:"includeWorlds"`
+		RequestID     string `json:"requestId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	progressChan := make(chan domain.ProgressEvent)
+	hubID := "progress"
+	if req.RequestID != "" {
+		hubID = req.RequestID
+	}
+	hub := api.HubManager.GetHub(hubID)
+
+	go func() {
+		for event := range progressChan {
+			if event.ServerID == "" {
+				event.ServerID = "new-server"
+			}
+			jsonBytes, _ := json.Marshal(event)
+			hub.Broadcast(jsonBytes)
+		}
+	}()
+
+	api.Manager.StartCloneServerJob(id, req.Name, req.IncludeWorlds, progressChan)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": "creating",
+		"id":     req.RequestID,
+	})
+}
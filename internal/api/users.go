@@ -3,11 +3,14 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 
 	"naviger/internal/domain"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh"
 )
 
 func (api *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
@@ -171,3 +174,104 @@ func (api *Server) handleUpdatePassword(w http.ResponseWriter, r *http.Request)
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// sshKeyUser returns the user whose SSH keys a request manages, which only
+// admins may do for other users.
+func sshKeyUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	userCtx := r.Context().Value(UserContextKey)
+	if userCtx == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+	claims := userCtx.(map[string]string)
+	if claims["role"] != "admin" && claims["id"] != id {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return "", false
+	}
+	return id, true
+}
+
+func (api *Server) handleListSSHKeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sshKeyUser(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := api.Store.ListSSHKeys(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// handleAddSSHKey registers a public key, in authorized_keys format, for
+// signing in to the SFTP server.
+func (api *Server) handleAddSSHKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sshKeyUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name      string `json:"name"`
+		PublicKey string `json:"publicKey"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	publicKey, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(req.PublicKey))
+	if err != nil {
+		http.Error(w, "Invalid public key", http.StatusBadRequest)
+		return
+	}
+	fingerprint := ssh.FingerprintSHA256(publicKey)
+	existing, err := api.Store.GetSSHKeyByFingerprint(fingerprint)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		http.Error(w, "Key already registered", http.StatusConflict)
+		return
+	}
+
+	name := req.Name
+	if name == "" {
+		name = comment
+	}
+	key := &domain.SSHKey{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		Name:        name,
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))),
+		Fingerprint: fingerprint,
+		CreatedAt:   time.Now(),
+	}
+	if err := api.Store.AddSSHKey(key); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(key)
+}
+
+func (api *Server) handleDeleteSSHKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sshKeyUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := api.Store.DeleteSSHKey(userID, r.PathValue("keyId")); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,285 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"naviger/internal/domain"
+	"naviger/internal/server"
+)
+
+func (api *Server) handleListWorlds(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	worlds, err := api.Manager.ListWorlds(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(worlds)
+}
+
+// handleUploadWorld adds a world from a zip sent as the "file" form field,
+// named after the "name" field or the zip.
+func (api *Server) handleUploadWorld(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 4<<30)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		http.Error(w, "File too large", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Invalid file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	name := r.FormValue("name")
+	if name == "" {
+		name = filepath.Base(header.Filename)
+		name = name[:len(name)-len(filepath.Ext(name))]
+	}
+
+	world, err := api.Manager.ImportWorld(r.PathValue("id"), name, file, header.Size)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(world)
+}
+
+func (api *Server) handleSetActiveWorld(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.Manager.SetActiveWorld(r.PathValue("id"), req.Name); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (api *Server) handleDownloadWorld(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	name := r.PathValue("name")
+	if _, err := api.Manager.GetWorld(id, name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "attachment; filename="+name+".zip")
+	w.Header().Set("Content-Type", "application/zip")
+	// Once the zip has started there is no way left to report an error, the
+	// client sees a truncated download.
+	api.Manager.ExportWorld(id, name, w)
+}
+
+// handleResetWorld backs the server up and deletes its active world, which
+// is generated again with the old, a given or a random seed on the next
+// start.
+func (api *Server) handleResetWorld(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var opts server.ResetWorldOptions
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+	if opts.RandomSeed && opts.Seed != "" {
+		http.Error(w, "seed and randomSeed cannot be combined", http.StatusBadRequest)
+		return
+	}
+	if api.Supervisor.IsRunning(id) {
+		http.Error(w, "Stop the server before resetting a world", http.StatusConflict)
+		return
+	}
+	world, err := api.Manager.GetWorld(id, r.PathValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !world.Active {
+		http.Error(w, "Only the active world can be reset", http.StatusBadRequest)
+		return
+	}
+
+	backupPath, err := api.BackupManager.CreateSnapshot(r.Context(), id, "before-reset", nil)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Backup failed, world not reset: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := api.Manager.ResetWorld(id, r.PathValue("name"), opts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"backup": filepath.Base(backupPath)})
+}
+
+// handlePregenerateWorld starts pregenerating chunks with Chunky on a
+// running server.
+func (api *Server) handlePregenerateWorld(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var req struct {
+		Radius int `json:"radius"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !api.Supervisor.IsRunning(id) {
+		http.Error(w, "Server is not running", http.StatusConflict)
+		return
+	}
+
+	commands, err := api.Manager.PregenerateCommands(id, r.PathValue("name"), req.Radius)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, command := range commands {
+		if err := api.Supervisor.SendCommand(id, command); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (api *Server) handleGetLevelData(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	data, err := api.Manager.GetLevelData(id, r.PathValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+func (api *Server) handleListPlayerData(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	players, err := api.Manager.ListPlayerData(id, r.PathValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(players)
+}
+
+func (api *Server) handleGetPlayerData(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	data, err := api.Manager.GetPlayerData(id, r.PathValue("name"), r.PathValue("uuid"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+// handlePatchPlayerData edits an offline player's save with a JSON merge
+// patch. The server must be stopped, and is backed up first.
+func (api *Server) handlePatchPlayerData(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	world, player := r.PathValue("name"), r.PathValue("uuid")
+
+	var patch map[string]any
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&patch); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if api.Supervisor.IsRunning(id) {
+		http.Error(w, "Stop the server before editing player data", http.StatusConflict)
+		return
+	}
+	if _, err := api.Manager.GetPlayerData(id, world, player); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if _, err := api.BackupManager.CreateSnapshot(r.Context(), id, "before-playerdata", nil); err != nil {
+		http.Error(w, fmt.Sprintf("Backup failed, player data not changed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := api.Manager.PatchPlayerData(id, world, player, patch)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+func (api *Server) handleGetChunk(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !api.checkPermission(r, id, func(p *domain.Permission) bool { return p.CanViewConsole }) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	x, errX := strconv.Atoi(r.PathValue("x"))
+	z, errZ := strconv.Atoi(r.PathValue("z"))
+	if errX != nil || errZ != nil {
+		http.Error(w, "Invalid chunk coordinates", http.StatusBadRequest)
+		return
+	}
+
+	chunk, err := api.Manager.GetChunk(id, r.PathValue("name"), r.URL.Query().Get("dimension"), x, z)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chunk)
+}
@@ -0,0 +1,30 @@
+//go:build darwin
+
+package backup
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile creates dst as an APFS clone of src, which shares its data.
+func cloneFile(src, dst string, perm fs.FileMode) error {
+	if err := unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW); err != nil {
+		if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EXDEV) {
+			return errNoCOW
+		}
+		return err
+	}
+	return os.Chmod(dst, perm)
+}
+
+func snapshotSubvolume(src, dst string) bool {
+	return false
+}
+
+func removeTree(path string) error {
+	return os.RemoveAll(path)
+}
@@ -0,0 +1,86 @@
+//go:build linux
+
+package backup
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+
+	"golang.org/x/sys/unix"
+)
+
+// btrfsSubvolumeIno is the inode number of the root of every btrfs
+// subvolume.
+const btrfsSubvolumeIno = 256
+
+// cloneFile creates dst sharing the data of src through the FICLONE ioctl,
+// which btrfs, XFS with reflink, bcachefs and ZFS with block cloning
+// support.
+func cloneFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if err := unix.IoctlFileClone(int(out.Fd()), int(in.Fd())); err != nil {
+		out.Close()
+		os.Remove(dst)
+		if errors.Is(err, unix.EXDEV) || errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EINVAL) ||
+			errors.Is(err, unix.ENOTTY) || errors.Is(err, unix.ENOSYS) {
+			return errNoCOW
+		}
+		return err
+	}
+	return out.Close()
+}
+
+// snapshotSubvolume takes a read-only btrfs snapshot of src at dst when src
+// is a subvolume and the btrfs tool is installed, and reports false
+// otherwise.
+func snapshotSubvolume(src, dst string) bool {
+	if !isSubvolume(src) {
+		return false
+	}
+	btrfs, err := exec.LookPath("btrfs")
+	if err != nil {
+		return false
+	}
+
+	// Snapshots only work within one filesystem, in which case reflinks are
+	// tried next and fail the same way.
+	if err := exec.Command(btrfs, "subvolume", "snapshot", "-r", src, dst).Run(); err != nil {
+		return false
+	}
+	return true
+}
+
+func isSubvolume(path string) bool {
+	var fsStat unix.Statfs_t
+	if err := unix.Statfs(path, &fsStat); err != nil || fsStat.Type != unix.BTRFS_SUPER_MAGIC {
+		return false
+	}
+	var stat unix.Stat_t
+	if err := unix.Stat(path, &stat); err != nil {
+		return false
+	}
+	return stat.Ino == btrfsSubvolumeIno
+}
+
+// removeTree deletes a snapshot, with the btrfs tool for subvolume
+// snapshots which are read-only.
+func removeTree(path string) error {
+	if isSubvolume(path) {
+		if btrfs, err := exec.LookPath("btrfs"); err == nil {
+			return exec.Command(btrfs, "subvolume", "delete", path).Run()
+		}
+	}
+	return os.RemoveAll(path)
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin
+
+package backup
+
+import (
+	"io/fs"
+	"os"
+)
+
+func cloneFile(src, dst string, perm fs.FileMode) error {
+	return errNoCOW
+}
+
+func snapshotSubvolume(src, dst string) bool {
+	return false
+}
+
+func removeTree(path string) error {
+	return os.RemoveAll(path)
+}
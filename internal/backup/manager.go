@@ -3,9 +3,11 @@ package backup
 import (
 	"archive/zip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"naviger/internal/domain"
+	"naviger/internal/properties"
 	"naviger/internal/server"
 	"naviger/internal/storage"
 	"os"
@@ -22,6 +24,9 @@ type Manager struct {
 	ServersPath string
 	BackupsPath string
 	Store       *storage.GormStore
+	// OnBackup, when set, is told how every backup job ended. Cancelled jobs
+	// are not reported.
+	OnBackup func(serverID string, result JobResult)
 
 	activeBackups   map[string]context.CancelFunc
 	activeBackupsMu sync.Mutex
@@ -36,9 +41,19 @@ func NewManager(serversPath, backupsPath string, store *storage.GormStore) *Mana
 	}
 }
 
+// JobResult describes a finished backup job. Name and Size are only set
+// when the backup succeeded.
+type JobResult struct {
+	Name     string
+	Size     int64
+	Duration time.Duration
+	Err      error
+}
+
 type BackupInfo struct {
 	Name string `json:"name"`
 	Size int64  `json:"size"`
+	Kind string `json:"kind"`
 }
 
 func (m *Manager) DeleteBackup(name string) error {
@@ -46,9 +61,13 @@ func (m *Manager) DeleteBackup(name string) error {
 		return fmt.Errorf("invalid backup name")
 	}
 	backupPath := filepath.Join(m.BackupsPath, name)
-	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+	info, err := os.Stat(backupPath)
+	if os.IsNotExist(err) {
 		return fmt.Errorf("backup not found")
 	}
+	if err == nil && info.IsDir() {
+		return removeTree(backupPath)
+	}
 	return os.Remove(backupPath)
 }
 
@@ -60,18 +79,9 @@ func (m *Manager) ListAllBackups() ([]BackupInfo, error) {
 
 	var backups []BackupInfo
 	for _, file := range files {
-		if file.IsDir() || strings.HasSuffix(file.Name(), ".temp") {
-			continue
+		if backup, ok := m.backupInfo(file); ok {
+			backups = append(backups, backup)
 		}
-
-		info, err := file.Info()
-		if err != nil {
-			continue
-		}
-		backups = append(backups, BackupInfo{
-			Name: file.Name(),
-			Size: info.Size(),
-		})
 	}
 
 	return backups, nil
@@ -95,26 +105,73 @@ func (m *Manager) ListBackups(serverID string) ([]BackupInfo, error) {
 
 	var backups []BackupInfo
 	for _, file := range files {
-		if file.IsDir() || strings.HasSuffix(file.Name(), ".temp") {
+		if !strings.HasPrefix(file.Name(), safeName) {
 			continue
 		}
-
-		if strings.HasPrefix(file.Name(), safeName) {
-			info, err := file.Info()
-			if err != nil {
-				continue
-			}
-			backups = append(backups, BackupInfo{
-				Name: file.Name(),
-				Size: info.Size(),
-			})
+		if backup, ok := m.backupInfo(file); ok {
+			backups = append(backups, backup)
 		}
 	}
 
 	return backups, nil
 }
 
-func (m *Manager) StartBackupJob(serverID, backupName, requestID string, progressChan chan<- domain.ProgressEvent) {
+// backupInfo describes a zip backup or a snapshot found in the backups
+// folder, and reports false for anything else.
+func (m *Manager) backupInfo(file os.DirEntry) (BackupInfo, bool) {
+	name := file.Name()
+	if strings.HasSuffix(name, ".temp") {
+		return BackupInfo{}, false
+	}
+
+	if file.IsDir() {
+		if !strings.HasSuffix(name, SnapshotSuffix) {
+			return BackupInfo{}, false
+		}
+		return BackupInfo{Name: name, Size: treeSize(filepath.Join(m.BackupsPath, name)), Kind: KindSnapshot}, true
+	}
+
+	info, err := file.Info()
+	if err != nil {
+		return BackupInfo{}, false
+	}
+	return BackupInfo{Name: name, Size: info.Size(), Kind: KindZip}, true
+}
+
+// StartBackupJob creates a backup in the background. With snapshot set it
+// takes a copy-on-write snapshot, falling back to a zip backup where the
+// filesystem cannot.
+func (m *Manager) StartBackupJob(serverID, backupName, requestID string, snapshot bool, progressChan chan<- domain.ProgressEvent) {
+	create := m.CreateBackup
+	if snapshot {
+		create = m.CreateSnapshot
+	}
+
+	m.startJob(requestID, serverID, "Backup created successfully", progressChan, func(ctx context.Context) (string, error) {
+		start := time.Now()
+		path, err := create(ctx, serverID, backupName, progressChan)
+		if m.OnBackup != nil && !errors.Is(err, context.Canceled) {
+			result := JobResult{Duration: time.Since(start), Err: err}
+			if err == nil {
+				result.Name = filepath.Base(path)
+				result.Size = treeSize(path)
+			}
+			m.OnBackup(serverID, result)
+		}
+		return path, err
+	})
+}
+
+// StartConvertJob writes a zip backup of a snapshot in the background.
+func (m *Manager) StartConvertJob(name, requestID string, progressChan chan<- domain.ProgressEvent) {
+	m.startJob(requestID, "", "Snapshot converted successfully", progressChan, func(ctx context.Context) (string, error) {
+		return m.ConvertSnapshot(ctx, name, progressChan)
+	})
+}
+
+// startJob runs job with a context CancelBackup can cancel through
+// requestID, reports its outcome on progressChan and closes it.
+func (m *Manager) startJob(requestID, serverID, done string, progressChan chan<- domain.ProgressEvent, job func(ctx context.Context) (string, error)) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	m.activeBackupsMu.Lock()
@@ -129,8 +186,7 @@ func (m *Manager) StartBackupJob(serverID, backupName, requestID string, progres
 			m.activeBackupsMu.Unlock()
 		}()
 
-		_, err := m.CreateBackup(ctx, serverID, backupName, progressChan)
-		if err != nil {
+		if _, err := job(ctx); err != nil {
 			event := domain.ProgressEvent{
 				ServerID: serverID,
 				Message:  fmt.Sprintf("Error: %v", err),
@@ -142,7 +198,7 @@ func (m *Manager) StartBackupJob(serverID, backupName, requestID string, progres
 
 		event := domain.ProgressEvent{
 			ServerID: serverID,
-			Message:  "Backup created successfully",
+			Message:  done,
 			Progress: 100,
 		}
 		progressChan <- event
@@ -163,12 +219,27 @@ func (m *Manager) CancelBackup(requestID string) {
 }
 
 func (m *Manager) CreateBackup(ctx context.Context, serverID string, backupName string, progressChan chan<- domain.ProgressEvent) (string, error) {
+	backupFilePath, serverDir, err := m.newBackupPath(serverID, backupName, ".zip")
+	if err != nil {
+		return "", err
+	}
+
+	if err := zipDir(ctx, serverDir, backupFilePath, progressChan); err != nil {
+		return "", err
+	}
+	return backupFilePath, nil
+}
+
+// newBackupPath returns where a new backup of a server goes, named after
+// backupName or the server and the current time, along with the server's
+// folder.
+func (m *Manager) newBackupPath(serverID, backupName, suffix string) (string, string, error) {
 	srv, err := m.Store.GetServerByID(serverID)
 	if err != nil {
-		return "", fmt.Errorf("could not get server info: %w", err)
+		return "", "", fmt.Errorf("could not get server info: %w", err)
 	}
 	if srv == nil {
-		return "", fmt.Errorf("server with ID '%s' not found in database", serverID)
+		return "", "", fmt.Errorf("server with ID '%s' not found in database", serverID)
 	}
 
 	folderName := srv.FolderName
@@ -178,25 +249,29 @@ func (m *Manager) CreateBackup(ctx context.Context, serverID string, backupName
 	serverDir := filepath.Join(m.ServersPath, folderName)
 
 	if _, err := os.Stat(serverDir); os.IsNotExist(err) {
-		return "", fmt.Errorf("server directory for ID '%s' does not exist", serverID)
+		return "", "", fmt.Errorf("server directory for ID '%s' does not exist", serverID)
 	}
 
 	if backupName == "" {
 		backupName = srv.Name
 	}
 
+	if err := os.MkdirAll(m.BackupsPath, 0755); err != nil {
+		return "", "", fmt.Errorf("could not create backups directory: %w", err)
+	}
+
 	safeName := sanitizeFileName(backupName)
 	timestamp := time.Now().Format("20060102-150405")
-	backupFileName := fmt.Sprintf("%s-%s.zip", safeName, timestamp)
-	backupFilePath := filepath.Join(m.BackupsPath, backupFileName)
-	tempBackupFilePath := backupFilePath + ".temp"
+	return filepath.Join(m.BackupsPath, fmt.Sprintf("%s-%s%s", safeName, timestamp, suffix)), serverDir, nil
+}
 
-	if err := os.MkdirAll(m.BackupsPath, 0755); err != nil {
-		return "", fmt.Errorf("could not create backups directory: %w", err)
-	}
+// zipDir writes the contents of srcDir to a zip file at backupFilePath,
+// reporting progress by bytes written.
+func zipDir(ctx context.Context, srcDir, backupFilePath string, progressChan chan<- domain.ProgressEvent) error {
+	tempBackupFilePath := backupFilePath + ".temp"
 
 	var totalSize int64
-	filepath.Walk(serverDir, func(path string, info os.FileInfo, err error) error {
+	filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
 		if err == nil && !info.IsDir() {
 			totalSize += info.Size()
 		}
@@ -205,7 +280,7 @@ func (m *Manager) CreateBackup(ctx context.Context, serverID string, backupName
 
 	backupFile, err := os.Create(tempBackupFilePath)
 	if err != nil {
-		return "", fmt.Errorf("could not create backup file: %w", err)
+		return fmt.Errorf("could not create backup file: %w", err)
 	}
 
 	zipWriter := zip.NewWriter(backupFile)
@@ -213,7 +288,7 @@ func (m *Manager) CreateBackup(ctx context.Context, serverID string, backupName
 	var processedSize int64
 	var lastProgress int
 
-	err = filepath.Walk(serverDir, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -224,12 +299,13 @@ func (m *Manager) CreateBackup(ctx context.Context, serverID string, backupName
 		default:
 		}
 
-		relPath, err := filepath.Rel(serverDir, path)
+		relPath, err := filepath.Rel(srcDir, path)
 		if err != nil {
 			return err
 		}
 
-		if relPath == "." {
+		// Links could point anywhere once extracted, so they are left out.
+		if relPath == "." || info.Mode()&os.ModeSymlink != 0 {
 			return nil
 		}
 
@@ -286,23 +362,25 @@ func (m *Manager) CreateBackup(ctx context.Context, serverID string, backupName
 	if err != nil || zipErr != nil || fileErr != nil {
 		os.Remove(tempBackupFilePath)
 		if err != nil {
-			return "", fmt.Errorf("error creating backup: %w", err)
+			return fmt.Errorf("error creating backup: %w", err)
 		}
-		return "", fmt.Errorf("error closing files: %v, %v", zipErr, fileErr)
+		return fmt.Errorf("error closing files: %v, %v", zipErr, fileErr)
 	}
 
 	if err := os.Rename(tempBackupFilePath, backupFilePath); err != nil {
-		return "", fmt.Errorf("error renaming temp file: %w", err)
+		return fmt.Errorf("error renaming temp file: %w", err)
 	}
 
-	return backupFilePath, nil
+	return nil
 }
 
 func (m *Manager) RestoreBackup(backupName string, targetServerID string, newServerName string, newServerRAM int, newServerLoader, newServerVersion string) error {
 	backupPath := filepath.Join(m.BackupsPath, backupName)
-	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+	backupInfo, err := os.Stat(backupPath)
+	if os.IsNotExist(err) {
 		return fmt.Errorf("backup not found")
 	}
+	snapshot := err == nil && backupInfo.IsDir()
 
 	var targetDir string
 	var targetPort int
@@ -379,11 +457,15 @@ func (m *Manager) RestoreBackup(backupName string, targetServerID string, newSer
 		}
 	}
 
-	if err := unzip(backupPath, targetDir); err != nil {
+	if snapshot {
+		if err := copyTree(context.Background(), backupPath, targetDir, false); err != nil {
+			return fmt.Errorf("failed to copy snapshot: %w", err)
+		}
+	} else if err := unzip(backupPath, targetDir); err != nil {
 		return fmt.Errorf("failed to unzip backup: %w", err)
 	}
 
-	if err := server.UpdateServerProperties(targetDir, targetPort); err != nil {
+	if err := properties.SetPort(targetDir, targetPort); err != nil {
 		return fmt.Errorf("failed to update server properties: %w", err)
 	}
 
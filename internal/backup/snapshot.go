@@ -0,0 +1,180 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"naviger/internal/domain"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of backups.
+const (
+	KindZip      = "zip"
+	KindSnapshot = "snapshot"
+)
+
+// SnapshotSuffix ends the name of snapshot folders in the backups folder.
+const SnapshotSuffix = ".snap"
+
+// errNoCOW is returned when a filesystem cannot share data between files, or
+// the servers and backups folders are on different filesystems.
+var errNoCOW = errors.New("copy-on-write copies are not supported")
+
+// CreateSnapshot takes a copy-on-write snapshot of a server folder: a btrfs
+// subvolume snapshot when the folder is a subvolume, otherwise reflinked
+// copies of its files. The snapshot only takes space as the server changes
+// its files. Where neither works it creates a zip backup instead.
+func (m *Manager) CreateSnapshot(ctx context.Context, serverID string, backupName string, progressChan chan<- domain.ProgressEvent) (string, error) {
+	snapshotPath, serverDir, err := m.newBackupPath(serverID, backupName, SnapshotSuffix)
+	if err != nil {
+		return "", err
+	}
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: "Creating snapshot..."}
+	}
+
+	method, err := cloneTree(ctx, serverDir, snapshotPath)
+	if errors.Is(err, errNoCOW) {
+		if progressChan != nil {
+			progressChan <- domain.ProgressEvent{Message: "Snapshots are not supported here, creating a zip backup"}
+		}
+		return m.CreateBackup(ctx, serverID, backupName, progressChan)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error creating snapshot: %w", err)
+	}
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: fmt.Sprintf("Snapshot created (%s)", method), Progress: 100}
+	}
+	return snapshotPath, nil
+}
+
+// ConvertSnapshot writes a zip backup with the contents of a snapshot next
+// to it, for moving it to another machine. The snapshot is kept.
+func (m *Manager) ConvertSnapshot(ctx context.Context, name string, progressChan chan<- domain.ProgressEvent) (string, error) {
+	if strings.Contains(name, "..") || !strings.HasSuffix(name, SnapshotSuffix) {
+		return "", fmt.Errorf("invalid snapshot name")
+	}
+	snapshotPath := filepath.Join(m.BackupsPath, name)
+	if info, err := os.Stat(snapshotPath); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("snapshot not found")
+	}
+
+	zipPath := filepath.Join(m.BackupsPath, strings.TrimSuffix(name, SnapshotSuffix)+".zip")
+	if _, err := os.Stat(zipPath); err == nil {
+		return "", fmt.Errorf("backup %s already exists", filepath.Base(zipPath))
+	}
+
+	if err := zipDir(ctx, snapshotPath, zipPath, progressChan); err != nil {
+		return "", err
+	}
+	return zipPath, nil
+}
+
+// cloneTree makes dst a copy-on-write copy of src and names the method
+// used. It fails with errNoCOW, leaving nothing behind, when the
+// filesystem cannot.
+func cloneTree(ctx context.Context, src, dst string) (string, error) {
+	if snapshotSubvolume(src, dst) {
+		return "btrfs subvolume snapshot", nil
+	}
+
+	tempPath := dst + ".temp"
+	if err := copyTree(ctx, src, tempPath, true); err != nil {
+		removeTree(tempPath)
+		return "", err
+	}
+	if err := os.Rename(tempPath, dst); err != nil {
+		removeTree(tempPath)
+		return "", err
+	}
+	return "reflink", nil
+}
+
+// copyTree copies the contents of src into dst, cloning files where the
+// filesystem can. With cloneOnly set it fails with errNoCOW instead of
+// copying data.
+func copyTree(ctx context.Context, src, dst string, cloneOnly bool) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			err := cloneFile(path, target, info.Mode().Perm())
+			if errors.Is(err, errNoCOW) && !cloneOnly {
+				err = copyFile(path, target, info.Mode().Perm())
+			}
+			if err != nil {
+				return err
+			}
+			return os.Chtimes(target, info.ModTime(), info.ModTime())
+		}
+		// Sockets and other special files are not part of a server.
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// treeSize is the apparent size of a file or of the files in a folder. The
+// files of a snapshot share their data with the server until it changes
+// them, so this is the most a snapshot can take.
+func treeSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,58 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyTree(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "world", "region"), 0755)
+	os.WriteFile(filepath.Join(src, "world", "region", "r.0.0.mca"), []byte("region"), 0644)
+	os.WriteFile(filepath.Join(src, "server.properties"), []byte("server-port=25565\n"), 0600)
+	if err := os.Symlink("world", filepath.Join(src, "current")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "copy")
+	if err := copyTree(context.Background(), src, dst, false); err != nil {
+		t.Fatalf("copyTree: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dst, "world", "region", "r.0.0.mca"))
+	if err != nil || string(data) != "region" {
+		t.Errorf("region file = %q, %v", data, err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "server.properties"))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("server.properties mode = %v, %v", info.Mode(), err)
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "current")); err != nil || link != "world" {
+		t.Errorf("link = %q, %v", link, err)
+	}
+	if size := treeSize(dst); size != treeSize(src) || size != int64(len("region")+len("server-port=25565\n")) {
+		t.Errorf("treeSize = %d, source %d", size, treeSize(src))
+	}
+}
+
+func TestCloneTreeLeavesNothingWithoutCOW(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "level.dat"), []byte("level"), 0644)
+
+	dst := filepath.Join(t.TempDir(), "server.snap")
+	_, err := cloneTree(context.Background(), src, dst)
+	if err != nil {
+		if _, statErr := os.Stat(dst); !os.IsNotExist(statErr) {
+			t.Errorf("failed clone left %s behind", dst)
+		}
+		if _, statErr := os.Stat(dst + ".temp"); !os.IsNotExist(statErr) {
+			t.Errorf("failed clone left %s.temp behind", dst)
+		}
+		return
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "level.dat")); err != nil || string(data) != "level" {
+		t.Errorf("cloned level.dat = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"naviger/internal/cli/spec"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyFile   string
+	applyDryRun bool
+	applyPrune  bool
+
+	exportFile   string
+	exportFormat string
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create, update or delete servers to match a YAML/JSON spec",
+	Run: func(cmd *cobra.Command, args []string) {
+		if applyFile == "" {
+			log.Fatal("Error: You must specify a spec file with -f")
+		}
+		handleApply(applyFile, applyDryRun, applyPrune)
+	},
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the current servers as a spec",
+	Run: func(cmd *cobra.Command, args []string) {
+		handleExport(exportFile, exportFormat)
+	},
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "", "Spec file (.yaml, .yml or .json)")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Only print the plan")
+	applyCmd.Flags().BoolVar(&applyPrune, "prune", false, "Delete servers that are not in the spec")
+
+	exportCmd.Flags().StringVarP(&exportFile, "output", "o", "", "Output file (defaults to stdout)")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "Output format: yaml or json (defaults to the output file extension, or yaml)")
+
+	RootCmd.AddCommand(applyCmd, exportCmd)
+}
+
+func handleApply(file string, dryRun, prune bool) {
+	s, err := spec.Load(file)
+	if err != nil {
+		log.Fatalf("Error loading spec: %v", err)
+	}
+
+	plan, err := spec.BuildPlan(Client, s, prune)
+	if err != nil {
+		log.Fatalf("Error building plan: %v", err)
+	}
+
+	plan.Print(os.Stdout)
+	if dryRun || plan.Empty() {
+		return
+	}
+
+	if err := spec.Apply(Client, plan, os.Stdout); err != nil {
+		log.Fatalf("Error applying spec: %v", err)
+	}
+	fmt.Println("Spec applied successfully.")
+}
+
+func handleExport(file, format string) {
+	if format == "" && strings.EqualFold(filepath.Ext(file), ".json") {
+		format = "json"
+	}
+
+	s, err := spec.Export(Client)
+	if err != nil {
+		log.Fatalf("Error exporting servers: %v", err)
+	}
+
+	out := os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			log.Fatalf("Error creating %s: %v", file, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := spec.Write(out, s, format); err != nil {
+		log.Fatalf("Error writing spec: %v", err)
+	}
+}
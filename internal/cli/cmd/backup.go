@@ -55,6 +55,17 @@ var backupDeleteCmd = &cobra.Command{
 	},
 }
 
+var backupConvertCmd = &cobra.Command{
+	Use:   "convert [name]",
+	Short: "Write a zip backup of a snapshot",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		handleConvertBackup(args[0])
+	},
+}
+
+var backupSnapshot bool
+
 var restoreTarget, restoreName, restoreVer, restoreLoader string
 var restoreRam int
 var restoreNew bool
@@ -69,6 +80,8 @@ var backupRestoreCmd = &cobra.Command{
 }
 
 func init() {
+	backupCreateCmd.Flags().BoolVar(&backupSnapshot, "snapshot", false, "Take a copy-on-write snapshot where the filesystem supports it")
+
 	backupRestoreCmd.Flags().StringVar(&restoreTarget, "target", "", "Target server ID (to restore to existing)")
 	backupRestoreCmd.Flags().BoolVar(&restoreNew, "new", false, "Create new server from backup")
 	backupRestoreCmd.Flags().StringVar(&restoreName, "name", "", "New server name")
@@ -76,11 +89,19 @@ func init() {
 	backupRestoreCmd.Flags().StringVar(&restoreLoader, "loader", "vanilla", "New server loader")
 	backupRestoreCmd.Flags().IntVar(&restoreRam, "ram", 2048, "New server RAM")
 
-	backupCmd.AddCommand(backupCreateCmd, backupListCmd, backupDeleteCmd, backupRestoreCmd)
+	backupCmd.AddCommand(backupCreateCmd, backupListCmd, backupDeleteCmd, backupRestoreCmd, backupConvertCmd)
 	RootCmd.AddCommand(backupCmd)
 }
 
 func handleBackupCreate(serverID, name string) {
+	if backupSnapshot {
+		if err := Client.CreateSnapshot(serverID, name); err != nil {
+			log.Fatalf("Error creating snapshot: %v", err)
+		}
+		fmt.Println("Snapshot started.")
+		return
+	}
+
 	resp, err := Client.CreateBackup(serverID, name)
 	if err != nil {
 		log.Fatalf("Error creating backup: %v", err)
@@ -108,10 +129,21 @@ func handleListAllBackups() {
 func printBackups(backups []sdk.BackupInfo) {
 	fmt.Println("Backups:")
 	for _, b := range backups {
+		if b.Kind == "snapshot" {
+			fmt.Printf("- %s (snapshot of %.2f MB)\n", b.Name, float64(b.Size)/1024/1024)
+			continue
+		}
 		fmt.Printf("- %s (%.2f MB)\n", b.Name, float64(b.Size)/1024/1024)
 	}
 }
 
+func handleConvertBackup(name string) {
+	if err := Client.ConvertBackup(name, ""); err != nil {
+		log.Fatalf("Error converting snapshot: %v", err)
+	}
+	fmt.Println("Conversion started.")
+}
+
 func handleDeleteBackup(name string) {
 	if err := Client.DeleteBackup(name); err != nil {
 		log.Fatalf("Error deleting backup: %v", err)
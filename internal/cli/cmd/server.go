@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"naviger/internal/cli/ui"
+	"naviger/pkg/sdk"
 
 	"github.com/spf13/cobra"
 )
@@ -51,8 +52,53 @@ var serverStopCmd = &cobra.Command{
 	},
 }
 
+var (
+	limitMemoryMax int
+	limitCPUWeight int
+	limitCPUQuota  int
+	limitPidsMax   int
+	limitIOWeight  int
+)
+
+var serverLimitsCmd = &cobra.Command{
+	Use:   "limits [id]",
+	Short: "Show or change the resource limits of a server (Linux)",
+	Long: `Show or change the cgroup limits a server runs under on Linux.
+Changes apply on the next start. 0 restores the default and -1 removes a limit.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		handleServerLimits(cmd, args[0])
+	},
+}
+
+var commandProfile string
+
+var serverCommandCmd = &cobra.Command{
+	Use:   "command [id]",
+	Short: "Show the command line a server starts with",
+	Long: `Show the full command line a server would start with, JVM profile and
+custom arguments included. --profile switches the server to another JVM
+profile first; run without an id to list the profiles.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			handleListJVMProfiles()
+			return
+		}
+		handleServerCommand(args[0])
+	},
+}
+
 func init() {
-	serverCmd.AddCommand(serverDeleteCmd, serverStartCmd, serverStopCmd)
+	serverCommandCmd.Flags().StringVar(&commandProfile, "profile", "", "JVM profile to switch the server to")
+
+	serverLimitsCmd.Flags().IntVar(&limitMemoryMax, "memory-max", 0, "Memory limit in MB, heap included")
+	serverLimitsCmd.Flags().IntVar(&limitCPUWeight, "cpu-weight", 0, "CPU weight from 1 to 10000")
+	serverLimitsCmd.Flags().IntVar(&limitCPUQuota, "cpu-quota", 0, "CPU quota in percent of one core")
+	serverLimitsCmd.Flags().IntVar(&limitPidsMax, "pids-max", 0, "Maximum number of processes and threads")
+	serverLimitsCmd.Flags().IntVar(&limitIOWeight, "io-weight", 0, "IO weight from 1 to 10000")
+
+	serverCmd.AddCommand(serverDeleteCmd, serverStartCmd, serverStopCmd, serverLimitsCmd, serverCommandCmd)
 	RootCmd.AddCommand(serverCmd)
 }
 
@@ -70,9 +116,93 @@ func handleStartServer(id string) {
 	fmt.Printf("Start command sent to server %s.\n", id)
 }
 
+func handleServerLimits(cmd *cobra.Command, id string) {
+	current, err := Client.GetServerLimits(id)
+	if err != nil {
+		log.Fatalf("Error getting limits: %v", err)
+	}
+
+	if cmd.Flags().NFlag() > 0 {
+		limits := current.Limits
+		flags := cmd.Flags()
+		if flags.Changed("memory-max") {
+			limits.MemoryMaxMB = limitMemoryMax
+		}
+		if flags.Changed("cpu-weight") {
+			limits.CPUWeight = limitCPUWeight
+		}
+		if flags.Changed("cpu-quota") {
+			limits.CPUQuota = limitCPUQuota
+		}
+		if flags.Changed("pids-max") {
+			limits.PidsMax = limitPidsMax
+		}
+		if flags.Changed("io-weight") {
+			limits.IOWeight = limitIOWeight
+		}
+		if err := Client.UpdateServerLimits(id, limits); err != nil {
+			log.Fatalf("Error updating limits: %v", err)
+		}
+		if current, err = Client.GetServerLimits(id); err != nil {
+			log.Fatalf("Error getting limits: %v", err)
+		}
+		fmt.Println("Limits updated, they apply on the next start.")
+	}
+
+	limit := func(value int, unit string) string {
+		if value < 0 {
+			return "unlimited"
+		}
+		return fmt.Sprintf("%d%s", value, unit)
+	}
+	e := current.Effective
+	quota := "none"
+	if e.CPUQuota > 0 {
+		quota = fmt.Sprintf("%d%%", e.CPUQuota)
+	}
+	fmt.Printf("Memory max: %s\nCPU weight: %d\nCPU quota:  %s\nPids max:   %s\nIO weight:  %d\n",
+		limit(e.MemoryMaxMB, " MB"), e.CPUWeight, quota, limit(e.PidsMax, ""), e.IOWeight)
+	if !current.Enforced {
+		fmt.Println("Not enforced: the daemon host has no delegated cgroup v2.")
+	}
+	if u := current.Usage; u != nil {
+		fmt.Printf("Running: %d MB used, %d processes, limit hit %d times, throttled %.1fs, %d OOM kills\n",
+			u.MemoryCurrent/1024/1024, u.PidsCurrent, u.MemoryMaxHits, u.ThrottledSeconds, u.OOMKills)
+	}
+}
+
 func handleStopServer(id string) {
 	if err := Client.StopServer(id); err != nil {
 		log.Fatalf("Error stopping server: %v", err)
 	}
 	fmt.Printf("Stop command sent to server %s.\n", id)
 }
+
+func handleListJVMProfiles() {
+	profiles, err := Client.ListJVMProfiles()
+	if err != nil {
+		log.Fatalf("Error listing JVM profiles: %v", err)
+	}
+	for _, p := range profiles {
+		minJava := ""
+		if p.MinJava > 0 {
+			minJava = fmt.Sprintf(" (Java %d+)", p.MinJava)
+		}
+		fmt.Printf("%-12s %s%s\n", p.Name, p.Description, minJava)
+	}
+}
+
+func handleServerCommand(id string) {
+	if commandProfile != "" {
+		if err := Client.UpdateServer(id, sdk.UpdateServerRequest{JVMProfile: &commandProfile}); err != nil {
+			log.Fatalf("Error setting JVM profile: %v", err)
+		}
+		fmt.Printf("Server %s now uses the %s JVM profile.\n", id, commandProfile)
+	}
+
+	preview, err := Client.PreviewServerCommand(id)
+	if err != nil {
+		log.Fatalf("Error previewing command: %v", err)
+	}
+	fmt.Printf("Run mode: %s, JVM profile: %s, Java %d\n%s\n", preview.RunMode, preview.JVMProfile, preview.JavaVersion, preview.CommandLine)
+}
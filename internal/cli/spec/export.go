@@ -0,0 +1,51 @@
+package spec
+
+import (
+	"fmt"
+	"naviger/pkg/sdk"
+)
+
+// Export builds a spec describing the daemon's current servers. Mods are
+// listed by name and hash only, since their download URLs are not known.
+func Export(client *sdk.Client) (*Spec, error) {
+	servers, err := client.ListServers()
+	if err != nil {
+		return nil, fmt.Errorf("error listing servers: %w", err)
+	}
+
+	s := &Spec{Servers: []ServerSpec{}}
+	for _, srv := range servers {
+		view, err := client.GetServerProperties(srv.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error reading properties of %s: %w", srv.Name, err)
+		}
+		props := view.Values()
+		delete(props, "server-port")
+
+		entry := ServerSpec{
+			Name:       srv.Name,
+			Loader:     srv.Loader,
+			Version:    srv.Version,
+			RAM:        srv.RAM,
+			CustomArgs: srv.CustomArgs,
+		}
+		if len(props) > 0 {
+			entry.Properties = props
+		}
+
+		if mods, err := client.ListMods(srv.ID); err == nil {
+			for _, mod := range mods {
+				entry.Mods = append(entry.Mods, ModSpec{Name: mod.Name, SHA1: mod.SHA1})
+			}
+		}
+
+		if schedules, err := client.ListSchedules(srv.ID); err == nil {
+			for _, schedule := range schedules {
+				entry.Schedules = append(entry.Schedules, ScheduleSpec{Cron: schedule.Cron, Action: schedule.Action, Command: schedule.Command})
+			}
+		}
+
+		s.Servers = append(s.Servers, entry)
+	}
+	return s, nil
+}
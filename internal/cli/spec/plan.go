@@ -0,0 +1,353 @@
+package spec
+
+import (
+	"fmt"
+	"io"
+	"naviger/pkg/sdk"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+type ActionKind string
+
+const (
+	ActionCreate ActionKind = "create"
+	ActionUpdate ActionKind = "update"
+	ActionDelete ActionKind = "delete"
+)
+
+// Action is one step needed to converge a server on its spec.
+type Action struct {
+	Kind     ActionKind
+	Name     string
+	ServerID string
+	Changes  []string
+
+	spec        *ServerSpec
+	update      sdk.UpdateServerRequest
+	properties  map[string]string
+	installMods []ModSpec
+	removeMods  []string
+
+	addSchedules    []ScheduleSpec
+	removeSchedules []string
+}
+
+type Plan struct {
+	Actions  []Action
+	Warnings []string
+}
+
+func (p *Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// BuildPlan diffs the spec against the daemon's current servers. Servers that
+// exist but are not in the spec are only deleted when prune is set.
+func BuildPlan(client *sdk.Client, s *Spec, prune bool) (*Plan, error) {
+	servers, err := client.ListServers()
+	if err != nil {
+		return nil, fmt.Errorf("error listing servers: %w", err)
+	}
+
+	existing := make(map[string]sdk.Server)
+	for _, srv := range servers {
+		existing[srv.Name] = srv
+	}
+
+	plan := &Plan{}
+	for i := range s.Servers {
+		want := &s.Servers[i]
+
+		current, ok := existing[want.Name]
+		if !ok {
+			plan.Actions = append(plan.Actions, createAction(want))
+			continue
+		}
+		delete(existing, want.Name)
+
+		action, warnings, err := updateAction(client, want, current)
+		if err != nil {
+			return nil, err
+		}
+		plan.Warnings = append(plan.Warnings, warnings...)
+		if action != nil {
+			plan.Actions = append(plan.Actions, *action)
+		}
+	}
+
+	var unmanaged []string
+	for name := range existing {
+		unmanaged = append(unmanaged, name)
+	}
+	sort.Strings(unmanaged)
+	for _, name := range unmanaged {
+		if prune {
+			plan.Actions = append(plan.Actions, Action{Kind: ActionDelete, Name: name, ServerID: existing[name].ID})
+		} else {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s: not in the spec; use --prune to delete it", name))
+		}
+	}
+
+	return plan, nil
+}
+
+func createAction(want *ServerSpec) Action {
+	action := Action{
+		Kind:         ActionCreate,
+		Name:         want.Name,
+		spec:         want,
+		properties:   want.Properties,
+		installMods:  want.Mods,
+		addSchedules: want.Schedules,
+		Changes:      []string{fmt.Sprintf("%s %s, %d MB", want.Loader, want.Version, want.RAM)},
+	}
+	if want.CustomArgs != "" {
+		args := want.CustomArgs
+		action.update.CustomArgs = &args
+		action.Changes = append(action.Changes, fmt.Sprintf("customArgs: %q", args))
+	}
+	for _, key := range sortedKeys(want.Properties) {
+		action.Changes = append(action.Changes, fmt.Sprintf("property %s=%s", key, want.Properties[key]))
+	}
+	for _, mod := range want.Mods {
+		action.Changes = append(action.Changes, fmt.Sprintf("install %s", mod.FileName()))
+	}
+	for _, schedule := range want.Schedules {
+		action.Changes = append(action.Changes, fmt.Sprintf("schedule %s", schedule))
+	}
+	return action
+}
+
+func updateAction(client *sdk.Client, want *ServerSpec, current sdk.Server) (*Action, []string, error) {
+	var warnings []string
+	action := &Action{Kind: ActionUpdate, Name: want.Name, ServerID: current.ID}
+
+	if want.Loader != current.Loader || want.Version != current.Version {
+		warnings = append(warnings, fmt.Sprintf("%s: loader/version differ (%s %s -> %s %s); recreate the server to change them",
+			want.Name, current.Loader, current.Version, want.Loader, want.Version))
+	}
+
+	if want.RAM != current.RAM {
+		ram := want.RAM
+		action.update.RAM = &ram
+		action.Changes = append(action.Changes, fmt.Sprintf("ram: %d -> %d", current.RAM, want.RAM))
+	}
+	if want.CustomArgs != current.CustomArgs {
+		args := want.CustomArgs
+		action.update.CustomArgs = &args
+		action.Changes = append(action.Changes, fmt.Sprintf("customArgs: %q -> %q", current.CustomArgs, want.CustomArgs))
+	}
+
+	if len(want.Properties) > 0 {
+		view, err := client.GetServerProperties(current.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading properties of %s: %w", want.Name, err)
+		}
+		props := view.Values()
+		for _, key := range sortedKeys(want.Properties) {
+			value := want.Properties[key]
+			if old, ok := props[key]; !ok || old != value {
+				if action.properties == nil {
+					action.properties = make(map[string]string)
+				}
+				action.properties[key] = value
+				action.Changes = append(action.Changes, fmt.Sprintf("property %s: %q -> %q", key, old, value))
+			}
+		}
+	}
+
+	if want.Mods != nil {
+		installed, err := client.ListMods(current.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing mods of %s: %w", want.Name, err)
+		}
+		have := make(map[string]sdk.ModFile)
+		for _, mod := range installed {
+			have[mod.Name] = mod
+		}
+
+		wanted := make(map[string]bool)
+		for _, mod := range want.Mods {
+			name := mod.FileName()
+			wanted[name] = true
+			existing, ok := have[name]
+			if ok && (mod.SHA1 == "" || strings.EqualFold(mod.SHA1, existing.SHA1)) {
+				continue
+			}
+			if mod.URL == "" {
+				warnings = append(warnings, fmt.Sprintf("%s: mod %s is missing or outdated and has no url", want.Name, name))
+				continue
+			}
+			action.installMods = append(action.installMods, mod)
+			action.Changes = append(action.Changes, fmt.Sprintf("install %s", name))
+		}
+		for _, mod := range installed {
+			if !wanted[mod.Name] {
+				action.removeMods = append(action.removeMods, mod.Name)
+				action.Changes = append(action.Changes, fmt.Sprintf("remove %s", mod.Name))
+			}
+		}
+	}
+
+	if want.Schedules != nil {
+		scheduled, err := client.ListSchedules(current.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing schedules of %s: %w", want.Name, err)
+		}
+		// Schedules have no name, so one that changes in any way is replaced.
+		have := make(map[ScheduleSpec]bool)
+		for _, schedule := range scheduled {
+			key := ScheduleSpec{Cron: schedule.Cron, Action: schedule.Action, Command: schedule.Command}
+			if have[key] || !slices.Contains(want.Schedules, key) {
+				action.removeSchedules = append(action.removeSchedules, schedule.ID)
+				action.Changes = append(action.Changes, fmt.Sprintf("unschedule %s", key))
+			}
+			have[key] = true
+		}
+		for _, schedule := range want.Schedules {
+			if !have[schedule] {
+				have[schedule] = true
+				action.addSchedules = append(action.addSchedules, schedule)
+				action.Changes = append(action.Changes, fmt.Sprintf("schedule %s", schedule))
+			}
+		}
+	}
+
+	if len(action.Changes) == 0 {
+		return nil, warnings, nil
+	}
+	return action, warnings, nil
+}
+
+// Print writes a human-readable summary of the plan.
+func (p *Plan) Print(w io.Writer) {
+	for _, warning := range p.Warnings {
+		fmt.Fprintf(w, "! %s\n", warning)
+	}
+	if p.Empty() {
+		fmt.Fprintln(w, "No changes. Servers match the spec.")
+		return
+	}
+
+	symbols := map[ActionKind]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}
+	for _, action := range p.Actions {
+		fmt.Fprintf(w, "%s %s %s\n", symbols[action.Kind], action.Kind, action.Name)
+		for _, change := range action.Changes {
+			fmt.Fprintf(w, "    %s\n", change)
+		}
+	}
+}
+
+// Apply executes the plan in order, stopping at the first failure.
+func Apply(client *sdk.Client, plan *Plan, out io.Writer) error {
+	for _, action := range plan.Actions {
+		fmt.Fprintf(out, "%s %s...\n", action.Kind, action.Name)
+
+		switch action.Kind {
+		case ActionDelete:
+			if err := client.DeleteServer(action.ServerID); err != nil {
+				return fmt.Errorf("error deleting %s: %w", action.Name, err)
+			}
+			continue
+		case ActionCreate:
+			id, err := createServer(client, action.spec)
+			if err != nil {
+				return fmt.Errorf("error creating %s: %w", action.Name, err)
+			}
+			action.ServerID = id
+		}
+
+		if action.update.RAM != nil || action.update.CustomArgs != nil {
+			if err := client.UpdateServer(action.ServerID, action.update); err != nil {
+				return fmt.Errorf("error updating %s: %w", action.Name, err)
+			}
+		}
+		if len(action.properties) > 0 {
+			if err := client.UpdateServerProperties(action.ServerID, action.properties); err != nil {
+				return fmt.Errorf("error updating properties of %s: %w", action.Name, err)
+			}
+		}
+		for _, name := range action.removeMods {
+			if err := client.DeleteMod(action.ServerID, name); err != nil {
+				return fmt.Errorf("error removing %s from %s: %w", name, action.Name, err)
+			}
+		}
+		for _, mod := range action.installMods {
+			if mod.URL == "" {
+				continue
+			}
+			if _, err := client.InstallMod(action.ServerID, sdk.InstallModRequest{URL: mod.URL, Name: mod.Name, SHA1: mod.SHA1}); err != nil {
+				return fmt.Errorf("error installing %s on %s: %w", mod.FileName(), action.Name, err)
+			}
+		}
+		for _, id := range action.removeSchedules {
+			if err := client.DeleteSchedule(action.ServerID, id); err != nil {
+				return fmt.Errorf("error removing a schedule of %s: %w", action.Name, err)
+			}
+		}
+		for _, schedule := range action.addSchedules {
+			req := sdk.ScheduleRequest{Cron: schedule.Cron, Action: schedule.Action, Command: schedule.Command}
+			if _, err := client.CreateSchedule(action.ServerID, req); err != nil {
+				return fmt.Errorf("error scheduling %s on %s: %w", schedule, action.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// createServer starts a creation job and follows its progress stream until
+// the daemon reports the new server's ID or an error.
+func createServer(client *sdk.Client, want *ServerSpec) (string, error) {
+	requestID := uuid.New().String()
+	wsURL, err := client.GetWebSocketURL(fmt.Sprintf("/ws/progress/%s", requestID))
+	if err != nil {
+		return "", err
+	}
+
+	header := http.Header{}
+	header.Set("X-Naviger-Client", "CLI")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err != nil {
+		return "", fmt.Errorf("could not follow creation progress: %w", err)
+	}
+	defer conn.Close()
+
+	err = client.CreateServer(sdk.CreateServerRequest{
+		Name:      want.Name,
+		Loader:    want.Loader,
+		Version:   want.Version,
+		Ram:       want.RAM,
+		RequestID: requestID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		var event sdk.ProgressEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			return "", fmt.Errorf("lost creation progress: %w", err)
+		}
+		switch {
+		case event.ServerID == "error":
+			return "", fmt.Errorf("%s", strings.TrimPrefix(event.Message, "Error: "))
+		case event.ServerID != "new-server" && event.Progress >= 100:
+			return event.ServerID, nil
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
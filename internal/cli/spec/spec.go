@@ -0,0 +1,190 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the declarative description of a fleet of servers, loaded from a
+// YAML or JSON file. Servers are matched to existing ones by name.
+type Spec struct {
+	Servers []ServerSpec `json:"servers" yaml:"servers"`
+}
+
+type ServerSpec struct {
+	Name       string     `json:"name" yaml:"name"`
+	Loader     string     `json:"loader" yaml:"loader"`
+	Version    string     `json:"version" yaml:"version"`
+	RAM        int        `json:"ram" yaml:"ram"`
+	CustomArgs string     `json:"customArgs,omitempty" yaml:"customArgs,omitempty"`
+	Properties Properties `json:"properties,omitempty" yaml:"properties,omitempty"`
+	// Mods is the complete list of jars in the mods or plugins folder. When
+	// it is omitted the folder is left alone; when it is present, jars not in
+	// the list are removed.
+	Mods []ModSpec `json:"mods,omitempty" yaml:"mods,omitempty"`
+	// Schedules is, like Mods, the complete list of the server's schedules
+	// when present.
+	Schedules []ScheduleSpec `json:"schedules,omitempty" yaml:"schedules,omitempty"`
+}
+
+type ModSpec struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	URL  string `json:"url,omitempty" yaml:"url,omitempty"`
+	SHA1 string `json:"sha1,omitempty" yaml:"sha1,omitempty"`
+}
+
+// ScheduleSpec runs Action at the times of Cron, a five-field cron expression.
+// Action is start, stop, restart, command or backup; Command is the console
+// command of command schedules.
+type ScheduleSpec struct {
+	Cron    string `json:"cron" yaml:"cron"`
+	Action  string `json:"action" yaml:"action"`
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+}
+
+// Properties holds server.properties values. JSON numbers and booleans are
+// accepted and stored in their textual form.
+type Properties map[string]string
+
+func (p *Properties) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	props := make(Properties, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			props[key] = v
+		case nil:
+			props[key] = ""
+		default:
+			props[key] = fmt.Sprint(v)
+		}
+	}
+	*p = props
+	return nil
+}
+
+// FileName returns the jar name a mod is stored under.
+func (m ModSpec) FileName() string {
+	if m.Name != "" {
+		return m.Name
+	}
+	name := m.URL
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+var scheduleActions = []string{"start", "stop", "restart", "command", "backup"}
+
+func (sc ScheduleSpec) validate() error {
+	if _, err := cron.ParseStandard(sc.Cron); err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", sc.Cron, err)
+	}
+	if !slices.Contains(scheduleActions, sc.Action) {
+		return fmt.Errorf("unknown schedule action %q, expected one of %s", sc.Action, strings.Join(scheduleActions, ", "))
+	}
+	if (sc.Action == "command") != (sc.Command != "") {
+		return fmt.Errorf("schedule %q: command schedules, and only they, need a command", sc.Cron)
+	}
+	return nil
+}
+
+func (sc ScheduleSpec) String() string {
+	if sc.Command != "" {
+		return fmt.Sprintf("%s %s %q", sc.Cron, sc.Action, sc.Command)
+	}
+	return fmt.Sprintf("%s %s", sc.Cron, sc.Action)
+}
+
+// Load reads a spec from path, choosing the format by file extension.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Spec
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&s)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Spec) Validate() error {
+	seen := make(map[string]bool)
+	for i, srv := range s.Servers {
+		if srv.Name == "" {
+			return fmt.Errorf("server #%d: name is required", i+1)
+		}
+		if seen[srv.Name] {
+			return fmt.Errorf("server '%s' is declared more than once", srv.Name)
+		}
+		seen[srv.Name] = true
+
+		if srv.Loader == "" || srv.Version == "" {
+			return fmt.Errorf("server '%s': loader and version are required", srv.Name)
+		}
+		if srv.RAM <= 0 {
+			return fmt.Errorf("server '%s': ram must be positive", srv.Name)
+		}
+		if _, ok := srv.Properties["server-port"]; ok {
+			return fmt.Errorf("server '%s': server-port is managed by Naviger and cannot be set", srv.Name)
+		}
+		for _, mod := range srv.Mods {
+			if mod.FileName() == "" {
+				return fmt.Errorf("server '%s': every mod needs a name or url", srv.Name)
+			}
+		}
+		for _, schedule := range srv.Schedules {
+			if err := schedule.validate(); err != nil {
+				return fmt.Errorf("server '%s': %w", srv.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Write encodes the spec as "yaml" or "json".
+func Write(w io.Writer, s *Spec, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case "yaml", "":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unknown format '%s'", format)
+	}
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"naviger/pkg/sdk"
+
+	"github.com/gorilla/websocket"
+)
+
+const consoleReconnectDelay = 2 * time.Second
+
+// consoleStream keeps a protocol connection to a server console open,
+// reconnecting and resuming from the last sequence number it saw whenever
+// the socket drops (for example when the server restarts).
+type consoleStream struct {
+	client   *sdk.Client
+	serverID string
+	frames   chan sdk.ConsoleFrame
+	done     chan struct{}
+
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	session string
+	seq     uint64
+}
+
+func dialConsole(client *sdk.Client, serverID string) (*consoleStream, error) {
+	s := &consoleStream{
+		client:   client,
+		serverID: serverID,
+		frames:   make(chan sdk.ConsoleFrame, 256),
+		done:     make(chan struct{}),
+	}
+	conn, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+	s.conn = conn
+	go s.run(conn)
+	return s, nil
+}
+
+func (s *consoleStream) dial() (*websocket.Conn, error) {
+	s.mu.Lock()
+	wsURL, err := s.client.ConsoleURL(s.serverID, s.session, s.seq)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set("X-Naviger-Client", "CLI")
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	return conn, err
+}
+
+func (s *consoleStream) run(conn *websocket.Conn) {
+	defer close(s.frames)
+	for {
+		s.read(conn)
+
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-time.After(consoleReconnectDelay):
+			}
+			next, err := s.dial()
+			if err != nil {
+				continue
+			}
+			s.mu.Lock()
+			select {
+			case <-s.done:
+				s.mu.Unlock()
+				next.Close()
+				return
+			default:
+			}
+			s.conn = next
+			s.mu.Unlock()
+			conn = next
+			break
+		}
+	}
+}
+
+func (s *consoleStream) read(conn *websocket.Conn) {
+	defer conn.Close()
+	for {
+		var frame sdk.ConsoleFrame
+		if err := conn.ReadJSON(&frame); err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if frame.Type == "hello" {
+			var hello sdk.ConsoleHello
+			if err := frame.Decode(&hello); err == nil {
+				s.session = hello.Session
+			}
+		}
+		if frame.Seq > 0 {
+			s.seq = frame.Seq
+		}
+		s.mu.Unlock()
+
+		select {
+		case s.frames <- frame:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *consoleStream) send(id, command string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return fmt.Errorf("not connected")
+	}
+	return s.conn.WriteJSON(sdk.NewConsoleCommand(id, command))
+}
+
+func (s *consoleStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
@@ -4,20 +4,20 @@ import (
 	"fmt"
 	"log"
 	"naviger/pkg/sdk"
-	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/gorilla/websocket"
 )
 
 type logModel struct {
-	sub       chan string
-	conn      *websocket.Conn
+	stream    *consoleStream
 	viewport  viewport.Model
 	textInput textinput.Model
 	err       error
@@ -30,9 +30,38 @@ type logModel struct {
 	client    *sdk.Client
 	width     int
 	height    int
+	stats     *sdk.ServerStats
+	commandID int
+
+	// Scrollback past the live buffer is read from the daemon's log archive,
+	// in pages of lines older than the first line the console delivered.
+	oldestLive     time.Time
+	historyLines   int
+	historyAnchor  time.Time
+	historyLoading bool
+	historyDone    bool
+
+	// Tab swaps the console for the server's crash incidents.
+	showIncidents  bool
+	incidents      []sdk.Incident
+	incidentIndex  int
+	incidentDetail *sdk.Incident
 }
 
-func initialLogModel(id string, conn *websocket.Conn, sub chan string, client *sdk.Client) logModel {
+var (
+	warnLineStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
+	errorLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("160"))
+	noticeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Italic(true)
+)
+
+const (
+	historyPageSize = 200
+	// incidentCheckDelay gives the daemon time to store an incident after the
+	// server stops before the console asks for it.
+	incidentCheckDelay = time.Second
+)
+
+func initialLogModel(id string, stream *consoleStream, client *sdk.Client) logModel {
 	ti := textinput.New()
 	ti.Placeholder = "Type a command..."
 	ti.Focus()
@@ -40,8 +69,7 @@ func initialLogModel(id string, conn *websocket.Conn, sub chan string, client *s
 	ti.Width = 20
 
 	return logModel{
-		sub:       sub,
-		conn:      conn,
+		stream:    stream,
 		textInput: ti,
 		serverID:  id,
 		client:    client,
@@ -51,29 +79,90 @@ func initialLogModel(id string, conn *websocket.Conn, sub chan string, client *s
 func (m logModel) Init() tea.Cmd {
 	return tea.Batch(
 		textinput.Blink,
-		waitForLog(m.sub),
+		waitForFrame(m.stream.frames),
 		getServerDetails(m.client, m.serverID),
 		tickCmd(),
+		loadIncidents(m.client, m.serverID, false),
 	)
 }
 
-type logMsg string
+type frameMsg sdk.ConsoleFrame
 type errMsg2 error
 type serverDetailsMsg *sdk.Server
 
-func waitForLog(sub chan string) tea.Cmd {
+type logHistoryMsg struct {
+	lines []string
+	err   error
+}
+
+type incidentsMsg struct {
+	incidents []sdk.Incident
+	notify    bool
+	err       error
+}
+
+type incidentDetailMsg *sdk.Incident
+
+func waitForFrame(frames chan sdk.ConsoleFrame) tea.Cmd {
 	return func() tea.Msg {
-		if sub == nil {
+		frame, ok := <-frames
+		if !ok {
 			return nil
 		}
-		msg, ok := <-sub
-		if !ok {
+		return frameMsg(frame)
+	}
+}
+
+func loadLogHistory(client *sdk.Client, id string, anchor time.Time, offset int) tea.Cmd {
+	return func() tea.Msg {
+		page, err := client.SearchLogs(id, sdk.LogQuery{
+			To:     anchor,
+			Limit:  historyPageSize,
+			Offset: offset,
+			Tail:   true,
+		})
+		if err != nil {
+			return logHistoryMsg{err: err}
+		}
+		lines := make([]string, 0, len(page.Lines))
+		for _, line := range page.Lines {
+			lines = append(lines, line.Line)
+		}
+		return logHistoryMsg{lines: lines}
+	}
+}
+
+func loadIncidents(client *sdk.Client, id string, notify bool) tea.Cmd {
+	return func() tea.Msg {
+		incidents, err := client.ListIncidents(id)
+		return incidentsMsg{incidents: incidents, notify: notify, err: err}
+	}
+}
+
+func loadIncidentDetail(client *sdk.Client, id, incidentID string) tea.Cmd {
+	return func() tea.Msg {
+		incident, err := client.GetIncident(id, incidentID)
+		if err != nil {
 			return nil
 		}
-		return logMsg(msg)
+		return incidentDetailMsg(incident)
 	}
 }
 
+func (m *logModel) requestHistory() tea.Cmd {
+	if m.historyLoading || m.historyDone {
+		return nil
+	}
+	if m.historyAnchor.IsZero() {
+		m.historyAnchor = time.Now()
+		if !m.oldestLive.IsZero() {
+			m.historyAnchor = m.oldestLive.Add(-time.Nanosecond)
+		}
+	}
+	m.historyLoading = true
+	return loadLogHistory(m.client, m.serverID, m.historyAnchor, m.historyLines)
+}
+
 func getServerDetails(client *sdk.Client, id string) tea.Cmd {
 	return func() tea.Msg {
 		srv, err := client.GetServer(id)
@@ -103,10 +192,46 @@ func (m logModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.textInput.Value() != "" {
 				cmd := m.textInput.Value()
 				m.textInput.SetValue("")
-				if m.conn != nil {
-					_ = m.conn.WriteMessage(websocket.TextMessage, []byte(cmd+"\n"))
+				m.commandID++
+				if err := m.stream.send(strconv.Itoa(m.commandID), cmd); err != nil {
+					m.appendLine(errorLineStyle.Render("! " + err.Error()))
 				}
 			}
+		case tea.KeyTab:
+			m.showIncidents = !m.showIncidents
+			if m.showIncidents {
+				m.renderIncidents()
+				return m, tea.Batch(loadIncidents(m.client, m.serverID, false), m.selectIncident(m.incidentIndex))
+			}
+			m.viewport.SetContent(m.content)
+			m.viewport.GotoBottom()
+			return m, nil
+		case tea.KeyUp, tea.KeyDown:
+			if m.showIncidents {
+				index := m.incidentIndex + 1
+				if msg.Type == tea.KeyUp {
+					index = m.incidentIndex - 1
+				}
+				return m, m.selectIncident(index)
+			}
+			if msg.Type == tea.KeyUp && m.ready && m.viewport.AtTop() {
+				historyCmd := m.requestHistory()
+				m.viewport, vpCmd = m.viewport.Update(msg)
+				return m, tea.Batch(historyCmd, vpCmd)
+			}
+		case tea.KeyPgUp:
+			if !m.showIncidents && m.ready && m.viewport.AtTop() {
+				historyCmd := m.requestHistory()
+				m.viewport, vpCmd = m.viewport.Update(msg)
+				return m, tea.Batch(historyCmd, vpCmd)
+			}
+		}
+
+	case tea.MouseMsg:
+		if msg.Button == tea.MouseButtonWheelUp && !m.showIncidents && m.ready && m.viewport.AtTop() {
+			historyCmd := m.requestHistory()
+			m.viewport, vpCmd = m.viewport.Update(msg)
+			return m, tea.Batch(historyCmd, vpCmd)
 		}
 
 	case tea.WindowSizeMsg:
@@ -127,11 +252,55 @@ func (m logModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.Height = msg.Height - verticalMarginHeight
 		}
 
-	case logMsg:
-		m.content += string(msg) + "\n"
-		m.viewport.SetContent(m.content)
-		m.viewport.GotoBottom()
-		return m, waitForLog(m.sub)
+	case frameMsg:
+		return m, tea.Batch(m.handleFrame(sdk.ConsoleFrame(msg)), waitForFrame(m.stream.frames))
+
+	case incidentsMsg:
+		if msg.err != nil {
+			return m, nil
+		}
+		known := ""
+		if len(m.incidents) > 0 {
+			known = m.incidents[0].ID
+		}
+		m.incidents = msg.incidents
+		if msg.notify && len(m.incidents) > 0 && m.incidents[0].ID != known {
+			latest := m.incidents[0]
+			m.appendLine(errorLineStyle.Render(fmt.Sprintf("!! Server crashed: %s — %s (tab: incidents)", latest.Cause, latest.Summary)))
+		}
+		if m.incidentIndex >= len(m.incidents) {
+			m.incidentIndex = 0
+		}
+		if m.showIncidents {
+			m.renderIncidents()
+		}
+		return m, nil
+
+	case incidentDetailMsg:
+		m.incidentDetail = msg
+		if m.showIncidents {
+			m.renderIncidents()
+		}
+		return m, nil
+
+	case logHistoryMsg:
+		m.historyLoading = false
+		if msg.err != nil {
+			m.historyDone = true
+			return m, nil
+		}
+		if len(msg.lines) < historyPageSize {
+			m.historyDone = true
+		}
+		if len(msg.lines) > 0 {
+			m.historyLines += len(msg.lines)
+			m.content = strings.Join(msg.lines, "\n") + "\n" + m.content
+			if !m.showIncidents {
+				m.viewport.SetContent(m.content)
+				m.viewport.SetYOffset(m.viewport.YOffset + len(msg.lines))
+			}
+		}
+		return m, nil
 
 	case serverDetailsMsg:
 		m.server = msg
@@ -149,6 +318,138 @@ func (m logModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(tiCmd, vpCmd)
 }
 
+func (m *logModel) handleFrame(frame sdk.ConsoleFrame) tea.Cmd {
+	switch frame.Type {
+	case "hello":
+		var hello sdk.ConsoleHello
+		if err := frame.Decode(&hello); err == nil && hello.Gap && m.content != "" {
+			m.appendLine(noticeStyle.Render("-- reconnected, some output was missed --"))
+		}
+	case "log":
+		var line sdk.ConsoleLog
+		if err := frame.Decode(&line); err != nil {
+			return nil
+		}
+		if m.oldestLive.IsZero() {
+			m.oldestLive = frame.Time
+		}
+		m.appendLine(renderLogLine(line))
+	case "status":
+		var status sdk.ConsoleStatus
+		if err := frame.Decode(&status); err != nil {
+			return nil
+		}
+		if m.server != nil {
+			m.server.Status = status.Status
+		}
+		if status.Status == "STOPPED" {
+			client, id := m.client, m.serverID
+			return tea.Tick(incidentCheckDelay, func(time.Time) tea.Msg {
+				return loadIncidents(client, id, true)()
+			})
+		}
+	case "stats":
+		var stats sdk.ServerStats
+		if err := frame.Decode(&stats); err == nil {
+			m.stats = &stats
+		}
+	case "error":
+		var consoleErr sdk.ConsoleError
+		if err := frame.Decode(&consoleErr); err == nil {
+			m.appendLine(errorLineStyle.Render("! " + consoleErr.Message))
+		}
+	}
+	return nil
+}
+
+func (m *logModel) appendLine(line string) {
+	m.content += line + "\n"
+	if m.showIncidents {
+		return
+	}
+	follow := m.viewport.AtBottom()
+	m.viewport.SetContent(m.content)
+	if follow {
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m *logModel) selectIncident(index int) tea.Cmd {
+	if len(m.incidents) == 0 {
+		return nil
+	}
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(m.incidents) {
+		index = len(m.incidents) - 1
+	}
+	m.incidentIndex = index
+	m.renderIncidents()
+	if m.incidentDetail != nil && m.incidentDetail.ID == m.incidents[index].ID {
+		return nil
+	}
+	return loadIncidentDetail(m.client, m.serverID, m.incidents[index].ID)
+}
+
+// renderIncidents shows the incident list with the details of the selected
+// incident below it.
+func (m *logModel) renderIncidents() {
+	if len(m.incidents) == 0 {
+		m.viewport.SetContent(noticeStyle.Render("No incidents recorded for this server."))
+		m.viewport.GotoTop()
+		return
+	}
+
+	var b strings.Builder
+	for i, incident := range m.incidents {
+		line := fmt.Sprintf("%s  %-18s exit %d", incident.CreatedAt.Local().Format("2006-01-02 15:04:05"), incident.Cause, incident.ExitCode)
+		if i == m.incidentIndex {
+			b.WriteString(warnLineStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	selected := m.incidents[m.incidentIndex]
+	if m.incidentDetail != nil && m.incidentDetail.ID == selected.ID {
+		selected = *m.incidentDetail
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "Cause:     %s\n", selected.Cause)
+	fmt.Fprintf(&b, "Summary:   %s\n", selected.Summary)
+	if selected.Evidence != "" {
+		fmt.Fprintf(&b, "Evidence:  %s\n", errorLineStyle.Render(selected.Evidence))
+	}
+	fmt.Fprintf(&b, "Exit code: %d\n", selected.ExitCode)
+	if selected.CrashReport != "" {
+		fmt.Fprintf(&b, "Crash report: %s\n", selected.CrashReport)
+	}
+	if selected.JVMErrorLog != "" {
+		fmt.Fprintf(&b, "JVM error log: %s\n", selected.JVMErrorLog)
+	}
+	if len(selected.ConsoleTail) > 0 {
+		b.WriteString("\n" + noticeStyle.Render("-- last console output --") + "\n")
+		b.WriteString(strings.Join(selected.ConsoleTail, "\n"))
+	}
+
+	m.viewport.SetContent(b.String())
+	m.viewport.GotoTop()
+}
+
+func renderLogLine(line sdk.ConsoleLog) string {
+	switch {
+	case line.Level == "ERROR" || line.Level == "FATAL":
+		return errorLineStyle.Render(line.Line)
+	case line.Level == "WARN":
+		return warnLineStyle.Render(line.Line)
+	case line.Stream == "stderr" && line.Level == "":
+		return errorLineStyle.Render(line.Line)
+	}
+	return line.Line
+}
+
 func (m logModel) View() string {
 	if !m.ready {
 		return "\n  Initializing..."
@@ -180,6 +481,15 @@ func (m logModel) View() string {
 			m.server.Version,
 			m.server.RAM,
 		)
+		if m.stats != nil {
+			serverInfoContent += fmt.Sprintf("\nCPU: %.1f%%  •  Memory: %d MB", m.stats.CPU, m.stats.RAM/1024/1024)
+			if m.stats.TPS > 0 {
+				serverInfoContent += fmt.Sprintf("  •  TPS: %.1f", m.stats.TPS)
+				if m.stats.MSPT > 0 {
+					serverInfoContent += fmt.Sprintf(" (%.1f ms)", m.stats.MSPT)
+				}
+			}
+		}
 	} else {
 		serverInfoContent = "Loading server details..."
 	}
@@ -195,6 +505,8 @@ func (m logModel) View() string {
 		Render(m.viewport.View())
 
 	keys := []string{
+		keyStyle.Render("pgup") + descStyle.Render(": older logs"),
+		keyStyle.Render("tab") + descStyle.Render(": incidents"),
 		keyStyle.Render("esc") + descStyle.Render(": back"),
 		keyStyle.Render("ctrl+c") + descStyle.Render(": quit"),
 	}
@@ -237,37 +549,16 @@ func RunLogs(client *sdk.Client, id string) bool {
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt)
 
-	wsURL, err := client.GetWebSocketURL(fmt.Sprintf("/ws/servers/%s/console", id))
-	if err != nil {
-		log.Fatal("Error parsing base URL:", err)
-	}
-
-	header := http.Header{}
-	header.Set("X-Naviger-Client", "CLI")
-
-	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	stream, err := dialConsole(client, id)
 	if err != nil {
 		fmt.Printf("Error connecting to logs: %v\nPress Enter to continue...", err)
 		fmt.Scanln()
 		return true
 	}
-	defer conn.Close()
-
-	sub := make(chan string)
-
-	go func() {
-		defer close(sub)
-		for {
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				return
-			}
-			sub <- string(message)
-		}
-	}()
+	defer stream.close()
 
 	p := tea.NewProgram(
-		initialLogModel(id, conn, sub, client),
+		initialLogModel(id, stream, client),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
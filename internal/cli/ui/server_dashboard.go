@@ -37,6 +37,9 @@ type model struct {
 	mode             dashboardMode
 	deleteServerID   string
 	deleteServerName string
+	history          *sdk.StatsHistory
+	historyFor       string
+	historyRange     int
 }
 
 type dashboardMode int
@@ -52,8 +55,28 @@ type serverDataMsg struct {
 	stats   map[string]sdk.ServerStats
 }
 
+type statsHistoryMsg struct {
+	serverID string
+	history  *sdk.StatsHistory
+}
+
 type errMsg error
 
+// historyRanges are the spans the history sparklines cycle through, with a
+// step that keeps them under about a hundred columns.
+var historyRanges = []struct {
+	label string
+	rng   string
+	step  string
+}{
+	{"1h", "1h", "1m"},
+	{"6h", "6h", "5m"},
+	{"24h", "24h", "15m"},
+	{"7d", "7d", "2h"},
+}
+
+const historyBoxHeight = 5
+
 func RunServerDashboard(client *sdk.Client) string {
 	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
 	l.Title = "Servers"
@@ -217,6 +240,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.mode = ViewDeleteConfirm
 				return m, nil
 			}
+		case "h":
+			m.historyRange = (m.historyRange + 1) % len(historyRanges)
+			m.history = nil
+			m.historyFor = m.selectedID()
+			return m, fetchHistoryCmd(m.client, m.historyFor, m.historyRange)
 		case "enter":
 			m.message = "navigate_logs"
 			return m, tea.Quit
@@ -233,7 +261,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.list.SetWidth(msg.Width - 4)
-		m.list.SetHeight(msg.Height - 12)
+		m.list.SetHeight(msg.Height - 12 - historyBoxHeight)
 		if m.mode == ViewWizard {
 		}
 	case serverDataMsg:
@@ -241,9 +269,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.servers = msg.servers
 		m.stats = msg.stats
 		m.updateList()
+		if m.historyFor == "" {
+			m.historyFor = m.selectedID()
+			return m, fetchHistoryCmd(m.client, m.historyFor, m.historyRange)
+		}
+		return m, nil
+	case statsHistoryMsg:
+		if msg.serverID == m.historyFor {
+			m.history = msg.history
+		}
 		return m, nil
 	case tickMsg:
-		return m, tea.Batch(fetchDataCmd(m.client), tickCmd())
+		return m, tea.Batch(fetchDataCmd(m.client), fetchHistoryCmd(m.client, m.historyFor, m.historyRange), tickCmd())
 	case errMsg:
 		m.err = msg
 		return m, nil
@@ -272,9 +309,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	m.list, cmd = m.list.Update(msg)
+	if id := m.selectedID(); id != m.historyFor {
+		m.history = nil
+		m.historyFor = id
+		return m, tea.Batch(varcmd(), cmd, fetchHistoryCmd(m.client, id, m.historyRange))
+	}
 	return m, tea.Batch(varcmd(), cmd)
 }
 
+func (m model) selectedID() string {
+	if i := m.list.SelectedItem(); i != nil {
+		return i.(serverListItem).id
+	}
+	return ""
+}
+
 func (m *model) updateList() {
 	var items []list.Item
 	for _, s := range m.servers {
@@ -297,16 +346,20 @@ func (m *model) updateList() {
 		cpu := "-"
 		ram := "-"
 		disk := "-"
+		tps := ""
 		if stat, ok := m.stats[s.ID]; ok {
 			cpu = fmt.Sprintf("%.1f%%", stat.CPU)
 			ram = fmt.Sprintf("%s / %dMB", formatBytesShort(int64(stat.RAM)), s.RAM)
 			disk = formatBytesShort(stat.Disk)
+			if stat.TPS > 0 {
+				tps = fmt.Sprintf(" • TPS: %.1f", stat.TPS)
+			}
 		}
 
 		title := fmt.Sprintf("%s %s", statusIcon, s.Name)
 
-		desc := fmt.Sprintf("ID: %s • Port: %d • Ver: %s • CPU: %s • RAM: %s • Disk: %s",
-			s.ID, s.Port, s.Version, cpu, ram, disk)
+		desc := fmt.Sprintf("ID: %s • Port: %d • Ver: %s • CPU: %s • RAM: %s • Disk: %s%s",
+			s.ID, s.Port, s.Version, cpu, ram, disk, tps)
 
 		items = append(items, serverListItem{
 			id:          s.ID,
@@ -367,15 +420,20 @@ func (m model) View() string {
 
 	listContainer := baseStyle.
 		Width(m.width - 4).
-		Height(m.height - 12).
+		Height(m.height - 12 - historyBoxHeight).
 		Render(m.list.View())
 
+	historyBox := baseStyle.
+		Width(m.width - 4).
+		Render(m.renderHistory(m.width - 8))
+
 	keys := []string{
 		keyStyle.Render("/") + descStyle.Render(": filter"),
 		keyStyle.Render("c") + descStyle.Render(": create"),
 		keyStyle.Render("s") + descStyle.Render(": start"),
 		keyStyle.Render("x") + descStyle.Render(": stop"),
 		keyStyle.Render("d") + descStyle.Render(": delete"),
+		keyStyle.Render("h") + descStyle.Render(": history range"),
 		keyStyle.Render("enter") + descStyle.Render(": logs"),
 		keyStyle.Render("q/esc") + descStyle.Render(": quit"),
 	}
@@ -402,6 +460,7 @@ func (m model) View() string {
 		title,
 		headerBox,
 		listContainer,
+		historyBox,
 		footerBox,
 	)
 }
@@ -430,6 +489,94 @@ func fetchDataCmd(client *sdk.Client) tea.Cmd {
 	}
 }
 
+func fetchHistoryCmd(client *sdk.Client, serverID string, rangeIndex int) tea.Cmd {
+	if serverID == "" {
+		return nil
+	}
+	r := historyRanges[rangeIndex]
+	return func() tea.Msg {
+		history, err := client.GetStatsHistory(serverID, r.rng, r.step)
+		if err != nil {
+			return statsHistoryMsg{serverID: serverID}
+		}
+		return statsHistoryMsg{serverID: serverID, history: history}
+	}
+}
+
+// renderHistory draws CPU and memory sparklines of the selected server so
+// leaks and spikes stand out.
+func (m model) renderHistory(width int) string {
+	title := descStyle.Render(fmt.Sprintf("History (%s)", historyRanges[m.historyRange].label))
+	h := m.history
+	if h == nil || h.ServerID != m.historyFor || h.Step <= 0 || len(h.Points) == 0 {
+		return title + "\n" + descStyle.Render("No samples yet") + "\n"
+	}
+
+	step := time.Duration(h.Step) * time.Second
+	slots := int(h.To.Sub(h.From)/step) + 1
+	cpu := make([]float64, slots)
+	ram := make([]float64, slots)
+	present := make([]bool, slots)
+	var cpuPeak float64
+	var ramPeak uint64
+	for _, p := range h.Points {
+		i := int(p.Time.Sub(h.From) / step)
+		if i < 0 || i >= slots {
+			continue
+		}
+		cpu[i], ram[i], present[i] = p.CPU, float64(p.RAM), true
+		cpuPeak = max(cpuPeak, p.CPUMax)
+		ramPeak = max(ramPeak, p.RAMMax)
+	}
+	last := h.Points[len(h.Points)-1]
+
+	cpuLabel := fmt.Sprintf(" now %.1f%% • peak %.1f%%", last.CPU, cpuPeak)
+	ramLabel := fmt.Sprintf(" now %s • peak %s", formatBytesShort(int64(last.RAM)), formatBytesShort(int64(ramPeak)))
+	sparkWidth := width - 4 - max(lipgloss.Width(cpuLabel), lipgloss.Width(ramLabel))
+
+	cpuStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	ramStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	return title + "\n" +
+		"CPU " + cpuStyle.Render(sparkline(cpu, present, sparkWidth)) + descStyle.Render(cpuLabel) + "\n" +
+		"RAM " + ramStyle.Render(sparkline(ram, present, sparkWidth)) + descStyle.Render(ramLabel)
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values scaled from zero to their maximum, leaving blanks
+// where present is false. When there are more values than width, the newest
+// ones are kept.
+func sparkline(values []float64, present []bool, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+		present = present[len(present)-width:]
+	}
+
+	var top float64
+	for i, v := range values {
+		if present[i] {
+			top = max(top, v)
+		}
+	}
+
+	runes := make([]rune, len(values))
+	for i, v := range values {
+		switch {
+		case !present[i]:
+			runes[i] = ' '
+		case top <= 0:
+			runes[i] = sparkBlocks[0]
+		default:
+			level := int(v / top * float64(len(sparkBlocks)-1))
+			runes[i] = sparkBlocks[min(max(level, 0), len(sparkBlocks)-1)]
+		}
+	}
+	return string(runes)
+}
+
 func formatBytesShort(bytes int64) string {
 	if bytes == 0 {
 		return "0B"
@@ -15,19 +15,64 @@ const (
 	defaultServersDir    = "servers"
 	defaultBackupsDir    = "backups"
 	defaultRuntimesDir   = "runtimes"
+	defaultTemplatesDir  = "templates"
+	defaultLogsDir       = "logs"
 	defaultDatabaseFile  = "manager.db"
 	defaultPort          = 23008
 	devPort              = 23009
 	defaultLogBufferSize = 1000
+	defaultLogMaxSizeMB  = 10
+	defaultLogMaxFiles   = 20
+	defaultDiskLowMB     = 2048
+	defaultStatsInterval = 10
+	defaultTPSInterval   = 30
+	defaultTPSAlertBelow = 15
+	defaultTPSAlertAfter = 2
+	defaultFirstUID      = 200000
+	defaultUIDCount      = 10000
+	defaultEngine        = "docker"
+	defaultEngineImage   = "eclipse-temurin:{java}-jre"
 )
 
 type Config struct {
 	ServersPath   string `json:"servers_path"`
 	BackupsPath   string `json:"backups_path"`
 	RuntimesPath  string `json:"runtimes_path"`
+	TemplatesPath string `json:"templates_path"`
+	LogsPath      string `json:"logs_path"`
 	DatabasePath  string `json:"database_path"`
 	JWTSecret     string `json:"-"`
 	LogBufferSize int    `json:"log_buffer_size"`
+	LogMaxSizeMB  int    `json:"log_max_size_mb"`
+	LogMaxFiles   int    `json:"log_max_files"`
+	DiskLowMB     int    `json:"disk_low_mb"`
+	// StatsInterval is how often, in seconds, running servers are sampled
+	// for the stats history, at most 30.
+	StatsInterval int `json:"stats_interval_seconds"`
+	// TPSInterval is how often, in seconds, the tick rate of running servers
+	// is probed; negative disables probing. A server that stays below
+	// TPSAlertBelow TPS for TPSAlertAfter minutes triggers an alert; a
+	// negative TPSAlertBelow disables it.
+	TPSInterval   int     `json:"tps_interval_seconds"`
+	TPSAlertBelow float64 `json:"tps_alert_below"`
+	TPSAlertAfter int     `json:"tps_alert_minutes"`
+	// MetricsToken, when set, lets scrapers read /metrics with a static
+	// bearer token instead of an admin session.
+	MetricsToken string `json:"metrics_token,omitempty"`
+	// Hardened runs every server as its own system user, with a UID and GID
+	// taken from UIDCount IDs starting at FirstUID. Unix only; the daemon
+	// needs root or the capabilities install.sh grants.
+	Hardened bool `json:"hardened"`
+	FirstUID int  `json:"hardened_first_uid"`
+	UIDCount int  `json:"hardened_uid_count"`
+	// Engine is the container engine CLI (docker or podman) that runs
+	// servers in container mode, inside EngineImage where {java} stands for
+	// the Java major version.
+	Engine      string `json:"container_engine"`
+	EngineImage string `json:"container_image"`
+	// SFTPPort, when set, serves the folders of the servers each user may
+	// access over SFTP on that port.
+	SFTPPort int `json:"sftp_port,omitempty"`
 }
 
 func LoadConfig(configDir string) (*Config, error) {
@@ -51,10 +96,78 @@ func LoadConfig(configDir string) (*Config, error) {
 		return nil, err
 	}
 
+	if cfg.ServersPath == "" {
+		cfg.ServersPath = filepath.Join(configDir, defaultServersDir)
+	}
+
+	if cfg.BackupsPath == "" {
+		cfg.BackupsPath = filepath.Join(configDir, defaultBackupsDir)
+	}
+
+	if cfg.RuntimesPath == "" {
+		cfg.RuntimesPath = filepath.Join(configDir, defaultRuntimesDir)
+	}
+
+	if cfg.DatabasePath == "" {
+		cfg.DatabasePath = filepath.Join(configDir, defaultDatabaseFile)
+	}
+
+	if cfg.TemplatesPath == "" {
+		cfg.TemplatesPath = filepath.Join(configDir, defaultTemplatesDir)
+	}
+
+	if cfg.LogsPath == "" {
+		cfg.LogsPath = filepath.Join(configDir, defaultLogsDir)
+	}
+
 	if cfg.LogBufferSize <= 0 {
 		cfg.LogBufferSize = defaultLogBufferSize
 	}
 
+	if cfg.LogMaxSizeMB <= 0 {
+		cfg.LogMaxSizeMB = defaultLogMaxSizeMB
+	}
+
+	if cfg.LogMaxFiles <= 0 {
+		cfg.LogMaxFiles = defaultLogMaxFiles
+	}
+
+	if cfg.DiskLowMB == 0 {
+		cfg.DiskLowMB = defaultDiskLowMB
+	}
+
+	if cfg.StatsInterval <= 0 {
+		cfg.StatsInterval = defaultStatsInterval
+	}
+
+	if cfg.TPSInterval == 0 {
+		cfg.TPSInterval = defaultTPSInterval
+	}
+
+	if cfg.TPSAlertBelow == 0 {
+		cfg.TPSAlertBelow = defaultTPSAlertBelow
+	}
+
+	if cfg.TPSAlertAfter <= 0 {
+		cfg.TPSAlertAfter = defaultTPSAlertAfter
+	}
+
+	if cfg.FirstUID <= 0 {
+		cfg.FirstUID = defaultFirstUID
+	}
+
+	if cfg.UIDCount <= 0 {
+		cfg.UIDCount = defaultUIDCount
+	}
+
+	if cfg.Engine == "" {
+		cfg.Engine = defaultEngine
+	}
+
+	if cfg.EngineImage == "" {
+		cfg.EngineImage = defaultEngineImage
+	}
+
 	cfg.JWTSecret = LoadOrGenerateSecret(configDir)
 
 	return &cfg, nil
@@ -65,8 +178,21 @@ func createDefaultConfig(configPath, configDir string) (*Config, error) {
 		ServersPath:   filepath.Join(configDir, defaultServersDir),
 		BackupsPath:   filepath.Join(configDir, defaultBackupsDir),
 		RuntimesPath:  filepath.Join(configDir, defaultRuntimesDir),
+		TemplatesPath: filepath.Join(configDir, defaultTemplatesDir),
+		LogsPath:      filepath.Join(configDir, defaultLogsDir),
 		DatabasePath:  filepath.Join(configDir, defaultDatabaseFile),
 		LogBufferSize: defaultLogBufferSize,
+		LogMaxSizeMB:  defaultLogMaxSizeMB,
+		LogMaxFiles:   defaultLogMaxFiles,
+		DiskLowMB:     defaultDiskLowMB,
+		StatsInterval: defaultStatsInterval,
+		TPSInterval:   defaultTPSInterval,
+		TPSAlertBelow: defaultTPSAlertBelow,
+		TPSAlertAfter: defaultTPSAlertAfter,
+		FirstUID:      defaultFirstUID,
+		UIDCount:      defaultUIDCount,
+		Engine:        defaultEngine,
+		EngineImage:   defaultEngineImage,
 	}
 
 	data, err := json.MarshalIndent(cfg, "", "  ")
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// Incident records an unexpected server exit: the classified cause, the
+// crash artifacts found in the server folder and the console output that led
+// up to it.
+type Incident struct {
+	ID              string    `json:"id"`
+	ServerID        string    `json:"serverId"`
+	ExitCode        int       `json:"exitCode"`
+	Cause           string    `json:"cause"`
+	Summary         string    `json:"summary"`
+	Evidence        string    `json:"evidence"`
+	CrashReport     string    `json:"crashReport"`
+	CrashReportText string    `json:"crashReportText,omitempty"`
+	JVMErrorLog     string    `json:"jvmErrorLog"`
+	JVMErrorLogText string    `json:"jvmErrorLogText,omitempty"`
+	ConsoleTail     []string  `json:"consoleTail,omitempty"`
+	StartedAt       time.Time `json:"startedAt"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
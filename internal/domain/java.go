@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// JavaRuntime is a Java installation on the host registered to run servers,
+// next to the runtimes downloaded into the runtimes folder.
+type JavaRuntime struct {
+	ID        string    `json:"id"`
+	Home      string    `json:"home"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+type Network struct {
+	ID               string          `json:"id"`
+	Name             string          `json:"name"`
+	ProxyID          string          `json:"proxyId"`
+	ForwardingSecret string          `json:"-"`
+	Servers          []NetworkServer `json:"servers"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+type NetworkServer struct {
+	NetworkID string `json:"networkId"`
+	ServerID  string `json:"serverId"`
+	Alias     string `json:"alias"`
+	Priority  int    `json:"priority"`
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+const (
+	SinkWebhook = "webhook"
+	SinkDiscord = "discord"
+	SinkEmail   = "email"
+)
+
+// NotificationSink is a destination for server events. URL is the webhook or
+// Discord webhook address; the SMTP fields are only used by email sinks. An
+// empty Events list subscribes to every event.
+type NotificationSink struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	Enabled      bool      `json:"enabled"`
+	Events       []string  `json:"events"`
+	URL          string    `json:"url,omitempty"`
+	Secret       string    `json:"-"`
+	SMTPHost     string    `json:"smtpHost,omitempty"`
+	SMTPPort     int       `json:"smtpPort,omitempty"`
+	SMTPUsername string    `json:"smtpUsername,omitempty"`
+	SMTPPassword string    `json:"-"`
+	EmailFrom    string    `json:"emailFrom,omitempty"`
+	EmailTo      []string  `json:"emailTo,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Subscribed reports whether the sink wants events of the given type.
+func (s NotificationSink) Subscribed(eventType string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, event := range s.Events {
+		if event == eventType || event == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// DeadLetter is a notification that could not be delivered after every
+// retry.
+type DeadLetter struct {
+	ID        uint      `json:"id"`
+	SinkID    string    `json:"sinkId"`
+	SinkName  string    `json:"sinkName"`
+	EventType string    `json:"eventType"`
+	Payload   string    `json:"payload"`
+	Error     string    `json:"error"`
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -1,9 +1,17 @@
 package domain
 
+import "time"
+
 type ServerRepository interface {
 	SaveServer(srv *Server) error
 	UpdateServer(id string, name *string, ram *int, customArgs *string) error
 	UpdateServerPort(id string, port int) error
+	UpdateServerLimits(id string, limits ResourceLimits) error
+	UpdateServerUID(id string, uid int) error
+	UpdateServerRunMode(id string, mode string) error
+	UpdateServerJVMProfile(id string, profile string) error
+	UpdateServerJavaRuntime(id string, runtimeID string) error
+	UpdateServerJavaVersion(id string, javaVersion int) error
 	ListServers() ([]Server, error)
 	GetServerByID(id string) (*Server, error)
 	DeleteServer(id string) error
@@ -21,6 +29,13 @@ type UserRepository interface {
 	UpdatePassword(userID string, hashedPassword string) error
 }
 
+type SSHKeyRepository interface {
+	AddSSHKey(key *SSHKey) error
+	ListSSHKeys(userID string) ([]SSHKey, error)
+	GetSSHKeyByFingerprint(fingerprint string) (*SSHKey, error)
+	DeleteSSHKey(userID, id string) error
+}
+
 type SettingRepository interface {
 	GetSetting(key string) (string, error)
 	SetSetting(key string, value string) error
@@ -35,9 +50,83 @@ type PublicLinkRepository interface {
 	DeletePublicLink(token string) error
 }
 
+type NetworkRepository interface {
+	CreateNetwork(network *Network) error
+	GetNetwork(id string) (*Network, error)
+	GetNetworkByProxyID(proxyID string) (*Network, error)
+	ListNetworks() ([]Network, error)
+	DeleteNetwork(id string) error
+	AddNetworkServer(member *NetworkServer) error
+	RemoveNetworkServer(networkID, serverID string) error
+	GetNetworkByServerID(serverID string) (*Network, error)
+}
+
+type TemplateRepository interface {
+	CreateTemplate(template *Template) error
+	GetTemplate(id string) (*Template, error)
+	ListTemplates() ([]Template, error)
+	DeleteTemplate(id string) error
+}
+
+type PlayerSessionRepository interface {
+	CreatePlayerSession(session *PlayerSession) error
+	GetOpenPlayerSession(serverID, playerName string) (*PlayerSession, error)
+	ListOpenPlayerSessions(serverID string) ([]PlayerSession, error)
+	ListPlayerSessions(serverID, playerName string) ([]PlayerSession, error)
+	ClosePlayerSession(id uint, leftAt time.Time) error
+	CloseOpenPlayerSessions(serverID string, leftAt time.Time) error
+	IncrementPlayerSessionCounter(id uint, counter string) error
+}
+
+type IncidentRepository interface {
+	CreateIncident(incident *Incident) error
+	GetIncident(id string) (*Incident, error)
+	ListIncidents(serverID string) ([]Incident, error)
+}
+
+type NotificationRepository interface {
+	CreateNotificationSink(sink *NotificationSink) error
+	UpdateNotificationSink(sink *NotificationSink) error
+	GetNotificationSink(id string) (*NotificationSink, error)
+	ListNotificationSinks() ([]NotificationSink, error)
+	DeleteNotificationSink(id string) error
+	CreateDeadLetter(letter *DeadLetter) error
+	ListDeadLetters(limit int) ([]DeadLetter, error)
+}
+
+type StatSampleRepository interface {
+	AddStatSamples(resolution time.Duration, samples []StatSample) error
+	ListStatSamples(serverID string, resolution time.Duration, from, to time.Time) ([]StatSample, error)
+	LatestStatSampleTime(resolution time.Duration) (time.Time, error)
+	DeleteStatSamplesBefore(resolution time.Duration, before time.Time) error
+}
+
+type ScheduleRepository interface {
+	CreateSchedule(schedule *Schedule) error
+	ListSchedules(serverID string) ([]Schedule, error)
+	ListAllSchedules() ([]Schedule, error)
+	DeleteSchedule(serverID, id string) error
+	UpdateScheduleRun(id string, at time.Time, runErr string) error
+}
+
+type JavaRuntimeRepository interface {
+	SaveJavaRuntime(runtime *JavaRuntime) error
+	ListJavaRuntimes() ([]JavaRuntime, error)
+	DeleteJavaRuntime(id string) error
+}
+
 type Repository interface {
 	ServerRepository
 	UserRepository
+	SSHKeyRepository
 	SettingRepository
 	PublicLinkRepository
+	NetworkRepository
+	TemplateRepository
+	PlayerSessionRepository
+	IncidentRepository
+	NotificationRepository
+	StatSampleRepository
+	JavaRuntimeRepository
+	ScheduleRepository
 }
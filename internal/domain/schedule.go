@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Schedule actions.
+const (
+	ScheduleStart   = "start"
+	ScheduleStop    = "stop"
+	ScheduleRestart = "restart"
+	ScheduleCommand = "command"
+	ScheduleBackup  = "backup"
+)
+
+// Schedule runs an action on a server at the times of a standard five-field
+// cron expression, in the daemon's time zone.
+type Schedule struct {
+	ID       string `json:"id"`
+	ServerID string `json:"serverId"`
+	Cron     string `json:"cron"`
+	Action   string `json:"action"`
+	// Command is the console command of command schedules.
+	Command   string    `json:"command,omitempty"`
+	LastRunAt time.Time `json:"lastRunAt,omitempty"`
+	LastError string    `json:"lastError,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
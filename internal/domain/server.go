@@ -3,17 +3,51 @@ package domain
 import "time"
 
 type Server struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	FolderName  string      `json:"folderName"`
-	Version     string      `json:"version"`
-	Loader      string      `json:"loader"`
-	Port        int         `json:"port"`
-	RAM         int         `json:"ram"`
-	Status      string      `json:"status"`
-	CustomArgs  string      `json:"customArgs"`
-	CreatedAt   time.Time   `json:"created_at"`
-	Permissions *Permission `json:"permissions,omitempty"`
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	FolderName  string         `json:"folderName"`
+	Version     string         `json:"version"`
+	Loader      string         `json:"loader"`
+	Port        int            `json:"port"`
+	RAM         int            `json:"ram"`
+	Status      string         `json:"status"`
+	CustomArgs  string         `json:"customArgs"`
+	JavaVersion int            `json:"javaVersion"`
+	Limits      ResourceLimits `json:"limits"`
+	UID         int            `json:"uid,omitempty"`
+	RunMode     string         `json:"runMode"`
+	JVMProfile  string         `json:"jvmProfile"`
+	JavaRuntime string         `json:"javaRuntime"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Permissions *Permission    `json:"permissions,omitempty"`
+}
+
+// Run modes of a server. An empty run mode is RunModeProcess.
+const (
+	RunModeProcess   = "process"
+	RunModeContainer = "container"
+)
+
+// ResourceLimits are the cgroup limits a server runs under on Linux. Zero
+// values use the defaults and -1 removes a limit. CPUQuota is in percent of
+// one core; the weights range from 1 to 10000.
+type ResourceLimits struct {
+	MemoryMaxMB int `json:"memoryMaxMb"`
+	CPUWeight   int `json:"cpuWeight"`
+	CPUQuota    int `json:"cpuQuota"`
+	PidsMax     int `json:"pidsMax"`
+	IOWeight    int `json:"ioWeight"`
+}
+
+// CgroupUsage is what the cgroup of a running server reports. The counters
+// cover the current run.
+type CgroupUsage struct {
+	MemoryCurrent    uint64  `json:"memoryCurrent"`
+	MemoryMaxHits    int64   `json:"memoryMaxHits"`
+	OOMKills         int64   `json:"oomKills"`
+	ThrottledPeriods int64   `json:"throttledPeriods"`
+	ThrottledSeconds float64 `json:"throttledSeconds"`
+	PidsCurrent      int64   `json:"pidsCurrent"`
 }
 
 type BackupInfo struct {
@@ -33,4 +67,33 @@ type ServerStats struct {
 	CPU  float64 `json:"cpu"`
 	RAM  uint64  `json:"ram"`
 	Disk int64   `json:"disk"`
+	// TPS and MSPT are the last measured tick rate and milliseconds per
+	// tick, left out until the server has been measured.
+	TPS  float64 `json:"tps,omitempty"`
+	MSPT float64 `json:"mspt,omitempty"`
+	// Cgroup is set when the server runs in its own cgroup.
+	Cgroup *CgroupUsage `json:"cgroup,omitempty"`
+}
+
+// StatSample is ServerStats aggregated over one step of a stats history.
+// CPU and RAM are averages over the step; CPUMax and RAMMax are the peaks.
+type StatSample struct {
+	ServerID string    `json:"-"`
+	Time     time.Time `json:"time"`
+	CPU      float64   `json:"cpu"`
+	CPUMax   float64   `json:"cpuMax"`
+	RAM      uint64    `json:"ram"`
+	RAMMax   uint64    `json:"ramMax"`
+	Disk     int64     `json:"disk"`
+}
+
+// ProcessExit describes how a server process ended.
+type ProcessExit struct {
+	ExitCode      int  `json:"exitCode"`
+	StopRequested bool `json:"stopRequested"`
+	// OOMKilled is set when the kernel killed the server for exceeding its
+	// cgroup memory limit.
+	OOMKilled bool      `json:"oomKilled,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	ExitedAt  time.Time `json:"exitedAt"`
 }
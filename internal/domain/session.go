@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// PlayerSession is one stay of a player on a server, from the join line in
+// the console to the matching leave line (or the server stopping).
+type PlayerSession struct {
+	ID           uint       `json:"id"`
+	ServerID     string     `json:"serverId"`
+	PlayerName   string     `json:"playerName"`
+	PlayerUUID   string     `json:"playerUuid"`
+	JoinedAt     time.Time  `json:"joinedAt"`
+	LeftAt       *time.Time `json:"leftAt"`
+	Deaths       int        `json:"deaths"`
+	Advancements int        `json:"advancements"`
+	ChatMessages int        `json:"chatMessages"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Template is a reusable snapshot of a server's files (without worlds) and
+// settings that new servers can be created from.
+type Template struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Loader         string    `json:"loader"`
+	Version        string    `json:"version"`
+	RAM            int       `json:"ram"`
+	CustomArgs     string    `json:"customArgs"`
+	JavaVersion    int       `json:"javaVersion"`
+	SourceServerID string    `json:"sourceServerId"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type User struct {
 	ID       string `json:"id"`
 	Username string `json:"username"`
@@ -14,6 +16,16 @@ type Permission struct {
 	CanControlPower bool   `json:"canControlPower"`
 }
 
+// SSHKey is a public key a user can sign in to the SFTP server with.
+type SSHKey struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userId"`
+	Name        string    `json:"name"`
+	PublicKey   string    `json:"publicKey"`
+	Fingerprint string    `json:"fingerprint"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
 type PublicLink struct {
 	Token    string `json:"token"`
 	ServerID string `json:"serverId"`
@@ -0,0 +1,198 @@
+// Package incidents turns unexpected server exits into stored incidents,
+// bundling the crash artifacts the server left behind with the console
+// output that preceded the exit.
+package incidents
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"naviger/internal/domain"
+	"naviger/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultTailLines is how many console lines are kept for each incident.
+	DefaultTailLines = 100
+	// maxArtifactSize caps how much of a crash report or JVM error log is
+	// stored; the cause is always near the top of both.
+	maxArtifactSize = 256 * 1024
+	crashReportsDir = "crash-reports"
+)
+
+type Manager struct {
+	Store       *storage.GormStore
+	ServersPath string
+	TailLines   int
+	// OnExit, when set, is told about every exit once it has been
+	// classified; incident is nil unless the exit was a crash.
+	OnExit func(serverID string, exit domain.ProcessExit, incident *domain.Incident)
+	tails  map[string][]string
+	mu     sync.Mutex
+}
+
+func NewManager(store *storage.GormStore, serversPath string) *Manager {
+	return &Manager{
+		Store:       store,
+		ServersPath: serversPath,
+		TailLines:   DefaultTailLines,
+		tails:       make(map[string][]string),
+	}
+}
+
+// ObserveLine keeps the last TailLines console lines of every server.
+func (m *Manager) ObserveLine(serverID, line string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tail := append(m.tails[serverID], line)
+	if len(tail) > m.TailLines {
+		tail = tail[len(tail)-m.TailLines:]
+	}
+	m.tails[serverID] = tail
+}
+
+// ServerExited records an incident unless the exit was asked for. Exits
+// with code 0 only count when they left a crash artifact behind or the
+// console shows a known failure, since typing "stop" also exits cleanly.
+func (m *Manager) ServerExited(serverID string, exit domain.ProcessExit) {
+	m.mu.Lock()
+	tail := m.tails[serverID]
+	delete(m.tails, serverID)
+	m.mu.Unlock()
+
+	incident := m.record(serverID, exit, tail)
+	if m.OnExit != nil {
+		m.OnExit(serverID, exit, incident)
+	}
+}
+
+func (m *Manager) record(serverID string, exit domain.ProcessExit, tail []string) *domain.Incident {
+	if exit.StopRequested {
+		return nil
+	}
+
+	incident, err := m.collect(serverID, exit, tail)
+	if err != nil {
+		slog.Warn("Could not analyse server exit", "server", serverID, "error", err)
+		return nil
+	}
+	if exit.ExitCode == 0 && incident.Cause == CauseUnknown && incident.CrashReport == "" && incident.JVMErrorLog == "" {
+		return nil
+	}
+
+	if err := m.Store.CreateIncident(incident); err != nil {
+		slog.Warn("Could not store incident", "server", serverID, "error", err)
+	}
+	slog.Warn("Server crashed", "server", serverID, "exitCode", exit.ExitCode, "cause", incident.Cause)
+	return incident
+}
+
+func (m *Manager) collect(serverID string, exit domain.ProcessExit, tail []string) (*domain.Incident, error) {
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files written before this run belong to earlier crashes.
+	since := exit.StartedAt.Add(-time.Second)
+
+	incident := &domain.Incident{
+		ID:          uuid.New().String(),
+		ServerID:    serverID,
+		ExitCode:    exit.ExitCode,
+		ConsoleTail: tail,
+		StartedAt:   exit.StartedAt,
+		CreatedAt:   exit.ExitedAt,
+	}
+
+	if path := newestFile(filepath.Join(dir, crashReportsDir), "crash-*.txt", since); path != "" {
+		incident.CrashReport = filepath.ToSlash(filepath.Join(crashReportsDir, filepath.Base(path)))
+		incident.CrashReportText = readArtifact(path)
+	}
+	if path := newestFile(dir, "hs_err_pid*.log", since); path != "" {
+		incident.JVMErrorLog = filepath.Base(path)
+		incident.JVMErrorLogText = readArtifact(path)
+	}
+
+	incident.Cause, incident.Summary, incident.Evidence = Classify(exit.ExitCode,
+		strings.Join(tail, "\n"), incident.CrashReportText, incident.JVMErrorLogText)
+	// A kill by the cgroup memory limit leaves nothing in the logs.
+	if exit.OOMKilled {
+		incident.Cause = CauseOutOfMemory
+		incident.Summary = "The server went over its memory limit and was killed; raise its memoryMaxMb limit or RAM allocation."
+		incident.Evidence = "oom_kill in the server cgroup's memory.events"
+	}
+	return incident, nil
+}
+
+// List returns the incidents of a server, newest first.
+func (m *Manager) List(serverID string) ([]domain.Incident, error) {
+	return m.Store.ListIncidents(serverID)
+}
+
+// Get returns an incident of the given server, or nil when there is none.
+func (m *Manager) Get(serverID, incidentID string) (*domain.Incident, error) {
+	incident, err := m.Store.GetIncident(incidentID)
+	if err != nil || incident == nil || incident.ServerID != serverID {
+		return nil, err
+	}
+	return incident, nil
+}
+
+func (m *Manager) serverDir(serverID string) (string, error) {
+	srv, err := m.Store.GetServerByID(serverID)
+	if err != nil {
+		return "", err
+	}
+	if srv == nil {
+		return "", fmt.Errorf("server not found")
+	}
+	folderName := srv.FolderName
+	if folderName == "" {
+		folderName = srv.ID
+	}
+	return filepath.Join(m.ServersPath, folderName), nil
+}
+
+func newestFile(dir, pattern string, since time.Time) string {
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return ""
+	}
+
+	var newest string
+	var newestTime time.Time
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.ModTime().Before(since) {
+			continue
+		}
+		if info.ModTime().After(newestTime) {
+			newest = path
+			newestTime = info.ModTime()
+		}
+	}
+	return newest
+}
+
+func readArtifact(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxArtifactSize))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
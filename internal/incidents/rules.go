@@ -0,0 +1,115 @@
+package incidents
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"naviger/internal/logevents"
+)
+
+const (
+	CauseOutOfMemory = "out_of_memory"
+	CauseWrongJava   = "wrong_java"
+	CauseEULA        = "eula_not_accepted"
+	CausePortInUse   = "port_in_use"
+	CauseModMismatch = "mod_mismatch"
+	CauseUnknown     = "unknown"
+)
+
+// maxEvidenceLength keeps the quoted matching line readable in lists.
+const maxEvidenceLength = 300
+
+// Rule classifies a crash when any of its patterns matches a line of the
+// console tail, crash report or JVM error log.
+type Rule struct {
+	Cause    string
+	Summary  string
+	Patterns []*regexp.Regexp
+}
+
+// Rules are tried in order; the first one with a matching line wins, so
+// causes that trigger others (running out of memory while loading mods, for
+// example) come first.
+var Rules = []Rule{
+	{
+		Cause:   CauseOutOfMemory,
+		Summary: "The server ran out of memory; raise its RAM allocation or remove memory-heavy mods.",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`java\.lang\.OutOfMemoryError`),
+			regexp.MustCompile(`There is insufficient memory for the Java Runtime Environment`),
+			regexp.MustCompile(`Could not reserve enough space for .*object heap`),
+		},
+	},
+	{
+		Cause:   CauseWrongJava,
+		Summary: "The server or one of its mods needs a different Java version.",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`UnsupportedClassVersionError`),
+			regexp.MustCompile(`compiled by a more recent version of the Java Runtime`),
+			regexp.MustCompile(`Unsupported Java detected`),
+			regexp.MustCompile(`(?i)requires (?:java|a java runtime) \d+`),
+			regexp.MustCompile(`Unrecognized VM option`),
+		},
+	},
+	{
+		Cause:   CauseEULA,
+		Summary: "The Minecraft EULA has not been accepted; set eula=true in eula.txt.",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`You need to agree to the EULA`),
+			regexp.MustCompile(`Failed to load eula\.txt`),
+		},
+	},
+	{
+		Cause:   CausePortInUse,
+		Summary: "The server port is already in use by another process.",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)failed to bind to port`),
+			regexp.MustCompile(`Address already in use`),
+			regexp.MustCompile(`java\.net\.BindException`),
+		},
+	},
+	{
+		Cause:   CauseModMismatch,
+		Summary: "Mods failed to load because of missing, duplicate or incompatible dependencies.",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`Missing or unsupported mandatory dependencies`),
+			regexp.MustCompile(`Incompatible mods? found`),
+			regexp.MustCompile(`ModResolutionException`),
+			regexp.MustCompile(`Mod resolution failed`),
+			regexp.MustCompile(`(?i)found duplicate mods`),
+			regexp.MustCompile(`DuplicateModsFoundException`),
+			regexp.MustCompile(`ModLoadingException`),
+			regexp.MustCompile(`requires (?:version|any version) .* of`),
+		},
+	},
+}
+
+// Classify applies Rules to the given texts and returns the cause, a short
+// explanation and the line that matched. Chat lines are skipped, so a player
+// typing an error message does not decide the cause.
+func Classify(exitCode int, texts ...string) (string, string, string) {
+	for _, rule := range Rules {
+		for _, text := range texts {
+			for _, line := range strings.Split(text, "\n") {
+				if event, ok := logevents.Parse(line); ok && event.Type == logevents.Chat {
+					continue
+				}
+				for _, pattern := range rule.Patterns {
+					if pattern.MatchString(line) {
+						return rule.Cause, rule.Summary, evidence(line)
+					}
+				}
+			}
+		}
+	}
+	return CauseUnknown, fmt.Sprintf("The server exited unexpectedly with code %d.", exitCode), ""
+}
+
+func evidence(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) > maxEvidenceLength {
+		line = line[:maxEvidenceLength] + "…"
+	}
+	return line
+}
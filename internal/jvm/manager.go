@@ -3,6 +3,7 @@ package jvm
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
@@ -11,64 +12,93 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+
+	"naviger/internal/domain"
+	"naviger/internal/loader"
 )
 
 type Manager struct {
 	RuntimesPath string
+	// Registry stores the system runtimes registered with Register; without
+	// it only the managed runtimes are available.
+	Registry  domain.JavaRuntimeRepository
+	installMu sync.Mutex
 }
 
 func NewManager(runtimesPath string) *Manager {
 	return &Manager{RuntimesPath: runtimesPath}
 }
 
+// EnsureJava returns the Java binary of the managed runtime for a major
+// version, installing it first when it is missing.
 func (m *Manager) EnsureJava(version int) (string, error) {
-	installDir := filepath.Join(m.RuntimesPath, fmt.Sprintf("java-%d", version))
-
-	javaBinName := "java"
-	if runtime.GOOS == "windows" {
-		javaBinName = "java.exe"
+	rt, err := m.Install(version, nil)
+	if err != nil {
+		return "", err
 	}
+	return rt.Java, nil
+}
 
-	isValid := func(path string) bool {
-		ok, _ := validateJavaVersion(path, version)
-		return ok
-	}
+// Install downloads the managed runtime for a major version unless a valid
+// one is installed already, reporting the download on progress when it is
+// not nil.
+func (m *Manager) Install(version int, progress chan<- domain.ProgressEvent) (Runtime, error) {
+	m.installMu.Lock()
+	defer m.installMu.Unlock()
+
+	id := ManagedID(version)
+	installDir := filepath.Join(m.RuntimesPath, id)
 
 	if fi, err := os.Stat(installDir); err == nil && fi.IsDir() {
-		if found, err := findJavaBin(installDir, javaBinName); err == nil {
-			if isValid(found) {
+		if found, err := findJavaBin(installDir, javaBinName()); err == nil {
+			if ok, _ := validateJavaVersion(found, version); ok {
 				if abs, err := filepath.Abs(found); err == nil {
-					return abs, nil
+					return describe(id, KindManaged, abs), nil
 				}
 			}
 		}
 	}
 
-	fmt.Printf("Java %d not detected. Starting automatic installation (%s)...\n", version, runtime.GOOS)
+	slog.Info("Installing Java runtime", "version", version, "os", runtime.GOOS)
 
-	if err := m.downloadAndInstall(version, installDir); err != nil {
+	if err := m.downloadAndInstall(version, installDir, progress); err != nil {
 		_ = os.RemoveAll(installDir)
-		return "", err
+		return Runtime{}, err
 	}
 
-	finalBin, err := findJavaBin(installDir, javaBinName)
+	finalBin, err := findJavaBin(installDir, javaBinName())
 	if err != nil {
-		return "", err
+		return Runtime{}, err
 	}
 
 	absPath, err := filepath.Abs(finalBin)
 	if err != nil {
-		return "", fmt.Errorf("could not get absolute path: %w", err)
+		return Runtime{}, fmt.Errorf("could not get absolute path: %w", err)
 	}
 
 	if runtime.GOOS != "windows" {
 		_ = os.Chmod(absPath, 0755)
 	}
 
-	return absPath, nil
+	return describe(id, KindManaged, absPath), nil
 }
 
-func (m *Manager) downloadAndInstall(version int, destDir string) error {
+// InstalledJava returns the Java binary of an installed runtime without
+// validating it, and false when the version is not installed.
+func (m *Manager) InstalledJava(version int) (string, bool) {
+	found, err := findJavaBin(filepath.Join(m.RuntimesPath, ManagedID(version)), javaBinName())
+	if err != nil {
+		return "", false
+	}
+	abs, err := filepath.Abs(found)
+	if err != nil {
+		return "", false
+	}
+	return abs, true
+}
+
+func (m *Manager) downloadAndInstall(version int, destDir string, progress chan<- domain.ProgressEvent) error {
 	osName := runtime.GOOS
 	arch := runtime.GOARCH
 
@@ -98,12 +128,13 @@ func (m *Manager) downloadAndInstall(version int, destDir string) error {
 		return fmt.Errorf("unsupported architecture: %s", arch)
 	}
 
+	id := ManagedID(version)
 	url := fmt.Sprintf(
 		"https://api.adoptium.net/v3/binary/latest/%d/ga/%s/%s/jre/hotspot/normal/eclipse",
 		version, apiOS, arch,
 	)
 
-	fmt.Printf("Downloading JRE from: %s\n", url)
+	slog.Info("Downloading Java runtime", "url", url)
 
 	tmpFile, err := os.CreateTemp("", "jdk-*"+ext)
 	if err != nil {
@@ -123,7 +154,14 @@ func (m *Manager) downloadAndInstall(version int, destDir string) error {
 	}
 
 	copyErr := func() error {
-		_, err := io.Copy(tmpFile, resp.Body)
+		body := &loader.ProgressReader{
+			Reader:       resp.Body,
+			Total:        resp.ContentLength,
+			ProgressChan: progress,
+			ServerID:     id,
+			Message:      fmt.Sprintf("Downloading Java %d", version),
+		}
+		_, err := io.Copy(tmpFile, body)
 		if err != nil {
 			_ = tmpFile.Close()
 			return err
@@ -141,7 +179,9 @@ func (m *Manager) downloadAndInstall(version int, destDir string) error {
 		return err
 	}
 
-	fmt.Printf("Unpacking %s...\n", ext)
+	if progress != nil {
+		progress <- domain.ProgressEvent{ServerID: id, Message: fmt.Sprintf("Unpacking Java %d", version), Progress: 100}
+	}
 
 	if ext == ".zip" {
 		if err := Unzip(tmpPath, destDir); err != nil {
@@ -156,6 +196,13 @@ func (m *Manager) downloadAndInstall(version int, destDir string) error {
 	return nil
 }
 
+func javaBinName() string {
+	if runtime.GOOS == "windows" {
+		return "java.exe"
+	}
+	return "java"
+}
+
 func findJavaBin(root, binName string) (string, error) {
 	var foundPath string
 	walkErr := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -187,31 +234,27 @@ func validateJavaVersion(javaPath string, required int) (bool, error) {
 	if err != nil {
 		return false, nil
 	}
-	s := string(out)
 	re := regexp.MustCompile(`version\s+"([^"]+)"`)
-	m := re.FindStringSubmatch(s)
+	m := re.FindStringSubmatch(string(out))
 	if len(m) < 2 {
 		return false, nil
 	}
-	verStr := m[1]
-	parts := strings.Split(verStr, ".")
-	var major int
-	if len(parts) > 0 {
-		if parts[0] == "1" && len(parts) > 1 {
-			sec := parts[1]
-			num := regexp.MustCompile(`\d+`).FindString(sec)
-			if num == "" {
-				return false, nil
-			}
-			major, _ = strconv.Atoi(num)
-		} else {
-			num := regexp.MustCompile(`\d+`).FindString(parts[0])
-			if num == "" {
-				return false, nil
-			}
-			major, _ = strconv.Atoi(num)
-		}
+	major := parseMajor(m[1])
+	if major == 0 {
+		return false, nil
 	}
 
 	return major >= required, nil
 }
+
+// parseMajor returns the major version of a Java version string such as
+// "21.0.4" or "1.8.0_412", or 0 when it has none.
+func parseMajor(version string) int {
+	parts := strings.Split(version, ".")
+	part := parts[0]
+	if part == "1" && len(parts) > 1 {
+		part = parts[1]
+	}
+	major, _ := strconv.Atoi(regexp.MustCompile(`\d+`).FindString(part))
+	return major
+}
@@ -0,0 +1,271 @@
+package jvm
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"naviger/internal/domain"
+)
+
+// Kinds of runtimes.
+const (
+	// KindManaged runtimes are downloaded into the runtimes folder.
+	KindManaged = "managed"
+	// KindSystem runtimes are existing installations registered by path.
+	KindSystem = "system"
+)
+
+var ErrRuntimeNotFound = errors.New("Java runtime not found")
+
+// Runtime is a Java installation servers can run with.
+type Runtime struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Home    string `json:"home"`
+	Java    string `json:"java"`
+	Major   int    `json:"major"`
+	Version string `json:"version"`
+	Vendor  string `json:"vendor"`
+	Size    int64  `json:"size"`
+}
+
+// ManagedID is the ID of the managed runtime for a Java major version, which
+// is also its folder in the runtimes folder.
+func ManagedID(major int) string {
+	return fmt.Sprintf("java-%d", major)
+}
+
+func managedMajor(id string) (int, bool) {
+	text, ok := strings.CutPrefix(id, "java-")
+	if !ok {
+		return 0, false
+	}
+	major, err := strconv.Atoi(text)
+	return major, err == nil && major > 0
+}
+
+// List returns the managed runtimes followed by the registered ones, with
+// the size of each installation.
+func (m *Manager) List() ([]Runtime, error) {
+	var runtimes []Runtime
+
+	entries, err := os.ReadDir(m.RuntimesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if _, ok := managedMajor(entry.Name()); !ok || !entry.IsDir() {
+			continue
+		}
+		rt, err := m.Runtime(entry.Name())
+		if err != nil {
+			// A folder without a Java binary is an interrupted install.
+			continue
+		}
+		runtimes = append(runtimes, rt)
+	}
+
+	if m.Registry != nil {
+		registered, err := m.Registry.ListJavaRuntimes()
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range registered {
+			runtimes = append(runtimes, describe(entry.ID, KindSystem, filepath.Join(entry.Home, "bin", javaBinName())))
+		}
+	}
+
+	for i := range runtimes {
+		// Runtimes registered before homes were checked may point at / or
+		// similar, which must not be walked.
+		if isJavaHome(runtimes[i].Home) {
+			runtimes[i].Size = dirSize(runtimes[i].Home)
+		}
+	}
+	return runtimes, nil
+}
+
+// Runtime returns an installed or registered runtime without downloading
+// anything.
+func (m *Manager) Runtime(id string) (Runtime, error) {
+	if _, ok := managedMajor(id); ok {
+		found, err := findJavaBin(filepath.Join(m.RuntimesPath, id), javaBinName())
+		if err != nil {
+			return Runtime{}, fmt.Errorf("%w: %s", ErrRuntimeNotFound, id)
+		}
+		abs, err := filepath.Abs(found)
+		if err != nil {
+			return Runtime{}, err
+		}
+		return describe(id, KindManaged, abs), nil
+	}
+
+	if m.Registry != nil {
+		registered, err := m.Registry.ListJavaRuntimes()
+		if err != nil {
+			return Runtime{}, err
+		}
+		for _, entry := range registered {
+			if entry.ID == id {
+				return describe(id, KindSystem, filepath.Join(entry.Home, "bin", javaBinName())), nil
+			}
+		}
+	}
+	return Runtime{}, fmt.Errorf("%w: %s", ErrRuntimeNotFound, id)
+}
+
+// Register adds an existing Java installation, given as its home folder or
+// its java binary. Registering the same installation twice returns the
+// existing runtime.
+func (m *Manager) Register(path string) (Runtime, error) {
+	if m.Registry == nil {
+		return Runtime{}, errors.New("registering runtimes is not available")
+	}
+
+	home, err := filepath.Abs(path)
+	if err != nil {
+		return Runtime{}, err
+	}
+	// Binaries like /usr/bin/java are usually links into the installation.
+	home, err = filepath.EvalSymlinks(home)
+	if err != nil {
+		return Runtime{}, err
+	}
+	fi, err := os.Stat(home)
+	if err != nil {
+		return Runtime{}, err
+	}
+	if !fi.IsDir() {
+		home = filepath.Dir(filepath.Dir(home))
+	}
+
+	javaPath := filepath.Join(home, "bin", javaBinName())
+	if _, err := os.Stat(javaPath); err != nil {
+		return Runtime{}, fmt.Errorf("no %s in %s", filepath.Join("bin", javaBinName()), home)
+	}
+	if !isJavaHome(home) {
+		return Runtime{}, fmt.Errorf("%s is not a Java installation: it has no release file or class library", home)
+	}
+
+	sum := sha1.Sum([]byte(home))
+	id := "system-" + hex.EncodeToString(sum[:4])
+
+	rt := describe(id, KindSystem, javaPath)
+	if rt.Major == 0 {
+		return Runtime{}, fmt.Errorf("%s is not a working Java runtime", javaPath)
+	}
+
+	if err := m.Registry.SaveJavaRuntime(&domain.JavaRuntime{ID: id, Home: home, CreatedAt: time.Now()}); err != nil {
+		return Runtime{}, err
+	}
+	return rt, nil
+}
+
+// isJavaHome reports whether home looks like the root of a JDK or JRE: it
+// has a release file or the class library in lib, so a folder such as / with
+// a bin/java in it is not taken for one.
+func isJavaHome(home string) bool {
+	for _, marker := range []string{"release", filepath.Join("lib", "modules"), filepath.Join("lib", "rt.jar"), filepath.Join("jre", "lib", "rt.jar")} {
+		if _, err := os.Stat(filepath.Join(home, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove deletes a managed runtime from disk, or forgets a registered one
+// and leaves its installation alone.
+func (m *Manager) Remove(id string) error {
+	rt, err := m.Runtime(id)
+	if err != nil {
+		return err
+	}
+	if rt.Kind == KindSystem {
+		return m.Registry.DeleteJavaRuntime(id)
+	}
+
+	m.installMu.Lock()
+	defer m.installMu.Unlock()
+	return os.RemoveAll(filepath.Join(m.RuntimesPath, id))
+}
+
+// describe reads the version and vendor of the runtime whose binary is
+// javaPath from the release file of the installation, or asks the binary
+// when there is none.
+func describe(id, kind, javaPath string) Runtime {
+	home := filepath.Dir(filepath.Dir(javaPath))
+	rt := Runtime{ID: id, Kind: kind, Home: home, Java: javaPath}
+
+	release := readRelease(filepath.Join(home, "release"))
+	rt.Version = release["JAVA_VERSION"]
+	rt.Vendor = release["IMPLEMENTOR"]
+	if rt.Version == "" {
+		props := probeProperties(javaPath)
+		rt.Version = props["java.version"]
+		rt.Vendor = props["java.vendor"]
+	}
+	rt.Major = parseMajor(rt.Version)
+	return rt
+}
+
+// readRelease parses the KEY="value" lines of a JDK release file.
+func readRelease(path string) map[string]string {
+	values := make(map[string]string)
+	file, err := os.Open(path)
+	if err != nil {
+		return values
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok {
+			values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return values
+}
+
+// probeProperties returns the system properties a Java binary prints with
+// -XshowSettings:properties.
+func probeProperties(javaPath string) map[string]string {
+	values := make(map[string]string)
+	out, err := exec.Command(javaPath, "-XshowSettings:properties", "-version").CombinedOutput()
+	if err != nil {
+		return values
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, ok := strings.Cut(line, " = ")
+		if ok {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,62 @@
+package jvm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMajor(t *testing.T) {
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{"21.0.4", 21},
+		{"17", 17},
+		{"1.8.0_412", 8},
+		{"25-ea", 25},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseMajor(tt.version); got != tt.want {
+			t.Errorf("parseMajor(%q) = %d, want %d", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestManagedMajor(t *testing.T) {
+	if major, ok := managedMajor(ManagedID(21)); !ok || major != 21 {
+		t.Errorf("managedMajor(%q) = %d, %v", ManagedID(21), major, ok)
+	}
+	for _, id := range []string{"java-", "java-x", "system-1234abcd", "java-0"} {
+		if _, ok := managedMajor(id); ok {
+			t.Errorf("managedMajor(%q) accepted", id)
+		}
+	}
+}
+
+func TestIsJavaHome(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  bool
+	}{
+		{[]string{"bin/java", "release"}, true},
+		{[]string{"bin/java", "lib/modules"}, true},
+		{[]string{"bin/java", "jre/lib/rt.jar"}, true},
+		{[]string{"bin/java", "lib/libc.so.6"}, false},
+		{[]string{"bin/java"}, false},
+	}
+
+	for _, tt := range tests {
+		home := t.TempDir()
+		for _, file := range tt.files {
+			path := filepath.Join(home, filepath.FromSlash(file))
+			os.MkdirAll(filepath.Dir(path), 0755)
+			os.WriteFile(path, nil, 0644)
+		}
+		if got := isJavaHome(home); got != tt.want {
+			t.Errorf("isJavaHome with %v = %v, want %v", tt.files, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package jvmflags
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ParseArgs splits s into arguments the way a POSIX shell would, without
+// expansions: whitespace separates arguments, single quotes keep everything
+// literally, double quotes keep everything but a backslash before " or \, and
+// a backslash outside quotes escapes the next character.
+func ParseArgs(s string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	// A backslash in double quotes only escapes a quote or a backslash, so
+	// Windows paths survive quoting.
+	literal := func(r rune) bool { return quote != '"' || r == '"' || r == '\\' }
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			if !literal(r) {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' {
+				escaped = true
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quote in arguments")
+	}
+	if escaped {
+		return nil, errors.New("arguments end with a backslash")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// QuoteArgs joins args into one shell command line that ParseArgs reads back
+// into the same arguments.
+func QuoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteArg(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func quoteArg(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	safe := strings.IndexFunc(arg, func(r rune) bool {
+		return !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("@%+=:,./_-", r)))
+	}) < 0
+	if safe {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
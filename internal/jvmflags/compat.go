@@ -0,0 +1,72 @@
+package jvmflags
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// javaRange is the Java major versions an option is accepted by; 0 leaves a
+// side open.
+type javaRange struct {
+	min, max int
+}
+
+// xxFlags are -XX options that stop the JVM from starting outside their
+// range, keyed by name.
+var xxFlags = map[string]javaRange{
+	"UseZGC":                         {min: 15},
+	"ZGenerational":                  {min: 21},
+	"UseShenandoahGC":                {min: 12},
+	"UseCompactObjectHeaders":        {min: 24},
+	"UseParNewGC":                    {max: 9},
+	"AggressiveOpts":                 {max: 12},
+	"UseConcMarkSweepGC":             {max: 13},
+	"CMSInitiatingOccupancyFraction": {max: 13},
+	"PermSize":                       {max: 16},
+	"MaxPermSize":                    {max: 16},
+}
+
+// options are other options with a limited range, keyed by the part before
+// any "=" or ":".
+var options = map[string]javaRange{
+	"--add-opens":      {min: 9},
+	"--add-exports":    {min: 9},
+	"--add-modules":    {min: 9},
+	"--enable-preview": {min: 12},
+	"-Xlog":            {min: 9},
+}
+
+// Check reports the options in args that the given Java major version does
+// not accept.
+func Check(args []string, java int) error {
+	var problems []error
+	for _, arg := range args {
+		name, supported, ok := lookup(arg)
+		if !ok {
+			continue
+		}
+		if supported.min > 0 && java < supported.min {
+			problems = append(problems, fmt.Errorf("%s needs Java %d or newer, the server uses Java %d", name, supported.min, java))
+		}
+		if supported.max > 0 && java > supported.max {
+			problems = append(problems, fmt.Errorf("%s was removed after Java %d, the server uses Java %d", name, supported.max, java))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+func lookup(arg string) (string, javaRange, bool) {
+	if flag, ok := strings.CutPrefix(arg, "-XX:"); ok {
+		flag = strings.TrimLeft(flag, "+-")
+		flag, _, _ = strings.Cut(flag, "=")
+		supported, ok := xxFlags[flag]
+		return "-XX:" + flag, supported, ok
+	}
+	name, _, _ := strings.Cut(arg, "=")
+	if strings.HasPrefix(name, "-X") {
+		name, _, _ = strings.Cut(name, ":")
+	}
+	supported, ok := options[name]
+	return name, supported, ok
+}
@@ -0,0 +1,122 @@
+package jvmflags
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+		fail bool
+	}{
+		{in: "", want: nil},
+		{in: "  -Da=1   -Db=2 ", want: []string{"-Da=1", "-Db=2"}},
+		{in: `-Dmotd="Hello world" -Dx=y`, want: []string{"-Dmotd=Hello world", "-Dx=y"}},
+		{in: `'-Dname=it''s'`, want: []string{"-Dname=its"}},
+		{in: `'-Dq=a "b"'`, want: []string{`-Dq=a "b"`}},
+		{in: `-Dpath="C:\Servers\My Server"`, want: []string{`-Dpath=C:\Servers\My Server`}},
+		{in: `-Dq="say \"hi\""`, want: []string{`-Dq=say "hi"`}},
+		{in: `a\ b ""`, want: []string{"a b", ""}},
+		{in: `-Dmotd="unterminated`, fail: true},
+		{in: `trailing\`, fail: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseArgs(tt.in)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("ParseArgs(%q) = %q, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseArgs(%q): %v", tt.in, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteArgsRoundTrip(t *testing.T) {
+	args := []string{"java", "-Dmotd=it's a server", "", `C:\Java\bin\java.exe`, "-Xmx2048M", "@libraries/args.txt"}
+	line := QuoteArgs(args)
+	got, err := ParseArgs(line)
+	if err != nil {
+		t.Fatalf("ParseArgs(%q): %v", line, err)
+	}
+	if !slices.Equal(got, args) {
+		t.Errorf("round trip of %q = %q", args, got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		args []string
+		java int
+		want string
+	}{
+		{args: []string{"-XX:+UseZGC", "-XX:+ZGenerational"}, java: 21},
+		{args: []string{"-XX:+UseZGC", "-XX:+ZGenerational"}, java: 17, want: "-XX:ZGenerational needs Java 21"},
+		{args: []string{"-XX:+UseConcMarkSweepGC"}, java: 8},
+		{args: []string{"-XX:+UseConcMarkSweepGC"}, java: 17, want: "-XX:UseConcMarkSweepGC was removed after Java 13"},
+		{args: []string{"-XX:MaxPermSize=256M"}, java: 17, want: "-XX:MaxPermSize was removed"},
+		{args: []string{"--add-opens=java.base/java.lang=ALL-UNNAMED"}, java: 8, want: "--add-opens needs Java 9"},
+		{args: []string{"-Xlog:gc*:file=gc.log"}, java: 8, want: "-Xlog needs Java 9"},
+		{args: []string{"-Xmx4G", "-Dfoo=bar"}, java: 8},
+	}
+
+	for _, tt := range tests {
+		err := Check(tt.args, tt.java)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("Check(%q, %d): %v", tt.args, tt.java, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Check(%q, %d) = %v, want %q", tt.args, tt.java, err, tt.want)
+		}
+	}
+}
+
+func TestProfilesSizeHeap(t *testing.T) {
+	tests := []struct {
+		profile string
+		ram     int
+		java    int
+		want    []string
+		absent  string
+	}{
+		{profile: "", ram: 4096, java: 17, want: []string{"-Xms512M", "-Xmx4096M"}},
+		{profile: "default", ram: 384, java: 8, want: []string{"-Xms384M", "-Xmx384M"}},
+		{profile: "aikar", ram: 8192, java: 17, want: []string{"-Xms8192M", "-Xmx8192M", "-XX:G1HeapRegionSize=8M"}},
+		{profile: "aikar", ram: 16384, java: 21, want: []string{"-XX:G1HeapRegionSize=16M", "-XX:G1NewSizePercent=40"}},
+		{profile: "zgc", ram: 8192, java: 21, want: []string{"-Xms8192M", "-XX:+UseZGC", "-XX:+ZGenerational"}},
+		{profile: "zgc", ram: 8192, java: 24, want: []string{"-XX:+UseZGC"}, absent: "-XX:+ZGenerational"},
+		{profile: "low-memory", ram: 1024, java: 17, want: []string{"-Xms256M", "-Xmx1024M", "-XX:+UseSerialGC"}},
+	}
+
+	for _, tt := range tests {
+		profile, ok := Get(tt.profile)
+		if !ok {
+			t.Fatalf("profile %q missing", tt.profile)
+		}
+		flags := profile.Flags(tt.ram, tt.java)
+		for _, want := range tt.want {
+			if !slices.Contains(flags, want) {
+				t.Errorf("%s(%d MB, Java %d) = %q, missing %s", profile.Name, tt.ram, tt.java, flags, want)
+			}
+		}
+		if tt.absent != "" && slices.Contains(flags, tt.absent) {
+			t.Errorf("%s(%d MB, Java %d) = %q, should not contain %s", profile.Name, tt.ram, tt.java, flags, tt.absent)
+		}
+		if err := Check(flags, tt.java); err != nil {
+			t.Errorf("%s flags rejected on Java %d: %v", profile.Name, tt.java, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Package jvmflags builds the Java options servers start with: named flag
+// profiles sized from the server's RAM, shell-style parsing of custom
+// arguments and a check of the options against the Java version.
+package jvmflags
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultProfile is used by servers without a profile.
+const DefaultProfile = "default"
+
+// Profile is a named set of JVM flags.
+type Profile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// MinJava is the oldest Java major version the flags work on.
+	MinJava int `json:"minJava,omitempty"`
+	// Flags returns the options for a heap of ram MB on Java major version
+	// java, heap sizes included.
+	Flags func(ram, java int) []string `json:"-"`
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]Profile{}
+)
+
+// Register adds a profile, replacing any with the same name.
+func Register(profile Profile) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles[profile.Name] = profile
+}
+
+// Get returns the named profile; an empty name is DefaultProfile.
+func Get(name string) (Profile, bool) {
+	if name == "" {
+		name = DefaultProfile
+	}
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	profile, ok := profiles[name]
+	return profile, ok
+}
+
+// List returns every profile sorted by name.
+func List() []Profile {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	list := make([]Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		list = append(list, profile)
+	}
+	slices.SortFunc(list, func(a, b Profile) int { return strings.Compare(a.Name, b.Name) })
+	return list
+}
+
+func heap(xms, xmx int) []string {
+	return []string{fmt.Sprintf("-Xms%dM", xms), fmt.Sprintf("-Xmx%dM", xmx)}
+}
+
+func init() {
+	Register(Profile{
+		Name:        DefaultProfile,
+		Description: "JVM defaults with the heap capped at the server's RAM",
+		Flags: func(ram, java int) []string {
+			return heap(min(512, ram), ram)
+		},
+	})
+
+	// https://docs.papermc.io/paper/aikars-flags
+	Register(Profile{
+		Name:        "aikar",
+		Description: "Aikar's tuned G1 flags, the usual choice for Paper servers",
+		Flags: func(ram, java int) []string {
+			newSize, maxNewSize, region, reserve, occupancy := 30, 40, 8, 20, 15
+			if ram >= 12*1024 {
+				newSize, maxNewSize, region, reserve, occupancy = 40, 50, 16, 15, 20
+			}
+			return append(heap(ram, ram),
+				"-XX:+UseG1GC",
+				"-XX:+ParallelRefProcEnabled",
+				"-XX:MaxGCPauseMillis=200",
+				"-XX:+UnlockExperimentalVMOptions",
+				"-XX:+DisableExplicitGC",
+				"-XX:+AlwaysPreTouch",
+				fmt.Sprintf("-XX:G1NewSizePercent=%d", newSize),
+				fmt.Sprintf("-XX:G1MaxNewSizePercent=%d", maxNewSize),
+				fmt.Sprintf("-XX:G1HeapRegionSize=%dM", region),
+				fmt.Sprintf("-XX:G1ReservePercent=%d", reserve),
+				"-XX:G1HeapWastePercent=5",
+				"-XX:G1MixedGCCountTarget=4",
+				fmt.Sprintf("-XX:InitiatingHeapOccupancyPercent=%d", occupancy),
+				"-XX:G1MixedGCLiveThresholdPercent=90",
+				"-XX:G1RSetUpdatingPauseTimePercent=5",
+				"-XX:SurvivorRatio=32",
+				"-XX:+PerfDisableSharedMem",
+				"-XX:MaxTenuringThreshold=1",
+				"-Dusing.aikars.flags=https://mcflags.emc.gs",
+				"-Daikars.new.flags=true",
+			)
+		},
+	})
+
+	Register(Profile{
+		Name:        "zgc",
+		Description: "Generational ZGC for large heaps with very short pauses",
+		MinJava:     21,
+		Flags: func(ram, java int) []string {
+			flags := append(heap(ram, ram), "-XX:+UseZGC")
+			// From Java 23 generational mode is the default, and the only
+			// one from 24 on.
+			if java < 23 {
+				flags = append(flags, "-XX:+ZGenerational")
+			}
+			return append(flags,
+				"-XX:+AlwaysPreTouch",
+				"-XX:+DisableExplicitGC",
+				"-XX:+PerfDisableSharedMem",
+			)
+		},
+	})
+
+	Register(Profile{
+		Name:        "low-memory",
+		Description: "Serial GC and a heap that shrinks when idle, for small servers",
+		Flags: func(ram, java int) []string {
+			return append(heap(min(256, ram), ram),
+				"-XX:+UseSerialGC",
+				"-XX:MinHeapFreeRatio=10",
+				"-XX:MaxHeapFreeRatio=30",
+				"-Xss512k",
+			)
+		},
+	})
+}
@@ -0,0 +1,45 @@
+package loader
+
+import (
+	"fmt"
+	"naviger/internal/domain"
+	"path/filepath"
+)
+
+const BungeeCordJarURL = "https://ci.md-5.net/job/BungeeCord/lastSuccessfulBuild/artifact/bootstrap/target/BungeeCord.jar"
+
+// BungeeCordLoader downloads the latest successful build from md_5's CI, which
+// does not publish versioned artifacts.
+type BungeeCordLoader struct{}
+
+func NewBungeeCordLoader() *BungeeCordLoader {
+	return &BungeeCordLoader{}
+}
+
+func (l *BungeeCordLoader) GetSupportedVersions() ([]string, error) {
+	return []string{"latest"}, nil
+}
+
+func (l *BungeeCordLoader) RequiredJava(version string) (int, error) {
+	return ProxyJavaVersion, nil
+}
+
+func (l *BungeeCordLoader) Load(versionID string, destDir string, progressChan chan<- domain.ProgressEvent) error {
+	if versionID != "latest" {
+		return fmt.Errorf("version %s not found in BungeeCord (only 'latest' is available)", versionID)
+	}
+
+	finalPath := filepath.Join(destDir, "server.jar")
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: fmt.Sprintf("Downloading BungeeCord.jar from: %s", BungeeCordJarURL)}
+	}
+
+	if err := downloadToFile(BungeeCordJarURL, finalPath, "Downloading BungeeCord.jar", progressChan); err != nil {
+		return err
+	}
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: "Installation completed.", Progress: 100}
+	}
+	return nil
+}
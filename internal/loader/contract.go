@@ -6,3 +6,24 @@ type ServerLoader interface {
 	Load(version string, destDir string, progressChan chan<- domain.ProgressEvent) error
 	GetSupportedVersions() ([]string, error)
 }
+
+// LoaderVersionPinner is implemented by mod loaders that can install a given
+// build of the loader instead of the latest one for the Minecraft version,
+// as modpacks require.
+type LoaderVersionPinner interface {
+	PinLoaderVersion(version string)
+}
+
+// pickLoaderVersion returns the pinned loader version when it is available,
+// or the latest available one when nothing is pinned.
+func pickLoaderVersion(available []string, pinned string) (string, bool) {
+	if pinned == "" {
+		return available[0], true
+	}
+	for _, v := range available {
+		if v == pinned {
+			return v, true
+		}
+	}
+	return "", false
+}
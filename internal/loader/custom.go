@@ -0,0 +1,69 @@
+package loader
+
+import (
+	"fmt"
+	"io"
+	"naviger/internal/domain"
+	"os"
+	"path/filepath"
+)
+
+// CustomLoader installs a user supplied server jar, either from a URL or from
+// a file that was already uploaded to the daemon.
+type CustomLoader struct {
+	URL        string
+	SourcePath string
+}
+
+func NewCustomLoader(url, sourcePath string) *CustomLoader {
+	return &CustomLoader{URL: url, SourcePath: sourcePath}
+}
+
+func (l *CustomLoader) GetSupportedVersions() ([]string, error) {
+	return []string{}, nil
+}
+
+func (l *CustomLoader) Load(versionID string, destDir string, progressChan chan<- domain.ProgressEvent) error {
+	finalPath := filepath.Join(destDir, "server.jar")
+
+	switch {
+	case l.SourcePath != "":
+		if progressChan != nil {
+			progressChan <- domain.ProgressEvent{Message: "Copying uploaded server.jar..."}
+		}
+		if err := copyFile(l.SourcePath, finalPath); err != nil {
+			return fmt.Errorf("error copying uploaded jar: %w", err)
+		}
+	case l.URL != "":
+		if progressChan != nil {
+			progressChan <- domain.ProgressEvent{Message: fmt.Sprintf("Downloading server.jar from: %s", l.URL)}
+		}
+		if err := downloadToFile(l.URL, finalPath, "Downloading server.jar", progressChan); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("custom loader requires a jar URL or an uploaded jar")
+	}
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: "Installation completed.", Progress: 100}
+	}
+	return nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,41 @@
+package loader
+
+import (
+	"fmt"
+	"io"
+	"naviger/internal/domain"
+	"net/http"
+	"os"
+)
+
+func downloadToFile(url string, dest string, message string, progressChan chan<- domain.ProgressEvent) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error downloading file: status %d", resp.StatusCode)
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: "Starting download..."}
+	}
+
+	progressReader := &ProgressReader{
+		Reader:       resp.Body,
+		Total:        resp.ContentLength,
+		ProgressChan: progressChan,
+		Message:      message,
+	}
+
+	_, err = io.Copy(out, progressReader)
+	return err
+}
@@ -26,12 +26,18 @@ type FabricInstallerVersion struct {
 	Stable  bool   `json:"stable"`
 }
 
-type FabricLoader struct{}
+type FabricLoader struct {
+	loaderVersion string
+}
 
 func NewFabricLoader() *FabricLoader {
 	return &FabricLoader{}
 }
 
+func (l *FabricLoader) PinLoaderVersion(version string) {
+	l.loaderVersion = version
+}
+
 func (l *FabricLoader) GetSupportedVersions() ([]string, error) {
 	return l.getGameVersions()
 }
@@ -68,7 +74,10 @@ func (l *FabricLoader) Load(versionID string, destDir string, progressChan chan<
 	if len(loaderVersions) == 0 {
 		return fmt.Errorf("no loader versions found for Fabric")
 	}
-	latestLoaderVersion := loaderVersions[0]
+	loaderVersion, ok := pickLoaderVersion(loaderVersions, l.loaderVersion)
+	if !ok {
+		return fmt.Errorf("loader version %s not found in Fabric", l.loaderVersion)
+	}
 
 	if progressChan != nil {
 		progressChan <- domain.ProgressEvent{Message: "Getting latest installer version..."}
@@ -79,7 +88,7 @@ func (l *FabricLoader) Load(versionID string, destDir string, progressChan chan<
 	}
 
 	downloadURL := fmt.Sprintf("%sloader/%s/%s/%s/server/jar",
-		FabricAPIURL, versionID, latestLoaderVersion, installerVersion)
+		FabricAPIURL, versionID, loaderVersion, installerVersion)
 
 	finalPath := filepath.Join(destDir, "server.jar")
 	if progressChan != nil {
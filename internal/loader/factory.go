@@ -1,22 +1,75 @@
 package loader
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
+
+type Kind string
+
+const (
+	KindServer Kind = "server"
+	KindProxy  Kind = "proxy"
+	KindCustom Kind = "custom"
+)
+
+type Factory func() ServerLoader
+
+type Registration struct {
+	Name string  `json:"name"`
+	Kind Kind    `json:"kind"`
+	New  Factory `json:"-"`
+}
+
+var (
+	registryMu    sync.RWMutex
+	registry      = make(map[string]Registration)
+	registryOrder []string
+)
+
+func init() {
+	Register("vanilla", KindServer, func() ServerLoader { return NewVanillaLoader() })
+	Register("paper", KindServer, func() ServerLoader { return NewPaperLoader() })
+	Register("spigot", KindServer, func() ServerLoader { return NewSpigotLoader() })
+	Register("fabric", KindServer, func() ServerLoader { return NewFabricLoader() })
+	Register("forge", KindServer, func() ServerLoader { return NewForgeLoader() })
+	Register("neoforge", KindServer, func() ServerLoader { return NewNeoForgeLoader() })
+	Register("velocity", KindProxy, func() ServerLoader { return NewVelocityLoader() })
+	Register("bungeecord", KindProxy, func() ServerLoader { return NewBungeeCordLoader() })
+	Register("waterfall", KindProxy, func() ServerLoader { return NewWaterfallLoader() })
+	Register("custom", KindCustom, func() ServerLoader { return NewCustomLoader("", "") })
+}
+
+// Register adds a loader under the given name, replacing any previous
+// registration with the same name. It is safe to call from init functions of
+// packages that ship their own loaders.
+func Register(name string, kind Kind, factory Factory) {
+	if name == "" || factory == nil {
+		panic("loader: Register requires a name and a factory")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; !exists {
+		registryOrder = append(registryOrder, name)
+	}
+	registry[name] = Registration{Name: name, Kind: kind, New: factory}
+}
+
+func GetRegistration(loaderType string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	reg, ok := registry[loaderType]
+	return reg, ok
+}
 
 func GetLoader(loaderType string) (ServerLoader, error) {
-	switch loaderType {
-	case "vanilla":
-		return NewVanillaLoader(), nil
-	case "paper":
-		return NewPaperLoader(), nil
-	case "fabric":
-		return NewFabricLoader(), nil
-	case "forge":
-		return NewForgeLoader(), nil
-	case "neoforge":
-		return NewNeoForgeLoader(), nil
-	default:
+	reg, ok := GetRegistration(loaderType)
+	if !ok {
 		return nil, fmt.Errorf("loader type '%s' not supported", loaderType)
 	}
+	return reg.New(), nil
 }
 
 func GetLoaderVersions(loaderType string) ([]string, error) {
@@ -28,5 +81,24 @@ func GetLoaderVersions(loaderType string) ([]string, error) {
 }
 
 func GetAvailableLoaders() []string {
-	return []string{"vanilla", "paper", "fabric", "forge", "neoforge"}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	loaders := make([]string, len(registryOrder))
+	copy(loaders, registryOrder)
+	return loaders
+}
+
+func ListRegistrations() []Registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	regs := make([]Registration, 0, len(registryOrder))
+	for _, name := range registryOrder {
+		regs = append(regs, registry[name])
+	}
+	return regs
+}
+
+func IsProxy(loaderType string) bool {
+	reg, ok := GetRegistration(loaderType)
+	return ok && reg.Kind == KindProxy
 }
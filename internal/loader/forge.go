@@ -13,12 +13,18 @@ import (
 
 const ForgeAPIURL = "https://bmclapi2.bangbang93.com/forge/"
 
-type ForgeLoader struct{}
+type ForgeLoader struct {
+	loaderVersion string
+}
 
 func NewForgeLoader() *ForgeLoader {
 	return &ForgeLoader{}
 }
 
+func (l *ForgeLoader) PinLoaderVersion(version string) {
+	l.loaderVersion = version
+}
+
 func (l *ForgeLoader) GetSupportedVersions() ([]string, error) {
 	resp, err := http.Get(ForgeAPIURL + "minecraft")
 	if err != nil {
@@ -100,9 +106,12 @@ func (l *ForgeLoader) Load(versionID string, destDir string, progressChan chan<-
 	if len(loaderVersions) == 0 {
 		return fmt.Errorf("no loader versions found for Forge on minecraft version %s", versionID)
 	}
-	latestLoaderVersion := loaderVersions[0]
+	loaderVersion, ok := pickLoaderVersion(loaderVersions, l.loaderVersion)
+	if !ok {
+		return fmt.Errorf("loader version %s not found in Forge for minecraft version %s", l.loaderVersion, versionID)
+	}
 
-	forgeVersion := fmt.Sprintf("%s-%s", versionID, latestLoaderVersion)
+	forgeVersion := fmt.Sprintf("%s-%s", versionID, loaderVersion)
 	downloadURL := fmt.Sprintf("https://maven.minecraftforge.net/net/minecraftforge/forge/%s/forge-%s-installer.jar", forgeVersion, forgeVersion)
 
 	installerPath := filepath.Join(destDir, "installer.jar")
@@ -0,0 +1,61 @@
+package loader
+
+import "fmt"
+
+// ProxyJavaVersion is the Java major version proxies run with. Their
+// versions are unrelated to Minecraft versions and current builds need the
+// latest LTS release.
+const ProxyJavaVersion = 21
+
+// JavaResolver is implemented by loaders whose servers need another Java
+// version than the Minecraft version they run would.
+type JavaResolver interface {
+	// RequiredJava returns the Java major version a server of the given
+	// version needs, or 0 to use the one Mojang lists.
+	RequiredJava(version string) (int, error)
+}
+
+// JavaRunner is implemented by loaders that run Java while installing, such
+// as to compile the server. SetJava gives them the binary of the runtime the
+// server needs; without it they use java from PATH.
+type JavaRunner interface {
+	SetJava(javaPath string)
+}
+
+// ResolveJava returns the Java major version a server of the given loader
+// and version needs: the loader's own requirement if it has one, otherwise
+// the one Mojang lists for the Minecraft version.
+func ResolveJava(l ServerLoader, version string) (int, error) {
+	if resolver, ok := l.(JavaResolver); ok {
+		java, err := resolver.RequiredJava(version)
+		if err != nil || java > 0 {
+			return java, err
+		}
+	}
+	return MojangJavaVersion(version)
+}
+
+// MojangJavaVersion reads the Java major version of a Minecraft version from
+// the javaVersion field of its version JSON.
+func MojangJavaVersion(mcVersion string) (int, error) {
+	vanilla := NewVanillaLoader()
+	manifest, err := vanilla.fetchManifest()
+	if err != nil {
+		return 0, fmt.Errorf("could not get version manifest: %w", err)
+	}
+
+	for _, v := range manifest.Versions {
+		if v.ID != mcVersion {
+			continue
+		}
+		details, err := vanilla.fetchVersionDetails(v.URL)
+		if err != nil {
+			return 0, err
+		}
+		if details.JavaVersion.MajorVersion == 0 {
+			return 0, fmt.Errorf("version %s does not list a Java version", mcVersion)
+		}
+		return details.JavaVersion.MajorVersion, nil
+	}
+	return 0, fmt.Errorf("version %s not found in Mojang", mcVersion)
+}
@@ -14,12 +14,18 @@ import (
 
 const NeoForgeAPIURL = "https://maven.neoforged.net/api/maven/versions/releases/net%2Fneoforged%2Fneoforge"
 
-type NeoForgeLoader struct{}
+type NeoForgeLoader struct {
+	loaderVersion string
+}
 
 func NewNeoForgeLoader() *NeoForgeLoader {
 	return &NeoForgeLoader{}
 }
 
+func (l *NeoForgeLoader) PinLoaderVersion(version string) {
+	l.loaderVersion = version
+}
+
 type NeoForgeVersionsResponse struct {
 	Versions []string `json:"versions"`
 }
@@ -135,6 +141,12 @@ func (l *NeoForgeLoader) getLoaderVersions(minecraftVersion string) ([]string, e
 	return loaderVersionsList, nil
 }
 
+// RequiredJava looks up the Minecraft version a NeoForge version is listed
+// under, where Mojang drops the ".0" of first releases such as 1.21.
+func (l *NeoForgeLoader) RequiredJava(version string) (int, error) {
+	return MojangJavaVersion(strings.TrimSuffix(version, ".0"))
+}
+
 func (l *NeoForgeLoader) Load(versionID string, destDir string, progressChan chan<- domain.ProgressEvent) error {
 	if progressChan != nil {
 		progressChan <- domain.ProgressEvent{Message: fmt.Sprintf("Searching for version %s...", versionID)}
@@ -168,9 +180,12 @@ func (l *NeoForgeLoader) Load(versionID string, destDir string, progressChan cha
 		return fmt.Errorf("no loader versions found for NeoForge on minecraft version %s", versionID)
 	}
 
-	latestLoaderVersion := loaderVersions[0]
+	loaderVersion, ok := pickLoaderVersion(loaderVersions, l.loaderVersion)
+	if !ok {
+		return fmt.Errorf("loader version %s not found in NeoForge for minecraft version %s", l.loaderVersion, versionID)
+	}
 
-	downloadURL := fmt.Sprintf("https://maven.neoforged.net/releases/net/neoforged/neoforge/%s/neoforge-%s-installer.jar", latestLoaderVersion, latestLoaderVersion)
+	downloadURL := fmt.Sprintf("https://maven.neoforged.net/releases/net/neoforged/neoforge/%s/neoforge-%s-installer.jar", loaderVersion, loaderVersion)
 
 	installerPath := filepath.Join(destDir, "installer.jar")
 	if progressChan != nil {
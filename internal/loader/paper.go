@@ -11,7 +11,10 @@ import (
 	"strings"
 )
 
-const PaperAPIURL = "https://api.papermc.io/v2/projects/paper/"
+const (
+	PaperMCProjectsURL = "https://api.papermc.io/v2/projects/"
+	PaperAPIURL        = PaperMCProjectsURL + "paper/"
+)
 
 type PaperVersionsResponse struct {
 	Versions []string `json:"versions"`
@@ -21,16 +24,41 @@ type PaperBuildsResponse struct {
 	Builds []int `json:"builds"`
 }
 
-type PaperLoader struct{}
+type PaperLoader struct {
+	project        string
+	displayName    string
+	allowSnapshots bool
+}
 
 func NewPaperLoader() *PaperLoader {
-	return &PaperLoader{}
+	return &PaperLoader{project: "paper", displayName: "Paper"}
+}
+
+func NewVelocityLoader() *PaperLoader {
+	return &PaperLoader{project: "velocity", displayName: "Velocity", allowSnapshots: true}
+}
+
+func NewWaterfallLoader() *PaperLoader {
+	return &PaperLoader{project: "waterfall", displayName: "Waterfall"}
+}
+
+func (l *PaperLoader) apiURL() string {
+	return PaperMCProjectsURL + l.project + "/"
 }
 
 func (l *PaperLoader) GetSupportedVersions() ([]string, error) {
 	return l.getVersions()
 }
 
+// RequiredJava leaves Paper servers to the Mojang requirement and runs the
+// proxies of the family on ProxyJavaVersion.
+func (l *PaperLoader) RequiredJava(version string) (int, error) {
+	if IsProxy(l.project) {
+		return ProxyJavaVersion, nil
+	}
+	return 0, nil
+}
+
 func (l *PaperLoader) Load(versionID string, destDir string, progressChan chan<- domain.ProgressEvent) error {
 	if progressChan != nil {
 		progressChan <- domain.ProgressEvent{Message: fmt.Sprintf("Searching for version %s...", versionID)}
@@ -38,7 +66,7 @@ func (l *PaperLoader) Load(versionID string, destDir string, progressChan chan<-
 
 	versions, err := l.getVersions()
 	if err != nil {
-		return fmt.Errorf("error getting Paper versions: %w", err)
+		return fmt.Errorf("error getting %s versions: %w", l.displayName, err)
 	}
 
 	versionExists := false
@@ -50,7 +78,7 @@ func (l *PaperLoader) Load(versionID string, destDir string, progressChan chan<-
 	}
 
 	if !versionExists {
-		return fmt.Errorf("version %s not found in Paper", versionID)
+		return fmt.Errorf("version %s not found in %s", versionID, l.displayName)
 	}
 
 	if progressChan != nil {
@@ -61,12 +89,12 @@ func (l *PaperLoader) Load(versionID string, destDir string, progressChan chan<-
 		return fmt.Errorf("error getting latest build: %w", err)
 	}
 
-	downloadURL := fmt.Sprintf("%sversions/%s/builds/%d/downloads/paper-%s-%d.jar",
-		PaperAPIURL, versionID, latestBuild, versionID, latestBuild)
+	downloadURL := fmt.Sprintf("%sversions/%s/builds/%d/downloads/%s-%s-%d.jar",
+		l.apiURL(), versionID, latestBuild, l.project, versionID, latestBuild)
 
 	finalPath := filepath.Join(destDir, "server.jar")
 	if progressChan != nil {
-		progressChan <- domain.ProgressEvent{Message: fmt.Sprintf("Downloading Paper server.jar from: %s", downloadURL)}
+		progressChan <- domain.ProgressEvent{Message: fmt.Sprintf("Downloading %s server.jar from: %s", l.displayName, downloadURL)}
 	}
 
 	err = l.downloadFile(downloadURL, finalPath, progressChan)
@@ -81,7 +109,7 @@ func (l *PaperLoader) Load(versionID string, destDir string, progressChan chan<-
 }
 
 func (l *PaperLoader) getVersions() ([]string, error) {
-	resp, err := http.Get(PaperAPIURL)
+	resp, err := http.Get(l.apiURL())
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +126,7 @@ func (l *PaperLoader) getVersions() ([]string, error) {
 
 	var filteredVersions []string
 	for _, v := range response.Versions {
-		if !strings.Contains(v, "-") {
+		if l.allowSnapshots || !strings.Contains(v, "-") {
 			filteredVersions = append(filteredVersions, v)
 		}
 	}
@@ -108,7 +136,7 @@ func (l *PaperLoader) getVersions() ([]string, error) {
 }
 
 func (l *PaperLoader) getLatestBuild(version string) (int, error) {
-	url := fmt.Sprintf("%sversions/%s", PaperAPIURL, version)
+	url := fmt.Sprintf("%sversions/%s", l.apiURL(), version)
 	resp, err := http.Get(url)
 	if err != nil {
 		return 0, err
@@ -156,7 +184,7 @@ func (l *PaperLoader) downloadFile(url string, dest string, progressChan chan<-
 		Reader:       resp.Body,
 		Total:        resp.ContentLength,
 		ProgressChan: progressChan,
-		Message:      "Downloading Paper server.jar",
+		Message:      fmt.Sprintf("Downloading %s server.jar", l.displayName),
 	}
 
 	_, err = io.Copy(out, progressReader)
@@ -1,8 +1,10 @@
 package loader
 
 import (
+	"bytes"
 	"io"
 	"naviger/internal/domain"
+	"strings"
 )
 
 type ProgressReader struct {
@@ -31,3 +33,51 @@ func (pr *ProgressReader) Read(p []byte) (int, error) {
 
 	return n, err
 }
+
+// outputTailLines is how many lines of a failed tool's output its error
+// includes.
+const outputTailLines = 20
+
+// ProgressLogger forwards the output lines of an installer or build tool as
+// progress messages and keeps the last ones for the error when it fails.
+type ProgressLogger struct {
+	ProgressChan chan<- domain.ProgressEvent
+	partial      []byte
+	tail         []string
+}
+
+func (pl *ProgressLogger) Write(p []byte) (int, error) {
+	pl.partial = append(pl.partial, p...)
+	for {
+		i := bytes.IndexByte(pl.partial, '\n')
+		if i < 0 {
+			break
+		}
+		pl.line(string(pl.partial[:i]))
+		pl.partial = pl.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+func (pl *ProgressLogger) line(text string) {
+	text = strings.TrimRight(text, "\r")
+	if text == "" {
+		return
+	}
+	if len(pl.tail) == outputTailLines {
+		pl.tail = pl.tail[1:]
+	}
+	pl.tail = append(pl.tail, text)
+	if pl.ProgressChan != nil {
+		pl.ProgressChan <- domain.ProgressEvent{Message: text}
+	}
+}
+
+// Tail returns the last lines written, including an unfinished one.
+func (pl *ProgressLogger) Tail() string {
+	lines := pl.tail
+	if len(pl.partial) > 0 {
+		lines = append(lines[:len(lines):len(lines)], string(pl.partial))
+	}
+	return strings.Join(lines, "\n")
+}
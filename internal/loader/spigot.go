@@ -0,0 +1,127 @@
+package loader
+
+import (
+	"fmt"
+	"io"
+	"naviger/internal/domain"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+)
+
+const (
+	SpigotVersionsURL = "https://hub.spigotmc.org/versions/"
+	BuildToolsURL     = "https://hub.spigotmc.org/jenkins/job/BuildTools/lastSuccessfulBuild/artifact/target/BuildTools.jar"
+)
+
+var spigotVersionPattern = regexp.MustCompile(`href="(1\.\d+(?:\.\d+)?)\.json"`)
+
+// SpigotLoader compiles Spigot with BuildTools, since Spigot does not
+// distribute prebuilt server jars.
+type SpigotLoader struct {
+	java string
+}
+
+func NewSpigotLoader() *SpigotLoader {
+	return &SpigotLoader{}
+}
+
+// SetJava makes BuildTools run with javaPath, which must be able to compile
+// the requested version, rather than java from PATH.
+func (l *SpigotLoader) SetJava(javaPath string) {
+	l.java = javaPath
+}
+
+func (l *SpigotLoader) GetSupportedVersions() ([]string, error) {
+	resp, err := http.Get(SpigotVersionsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API responded with status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	seen := make(map[string]bool)
+	for _, match := range spigotVersionPattern.FindAllStringSubmatch(string(body), -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			versions = append(versions, match[1])
+		}
+	}
+
+	SortVersions(versions)
+	return versions, nil
+}
+
+func (l *SpigotLoader) Load(versionID string, destDir string, progressChan chan<- domain.ProgressEvent) error {
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: fmt.Sprintf("Searching for version %s...", versionID)}
+	}
+
+	versions, err := l.GetSupportedVersions()
+	if err != nil {
+		return fmt.Errorf("error getting Spigot versions: %w", err)
+	}
+
+	versionExists := false
+	for _, v := range versions {
+		if v == versionID {
+			versionExists = true
+			break
+		}
+	}
+
+	if !versionExists {
+		return fmt.Errorf("version %s not found in Spigot", versionID)
+	}
+
+	workDir := filepath.Join(destDir, ".buildtools")
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: fmt.Sprintf("Downloading BuildTools.jar from: %s", BuildToolsURL)}
+	}
+	if err := downloadToFile(BuildToolsURL, filepath.Join(workDir, "BuildTools.jar"), "Downloading BuildTools.jar", progressChan); err != nil {
+		return err
+	}
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: "Compiling Spigot with BuildTools (this can take several minutes)..."}
+	}
+	javaPath := l.java
+	if javaPath == "" {
+		javaPath = "java"
+	}
+	cmd := exec.Command(javaPath, "-jar", "BuildTools.jar", "--rev", versionID, "--compile", "spigot", "--output-dir", destDir)
+	cmd.Dir = workDir
+	output := &ProgressLogger{ProgressChan: progressChan}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running BuildTools: %w\n%s", err, output.Tail())
+	}
+
+	builtJar := filepath.Join(destDir, fmt.Sprintf("spigot-%s.jar", versionID))
+	if err := os.Rename(builtJar, filepath.Join(destDir, "server.jar")); err != nil {
+		return fmt.Errorf("BuildTools did not produce %s: %w", filepath.Base(builtJar), err)
+	}
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: "Spigot installation completed.", Progress: 100}
+	}
+	return nil
+}
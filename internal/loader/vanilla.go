@@ -20,7 +20,12 @@ type Version struct {
 	URL string `json:"url"`
 }
 type VersionDetails struct {
-	Downloads Downloads `json:"downloads"`
+	Downloads   Downloads   `json:"downloads"`
+	JavaVersion JavaVersion `json:"javaVersion"`
+}
+type JavaVersion struct {
+	Component    string `json:"component"`
+	MajorVersion int    `json:"majorVersion"`
 }
 type Downloads struct {
 	Server DownloadInfo `json:"server"`
@@ -0,0 +1,327 @@
+// Package logarchive keeps the console output of every server on disk so it
+// survives the server stopping. Each server has a plain latest.log that is
+// gzip-compressed and rotated when it grows too large or the server exits.
+package logarchive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"naviger/internal/domain"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	latestFile    = "latest.log"
+	archiveSuffix = ".log.gz"
+	// archiveLayout names rotated files after their first line, so sorting
+	// the names sorts the files chronologically.
+	archiveLayout = "20060102-150405.000000000"
+	lineLayout    = time.RFC3339Nano
+)
+
+type Archive struct {
+	Dir         string
+	MaxFileSize int64
+	MaxFiles    int
+	writers     map[string]*writer
+	mu          sync.Mutex
+}
+
+type writer struct {
+	file  *os.File
+	size  int64
+	start time.Time
+	mu    sync.Mutex
+}
+
+func NewArchive(dir string, maxFileSize int64, maxFiles int) *Archive {
+	return &Archive{
+		Dir:         dir,
+		MaxFileSize: maxFileSize,
+		MaxFiles:    maxFiles,
+		writers:     make(map[string]*writer),
+	}
+}
+
+func (a *Archive) serverDir(serverID string) (string, error) {
+	if serverID == "" || serverID != filepath.Base(serverID) || serverID == "." || serverID == ".." {
+		return "", fmt.Errorf("invalid server id")
+	}
+	return filepath.Join(a.Dir, serverID), nil
+}
+
+// ObserveLine appends a console line, stamped with the current time, to the
+// server's latest.log.
+func (a *Archive) ObserveLine(serverID, line string) {
+	w, err := a.writer(serverID)
+	if err != nil {
+		slog.Warn("Could not open console log", "server", serverID, "error", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	if w.size == 0 {
+		w.start = now
+	}
+	n, err := w.file.WriteString(now.Format(lineLayout) + "\t" + line + "\n")
+	w.size += int64(n)
+	if err != nil {
+		slog.Warn("Could not write console log", "server", serverID, "error", err)
+		return
+	}
+
+	if a.MaxFileSize > 0 && w.size >= a.MaxFileSize {
+		if err := a.rotate(serverID, w); err != nil {
+			slog.Warn("Could not rotate console log", "server", serverID, "error", err)
+		}
+	}
+}
+
+// ServerExited rotates the log so every run of the server ends up in its own
+// archive.
+func (a *Archive) ServerExited(serverID string, _ domain.ProcessExit) {
+	a.mu.Lock()
+	w, ok := a.writers[serverID]
+	delete(a.writers, serverID)
+	a.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := a.rotate(serverID, w); err != nil {
+		slog.Warn("Could not rotate console log", "server", serverID, "error", err)
+	}
+	if w.file != nil {
+		_ = w.file.Close()
+		w.file = nil
+	}
+}
+
+// Close flushes and closes every open log without rotating it. Logs left in
+// latest.log are picked up again when the server next prints a line.
+func (a *Archive) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for id, w := range a.writers {
+		w.mu.Lock()
+		if w.file != nil {
+			_ = w.file.Close()
+			w.file = nil
+		}
+		w.mu.Unlock()
+		delete(a.writers, id)
+	}
+}
+
+// Remove deletes all stored console output of a server.
+func (a *Archive) Remove(serverID string) error {
+	dir, err := a.serverDir(serverID)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	if w, ok := a.writers[serverID]; ok {
+		w.mu.Lock()
+		if w.file != nil {
+			_ = w.file.Close()
+			w.file = nil
+		}
+		w.mu.Unlock()
+		delete(a.writers, serverID)
+	}
+	a.mu.Unlock()
+
+	return os.RemoveAll(dir)
+}
+
+func (a *Archive) writer(serverID string) (*writer, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if w, ok := a.writers[serverID]; ok {
+		return w, nil
+	}
+
+	dir, err := a.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, latestFile)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	w := &writer{file: file, size: info.Size()}
+	if w.size > 0 {
+		w.start = firstLineTime(path, info.ModTime())
+	}
+	a.writers[serverID] = w
+	return w, nil
+}
+
+// rotate compresses latest.log into a dated archive, starts a fresh
+// latest.log and drops the oldest archives beyond MaxFiles. The caller must
+// hold w.mu.
+func (a *Archive) rotate(serverID string, w *writer) error {
+	if w.size == 0 {
+		return nil
+	}
+
+	dir, err := a.serverDir(serverID)
+	if err != nil {
+		return err
+	}
+	latest := filepath.Join(dir, latestFile)
+	target := filepath.Join(dir, w.start.UTC().Format(archiveLayout)+archiveSuffix)
+
+	if err := compressFile(latest, target); err != nil {
+		return err
+	}
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	w.size = 0
+
+	return a.prune(dir)
+}
+
+func (a *Archive) prune(dir string) error {
+	if a.MaxFiles <= 0 {
+		return nil
+	}
+	archives, err := listArchives(dir)
+	if err != nil {
+		return err
+	}
+	for len(archives) > a.MaxFiles {
+		if err := os.Remove(archives[0].path); err != nil {
+			return err
+		}
+		archives = archives[1:]
+	}
+	return nil
+}
+
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	_, copyErr := io.Copy(gz, in)
+	closeErr := gz.Close()
+	fileErr := out.Close()
+	if err := firstError(copyErr, closeErr, fileErr); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type archiveFile struct {
+	path  string
+	start time.Time
+}
+
+func listArchives(dir string) ([]archiveFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var archives []archiveFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, archiveSuffix) {
+			continue
+		}
+		start, err := time.Parse(archiveLayout, strings.TrimSuffix(name, archiveSuffix))
+		if err != nil {
+			continue
+		}
+		archives = append(archives, archiveFile{path: filepath.Join(dir, name), start: start})
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].start.Before(archives[j].start)
+	})
+	return archives, nil
+}
+
+func firstLineTime(path string, fallback time.Time) time.Time {
+	f, err := os.Open(path)
+	if err != nil {
+		return fallback
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return fallback
+	}
+	if t, _, ok := parseLine(line); ok {
+		return t
+	}
+	return fallback
+}
+
+func parseLine(raw string) (time.Time, string, bool) {
+	stamp, text, ok := strings.Cut(strings.TrimRight(raw, "\r\n"), "\t")
+	if !ok {
+		return time.Time{}, "", false
+	}
+	t, err := time.Parse(lineLayout, stamp)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return t, text, true
+}
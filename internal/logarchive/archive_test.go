@@ -0,0 +1,185 @@
+package logarchive
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"naviger/internal/domain"
+)
+
+func texts(lines []Line) []string {
+	result := []string{}
+	for _, line := range lines {
+		result = append(result, line.Text)
+	}
+	return result
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	a := NewArchive(dir, 200, 2)
+
+	var written []string
+	for i := range 40 {
+		line := fmt.Sprintf("line %02d", i)
+		written = append(written, line)
+		a.ObserveLine("s1", line)
+	}
+
+	archives, err := listArchives(filepath.Join(dir, "s1"))
+	if err != nil {
+		t.Fatalf("listArchives: %v", err)
+	}
+	if len(archives) != 2 {
+		t.Fatalf("%d archives kept, want MaxFiles (2)", len(archives))
+	}
+	if info, err := os.Stat(filepath.Join(dir, "s1", latestFile)); err != nil || info.Size() >= 200 {
+		t.Errorf("latest.log was not rotated when it reached MaxFileSize: %v, %v", info, err)
+	}
+
+	// The oldest archives are dropped; what is left is the newest lines, in
+	// order.
+	page, err := a.Search("s1", Query{Limit: 100})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	got := texts(page.Lines)
+	if len(got) == 0 || len(got) == len(written) || page.Total != len(got) {
+		t.Fatalf("Search returned %d of %d lines, total %d", len(got), len(written), page.Total)
+	}
+	if want := written[len(written)-len(got):]; !reflect.DeepEqual(got, want) {
+		t.Errorf("kept lines %v, want %v", got, want)
+	}
+
+	// An exit rotates what is left, so the next run starts a new file.
+	a.ServerExited("s1", domain.ProcessExit{})
+	if info, err := os.Stat(filepath.Join(dir, "s1", latestFile)); err != nil || info.Size() != 0 {
+		t.Errorf("latest.log not rotated on exit: %v, %v", info, err)
+	}
+	a.ObserveLine("s1", "next run")
+	page, _ = a.Search("s1", Query{Tail: true, Limit: 2})
+	if want := []string{"line 39", "next run"}; !reflect.DeepEqual(texts(page.Lines), want) {
+		t.Errorf("lines around the exit %v, want %v", texts(page.Lines), want)
+	}
+	a.Close()
+
+	// A reopened archive appends to latest.log.
+	reopened := NewArchive(dir, 200, 2)
+	reopened.ObserveLine("s1", "after restart")
+	defer reopened.Close()
+	page, _ = reopened.Search("s1", Query{Tail: true, Limit: 2})
+	if want := []string{"next run", "after restart"}; !reflect.DeepEqual(texts(page.Lines), want) {
+		t.Errorf("lines after reopening %v, want %v", texts(page.Lines), want)
+	}
+
+	if _, err := a.Search("../s1", Query{Limit: 1}); err == nil {
+		t.Error("Search accepted a server id outside the archive")
+	}
+}
+
+// writeLog writes lines stamped one minute apart from start, gzipped when
+// the name is an archive.
+func writeLog(t *testing.T, path string, start time.Time, lines []string) {
+	t.Helper()
+	var b strings.Builder
+	for i, line := range lines {
+		b.WriteString(start.Add(time.Duration(i)*time.Minute).Format(lineLayout) + "\t" + line + "\n")
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if !strings.HasSuffix(path, archiveSuffix) {
+		f.WriteString(b.String())
+		return
+	}
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte(b.String()))
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchPagination(t *testing.T) {
+	dir := t.TempDir()
+	serverDir := filepath.Join(dir, "s1")
+	os.MkdirAll(serverDir, 0755)
+
+	// Three runs of five lines each, an hour apart: two archives and the live
+	// log. Every third line is an error.
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	hour := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	for run, start := range []time.Time{hour(10, 0), hour(11, 0), hour(12, 0)} {
+		var lines []string
+		for i := run * 5; i < run*5+5; i++ {
+			level := "INFO"
+			if i%3 == 0 {
+				level = "ERROR"
+			}
+			lines = append(lines, fmt.Sprintf("[%s] line %d", level, i))
+		}
+		name := start.Format(archiveLayout) + archiveSuffix
+		if run == 2 {
+			name = latestFile
+		}
+		writeLog(t, filepath.Join(serverDir, name), start, lines)
+	}
+
+	line := func(ids ...int) []string {
+		result := []string{}
+		for _, i := range ids {
+			level := "INFO"
+			if i%3 == 0 {
+				level = "ERROR"
+			}
+			result = append(result, fmt.Sprintf("[%s] line %d", level, i))
+		}
+		return result
+	}
+	errors := regexp.MustCompile(`ERROR`)
+
+	tests := []struct {
+		name  string
+		q     Query
+		want  []string
+		total int
+	}{
+		{"first page", Query{Limit: 3}, line(0, 1, 2), 15},
+		{"page across files", Query{Offset: 3, Limit: 4}, line(3, 4, 5, 6), 15},
+		{"last partial page", Query{Offset: 13, Limit: 5}, line(13, 14), 15},
+		{"offset past the end", Query{Offset: 20, Limit: 5}, line(), 15},
+		{"from and to inclusive", Query{From: hour(11, 1), To: hour(11, 3), Limit: 10}, line(6, 7, 8), 3},
+		{"from inside an archive", Query{From: hour(10, 3), Limit: 3}, line(3, 4, 5), 12},
+		{"to inside an archive", Query{To: hour(10, 1), Limit: 10}, line(0, 1), 2},
+		{"from after everything", Query{From: hour(13, 0), Limit: 10}, line(), 0},
+		{"q", Query{Pattern: errors, Limit: 10}, line(0, 3, 6, 9, 12), 5},
+		{"q paged", Query{Pattern: errors, Offset: 1, Limit: 2}, line(3, 6), 5},
+		{"q within range", Query{Pattern: errors, From: hour(11, 0), To: hour(12, 0), Limit: 10}, line(6, 9), 2},
+		{"tail", Query{Tail: true, Limit: 3}, line(12, 13, 14), 15},
+		{"tail scrolled back", Query{Tail: true, Offset: 3, Limit: 4}, line(8, 9, 10, 11), 15},
+		{"tail scrolled to the start", Query{Tail: true, Offset: 13, Limit: 4}, line(0, 1), 15},
+		{"tail with q and to", Query{Tail: true, Pattern: errors, To: hour(11, 4), Limit: 2}, line(6, 9), 4},
+	}
+
+	a := NewArchive(dir, 0, 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := a.Search("s1", tt.q)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if got := texts(page.Lines); !reflect.DeepEqual(got, tt.want) || page.Total != tt.total {
+				t.Errorf("got %v (total %d), want %v (total %d)", got, page.Total, tt.want, tt.total)
+			}
+		})
+	}
+}
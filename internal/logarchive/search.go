@@ -0,0 +1,165 @@
+package logarchive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+const maxLineSize = 1024 * 1024
+
+type Line struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"line"`
+}
+
+// Query selects archived lines. Zero From/To leave that end open and a nil
+// Pattern matches everything. With Tail set, Offset counts back from the
+// newest match instead of forward from the oldest, which is what a console
+// scrolling back in time needs.
+type Query struct {
+	From    time.Time
+	To      time.Time
+	Pattern *regexp.Regexp
+	Offset  int
+	Limit   int
+	Tail    bool
+}
+
+// Page is one window of matching lines in chronological order. Total counts
+// every match of the query, not only the returned ones.
+type Page struct {
+	Lines  []Line `json:"lines"`
+	Total  int    `json:"total"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+// Search scans the archives and the live log of a server, oldest first.
+func (a *Archive) Search(serverID string, q Query) (*Page, error) {
+	dir, err := a.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	archives, err := listArchives(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(archives)+1)
+	for i, archive := range archives {
+		if !q.To.IsZero() && archive.start.After(q.To) {
+			break
+		}
+		// A file ends where the next one starts.
+		if !q.From.IsZero() && i+1 < len(archives) && archives[i+1].start.Before(q.From) {
+			continue
+		}
+		files = append(files, archive.path)
+	}
+	files = append(files, filepath.Join(dir, latestFile))
+
+	collector := newCollector(q)
+	for _, path := range files {
+		if err := scanFile(path, q, collector.add); err != nil {
+			return nil, err
+		}
+	}
+	return collector.page(), nil
+}
+
+func scanFile(path string, q Query, fn func(Line)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if filepath.Ext(path) == ".gz" {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		t, text, ok := parseLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		if !q.From.IsZero() && t.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && t.After(q.To) {
+			break
+		}
+		if q.Pattern != nil && !q.Pattern.MatchString(text) {
+			continue
+		}
+		fn(Line{Time: t, Text: text})
+	}
+	return scanner.Err()
+}
+
+// collector keeps only the lines that can end up in the requested window:
+// the first Offset+Limit matches, or with Tail a ring of the last ones.
+type collector struct {
+	q     Query
+	total int
+	lines []Line
+}
+
+func newCollector(q Query) *collector {
+	return &collector{q: q}
+}
+
+func (c *collector) add(line Line) {
+	c.total++
+	window := c.q.Offset + c.q.Limit
+	if !c.q.Tail {
+		if c.total > c.q.Offset && c.total <= window {
+			c.lines = append(c.lines, line)
+		}
+		return
+	}
+	c.lines = append(c.lines, line)
+	if len(c.lines) > window {
+		c.lines = c.lines[1:]
+	}
+}
+
+func (c *collector) page() *Page {
+	lines := c.lines
+	if c.q.Tail {
+		end := len(lines) - c.q.Offset
+		if end < 0 {
+			end = 0
+		}
+		start := end - c.q.Limit
+		if start < 0 {
+			start = 0
+		}
+		lines = lines[start:end]
+	}
+	if lines == nil {
+		lines = []Line{}
+	}
+	return &Page{
+		Lines:  lines,
+		Total:  c.total,
+		Offset: c.q.Offset,
+		Limit:  c.q.Limit,
+	}
+}
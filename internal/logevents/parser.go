@@ -0,0 +1,161 @@
+// Package logevents recognises player activity in Minecraft server console
+// output. It understands the log layouts of vanilla, Paper/Spigot, Fabric and
+// Forge/NeoForge servers.
+package logevents
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	Join        Type = "join"
+	Leave       Type = "leave"
+	Chat        Type = "chat"
+	Death       Type = "death"
+	Advancement Type = "advancement"
+	UUID        Type = "uuid"
+)
+
+// Event is a single player action read from a console line. Detail holds the
+// chat text, the full death message or the advancement title.
+type Event struct {
+	Type   Type   `json:"type"`
+	Player string `json:"player"`
+	UUID   string `json:"uuid,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// namePart matches Java edition names plus the "." prefix Floodgate gives
+// Bedrock players.
+const namePart = `(\.?[A-Za-z0-9_]{1,16})`
+
+var (
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+	// Each prefix captures the level and the message that follows it.
+	prefixPatterns = []*regexp.Regexp{
+		// Forge/NeoForge: [18Oct2026 12:00:00.123] [Server thread/INFO] [net.minecraft.server.MinecraftServer/]: msg
+		regexp.MustCompile(`^\[[^\]]+\] \[[^\]]+/([A-Z]+)\] \[[^\]]*\]: (.*)$`),
+		// Fabric with a logger name: [12:00:00] [Server thread/INFO] (Minecraft) msg
+		regexp.MustCompile(`^\[[^\]]+\] \[[^\]]+/([A-Z]+)\] \([^)]*\) (.*)$`),
+		// Vanilla and Fabric: [12:00:00] [Server thread/INFO]: msg
+		regexp.MustCompile(`^\[[^\]]+\] \[[^\]]+/([A-Z]+)\]: (.*)$`),
+		// Paper and Spigot: [12:00:00 INFO]: msg
+		regexp.MustCompile(`^\[\d{2}:\d{2}:\d{2} ([A-Z]+)\]: (.*)$`),
+	}
+
+	joinPattern        = regexp.MustCompile(`^` + namePart + `(?: \(formerly known as [^)]+\))? joined the game$`)
+	leavePattern       = regexp.MustCompile(`^` + namePart + ` left the game$`)
+	uuidPattern        = regexp.MustCompile(`^UUID of player ` + namePart + ` is ([0-9a-fA-F-]{32,36})$`)
+	chatPattern        = regexp.MustCompile(`^(?:\[Not Secure\] )?<` + namePart + `> (.*)$`)
+	advancementPattern = regexp.MustCompile(`^` + namePart + ` has (?:made the advancement|completed the challenge|reached the goal) \[(.+)\]$`)
+	deathPattern       = regexp.MustCompile(`^` + namePart + ` (.+)$`)
+	readyPattern       = regexp.MustCompile(`^Done \(\d+[.,]\d+s\)!`)
+)
+
+// deathPhrases are the openings of the vanilla death messages that follow the
+// player name.
+var deathPhrases = []string{
+	"was slain by", "was shot by", "was killed", "was fireballed by", "was pummeled by",
+	"was blown up by", "was blown from a high place", "was impaled", "was squashed", "was squished",
+	"was skewered", "was pricked to death", "was stung to death", "was poked to death",
+	"was struck by lightning", "was obliterated", "was burned to a crisp", "was frozen to death",
+	"was roasted", "was doomed to fall", "was speared by", "was stomped by", "was sniped by",
+	"was spitballed by", "was smashed by", "was too soft for this world",
+	"drowned", "died", "blew up", "burned to death", "went up in flames", "went off with a bang",
+	"walked into fire", "walked into danger zone", "walked into a cactus", "tried to swim in lava",
+	"hit the ground too hard", "fell from a high place", "fell off", "fell while climbing",
+	"fell out of the world", "fell too far", "fell into a patch", "suffocated in a wall",
+	"starved to death", "withered away", "froze to death", "experienced kinetic energy",
+	"discovered the floor was lava", "didn't want to live in the same world as",
+	"left the confines of this world",
+}
+
+// Parse reads one console line and reports the player event it describes,
+// if any. Lines logged above INFO level never carry player events.
+func Parse(line string) (Event, bool) {
+	message, ok := messageOf(line)
+	if !ok {
+		return Event{}, false
+	}
+
+	if m := chatPattern.FindStringSubmatch(message); m != nil {
+		return Event{Type: Chat, Player: m[1], Detail: m[2]}, true
+	}
+	if m := uuidPattern.FindStringSubmatch(message); m != nil {
+		// Some servers log the undashed form; events carry the dashed one.
+		if id, err := uuid.Parse(m[2]); err == nil {
+			return Event{Type: UUID, Player: m[1], UUID: id.String()}, true
+		}
+		return Event{}, false
+	}
+	if m := joinPattern.FindStringSubmatch(message); m != nil {
+		return Event{Type: Join, Player: m[1]}, true
+	}
+	if m := leavePattern.FindStringSubmatch(message); m != nil {
+		return Event{Type: Leave, Player: m[1]}, true
+	}
+	if m := advancementPattern.FindStringSubmatch(message); m != nil {
+		return Event{Type: Advancement, Player: m[1], Detail: m[2]}, true
+	}
+	if m := deathPattern.FindStringSubmatch(message); m != nil && isDeathMessage(m[2]) {
+		return Event{Type: Death, Player: m[1], Detail: message}, true
+	}
+	return Event{}, false
+}
+
+// Ready reports whether a console line is the "Done (...)!" message a server
+// prints once it has finished starting and accepts players.
+func Ready(line string) bool {
+	message, ok := messageOf(line)
+	return ok && readyPattern.MatchString(message)
+}
+
+// Level returns the level a console line was logged at, such as INFO, WARN
+// or ERROR, or "" when the line has no recognised prefix.
+func Level(line string) string {
+	level, _, _ := split(line)
+	return level
+}
+
+// Message returns the text of a console line without its log prefix at any
+// level. Lines without a recognised prefix, such as the continuation lines of
+// multi-line command output, are returned whole.
+func Message(line string) string {
+	if _, message, ok := split(line); ok {
+		return message
+	}
+	return strings.TrimRight(ansiPattern.ReplaceAllString(line, ""), "\r\n ")
+}
+
+func messageOf(line string) (string, bool) {
+	level, message, ok := split(line)
+	if !ok || level != "INFO" {
+		return "", false
+	}
+	return message, true
+}
+
+func split(line string) (string, string, bool) {
+	line = strings.TrimRight(ansiPattern.ReplaceAllString(line, ""), "\r\n ")
+	for _, pattern := range prefixPatterns {
+		if m := pattern.FindStringSubmatch(line); m != nil {
+			return m[1], m[2], true
+		}
+	}
+	return "", "", false
+}
+
+func isDeathMessage(rest string) bool {
+	for _, phrase := range deathPhrases {
+		if rest == phrase || strings.HasPrefix(rest, phrase+" ") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package logevents
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func parseFixture(t *testing.T, name string) []Event {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if event, ok := Parse(scanner.Text()); ok {
+			events = append(events, event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []Event
+	}{
+		{
+			fixture: "vanilla.log",
+			want: []Event{
+				{Type: UUID, Player: "Steve", UUID: "8667ba71-b85a-4004-af54-457a9734eed7"},
+				{Type: Join, Player: "Steve"},
+				{Type: Chat, Player: "Steve", Detail: "hello world"},
+				{Type: Advancement, Player: "Steve", Detail: "Stone Age"},
+				{Type: Death, Player: "Steve", Detail: "Steve was slain by Zombie"},
+				{Type: Leave, Player: "Steve"},
+			},
+		},
+		{
+			fixture: "paper.log",
+			want: []Event{
+				{Type: UUID, Player: "Alex_99", UUID: "ec561538-f3fd-461d-aff5-086b22154bce"},
+				{Type: Join, Player: "Alex_99"},
+				{Type: Chat, Player: "Alex_99", Detail: "anyone here?"},
+				{Type: Advancement, Player: "Alex_99", Detail: "Return to Sender"},
+				{Type: Death, Player: "Alex_99", Detail: "Alex_99 fell from a high place"},
+				{Type: Join, Player: "Alex_99"},
+				{Type: Join, Player: ".BedrockGuy"},
+				{Type: Leave, Player: "Alex_99"},
+			},
+		},
+		{
+			fixture: "forge.log",
+			want: []Event{
+				{Type: UUID, Player: "Notch", UUID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"},
+				{Type: Join, Player: "Notch"},
+				{Type: Chat, Player: "Notch", Detail: "modded chat works"},
+				{Type: Advancement, Player: "Notch", Detail: "Cover Me in Debris"},
+				{Type: Death, Player: "Notch", Detail: "Notch drowned whilst trying to escape Drowned"},
+				{Type: Leave, Player: "Notch"},
+			},
+		},
+		{
+			fixture: "fabric.log",
+			want: []Event{
+				{Type: UUID, Player: "jeb_", UUID: "853c80ef-3c37-49fd-aa49-938b674adae6"},
+				{Type: Join, Player: "jeb_"},
+				{Type: Chat, Player: "jeb_", Detail: "testing fabric"},
+				{Type: Death, Player: "jeb_", Detail: "jeb_ died"},
+				{Type: Leave, Player: "jeb_"},
+			},
+		},
+		{
+			fixture: "ansi.log",
+			want: []Event{
+				{Type: Join, Player: "Steve"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got := parseFixture(t, tt.fixture)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events mismatch\n got: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIgnoresSpoofedChat(t *testing.T) {
+	lines := []string{
+		"[14:04:02] [Server thread/INFO]: <Steve> Bob joined the game",
+		"[14:04:02] [Server thread/INFO]: [Steve] Bob left the game",
+		"[14:04:02] [Server thread/INFO]: * Steve died",
+	}
+	for _, line := range lines {
+		event, ok := Parse(line)
+		if ok && event.Type != Chat {
+			t.Errorf("Parse(%q) = %+v, want no event or chat", line, event)
+		}
+	}
+}
+
+func TestParseDropsInvalidUUID(t *testing.T) {
+	for _, line := range []string{
+		"[14:04:02] [User Authenticator #1/INFO]: UUID of player Steve is 853c80ef3c3749fdaa49938b674adae6ff",
+		"[14:04:02] [User Authenticator #1/INFO]: UUID of player Steve is 853c80ef3c37-49fd-aa49-938b674adae6",
+	} {
+		if event, ok := Parse(line); ok {
+			t.Errorf("Parse(%q) = %+v, want no event", line, event)
+		}
+	}
+}
+
+func TestReadyFixtures(t *testing.T) {
+	for _, fixture := range []string{"vanilla.log", "paper.log", "forge.log", "fabric.log"} {
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ready := 0
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			if Ready(scanner.Text()) {
+				ready++
+			}
+		}
+		if ready != 1 {
+			t.Errorf("%s: got %d ready lines, want 1", fixture, ready)
+		}
+	}
+
+	if Ready(`[14:04:02] [Server thread/INFO]: <Steve> Done (1.0s)! For help, type "help"`) {
+		t.Error("chat message reported as ready")
+	}
+}
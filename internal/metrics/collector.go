@@ -0,0 +1,270 @@
+// Package metrics exposes daemon and server metrics in the Prometheus text
+// format.
+package metrics
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"naviger/internal/runner"
+	"naviger/internal/server"
+	"naviger/internal/sessions"
+	"naviger/internal/storage"
+	"naviger/internal/updater"
+	"naviger/internal/ws"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	serverStatuses = []string{"STOPPED", "STARTING", "RUNNING", "STOPPING"}
+
+	httpBuckets   = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	backupBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600}
+)
+
+type Collector struct {
+	Store      *storage.GormStore
+	Supervisor *runner.Supervisor
+	HubManager *ws.HubManager
+	Sessions   *sessions.Tracker
+	Disk       *server.DiskSampler
+
+	startedAt      time.Time
+	httpDuration   *histogramVec
+	backupDuration *histogramVec
+
+	mu            sync.Mutex
+	backupSize    map[string]float64
+	backupResults map[[2]string]float64
+}
+
+func NewCollector(store *storage.GormStore, supervisor *runner.Supervisor, hubManager *ws.HubManager, tracker *sessions.Tracker, disk *server.DiskSampler) *Collector {
+	return &Collector{
+		Store:      store,
+		Supervisor: supervisor,
+		HubManager: hubManager,
+		Sessions:   tracker,
+		Disk:       disk,
+		startedAt:  time.Now(),
+		httpDuration: newHistogramVec("naviger_http_request_duration_seconds",
+			"Time spent serving API requests.", httpBuckets, "method", "route", "code"),
+		backupDuration: newHistogramVec("naviger_backup_duration_seconds",
+			"Time taken to create backups.", backupBuckets, "server"),
+		backupSize:    make(map[string]float64),
+		backupResults: make(map[[2]string]float64),
+	}
+}
+
+// ObserveBackup records a finished backup job.
+func (c *Collector) ObserveBackup(serverID string, duration time.Duration, size int64, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+
+	c.mu.Lock()
+	c.backupResults[[2]string{serverID, result}]++
+	if err == nil {
+		c.backupSize[serverID] = float64(size)
+	}
+	c.mu.Unlock()
+
+	if err == nil {
+		c.backupDuration.observe(duration.Seconds(), serverID)
+	}
+}
+
+// Middleware times every request by the route pattern that served it.
+// Hijacked connections (console websockets) are not timed.
+func (c *Collector) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.hijacked {
+			return
+		}
+
+		// Patterns look like "GET /servers/{id}"; the method is its own label.
+		route := r.Pattern
+		if _, path, ok := strings.Cut(route, " "); ok {
+			route = path
+		}
+		if route == "" {
+			route = "unmatched"
+		}
+		c.httpDuration.observe(time.Since(start).Seconds(), r.Method, route, strconv.Itoa(rec.status))
+	})
+}
+
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	out := bufio.NewWriter(w)
+	for _, f := range c.collect() {
+		writeFamily(out, f)
+	}
+	out.Flush()
+}
+
+func (c *Collector) collect() []family {
+	families := c.serverFamilies()
+
+	daemonUptime := family{name: "naviger_daemon_uptime_seconds", help: "Seconds since the daemon started.", kind: kindGauge}
+	daemonUptime.add(time.Since(c.startedAt).Seconds())
+	buildInfo := family{name: "naviger_build_info", help: "Naviger version, always 1.", kind: kindGauge}
+	buildInfo.add(1, "version", updater.CurrentVersion, "go_version", runtime.Version())
+	goroutines := family{name: "naviger_goroutines", help: "Goroutines in the daemon.", kind: kindGauge}
+	goroutines.add(float64(runtime.NumGoroutine()))
+	families = append(families, daemonUptime, buildInfo, goroutines, c.httpDuration.family())
+
+	wsClients := family{name: "naviger_websocket_clients", help: "Console websocket clients connected per server hub.", kind: kindGauge}
+	if c.HubManager != nil {
+		counts := c.HubManager.ClientCounts()
+		for _, id := range sortedKeys(counts) {
+			wsClients.add(float64(counts[id]), "server", id)
+		}
+	}
+	families = append(families, wsClients, c.backupDuration.family())
+
+	backupSize := family{name: "naviger_backup_last_size_bytes", help: "Size of the last successful backup of each server.", kind: kindGauge}
+	backups := family{name: "naviger_backups_total", help: "Finished backup jobs by result.", kind: kindCounter}
+	c.mu.Lock()
+	for _, id := range sortedKeys(c.backupSize) {
+		backupSize.add(c.backupSize[id], "server", id)
+	}
+	resultKeys := make([][2]string, 0, len(c.backupResults))
+	for key := range c.backupResults {
+		resultKeys = append(resultKeys, key)
+	}
+	sort.Slice(resultKeys, func(i, j int) bool {
+		if resultKeys[i][0] != resultKeys[j][0] {
+			return resultKeys[i][0] < resultKeys[j][0]
+		}
+		return resultKeys[i][1] < resultKeys[j][1]
+	})
+	for _, key := range resultKeys {
+		backups.add(c.backupResults[key], "server", key[0], "result", key[1])
+	}
+	c.mu.Unlock()
+
+	return append(families, backupSize, backups)
+}
+
+func (c *Collector) serverFamilies() []family {
+	status := family{name: "naviger_server_status", help: "Current status of each server, 1 for the active status.", kind: kindGauge}
+	cpu := family{name: "naviger_server_cpu_percent", help: "CPU usage of the server process.", kind: kindGauge}
+	rss := family{name: "naviger_server_memory_rss_bytes", help: "Resident memory of the server process.", kind: kindGauge}
+	disk := family{name: "naviger_server_disk_bytes", help: "Size of the server folder, sampled in the background.", kind: kindGauge}
+	uptime := family{name: "naviger_server_uptime_seconds", help: "Seconds since the server process started, 0 when stopped.", kind: kindGauge}
+	restarts := family{name: "naviger_server_restarts_total", help: "Times the server was started again since the daemon launched.", kind: kindCounter}
+	players := family{name: "naviger_server_players_online", help: "Players currently online.", kind: kindGauge}
+	tps := family{name: "naviger_server_tps", help: "Last measured ticks per second.", kind: kindGauge}
+	mspt := family{name: "naviger_server_mspt", help: "Last measured milliseconds per tick.", kind: kindGauge}
+	throttled := family{name: "naviger_server_cpu_throttled_seconds_total", help: "Time the server cgroup was throttled by its CPU quota in the current run.", kind: kindCounter}
+	memoryMaxHits := family{name: "naviger_server_memory_limit_hits_total", help: "Times the server cgroup reached its memory limit in the current run.", kind: kindCounter}
+
+	servers, err := c.Store.ListServers()
+	if err != nil {
+		return nil
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].ID < servers[j].ID })
+
+	for _, srv := range servers {
+		labels := []string{"server", srv.ID, "name", srv.Name}
+
+		for _, s := range serverStatuses {
+			value := 0.0
+			if srv.Status == s {
+				value = 1
+			}
+			status.add(value, append(labels[:4:4], "status", s)...)
+		}
+
+		if c.Disk != nil {
+			disk.add(float64(c.Disk.Size(srv.ID)), labels...)
+		}
+		restarts.add(float64(c.Supervisor.Restarts(srv.ID)), labels...)
+
+		running, ok := c.Supervisor.Uptime(srv.ID)
+		if !ok {
+			uptime.add(0, labels...)
+			continue
+		}
+		uptime.add(running.Seconds(), labels...)
+
+		if stats, err := c.Supervisor.GetServerStats(srv.ID); err == nil {
+			cpu.add(stats.CPU, labels...)
+			rss.add(float64(stats.RAM), labels...)
+			if stats.TPS > 0 {
+				tps.add(stats.TPS, labels...)
+			}
+			if stats.MSPT > 0 {
+				mspt.add(stats.MSPT, labels...)
+			}
+			if stats.Cgroup != nil {
+				throttled.add(stats.Cgroup.ThrottledSeconds, labels...)
+				memoryMaxHits.add(float64(stats.Cgroup.MemoryMaxHits), labels...)
+			}
+		}
+		if c.Sessions != nil {
+			if online, err := c.Sessions.Online(srv.ID); err == nil {
+				players.add(float64(len(online)), labels...)
+			}
+		}
+	}
+
+	return []family{status, cpu, rss, disk, uptime, restarts, players, tps, mspt, throttled, memoryMaxHits}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// statusRecorder captures the response status while still letting the
+// websocket upgrader hijack the connection.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	r.hijacked = true
+	return hijacker.Hijack()
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	kindGauge     = "gauge"
+	kindCounter   = "counter"
+	kindHistogram = "histogram"
+)
+
+// family is one metric with all of its samples, written in the Prometheus
+// text exposition format.
+type family struct {
+	name    string
+	help    string
+	kind    string
+	samples []sample
+}
+
+// sample is a single line of a family. labels holds name/value pairs and
+// suffix is appended to the family name (_bucket, _sum and _count for
+// histograms).
+type sample struct {
+	suffix string
+	labels []string
+	value  float64
+}
+
+func (f *family) add(value float64, labels ...string) {
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+func writeFamily(w *bufio.Writer, f family) {
+	if len(f.samples) == 0 {
+		return
+	}
+	w.WriteString("# HELP " + f.name + " " + strings.ReplaceAll(f.help, "\n", " ") + "\n")
+	w.WriteString("# TYPE " + f.name + " " + f.kind + "\n")
+	for _, s := range f.samples {
+		w.WriteString(f.name + s.suffix)
+		if len(s.labels) > 0 {
+			w.WriteByte('{')
+			for i := 0; i+1 < len(s.labels); i += 2 {
+				if i > 0 {
+					w.WriteByte(',')
+				}
+				w.WriteString(s.labels[i] + `="` + escapeLabel(s.labels[i+1]) + `"`)
+			}
+			w.WriteByte('}')
+		}
+		w.WriteString(" " + formatValue(s.value) + "\n")
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// histogramVec is a histogram partitioned by label values.
+type histogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	series  map[string]*histogramSeries
+	mu      sync.Mutex
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+}
+
+func (h *histogramVec) observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func (h *histogramVec) family() family {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	f := family{name: h.name, help: h.help, kind: kindHistogram}
+	for _, key := range keys {
+		s := h.series[key]
+		labels := make([]string, 0, 2*len(h.labels)+2)
+		for i, name := range h.labels {
+			labels = append(labels, name, s.labelValues[i])
+		}
+		for i, bound := range h.buckets {
+			f.samples = append(f.samples, sample{suffix: "_bucket", labels: append(labels[:len(labels):len(labels)], "le", formatValue(bound)), value: float64(s.counts[i])})
+		}
+		f.samples = append(f.samples,
+			sample{suffix: "_bucket", labels: append(labels[:len(labels):len(labels)], "le", "+Inf"), value: float64(s.count)},
+			sample{suffix: "_sum", labels: labels, value: s.sum},
+			sample{suffix: "_count", labels: labels, value: float64(s.count)},
+		)
+	}
+	return f
+}
@@ -0,0 +1,156 @@
+package modpack
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	curseForgeManifestName = "manifest.json"
+	serverPackVariables    = "variables.txt"
+)
+
+// Installer jars shipped in server packs, which name the loader build and
+// usually the Minecraft version.
+var (
+	forgeInstallerPattern    = regexp.MustCompile(`^forge-(1\.[\d.]+)-([\d.]+)-installer\.jar$`)
+	neoForgeInstallerPattern = regexp.MustCompile(`^neoforge-(?:(1\.[\d.]+)-)?(\d+\.\d+\.[\w.+-]+)-installer\.jar$`)
+	fabricLauncherPattern    = regexp.MustCompile(`^fabric-server-mc\.([^-]+)-loader\.([^-]+)-launcher\.[^-]+\.jar$`)
+)
+
+// rejectCurseForgeManifest refuses CurseForge client packs. Their manifest
+// lists project IDs only: it does not say which mods are client-side and has
+// no hashes, and resolving the files needs CurseForge's API key.
+func rejectCurseForgeManifest() (*Pack, error) {
+	return nil, fmt.Errorf("this is a CurseForge client pack (%s); download the pack's server files from CurseForge and import that zip instead", curseForgeManifestName)
+}
+
+// parseServerPack reads a CurseForge server pack: a zip of a ready server
+// folder, possibly inside a single top-level directory. The loader is taken
+// from variables.txt or from the installer jar the pack ships.
+func parseServerPack(zr *zip.Reader) (*Pack, error) {
+	root, ok := serverPackRoot(zr)
+	if !ok {
+		return nil, fmt.Errorf("unrecognized modpack: no %s, %s or server files found", mrpackIndexName, curseForgeManifestName)
+	}
+
+	pack := &Pack{
+		Name:         strings.TrimSuffix(root, "/"),
+		OverrideDirs: []string{root},
+	}
+	for _, f := range zr.File {
+		name, found := strings.CutPrefix(f.Name, root)
+		if !found || strings.Contains(name, "/") {
+			continue
+		}
+		if name == serverPackVariables {
+			if err := readServerPackVariables(f, pack); err != nil {
+				return nil, err
+			}
+		} else if readInstallerName(name, pack) {
+			pack.SkipFiles = append(pack.SkipFiles, f.Name)
+		}
+	}
+
+	switch {
+	case pack.Loader == "quilt":
+		return nil, fmt.Errorf("modpacks for the Quilt loader are not supported")
+	case pack.Loader == "":
+		return nil, fmt.Errorf("server pack does not name its loader: no installer jar or %s found", serverPackVariables)
+	case pack.MinecraftVersion == "":
+		return nil, fmt.Errorf("server pack does not declare a minecraft version")
+	}
+	return pack, nil
+}
+
+// serverPackRoot finds the directory holding the server's mods folder, which
+// is the archive root or a single directory below it.
+func serverPackRoot(zr *zip.Reader) (string, bool) {
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, "mods/") {
+			return "", true
+		}
+		if dir, rest, ok := strings.Cut(f.Name, "/"); ok && strings.HasPrefix(rest, "mods/") {
+			return dir + "/", true
+		}
+	}
+	return "", false
+}
+
+// readServerPackVariables reads the MINECRAFT_VERSION, MODLOADER and
+// MODLOADER_VERSION lines the server starter scripts of many packs use.
+func readServerPackVariables(f *zip.File, pack *Pack) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		switch strings.TrimSpace(key) {
+		case "MINECRAFT_VERSION":
+			pack.MinecraftVersion = value
+		case "MODLOADER":
+			pack.Loader = strings.ToLower(value)
+		case "MODLOADER_VERSION":
+			pack.LoaderVersion = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("invalid %s: %w", serverPackVariables, err)
+	}
+	return nil
+}
+
+// readInstallerName fills in what the pack has not declared from the name of
+// a loader installer jar, and reports whether name is one.
+func readInstallerName(name string, pack *Pack) bool {
+	var loader, minecraft, version string
+	if m := forgeInstallerPattern.FindStringSubmatch(name); m != nil {
+		loader, minecraft, version = "forge", m[1], m[2]
+	} else if m := neoForgeInstallerPattern.FindStringSubmatch(name); m != nil {
+		loader, minecraft, version = "neoforge", m[1], m[2]
+		if minecraft == "" {
+			minecraft = neoForgeMinecraftVersion(version)
+		}
+	} else if m := fabricLauncherPattern.FindStringSubmatch(name); m != nil {
+		loader, minecraft, version = "fabric", m[1], m[2]
+	} else {
+		return false
+	}
+
+	if pack.Loader == "" {
+		pack.Loader = loader
+	}
+	if pack.Loader == loader && pack.LoaderVersion == "" {
+		pack.LoaderVersion = version
+	}
+	if pack.MinecraftVersion == "" {
+		pack.MinecraftVersion = minecraft
+	}
+	return true
+}
+
+// neoForgeMinecraftVersion derives the Minecraft version from a NeoForge
+// version, whose first two parts are the minor and patch of 1.x.y:
+// 20.4.80 is for 1.20.4 and 21.0.10 for 1.21.
+func neoForgeMinecraftVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	if parts[1] == "0" {
+		return "1." + parts[0]
+	}
+	return "1." + parts[0] + "." + parts[1]
+}
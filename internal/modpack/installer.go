@@ -0,0 +1,263 @@
+package modpack
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"naviger/internal/domain"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultAllowedHosts are the download hosts permitted by the Modrinth pack
+// format.
+var DefaultAllowedHosts = []string{
+	"cdn.modrinth.com",
+	"github.com",
+	"raw.githubusercontent.com",
+	"gitlab.com",
+}
+
+type Installer struct {
+	Client       *http.Client
+	AllowedHosts []string
+}
+
+func NewInstaller() *Installer {
+	return &Installer{
+		Client:       &http.Client{},
+		AllowedHosts: DefaultAllowedHosts,
+	}
+}
+
+// Open reads the manifest of the pack archive at archivePath.
+func Open(archivePath string) (*Pack, error) {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open modpack: %w", err)
+	}
+	defer zr.Close()
+	return Parse(&zr.Reader)
+}
+
+// Fetch downloads a pack archive from rawURL to dest.
+func (i *Installer) Fetch(rawURL, dest string, progressChan chan<- domain.ProgressEvent) error {
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: "Downloading modpack..."}
+	}
+
+	resp, err := i.Client.Get(rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error downloading modpack: status %d", resp.StatusCode)
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
+// Install downloads the pack's server-side files into serverDir, verifying
+// their hashes, and then applies the archive's override directories.
+func (i *Installer) Install(pack *Pack, archivePath, serverDir string, progressChan chan<- domain.ProgressEvent) error {
+	total := len(pack.Files)
+	for n, file := range pack.Files {
+		if progressChan != nil {
+			progressChan <- domain.ProgressEvent{
+				Message:  fmt.Sprintf("Downloading pack files (%d/%d): %s", n+1, total, file.Path),
+				Progress: float64(n) / float64(total) * 100,
+			}
+		}
+		if err := i.downloadFile(file, serverDir); err != nil {
+			return err
+		}
+	}
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: "Applying overrides..."}
+	}
+	return applyOverrides(archivePath, pack.OverrideDirs, pack.SkipFiles, serverDir)
+}
+
+func (i *Installer) downloadFile(file File, serverDir string) error {
+	var lastErr error
+	for _, rawURL := range file.URLs {
+		if err := i.checkHost(rawURL); err != nil {
+			lastErr = err
+			continue
+		}
+		lastErr = i.tryDownload(rawURL, file, serverDir)
+		if lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("could not download %s: %w", file.Path, lastErr)
+}
+
+func (i *Installer) tryDownload(rawURL string, file File, serverDir string) error {
+	resp, err := i.checkedClient().Get(rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	relPath := file.Path
+	if strings.HasSuffix(relPath, "/") {
+		name := responseFileName(resp)
+		if name == "" {
+			return fmt.Errorf("could not determine file name")
+		}
+		relPath = path.Join(relPath, name)
+	}
+	relPath, err = safeRelativePath(relPath)
+	if err != nil {
+		return err
+	}
+
+	dest := filepath.Join(serverDir, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	sha1Hash := sha1.New()
+	sha512Hash := sha512.New()
+	_, copyErr := io.Copy(io.MultiWriter(tmp, sha1Hash, sha512Hash), resp.Body)
+	closeErr := tmp.Close()
+	if copyErr != nil {
+		return copyErr
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	if file.SHA512 != "" && !strings.EqualFold(hex.EncodeToString(sha512Hash.Sum(nil)), file.SHA512) {
+		return fmt.Errorf("sha512 mismatch for %s", relPath)
+	}
+	if file.SHA1 != "" && !strings.EqualFold(hex.EncodeToString(sha1Hash.Sum(nil)), file.SHA1) {
+		return fmt.Errorf("sha1 mismatch for %s", relPath)
+	}
+
+	return os.Rename(tmp.Name(), dest)
+}
+
+// checkedClient returns the installer's client with every redirect checked
+// against the allowed hosts, so an allowed host cannot hand a download off to
+// any other.
+func (i *Installer) checkedClient() *http.Client {
+	client := *i.Client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return i.checkHost(req.URL.String())
+	}
+	return &client
+}
+
+func (i *Installer) checkHost(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("unsupported URL scheme: %s", u.Scheme)
+	}
+	host := u.Hostname()
+	for _, allowed := range i.AllowedHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("download host not allowed: %s", host)
+}
+
+func responseFileName(resp *http.Response) string {
+	if disposition := resp.Header.Get("Content-Disposition"); disposition != "" {
+		if _, params, err := mime.ParseMediaType(disposition); err == nil && params["filename"] != "" {
+			return path.Base(params["filename"])
+		}
+	}
+	name := path.Base(resp.Request.URL.Path)
+	if name == "/" || name == "." || name == "download" {
+		return ""
+	}
+	return name
+}
+
+func applyOverrides(archivePath string, dirs, skip []string, serverDir string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, dir := range dirs {
+		prefix := ""
+		if dir != "" {
+			prefix = strings.TrimSuffix(dir, "/") + "/"
+		}
+		for _, f := range zr.File {
+			if !strings.HasPrefix(f.Name, prefix) || f.FileInfo().IsDir() || slices.Contains(skip, f.Name) {
+				continue
+			}
+			relPath, err := safeRelativePath(strings.TrimPrefix(f.Name, prefix))
+			if err != nil {
+				return err
+			}
+			if err := extractFile(f, filepath.Join(serverDir, filepath.FromSlash(relPath))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func extractFile(f *zip.File, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, rc)
+	return err
+}
@@ -0,0 +1,340 @@
+package modpack
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeArchive(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+	archivePath := filepath.Join(dir, "pack.zip")
+	out, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to finish archive: %v", err)
+	}
+	out.Close()
+	return archivePath
+}
+
+func hashes(content string) map[string]string {
+	s1 := sha1.Sum([]byte(content))
+	s512 := sha512.Sum512([]byte(content))
+	return map[string]string{
+		"sha1":   hex.EncodeToString(s1[:]),
+		"sha512": hex.EncodeToString(s512[:]),
+	}
+}
+
+func newFakeServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mods/server-mod.jar", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("server mod"))
+	})
+	mux.HandleFunc("/mods/client-mod.jar", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("client mod"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func newTestInstaller(srv *httptest.Server) *Installer {
+	return &Installer{Client: srv.Client(), AllowedHosts: []string{"127.0.0.1"}}
+}
+
+func mrpackIndexJSON(t *testing.T, files []map[string]interface{}) string {
+	t.Helper()
+	index := map[string]interface{}{
+		"formatVersion": 1,
+		"game":          "minecraft",
+		"versionId":     "1.0.0",
+		"name":          "Test Pack",
+		"files":         files,
+		"dependencies": map[string]string{
+			"minecraft":     "1.20.1",
+			"fabric-loader": "0.15.7",
+		},
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		t.Fatalf("Failed to encode index: %v", err)
+	}
+	return string(data)
+}
+
+func TestInstallMrpack(t *testing.T) {
+	srv := newFakeServer()
+	defer srv.Close()
+
+	tempDir := t.TempDir()
+	index := mrpackIndexJSON(t, []map[string]interface{}{
+		{
+			"path":      "mods/server-mod.jar",
+			"hashes":    hashes("server mod"),
+			"downloads": []string{srv.URL + "/mods/server-mod.jar"},
+			"env":       map[string]string{"client": "required", "server": "required"},
+		},
+		{
+			"path":      "mods/client-mod.jar",
+			"hashes":    hashes("client mod"),
+			"downloads": []string{srv.URL + "/mods/client-mod.jar"},
+			"env":       map[string]string{"client": "required", "server": "unsupported"},
+		},
+	})
+	archivePath := writeArchive(t, tempDir, map[string]string{
+		"modrinth.index.json":              index,
+		"overrides/config/mod.toml":        "from overrides",
+		"server-overrides/config/mod.toml": "from server overrides",
+	})
+
+	pack, err := Open(archivePath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if pack.Loader != "fabric" || pack.LoaderVersion != "0.15.7" || pack.MinecraftVersion != "1.20.1" {
+		t.Errorf("Unexpected pack target: %s %s %s", pack.Loader, pack.LoaderVersion, pack.MinecraftVersion)
+	}
+	if len(pack.Files) != 1 {
+		t.Fatalf("Expected client-only file to be skipped, got %d files", len(pack.Files))
+	}
+
+	serverDir := filepath.Join(tempDir, "server")
+	if err := newTestInstaller(srv).Install(pack, archivePath, serverDir, nil); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(serverDir, "mods", "server-mod.jar"))
+	if err != nil || string(content) != "server mod" {
+		t.Errorf("Server mod not installed correctly: %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(serverDir, "mods", "client-mod.jar")); !os.IsNotExist(err) {
+		t.Error("Client-only mod should not be installed")
+	}
+	content, _ = os.ReadFile(filepath.Join(serverDir, "config", "mod.toml"))
+	if string(content) != "from server overrides" {
+		t.Errorf("Expected server-overrides to win, got %q", content)
+	}
+}
+
+func TestInstallRejectsHashMismatch(t *testing.T) {
+	srv := newFakeServer()
+	defer srv.Close()
+
+	tempDir := t.TempDir()
+	index := mrpackIndexJSON(t, []map[string]interface{}{
+		{
+			"path":      "mods/server-mod.jar",
+			"hashes":    hashes("something else"),
+			"downloads": []string{srv.URL + "/mods/server-mod.jar"},
+		},
+	})
+	archivePath := writeArchive(t, tempDir, map[string]string{"modrinth.index.json": index})
+
+	pack, err := Open(archivePath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	serverDir := filepath.Join(tempDir, "server")
+	err = newTestInstaller(srv).Install(pack, archivePath, serverDir, nil)
+	if err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Fatalf("Expected hash mismatch error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(serverDir, "mods", "server-mod.jar")); !os.IsNotExist(err) {
+		t.Error("File with bad hash should not be kept")
+	}
+}
+
+func TestInstallRejectsDisallowedHost(t *testing.T) {
+	srv := newFakeServer()
+	defer srv.Close()
+
+	tempDir := t.TempDir()
+	index := mrpackIndexJSON(t, []map[string]interface{}{
+		{
+			"path":      "mods/server-mod.jar",
+			"hashes":    hashes("server mod"),
+			"downloads": []string{srv.URL + "/mods/server-mod.jar"},
+		},
+	})
+	archivePath := writeArchive(t, tempDir, map[string]string{"modrinth.index.json": index})
+
+	pack, err := Open(archivePath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	installer := &Installer{Client: srv.Client(), AllowedHosts: DefaultAllowedHosts}
+	if err := installer.Install(pack, archivePath, filepath.Join(tempDir, "server"), nil); err == nil {
+		t.Fatal("Expected download from a non-allowed host to fail")
+	}
+}
+
+func TestInstallRejectsRedirectToDisallowedHost(t *testing.T) {
+	srv := newFakeServer()
+	defer srv.Close()
+
+	// The fake server is allowed as 127.0.0.1 but not as localhost.
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)+"/mods/server-mod.jar", http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	tempDir := t.TempDir()
+	index := mrpackIndexJSON(t, []map[string]interface{}{
+		{
+			"path":      "mods/server-mod.jar",
+			"hashes":    hashes("server mod"),
+			"downloads": []string{redirect.URL + "/mods/server-mod.jar"},
+		},
+	})
+	archivePath := writeArchive(t, tempDir, map[string]string{"modrinth.index.json": index})
+
+	pack, err := Open(archivePath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	err = newTestInstaller(srv).Install(pack, archivePath, filepath.Join(tempDir, "server"), nil)
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("Expected a redirect to a non-allowed host to fail, got %v", err)
+	}
+}
+
+func TestParseRejectsPathTraversal(t *testing.T) {
+	tempDir := t.TempDir()
+	index := mrpackIndexJSON(t, []map[string]interface{}{
+		{
+			"path":      "../escape.jar",
+			"hashes":    hashes("x"),
+			"downloads": []string{"https://cdn.modrinth.com/escape.jar"},
+		},
+	})
+	archivePath := writeArchive(t, tempDir, map[string]string{"modrinth.index.json": index})
+
+	if _, err := Open(archivePath); err == nil {
+		t.Fatal("Expected path traversal to be rejected")
+	}
+}
+
+func TestParseRejectsQuilt(t *testing.T) {
+	tempDir := t.TempDir()
+	index := `{"game": "minecraft", "name": "Quilt Pack", "files": [],
+		"dependencies": {"minecraft": "1.20.1", "quilt-loader": "0.23.1"}}`
+	archivePath := writeArchive(t, tempDir, map[string]string{"modrinth.index.json": index})
+
+	if _, err := Open(archivePath); err == nil || !strings.Contains(err.Error(), "Quilt") {
+		t.Fatalf("Expected Quilt packs to be rejected, got %v", err)
+	}
+}
+
+func TestInstallCurseForgeServerPack(t *testing.T) {
+	tempDir := t.TempDir()
+	archivePath := writeArchive(t, tempDir, map[string]string{
+		"CF Pack Server/forge-1.20.1-47.2.0-installer.jar": "installer",
+		"CF Pack Server/mods/cf-mod.jar":                   "curseforge mod",
+		"CF Pack Server/config/cf-mod.toml":                "enabled = true",
+		"CF Pack Server/server-icon.png":                   "icon",
+	})
+
+	pack, err := Open(archivePath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if pack.Name != "CF Pack Server" || pack.Loader != "forge" || pack.LoaderVersion != "47.2.0" || pack.MinecraftVersion != "1.20.1" {
+		t.Errorf("Unexpected pack: %+v", pack)
+	}
+
+	serverDir := filepath.Join(tempDir, "server")
+	if err := (&Installer{}).Install(pack, archivePath, serverDir, nil); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	for file, want := range map[string]string{"mods/cf-mod.jar": "curseforge mod", "config/cf-mod.toml": "enabled = true", "server-icon.png": "icon"} {
+		content, err := os.ReadFile(filepath.Join(serverDir, filepath.FromSlash(file)))
+		if err != nil || string(content) != want {
+			t.Errorf("%s not copied correctly: %q, %v", file, content, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(serverDir, "forge-1.20.1-47.2.0-installer.jar")); !os.IsNotExist(err) {
+		t.Error("Loader installer should not be copied")
+	}
+}
+
+func TestParseServerPackLoader(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		loader    string
+		version   string
+		minecraft string
+	}{
+		{
+			name: "variables.txt",
+			files: map[string]string{
+				"variables.txt": "# Server starter settings\nMINECRAFT_VERSION=1.21.1\nMODLOADER=NeoForge\nMODLOADER_VERSION=\"21.1.77\"\n",
+				"mods/a.jar":    "a",
+			},
+			loader: "neoforge", version: "21.1.77", minecraft: "1.21.1",
+		},
+		{
+			name:   "neoforge installer",
+			files:  map[string]string{"neoforge-20.4.80-beta-installer.jar": "", "mods/a.jar": "a"},
+			loader: "neoforge", version: "20.4.80-beta", minecraft: "1.20.4",
+		},
+		{
+			name:   "fabric launcher",
+			files:  map[string]string{"fabric-server-mc.1.20.1-loader.0.15.7-launcher.1.0.0.jar": "", "mods/a.jar": "a"},
+			loader: "fabric", version: "0.15.7", minecraft: "1.20.1",
+		},
+	}
+
+	for _, tt := range tests {
+		archivePath := writeArchive(t, t.TempDir(), tt.files)
+		pack, err := Open(archivePath)
+		if err != nil {
+			t.Errorf("%s: Open failed: %v", tt.name, err)
+			continue
+		}
+		if pack.Loader != tt.loader || pack.LoaderVersion != tt.version || pack.MinecraftVersion != tt.minecraft {
+			t.Errorf("%s: got %s %s for %s, want %s %s for %s", tt.name, pack.Loader, pack.LoaderVersion, pack.MinecraftVersion, tt.loader, tt.version, tt.minecraft)
+		}
+	}
+
+	archivePath := writeArchive(t, t.TempDir(), map[string]string{"mods/a.jar": "a"})
+	if _, err := Open(archivePath); err == nil || !strings.Contains(err.Error(), "loader") {
+		t.Errorf("Expected a server pack without a loader to be rejected, got %v", err)
+	}
+}
+
+func TestParseRejectsCurseForgeClientPack(t *testing.T) {
+	manifest := `{
+		"manifestType": "minecraftModpack",
+		"name": "CF Pack",
+		"minecraft": {"version": "1.20.1", "modLoaders": [{"id": "forge-47.2.0", "primary": true}]},
+		"files": [{"projectID": 100, "fileID": 200, "required": true}],
+		"overrides": "overrides"
+	}`
+	archivePath := writeArchive(t, t.TempDir(), map[string]string{"manifest.json": manifest})
+
+	if _, err := Open(archivePath); err == nil || !strings.Contains(err.Error(), "server files") {
+		t.Fatalf("Expected CurseForge client packs to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package modpack
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+)
+
+const mrpackIndexName = "modrinth.index.json"
+
+type mrpackIndex struct {
+	FormatVersion int               `json:"formatVersion"`
+	Game          string            `json:"game"`
+	VersionID     string            `json:"versionId"`
+	Name          string            `json:"name"`
+	Files         []mrpackFile      `json:"files"`
+	Dependencies  map[string]string `json:"dependencies"`
+}
+
+type mrpackFile struct {
+	Path      string            `json:"path"`
+	Hashes    map[string]string `json:"hashes"`
+	Env       map[string]string `json:"env"`
+	Downloads []string          `json:"downloads"`
+	FileSize  int64             `json:"fileSize"`
+}
+
+// mrpackLoaders maps Modrinth dependency keys to Naviger loader names, in the
+// order they are checked.
+var mrpackLoaders = []struct {
+	dependency string
+	loader     string
+}{
+	{"neoforge", "neoforge"},
+	{"forge", "forge"},
+	{"fabric-loader", "fabric"},
+}
+
+func parseMrpack(f *zip.File) (*Pack, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var index mrpackIndex
+	if err := json.NewDecoder(rc).Decode(&index); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", mrpackIndexName, err)
+	}
+	if index.Game != "" && index.Game != "minecraft" {
+		return nil, fmt.Errorf("unsupported game '%s'", index.Game)
+	}
+
+	pack := &Pack{
+		Name:             index.Name,
+		Version:          index.VersionID,
+		MinecraftVersion: index.Dependencies["minecraft"],
+		Loader:           "vanilla",
+		OverrideDirs:     []string{"overrides", "server-overrides"},
+	}
+	if pack.MinecraftVersion == "" {
+		return nil, fmt.Errorf("modpack does not declare a minecraft version")
+	}
+
+	if _, ok := index.Dependencies["quilt-loader"]; ok {
+		return nil, fmt.Errorf("modpacks for the Quilt loader are not supported")
+	}
+	for _, candidate := range mrpackLoaders {
+		if version, ok := index.Dependencies[candidate.dependency]; ok {
+			pack.Loader = candidate.loader
+			pack.LoaderVersion = version
+			break
+		}
+	}
+
+	for _, file := range index.Files {
+		if file.Env["server"] == "unsupported" {
+			continue
+		}
+		cleaned, err := safeRelativePath(file.Path)
+		if err != nil {
+			return nil, err
+		}
+		if file.Hashes["sha1"] == "" && file.Hashes["sha512"] == "" {
+			return nil, fmt.Errorf("file %s has no hashes", file.Path)
+		}
+		if len(file.Downloads) == 0 {
+			return nil, fmt.Errorf("file %s has no download URLs", file.Path)
+		}
+		pack.Files = append(pack.Files, File{
+			Path:   cleaned,
+			URLs:   file.Downloads,
+			SHA1:   file.Hashes["sha1"],
+			SHA512: file.Hashes["sha512"],
+			Size:   file.FileSize,
+		})
+	}
+
+	return pack, nil
+}
@@ -0,0 +1,58 @@
+package modpack
+
+import (
+	"archive/zip"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Pack is the loader-independent description of a modpack archive.
+type Pack struct {
+	Name             string
+	Version          string
+	MinecraftVersion string
+	Loader           string
+	LoaderVersion    string
+	Files            []File
+	// OverrideDirs lists the archive directories whose contents are copied
+	// over the server directory, in the order they must be applied.
+	OverrideDirs []string
+	// SkipFiles lists archive paths inside OverrideDirs that are not copied,
+	// such as the loader installer of a server pack.
+	SkipFiles []string
+}
+
+// File is a single file the pack expects to be downloaded into the server.
+type File struct {
+	Path   string
+	URLs   []string
+	SHA1   string
+	SHA512 string
+	Size   int64
+}
+
+// Parse detects the pack format of the archive and reads its manifest. An
+// archive without a manifest is read as a CurseForge server pack.
+func Parse(zr *zip.Reader) (*Pack, error) {
+	for _, f := range zr.File {
+		switch f.Name {
+		case mrpackIndexName:
+			return parseMrpack(f)
+		case curseForgeManifestName:
+			return rejectCurseForgeManifest()
+		}
+	}
+	return parseServerPack(zr)
+}
+
+// safeRelativePath rejects absolute paths and paths escaping the server
+// directory, and returns the cleaned slash-separated form.
+func safeRelativePath(p string) (string, error) {
+	p = strings.ReplaceAll(p, "\\", "/")
+	cleaned := path.Clean(p)
+	if cleaned == "." || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") || strings.Contains(cleaned, ":") {
+		return "", fmt.Errorf("invalid path in modpack: %s", p)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,252 @@
+package nbt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Merge applies a JSON merge patch (RFC 7386) to a decoded compound. JSON
+// has no tag types, so each value takes the type of the tag it replaces:
+// elements of a replaced list follow the old element at the same index or
+// the first one, which keeps items of an inventory typed like the others.
+// Values without a tag to follow become ints, longs, doubles, strings,
+// lists and compounds, and true and false become bytes. A null removes the
+// key.
+//
+// Patch values are those of encoding/json, preferably decoded with
+// UseNumber so that longs keep all their digits.
+func Merge(dst map[string]any, patch map[string]any) error {
+	return merge(dst, patch, "")
+}
+
+func merge(dst map[string]any, patch map[string]any, path string) error {
+	for key, value := range patch {
+		keyPath := path + "/" + key
+		if value == nil {
+			delete(dst, key)
+			continue
+		}
+		if object, ok := value.(map[string]any); ok {
+			if compound, ok := dst[key].(map[string]any); ok {
+				if err := merge(compound, object, keyPath); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		converted, err := convert(value, dst[key], keyPath)
+		if err != nil {
+			return err
+		}
+		dst[key] = converted
+	}
+	return nil
+}
+
+// convert turns a JSON value into a tag of the same type as template, or of
+// the natural type for the value when template is nil.
+func convert(value, template any, path string) (any, error) {
+	if template == nil {
+		return infer(value, path)
+	}
+
+	switch template.(type) {
+	case int8, int16, int32, int64:
+		n, err := jsonInt(value, path)
+		if err != nil {
+			return nil, err
+		}
+		return fitInt(n, template, path)
+	case float32:
+		f, err := jsonFloat(value, path)
+		return float32(f), err
+	case float64:
+		return jsonFloat(value, path)
+	case string:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a string", path)
+		}
+		return s, nil
+	case []int8, []int32, []int64:
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected an array", path)
+		}
+		return convertArray(items, template, path)
+	case []any:
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a list", path)
+		}
+		old := template.([]any)
+		list := make([]any, 0, len(items))
+		for i, item := range items {
+			var elemTemplate any
+			if i < len(old) {
+				elemTemplate = old[i]
+			} else if len(old) > 0 {
+				elemTemplate = old[0]
+			}
+			converted, err := convert(item, elemTemplate, path+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, converted)
+		}
+		return list, nil
+	case map[string]any:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected an object", path)
+		}
+		old := template.(map[string]any)
+		compound := make(map[string]any, len(object))
+		for key, item := range object {
+			if item == nil {
+				continue
+			}
+			converted, err := convert(item, old[key], path+"/"+key)
+			if err != nil {
+				return nil, err
+			}
+			compound[key] = converted
+		}
+		return compound, nil
+	}
+	return nil, fmt.Errorf("%s: cannot replace a %T", path, template)
+}
+
+func infer(value any, path string) (any, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return int8(1), nil
+		}
+		return int8(0), nil
+	case string:
+		return v, nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			if n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int32(n), nil
+			}
+			return n, nil
+		}
+		return v.Float64()
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int32(v), nil
+		}
+		return v, nil
+	case []any:
+		return convert(v, []any{}, path)
+	case map[string]any:
+		return convert(v, map[string]any{}, path)
+	}
+	return nil, fmt.Errorf("%s: unsupported value %T", path, value)
+}
+
+func convertArray(items []any, template any, path string) (any, error) {
+	var elemTemplate any
+	switch template.(type) {
+	case []int8:
+		elemTemplate = int8(0)
+	case []int32:
+		elemTemplate = int32(0)
+	default:
+		elemTemplate = int64(0)
+	}
+
+	ints := make([]int64, len(items))
+	for i, item := range items {
+		n, err := jsonInt(item, path+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fitInt(n, elemTemplate, path+"/"+strconv.Itoa(i)); err != nil {
+			return nil, err
+		}
+		ints[i] = n
+	}
+
+	switch template.(type) {
+	case []int8:
+		array := make([]int8, len(ints))
+		for i, n := range ints {
+			array[i] = int8(n)
+		}
+		return array, nil
+	case []int32:
+		array := make([]int32, len(ints))
+		for i, n := range ints {
+			array[i] = int32(n)
+		}
+		return array, nil
+	}
+	return ints, nil
+}
+
+// fitInt converts n to the integer type of template, refusing values that
+// do not fit.
+func fitInt(n int64, template any, path string) (any, error) {
+	var low, high int64
+	switch template.(type) {
+	case int8:
+		low, high = math.MinInt8, math.MaxInt8
+	case int16:
+		low, high = math.MinInt16, math.MaxInt16
+	case int32:
+		low, high = math.MinInt32, math.MaxInt32
+	default:
+		return n, nil
+	}
+	if n < low || n > high {
+		return nil, fmt.Errorf("%s: %d does not fit in a %T", path, n, template)
+	}
+	switch template.(type) {
+	case int8:
+		return int8(n), nil
+	case int16:
+		return int16(n), nil
+	}
+	return int32(n), nil
+}
+
+func jsonInt(value any, path string) (int64, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("%s: expected an integer", path)
+		}
+		return n, nil
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+			return 0, fmt.Errorf("%s: expected an integer", path)
+		}
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("%s: expected an integer", path)
+}
+
+func jsonFloat(value any, path string) (float64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("%s: expected a number", path)
+		}
+		return f, nil
+	case float64:
+		return v, nil
+	}
+	return 0, fmt.Errorf("%s: expected a number", path)
+}
@@ -0,0 +1,61 @@
+package nbt
+
+import (
+	"bytes"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Java writes strings in modified UTF-8: NUL takes two bytes and characters
+// outside the BMP are written as a surrogate pair of three bytes each.
+
+func decodeMUTF8(b []byte) string {
+	if utf8.Valid(b) && bytes.IndexByte(b, 0xc0) < 0 {
+		return string(b)
+	}
+
+	runes := make([]rune, 0, len(b))
+	for i := 0; i < len(b); {
+		c := b[i]
+		switch {
+		case c < 0x80:
+			runes = append(runes, rune(c))
+			i++
+		case c&0xe0 == 0xc0 && i+1 < len(b):
+			runes = append(runes, rune(c&0x1f)<<6|rune(b[i+1]&0x3f))
+			i += 2
+		case c&0xf0 == 0xe0 && i+2 < len(b):
+			r := rune(c&0x0f)<<12 | rune(b[i+1]&0x3f)<<6 | rune(b[i+2]&0x3f)
+			if n := len(runes); n > 0 && runes[n-1] >= 0xd800 && runes[n-1] < 0xdc00 && r >= 0xdc00 && r < 0xe000 {
+				runes[n-1] = utf16.DecodeRune(runes[n-1], r)
+			} else {
+				runes = append(runes, r)
+			}
+			i += 3
+		default:
+			// Real UTF-8 written by another tool.
+			r, size := utf8.DecodeRune(b[i:])
+			runes = append(runes, r)
+			i += size
+		}
+	}
+	return string(runes)
+}
+
+func encodeMUTF8(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == 0:
+			b = append(b, 0xc0, 0x80)
+		case r < 0x10000:
+			b = utf8.AppendRune(b, r)
+		default:
+			high, low := utf16.EncodeRune(r)
+			for _, c := range []rune{high, low} {
+				b = append(b, byte(0xe0|c>>12), byte(0x80|(c>>6)&0x3f), byte(0x80|c&0x3f))
+			}
+		}
+	}
+	return b
+}
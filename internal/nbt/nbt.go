@@ -0,0 +1,251 @@
+// Package nbt reads and writes Minecraft's Named Binary Tag format and reads
+// chunks from Anvil region files.
+//
+// Tags decode to plain Go values: compounds to map[string]any, lists to
+// []any, byte, int and long arrays to []int8, []int32 and []int64, and the
+// numeric tags to int8, int16, int32, int64, float32 and float64.
+package nbt
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Tag types.
+const (
+	TagEnd byte = iota
+	TagByte
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+	TagLongArray
+)
+
+// maxDepth bounds nesting so a hostile file cannot exhaust the stack.
+const maxDepth = 512
+
+// Decode reads one named root tag, which must be a compound.
+func Decode(r io.Reader) (string, map[string]any, error) {
+	d := decoder{r: bufio.NewReader(r)}
+	tagType, err := d.byte()
+	if err != nil {
+		return "", nil, err
+	}
+	if tagType != TagCompound {
+		return "", nil, fmt.Errorf("nbt: root tag is type %d, not a compound", tagType)
+	}
+	name, err := d.string()
+	if err != nil {
+		return "", nil, err
+	}
+	root, err := d.compound(0)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, root, nil
+}
+
+// DecodeCompressed reads a root compound that is gzip or zlib compressed, as
+// in level.dat and player files, or stored raw.
+func DecodeCompressed(data []byte) (string, map[string]any, error) {
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", nil, err
+		}
+		defer zr.Close()
+		return Decode(zr)
+	case len(data) >= 2 && data[0] == 0x78:
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", nil, err
+		}
+		defer zr.Close()
+		return Decode(zr)
+	default:
+		return Decode(bytes.NewReader(data))
+	}
+}
+
+// ReadFile reads a possibly compressed NBT file.
+func ReadFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	_, root, err := DecodeCompressed(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return root, nil
+}
+
+// Path follows compound keys from root and returns the value found, or nil.
+func Path(root map[string]any, keys ...string) any {
+	var value any = root
+	for _, key := range keys {
+		compound, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = compound[key]
+	}
+	return value
+}
+
+type decoder struct {
+	r *bufio.Reader
+}
+
+var errTooDeep = errors.New("nbt: tags nested too deeply")
+
+func (d *decoder) payload(tagType byte, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+	switch tagType {
+	case TagByte:
+		v, err := d.byte()
+		return int8(v), err
+	case TagShort:
+		var v int16
+		err := binary.Read(d.r, binary.BigEndian, &v)
+		return v, err
+	case TagInt:
+		return d.int()
+	case TagLong:
+		var v int64
+		err := binary.Read(d.r, binary.BigEndian, &v)
+		return v, err
+	case TagFloat:
+		var v uint32
+		err := binary.Read(d.r, binary.BigEndian, &v)
+		return math.Float32frombits(v), err
+	case TagDouble:
+		var v uint64
+		err := binary.Read(d.r, binary.BigEndian, &v)
+		return math.Float64frombits(v), err
+	case TagByteArray:
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		v := make([]int8, n)
+		return v, binary.Read(d.r, binary.BigEndian, v)
+	case TagString:
+		return d.string()
+	case TagList:
+		elemType, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]any, 0, min(n, 1024))
+		for range n {
+			item, err := d.payload(elemType, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	case TagCompound:
+		return d.compound(depth + 1)
+	case TagIntArray:
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		v := make([]int32, n)
+		return v, binary.Read(d.r, binary.BigEndian, v)
+	case TagLongArray:
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		v := make([]int64, n)
+		return v, binary.Read(d.r, binary.BigEndian, v)
+	}
+	return nil, fmt.Errorf("nbt: unknown tag type %d", tagType)
+}
+
+func (d *decoder) compound(depth int) (map[string]any, error) {
+	compound := make(map[string]any)
+	for {
+		tagType, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		if tagType == TagEnd {
+			return compound, nil
+		}
+		name, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.payload(tagType, depth)
+		if err != nil {
+			return nil, err
+		}
+		compound[name] = value
+	}
+}
+
+func (d *decoder) byte() (byte, error) {
+	b, err := d.r.ReadByte()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return b, err
+}
+
+func (d *decoder) int() (int32, error) {
+	var v int32
+	err := binary.Read(d.r, binary.BigEndian, &v)
+	return v, err
+}
+
+// length reads an array or list length, refusing sizes no real file has so
+// a corrupt one cannot allocate gigabytes.
+func (d *decoder) length() (int, error) {
+	n, err := d.int()
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 || n > 64<<20 {
+		return 0, fmt.Errorf("nbt: invalid length %d", n)
+	}
+	return int(n), nil
+}
+
+// string reads a length-prefixed modified UTF-8 string.
+func (d *decoder) string() (string, error) {
+	var n uint16
+	if err := binary.Read(d.r, binary.BigEndian, &n); err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return "", err
+	}
+	return decodeMUTF8(buf), nil
+}
@@ -0,0 +1,223 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tagWriter builds NBT by hand for the tests.
+type tagWriter struct {
+	bytes.Buffer
+}
+
+func (w *tagWriter) name(tagType byte, name string) {
+	w.WriteByte(tagType)
+	w.str(name)
+}
+
+func (w *tagWriter) str(s string) {
+	binary.Write(&w.Buffer, binary.BigEndian, uint16(len(s)))
+	w.WriteString(s)
+}
+
+func (w *tagWriter) put(v any) {
+	binary.Write(&w.Buffer, binary.BigEndian, v)
+}
+
+func levelDat() []byte {
+	var w tagWriter
+	w.name(TagCompound, "")
+	w.name(TagCompound, "Data")
+	w.name(TagString, "LevelName")
+	w.str("My World")
+	w.name(TagInt, "DataVersion")
+	w.put(int32(3955))
+	w.name(TagCompound, "WorldGenSettings")
+	w.name(TagLong, "seed")
+	w.put(int64(-4172144997902289642))
+	w.name(TagByte, "generate_features")
+	w.put(int8(1))
+	w.WriteByte(TagEnd)
+	w.name(TagList, "ServerBrands")
+	w.WriteByte(TagString)
+	w.put(int32(2))
+	w.str("vanilla")
+	w.str("paper")
+	w.name(TagIntArray, "WanderingTraderId")
+	w.put(int32(2))
+	w.put([]int32{7, -7})
+	w.name(TagDouble, "BorderSize")
+	w.put(float64(5.9999968e7))
+	w.name(TagList, "Empty")
+	w.WriteByte(TagEnd)
+	w.put(int32(0))
+	w.WriteByte(TagEnd)
+	w.WriteByte(TagEnd)
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(w.Bytes())
+	zw.Close()
+	return gz.Bytes()
+}
+
+func TestDecodeLevelDat(t *testing.T) {
+	_, root, err := DecodeCompressed(levelDat())
+	if err != nil {
+		t.Fatalf("DecodeCompressed: %v", err)
+	}
+
+	tests := []struct {
+		path []string
+		want any
+	}{
+		{[]string{"Data", "LevelName"}, "My World"},
+		{[]string{"Data", "DataVersion"}, int32(3955)},
+		{[]string{"Data", "WorldGenSettings", "seed"}, int64(-4172144997902289642)},
+		{[]string{"Data", "WorldGenSettings", "generate_features"}, int8(1)},
+		{[]string{"Data", "ServerBrands"}, []any{"vanilla", "paper"}},
+		{[]string{"Data", "WanderingTraderId"}, []int32{7, -7}},
+		{[]string{"Data", "BorderSize"}, float64(5.9999968e7)},
+		{[]string{"Data", "Empty"}, []any{}},
+		{[]string{"Data", "Missing"}, nil},
+		{[]string{"Data", "LevelName", "Deeper"}, nil},
+	}
+	for _, tt := range tests {
+		if got := Path(root, tt.path...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Path(%q) = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeRejectsCorruptData(t *testing.T) {
+	data := levelDat()
+	inputs := map[string][]byte{
+		"not a compound": {TagString, 0, 0, 0, 0},
+		"truncated":      {TagCompound, 0, 0, TagInt, 0, 1, 'x', 0},
+		"huge list":      {TagCompound, 0, 0, TagList, 0, 1, 'x', TagLong, 0x7f, 0xff, 0xff, 0xff},
+		"bad gzip":       data[:len(data)/2],
+	}
+	for name, input := range inputs {
+		if _, _, err := DecodeCompressed(input); err == nil {
+			t.Errorf("%s: decoded without an error", name)
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	_, root, err := DecodeCompressed(levelDat())
+	if err != nil {
+		t.Fatalf("DecodeCompressed: %v", err)
+	}
+	Path(root, "Data").(map[string]any)["Motd"] = "nul\x00 and 🎉"
+	Path(root, "Data").(map[string]any)["Heights"] = []int64{1, -1}
+
+	path := filepath.Join(t.TempDir(), "level.dat")
+	if err := WriteFile(path, root); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	again, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !reflect.DeepEqual(again, root) {
+		t.Errorf("round trip changed the data:\n got %#v\nwant %#v", again, root)
+	}
+}
+
+func TestEncodeModifiedUTF8(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, "", map[string]any{"s": "\x00🎉"}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	// NUL as C0 80 and the emoji as two three-byte surrogates.
+	want := []byte{0, 8, 0xc0, 0x80, 0xed, 0xa0, 0xbc, 0xed, 0xbe, 0x89}
+	if !bytes.Contains(buf.Bytes(), want) {
+		t.Errorf("encoded % x, want it to contain % x", buf.Bytes(), want)
+	}
+}
+
+func TestEncodeRejectsMixedLists(t *testing.T) {
+	err := Encode(&bytes.Buffer{}, "", map[string]any{"l": []any{int32(1), "two"}})
+	if err == nil {
+		t.Error("encoded a list mixing ints and strings")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	root := map[string]any{
+		"Health": float32(20),
+		"Pos":    []any{1.5, 64.0, -3.5},
+		"Inventory": []any{
+			map[string]any{"Slot": int8(0), "id": "minecraft:stone", "count": int32(64)},
+		},
+		"Score":     int32(7),
+		"XpSeed":    int64(0),
+		"Removed":   "x",
+		"Unchanged": map[string]any{"a": int16(1), "b": int16(2)},
+	}
+
+	var patch map[string]any
+	dec := json.NewDecoder(strings.NewReader(`{
+		"Health": 10,
+		"Pos": [0.5, 80, 0.5],
+		"Inventory": [
+			{"Slot": 0, "id": "minecraft:stone", "count": 1},
+			{"Slot": 8, "id": "minecraft:torch", "count": 16}
+		],
+		"XpSeed": 9007199254740993,
+		"Removed": null,
+		"Unchanged": {"b": 3},
+		"New": {"flag": true, "ratio": 0.5, "names": ["a"]}
+	}`))
+	dec.UseNumber()
+	if err := dec.Decode(&patch); err != nil {
+		t.Fatal(err)
+	}
+	if err := Merge(root, patch); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+
+	want := map[string]any{
+		"Health": float32(10),
+		"Pos":    []any{0.5, 80.0, 0.5},
+		"Inventory": []any{
+			map[string]any{"Slot": int8(0), "id": "minecraft:stone", "count": int32(1)},
+			map[string]any{"Slot": int8(8), "id": "minecraft:torch", "count": int32(16)},
+		},
+		"Score":     int32(7),
+		"XpSeed":    int64(9007199254740993),
+		"Unchanged": map[string]any{"a": int16(1), "b": int16(3)},
+		"New":       map[string]any{"flag": int8(1), "ratio": 0.5, "names": []any{"a"}},
+	}
+	if !reflect.DeepEqual(root, want) {
+		t.Errorf("Merge:\n got %#v\nwant %#v", root, want)
+	}
+}
+
+func TestMergeRejectsMismatches(t *testing.T) {
+	patches := map[string]string{
+		"string for int": `{"Score": "high"}`,
+		"overflow":       `{"Slot": 300}`,
+		"fraction":       `{"Score": 1.5}`,
+		"list for int":   `{"Score": [1]}`,
+	}
+	for name, patchJSON := range patches {
+		root := map[string]any{"Score": int32(7), "Slot": int8(1)}
+		var patch map[string]any
+		dec := json.NewDecoder(strings.NewReader(patchJSON))
+		dec.UseNumber()
+		if err := dec.Decode(&patch); err != nil {
+			t.Fatal(err)
+		}
+		if err := Merge(root, patch); err == nil {
+			t.Errorf("%s: merged without an error", name)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package nbt
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Anvil region files hold 32×32 chunks behind a header of 1024 chunk
+// locations and 1024 modification times, in 4 KiB sectors.
+const (
+	sectorSize    = 4096
+	regionChunks  = 32
+	externalChunk = 0x80
+)
+
+// Chunk compression schemes.
+const (
+	compressionGzip = 1
+	compressionZlib = 2
+	compressionNone = 3
+	compressionLZ4  = 4
+)
+
+// ErrChunkNotFound is returned for chunks that were never generated.
+var ErrChunkNotFound = errors.New("nbt: chunk not generated")
+
+// Region is an open Anvil region file (r.<x>.<z>.mca).
+type Region struct {
+	file       *os.File
+	locations  [regionChunks * regionChunks]uint32
+	timestamps [regionChunks * regionChunks]uint32
+}
+
+// RegionChunk is a chunk present in a region file, at absolute chunk
+// coordinates.
+type RegionChunk struct {
+	X        int       `json:"x"`
+	Z        int       `json:"z"`
+	Modified time.Time `json:"modified"`
+}
+
+// RegionFileName returns the name of the region file holding a chunk.
+func RegionFileName(chunkX, chunkZ int) string {
+	return fmt.Sprintf("r.%d.%d.mca", chunkX>>5, chunkZ>>5)
+}
+
+// OpenRegion opens a region file and reads its header.
+func OpenRegion(path string) (*Region, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Region{file: file}
+	header := io.NewSectionReader(file, 0, 2*sectorSize)
+	if err := binary.Read(header, binary.BigEndian, &r.locations); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: invalid region header: %w", path, err)
+	}
+	if err := binary.Read(header, binary.BigEndian, &r.timestamps); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: invalid region header: %w", path, err)
+	}
+	return r, nil
+}
+
+// Close closes the region file.
+func (r *Region) Close() error {
+	return r.file.Close()
+}
+
+// Chunks lists the generated chunks of the region. The chunk coordinates
+// come from the file name.
+func (r *Region) Chunks() []RegionChunk {
+	var regionX, regionZ int
+	fmt.Sscanf(filepath.Base(r.file.Name()), "r.%d.%d.mca", &regionX, &regionZ)
+
+	var chunks []RegionChunk
+	for i, location := range r.locations {
+		if location == 0 {
+			continue
+		}
+		chunks = append(chunks, RegionChunk{
+			X:        regionX*regionChunks + i%regionChunks,
+			Z:        regionZ*regionChunks + i/regionChunks,
+			Modified: time.Unix(int64(r.timestamps[i]), 0),
+		})
+	}
+	return chunks
+}
+
+// ReadChunk decodes the chunk at absolute chunk coordinates, which must lie
+// in this region.
+func (r *Region) ReadChunk(chunkX, chunkZ int) (map[string]any, error) {
+	index := (chunkZ&(regionChunks-1))*regionChunks + chunkX&(regionChunks-1)
+	location := r.locations[index]
+	if location == 0 {
+		return nil, ErrChunkNotFound
+	}
+	offset := int64(location>>8) * sectorSize
+	sectors := int64(location & 0xff)
+
+	var header struct {
+		Length      uint32
+		Compression byte
+	}
+	if err := binary.Read(io.NewSectionReader(r.file, offset, 5), binary.BigEndian, &header); err != nil {
+		return nil, fmt.Errorf("chunk %d,%d: %w", chunkX, chunkZ, err)
+	}
+	if header.Length == 0 || int64(header.Length)+4 > sectors*sectorSize {
+		return nil, fmt.Errorf("chunk %d,%d: invalid length %d", chunkX, chunkZ, header.Length)
+	}
+
+	var data io.Reader = io.NewSectionReader(r.file, offset+5, int64(header.Length)-1)
+	if header.Compression&externalChunk != 0 {
+		// Chunks over 1 MiB are stored next to the region file.
+		name := fmt.Sprintf("c.%d.%d.mcc", chunkX, chunkZ)
+		external, err := os.Open(filepath.Join(filepath.Dir(r.file.Name()), name))
+		if err != nil {
+			return nil, err
+		}
+		defer external.Close()
+		data = external
+	}
+
+	switch header.Compression &^ externalChunk {
+	case compressionGzip:
+		zr, err := gzip.NewReader(data)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		data = zr
+	case compressionZlib:
+		zr, err := zlib.NewReader(data)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		data = zr
+	case compressionNone:
+	case compressionLZ4:
+		return nil, fmt.Errorf("chunk %d,%d: LZ4 compressed chunks are not supported", chunkX, chunkZ)
+	default:
+		return nil, fmt.Errorf("chunk %d,%d: unknown compression %d", chunkX, chunkZ, header.Compression)
+	}
+
+	_, root, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("chunk %d,%d: %w", chunkX, chunkZ, err)
+	}
+	return root, nil
+}
@@ -0,0 +1,91 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeRegion writes r.-1.0.mca with one zlib compressed chunk at chunk
+// -31,5 and one stored in an external file at -32,0.
+func writeRegion(t *testing.T, dir string) string {
+	t.Helper()
+
+	var chunk bytes.Buffer
+	zw := zlib.NewWriter(&chunk)
+	if err := Encode(zw, "", map[string]any{"xPos": int32(-31), "zPos": int32(5), "Status": "minecraft:full"}); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+
+	var external bytes.Buffer
+	if err := Encode(&external, "", map[string]any{"xPos": int32(-32), "zPos": int32(0)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "c.-32.0.mcc"), external.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := make([]byte, 4*sectorSize)
+	// -31,5 is local 1,5: index 5*32+1, in sector 2.
+	binary.BigEndian.PutUint32(file[4*(5*32+1):], 2<<8|1)
+	binary.BigEndian.PutUint32(file[sectorSize+4*(5*32+1):], 1700000000)
+	binary.BigEndian.PutUint32(file[2*sectorSize:], uint32(chunk.Len()+1))
+	file[2*sectorSize+4] = compressionZlib
+	copy(file[2*sectorSize+5:], chunk.Bytes())
+	// -32,0 is local 0,0, stored outside with uncompressed data.
+	binary.BigEndian.PutUint32(file[0:], 3<<8|1)
+	binary.BigEndian.PutUint32(file[3*sectorSize:], 1)
+	file[3*sectorSize+4] = externalChunk | compressionNone
+
+	path := filepath.Join(dir, "r.-1.0.mca")
+	if err := os.WriteFile(path, file, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRegion(t *testing.T) {
+	dir := t.TempDir()
+	region, err := OpenRegion(writeRegion(t, dir))
+	if err != nil {
+		t.Fatalf("OpenRegion: %v", err)
+	}
+	defer region.Close()
+
+	chunks := region.Chunks()
+	if len(chunks) != 2 || chunks[0].X != -32 || chunks[0].Z != 0 || chunks[1].X != -31 || chunks[1].Z != 5 {
+		t.Fatalf("Chunks() = %+v", chunks)
+	}
+	if chunks[1].Modified.Unix() != 1700000000 {
+		t.Errorf("Modified = %v", chunks[1].Modified)
+	}
+
+	if got := RegionFileName(-31, 5); got != "r.-1.0.mca" {
+		t.Errorf("RegionFileName(-31, 5) = %q", got)
+	}
+
+	chunk, err := region.ReadChunk(-31, 5)
+	if err != nil {
+		t.Fatalf("ReadChunk: %v", err)
+	}
+	if chunk["Status"] != "minecraft:full" || chunk["zPos"] != int32(5) {
+		t.Errorf("ReadChunk(-31, 5) = %v", chunk)
+	}
+
+	chunk, err = region.ReadChunk(-32, 0)
+	if err != nil {
+		t.Fatalf("ReadChunk external: %v", err)
+	}
+	if chunk["xPos"] != int32(-32) {
+		t.Errorf("ReadChunk(-32, 0) = %v", chunk)
+	}
+
+	if _, err := region.ReadChunk(-30, 5); !errors.Is(err, ErrChunkNotFound) {
+		t.Errorf("ReadChunk of a missing chunk: %v", err)
+	}
+}
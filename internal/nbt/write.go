@@ -0,0 +1,199 @@
+package nbt
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Encode writes root as a named root compound. Compound keys are written in
+// sorted order and an empty list is written as a list of end tags, as
+// Minecraft does.
+func Encode(w io.Writer, name string, root map[string]any) error {
+	e := encoder{w: bufio.NewWriter(w)}
+	e.byte(TagCompound)
+	e.string(name)
+	e.compound(root, 0)
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// WriteFile gzips root into path, the format of level.dat and player files.
+// The file is replaced in one step so a failed write leaves the old one.
+func WriteFile(path string, root map[string]any) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	if err := Encode(zw, "", root); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// TypeOf returns the tag type a Go value is written as.
+func TypeOf(v any) (byte, error) {
+	switch v.(type) {
+	case int8:
+		return TagByte, nil
+	case int16:
+		return TagShort, nil
+	case int32:
+		return TagInt, nil
+	case int64:
+		return TagLong, nil
+	case float32:
+		return TagFloat, nil
+	case float64:
+		return TagDouble, nil
+	case []int8:
+		return TagByteArray, nil
+	case string:
+		return TagString, nil
+	case []any:
+		return TagList, nil
+	case map[string]any:
+		return TagCompound, nil
+	case []int32:
+		return TagIntArray, nil
+	case []int64:
+		return TagLongArray, nil
+	}
+	return 0, fmt.Errorf("nbt: cannot encode %T", v)
+}
+
+// encoder keeps the first error so the writing code can stay linear.
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) payload(v any, depth int) {
+	if e.err != nil {
+		return
+	}
+	if depth > maxDepth {
+		e.err = errTooDeep
+		return
+	}
+	switch v := v.(type) {
+	case int8:
+		e.byte(byte(v))
+	case int16:
+		e.put(v)
+	case int32:
+		e.put(v)
+	case int64:
+		e.put(v)
+	case float32:
+		e.put(math.Float32bits(v))
+	case float64:
+		e.put(math.Float64bits(v))
+	case []int8:
+		e.put(int32(len(v)))
+		e.put(v)
+	case string:
+		e.string(v)
+	case []any:
+		e.list(v, depth)
+	case map[string]any:
+		e.compound(v, depth+1)
+	case []int32:
+		e.put(int32(len(v)))
+		e.put(v)
+	case []int64:
+		e.put(int32(len(v)))
+		e.put(v)
+	default:
+		e.err = fmt.Errorf("nbt: cannot encode %T", v)
+	}
+}
+
+func (e *encoder) list(list []any, depth int) {
+	elemType := TagEnd
+	if len(list) > 0 {
+		if elemType, e.err = TypeOf(list[0]); e.err != nil {
+			return
+		}
+	}
+	e.byte(elemType)
+	e.put(int32(len(list)))
+	for i, item := range list {
+		if itemType, _ := TypeOf(item); itemType != elemType {
+			e.err = fmt.Errorf("nbt: list mixes %T and %T at index %d", list[0], item, i)
+			return
+		}
+		e.payload(item, depth+1)
+	}
+}
+
+func (e *encoder) compound(compound map[string]any, depth int) {
+	keys := make([]string, 0, len(compound))
+	for key := range compound {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		tagType, err := TypeOf(compound[key])
+		if err != nil {
+			e.err = fmt.Errorf("%w at %q", err, key)
+			return
+		}
+		e.byte(tagType)
+		e.string(key)
+		e.payload(compound[key], depth)
+		if e.err != nil {
+			return
+		}
+	}
+	e.byte(TagEnd)
+}
+
+func (e *encoder) byte(b byte) {
+	if e.err == nil {
+		e.err = e.w.WriteByte(b)
+	}
+}
+
+func (e *encoder) put(v any) {
+	if e.err == nil {
+		e.err = binary.Write(e.w, binary.BigEndian, v)
+	}
+}
+
+func (e *encoder) string(s string) {
+	b := encodeMUTF8(s)
+	if len(b) > math.MaxUint16 {
+		if e.err == nil {
+			e.err = fmt.Errorf("nbt: string of %d bytes is too long", len(b))
+		}
+		return
+	}
+	e.put(uint16(len(b)))
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
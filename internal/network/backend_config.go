@@ -0,0 +1,69 @@
+package network
+
+import (
+	"fmt"
+	"naviger/internal/loader"
+	"naviger/internal/properties"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configureBackend puts a backend behind the proxy. It returns a warning when
+// the backend's software cannot receive forwarded player data on its own.
+func configureBackend(serverDir, backendLoader, backendVersion string, velocity bool, secret string) (string, error) {
+	if err := properties.Update(serverDir, map[string]string{"online-mode": "false"}); err != nil {
+		return "", fmt.Errorf("could not update server.properties: %w", err)
+	}
+
+	switch backendLoader {
+	case "paper":
+		if velocity {
+			return enablePaperVelocityForwarding(serverDir, backendVersion, secret)
+		}
+		return "", enableBungeeCordForwarding(serverDir)
+	case "spigot":
+		if velocity {
+			return "Spigot does not support Velocity modern forwarding; players will join with offline UUIDs", nil
+		}
+		return "", enableBungeeCordForwarding(serverDir)
+	case "fabric":
+		return "Fabric backends need the FabricProxy-Lite mod configured with the network forwarding secret", nil
+	case "forge", "neoforge":
+		return "Forge backends need a proxy forwarding mod (e.g. Proxy-Compatible-Forge) configured with the network forwarding secret", nil
+	default:
+		return fmt.Sprintf("%s backends cannot verify forwarded players; players will join with offline UUIDs", backendLoader), nil
+	}
+}
+
+// enablePaperVelocityForwarding turns on modern forwarding, which Paper reads
+// from config/paper-global.yml since 1.19 and from paper.yml before that.
+func enablePaperVelocityForwarding(serverDir, version, secret string) (string, error) {
+	before := func(release string) bool { return loader.VersionSorter{version, release}.Less(0, 1) }
+	switch {
+	case before("1.13"):
+		return "Paper before 1.13 does not support Velocity modern forwarding; players will join with offline UUIDs", nil
+	case before("1.19"):
+		return "", updateYAMLFile(filepath.Join(serverDir, "paper.yml"), func(root *yaml.Node) {
+			settings := yamlMapping(root, "settings")
+			velocityNode := yamlMapping(settings, "velocity-support")
+			yamlSet(velocityNode, "enabled", yamlBool(true))
+			yamlSet(velocityNode, "online-mode", yamlBool(true))
+			yamlSet(velocityNode, "secret", yamlString(secret))
+		})
+	}
+	return "", updateYAMLFile(filepath.Join(serverDir, "config", "paper-global.yml"), func(root *yaml.Node) {
+		proxies := yamlMapping(root, "proxies")
+		velocityNode := yamlMapping(proxies, "velocity")
+		yamlSet(velocityNode, "enabled", yamlBool(true))
+		yamlSet(velocityNode, "online-mode", yamlBool(true))
+		yamlSet(velocityNode, "secret", yamlString(secret))
+	})
+}
+
+func enableBungeeCordForwarding(serverDir string) error {
+	return updateYAMLFile(filepath.Join(serverDir, "spigot.yml"), func(root *yaml.Node) {
+		settings := yamlMapping(root, "settings")
+		yamlSet(settings, "bungeecord", yamlBool(true))
+	})
+}
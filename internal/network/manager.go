@@ -0,0 +1,386 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"naviger/internal/domain"
+	"naviger/internal/loader"
+	"naviger/internal/runner"
+	"naviger/internal/storage"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	backendStartTimeout = 5 * time.Minute
+	proxyStopTimeout    = time.Minute
+)
+
+var aliasPattern = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+type Manager struct {
+	Store       *storage.GormStore
+	Supervisor  *runner.Supervisor
+	ServersPath string
+}
+
+func NewManager(store *storage.GormStore, supervisor *runner.Supervisor, serversPath string) *Manager {
+	return &Manager{
+		Store:       store,
+		Supervisor:  supervisor,
+		ServersPath: serversPath,
+	}
+}
+
+func (m *Manager) ListNetworks() ([]domain.Network, error) {
+	return m.Store.ListNetworks()
+}
+
+func (m *Manager) GetNetwork(id string) (*domain.Network, error) {
+	return m.Store.GetNetwork(id)
+}
+
+func (m *Manager) CreateNetwork(name, proxyID string) (*domain.Network, error) {
+	proxy, err := m.Store.GetServerByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	if proxy == nil {
+		return nil, fmt.Errorf("proxy server not found")
+	}
+	if !loader.IsProxy(proxy.Loader) {
+		return nil, fmt.Errorf("server '%s' is not a proxy (loader: %s)", proxy.Name, proxy.Loader)
+	}
+
+	existing, err := m.Store.GetNetworkByProxyID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("proxy is already used by network '%s'", existing.Name)
+	}
+
+	if name == "" {
+		name = proxy.Name
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	network := &domain.Network{
+		ID:               uuid.NewString(),
+		Name:             name,
+		ProxyID:          proxyID,
+		ForwardingSecret: secret,
+		Servers:          []domain.NetworkServer{},
+		CreatedAt:        time.Now(),
+	}
+
+	if err := m.Store.CreateNetwork(network); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+func (m *Manager) DeleteNetwork(id string) error {
+	return m.Store.DeleteNetwork(id)
+}
+
+func (m *Manager) AttachServer(networkID, serverID, alias string) ([]string, error) {
+	network, err := m.requireNetwork(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err := m.Store.GetServerByID(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if srv == nil {
+		return nil, fmt.Errorf("server not found")
+	}
+	if loader.IsProxy(srv.Loader) {
+		return nil, fmt.Errorf("a proxy cannot be attached as a backend server")
+	}
+
+	current, err := m.Store.GetNetworkByServerID(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.ID != networkID {
+		return nil, fmt.Errorf("server already belongs to network '%s'", current.Name)
+	}
+
+	if alias == "" {
+		alias = srv.Name
+	}
+	alias = strings.Trim(aliasPattern.ReplaceAllString(strings.ToLower(alias), "-"), "-")
+	if alias == "" {
+		alias = srv.ID[:8]
+	}
+
+	priority := len(network.Servers)
+	for _, member := range network.Servers {
+		if member.ServerID == serverID {
+			priority = member.Priority
+			continue
+		}
+		if member.Alias == alias {
+			return nil, fmt.Errorf("alias '%s' is already used in this network", alias)
+		}
+	}
+
+	if err := m.Store.AddNetworkServer(&domain.NetworkServer{
+		NetworkID: networkID,
+		ServerID:  serverID,
+		Alias:     alias,
+		Priority:  priority,
+	}); err != nil {
+		return nil, err
+	}
+
+	return m.ApplyConfig(networkID)
+}
+
+func (m *Manager) DetachServer(networkID, serverID string) ([]string, error) {
+	if _, err := m.requireNetwork(networkID); err != nil {
+		return nil, err
+	}
+	if err := m.Store.RemoveNetworkServer(networkID, serverID); err != nil {
+		return nil, err
+	}
+	return m.ApplyConfig(networkID)
+}
+
+// ApplyConfig regenerates the proxy's server list from the backends' current
+// ports and configures each backend for forwarding. The returned warnings
+// describe backends that need manual setup.
+func (m *Manager) ApplyConfig(networkID string) ([]string, error) {
+	network, err := m.requireNetwork(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := m.Store.GetServerByID(network.ProxyID)
+	if err != nil {
+		return nil, err
+	}
+	if proxy == nil {
+		return nil, fmt.Errorf("proxy server not found")
+	}
+	velocity := proxy.Loader == "velocity"
+
+	warnings := []string{}
+	var backends []backendAddress
+	for _, member := range network.Servers {
+		srv, err := m.Store.GetServerByID(member.ServerID)
+		if err != nil {
+			return nil, err
+		}
+		if srv == nil {
+			warnings = append(warnings, fmt.Sprintf("backend %s no longer exists and was skipped", member.ServerID))
+			continue
+		}
+
+		warning, err := configureBackend(m.serverDir(srv), srv.Loader, srv.Version, velocity, network.ForwardingSecret)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring backend '%s': %w", srv.Name, err)
+		}
+		if warning != "" {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", srv.Name, warning))
+		}
+
+		backends = append(backends, backendAddress{Alias: member.Alias, Name: srv.Name, Port: srv.Port})
+	}
+
+	proxyDir := m.serverDir(proxy)
+	if velocity {
+		err = writeVelocityConfig(proxyDir, backends, network.ForwardingSecret)
+	} else {
+		err = writeBungeeConfig(proxyDir, proxy.Port, backends)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error writing proxy config: %w", err)
+	}
+
+	return warnings, nil
+}
+
+// StartNetworkJob starts every backend, waits for each to accept connections,
+// refreshes the proxy config with their final ports and then starts the proxy.
+func (m *Manager) StartNetworkJob(networkID string, progressChan chan<- domain.ProgressEvent) {
+	go func() {
+		defer close(progressChan)
+		if err := m.startNetwork(networkID, progressChan); err != nil {
+			progressChan <- domain.ProgressEvent{
+				ServerID: networkID,
+				Message:  fmt.Sprintf("Error: %v", err),
+				Progress: -1,
+			}
+			return
+		}
+		progressChan <- domain.ProgressEvent{
+			ServerID: networkID,
+			Message:  "Network started successfully",
+			Progress: 100,
+		}
+	}()
+}
+
+func (m *Manager) startNetwork(networkID string, progressChan chan<- domain.ProgressEvent) error {
+	network, err := m.requireNetwork(networkID)
+	if err != nil {
+		return err
+	}
+
+	total := float64(len(network.Servers) + 1)
+	for i, member := range network.Servers {
+		if !m.Supervisor.IsRunning(member.ServerID) {
+			progressChan <- domain.ProgressEvent{
+				ServerID: networkID,
+				Message:  fmt.Sprintf("Starting backend %s...", member.Alias),
+				Progress: float64(i) / total * 100,
+			}
+			if err := m.Supervisor.StartServer(member.ServerID); err != nil {
+				return fmt.Errorf("error starting backend '%s': %w", member.Alias, err)
+			}
+		}
+
+		srv, err := m.Store.GetServerByID(member.ServerID)
+		if err != nil {
+			return err
+		}
+		if srv == nil {
+			continue
+		}
+
+		progressChan <- domain.ProgressEvent{
+			ServerID: networkID,
+			Message:  fmt.Sprintf("Waiting for backend %s on port %d...", member.Alias, srv.Port),
+			Progress: float64(i) / total * 100,
+		}
+		if err := m.waitForPort(member.ServerID, srv.Port, backendStartTimeout); err != nil {
+			return fmt.Errorf("backend '%s' did not come up: %w", member.Alias, err)
+		}
+	}
+
+	progressChan <- domain.ProgressEvent{ServerID: networkID, Message: "Writing proxy configuration..."}
+	warnings, err := m.ApplyConfig(networkID)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		progressChan <- domain.ProgressEvent{ServerID: networkID, Message: "Warning: " + warning}
+	}
+
+	if m.Supervisor.IsRunning(network.ProxyID) {
+		return nil
+	}
+	progressChan <- domain.ProgressEvent{
+		ServerID: networkID,
+		Message:  "Starting proxy...",
+		Progress: float64(len(network.Servers)) / total * 100,
+	}
+	return m.Supervisor.StartServer(network.ProxyID)
+}
+
+// StopNetworkJob stops the proxy first so players are not sent to backends
+// that are shutting down, then stops the backends.
+func (m *Manager) StopNetworkJob(networkID string, progressChan chan<- domain.ProgressEvent) {
+	go func() {
+		defer close(progressChan)
+		if err := m.stopNetwork(networkID, progressChan); err != nil {
+			progressChan <- domain.ProgressEvent{
+				ServerID: networkID,
+				Message:  fmt.Sprintf("Error: %v", err),
+				Progress: -1,
+			}
+			return
+		}
+		progressChan <- domain.ProgressEvent{
+			ServerID: networkID,
+			Message:  "Network stopped successfully",
+			Progress: 100,
+		}
+	}()
+}
+
+func (m *Manager) stopNetwork(networkID string, progressChan chan<- domain.ProgressEvent) error {
+	network, err := m.requireNetwork(networkID)
+	if err != nil {
+		return err
+	}
+
+	if m.Supervisor.IsRunning(network.ProxyID) {
+		progressChan <- domain.ProgressEvent{ServerID: networkID, Message: "Stopping proxy..."}
+		if err := m.Supervisor.StopServer(network.ProxyID); err != nil {
+			return fmt.Errorf("error stopping proxy: %w", err)
+		}
+		deadline := time.Now().Add(proxyStopTimeout)
+		for m.Supervisor.IsRunning(network.ProxyID) && time.Now().Before(deadline) {
+			time.Sleep(time.Second)
+		}
+	}
+
+	for _, member := range network.Servers {
+		if !m.Supervisor.IsRunning(member.ServerID) {
+			continue
+		}
+		progressChan <- domain.ProgressEvent{ServerID: networkID, Message: fmt.Sprintf("Stopping backend %s...", member.Alias)}
+		if err := m.Supervisor.StopServer(member.ServerID); err != nil {
+			return fmt.Errorf("error stopping backend '%s': %w", member.Alias, err)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) waitForPort(serverID string, port int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !m.Supervisor.IsRunning(serverID) {
+			return fmt.Errorf("process exited")
+		}
+		conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", port), time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return fmt.Errorf("timed out after %s", timeout)
+}
+
+func (m *Manager) requireNetwork(id string) (*domain.Network, error) {
+	network, err := m.Store.GetNetwork(id)
+	if err != nil {
+		return nil, err
+	}
+	if network == nil {
+		return nil, fmt.Errorf("network not found")
+	}
+	return network, nil
+}
+
+func (m *Manager) serverDir(srv *domain.Server) string {
+	folderName := srv.FolderName
+	if folderName == "" {
+		folderName = srv.ID
+	}
+	return filepath.Join(m.ServersPath, folderName)
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate forwarding secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
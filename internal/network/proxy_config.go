@@ -0,0 +1,154 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"naviger/internal/runner/strategy"
+
+	"gopkg.in/yaml.v3"
+)
+
+const forwardingSecretFile = "forwarding.secret"
+
+var tomlTableHeader = regexp.MustCompile(`^\s*\[[^\[\]]+\]\s*(#.*)?$`)
+
+type backendAddress struct {
+	Alias string
+	Name  string
+	Port  int
+}
+
+func (b backendAddress) address() string {
+	return fmt.Sprintf("127.0.0.1:%d", b.Port)
+}
+
+func writeVelocityConfig(proxyDir string, backends []backendAddress, secret string) error {
+	if err := os.WriteFile(filepath.Join(proxyDir, forwardingSecretFile), []byte(secret), 0600); err != nil {
+		return fmt.Errorf("could not write forwarding secret: %w", err)
+	}
+
+	// A proxy that has not run yet starts from Velocity's default config.
+	path := filepath.Join(proxyDir, "velocity.toml")
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		data, err = strategy.DefaultVelocityConfig, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+
+	lines = setTOMLRootKey(lines, "player-info-forwarding-mode", `"modern"`)
+	lines = setTOMLRootKey(lines, "forwarding-secret-file", fmt.Sprintf("%q", forwardingSecretFile))
+
+	servers := []string{"[servers]"}
+	var try []string
+	for _, b := range backends {
+		servers = append(servers, fmt.Sprintf("%s = %q", b.Alias, b.address()))
+		try = append(try, fmt.Sprintf("%q", b.Alias))
+	}
+	servers = append(servers, fmt.Sprintf("try = [%s]", strings.Join(try, ", ")), "")
+
+	// Velocity refuses to start when forced hosts point at unknown servers, and
+	// the default config ships with example entries.
+	lines = replaceTOMLTable(lines, "servers", servers)
+	lines = replaceTOMLTable(lines, "forced-hosts", []string{"[forced-hosts]", ""})
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func setTOMLRootKey(lines []string, key, value string) []string {
+	end := len(lines)
+	for i, line := range lines {
+		if tomlTableHeader.MatchString(line) {
+			end = i
+			break
+		}
+	}
+
+	keyPattern := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(key) + `\s*=`)
+	newLine := fmt.Sprintf("%s = %s", key, value)
+	for i := 0; i < end; i++ {
+		if keyPattern.MatchString(lines[i]) {
+			lines[i] = newLine
+			return lines
+		}
+	}
+
+	result := make([]string, 0, len(lines)+1)
+	result = append(result, lines[:end]...)
+	result = append(result, newLine)
+	return append(result, lines[end:]...)
+}
+
+func replaceTOMLTable(lines []string, table string, replacement []string) []string {
+	start := -1
+	for i, line := range lines {
+		if strings.TrimSpace(strings.SplitN(line, "#", 2)[0]) == "["+table+"]" {
+			start = i
+			break
+		}
+	}
+
+	if start == -1 {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		return append(lines, replacement...)
+	}
+
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if tomlTableHeader.MatchString(lines[i]) {
+			end = i
+			break
+		}
+	}
+
+	result := make([]string, 0, len(lines)+len(replacement))
+	result = append(result, lines[:start]...)
+	result = append(result, replacement...)
+	return append(result, lines[end:]...)
+}
+
+func writeBungeeConfig(proxyDir string, proxyPort int, backends []backendAddress) error {
+	var priorities []string
+	for _, b := range backends {
+		priorities = append(priorities, b.Alias)
+	}
+
+	return updateYAMLFile(filepath.Join(proxyDir, "config.yml"), func(root *yaml.Node) {
+		yamlSet(root, "ip_forward", yamlBool(true))
+
+		servers := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, b := range backends {
+			entry := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			yamlSet(entry, "motd", yamlString(b.Name))
+			yamlSet(entry, "address", yamlString(b.address()))
+			yamlSet(entry, "restricted", yamlBool(false))
+			yamlSet(servers, b.Alias, entry)
+		}
+		yamlSet(root, "servers", servers)
+
+		listeners := yamlChild(root, "listeners")
+		if listeners == nil || listeners.Kind != yaml.SequenceNode || len(listeners.Content) == 0 {
+			listener := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			yamlSet(listener, "host", yamlString(fmt.Sprintf("0.0.0.0:%d", proxyPort)))
+			listeners = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{listener}}
+			yamlSet(root, "listeners", listeners)
+		}
+		for _, listener := range listeners.Content {
+			if listener.Kind == yaml.MappingNode {
+				yamlSet(listener, "priorities", yamlStringList(priorities))
+			}
+		}
+	})
+}
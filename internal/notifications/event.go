@@ -0,0 +1,53 @@
+package notifications
+
+import "time"
+
+const (
+	EventServerStarted   = "server.started"
+	EventServerStopped   = "server.stopped"
+	EventServerCrashed   = "server.crashed"
+	EventBackupSucceeded = "backup.succeeded"
+	EventBackupFailed    = "backup.failed"
+	EventPlayerJoined    = "player.joined"
+	EventUpdateAvailable = "update.available"
+	EventDiskLow         = "disk.low"
+	EventTPSLow          = "tps.low"
+	EventTest            = "test"
+)
+
+// Events lists the event types a sink can subscribe to.
+var Events = []string{
+	EventServerStarted,
+	EventServerStopped,
+	EventServerCrashed,
+	EventBackupSucceeded,
+	EventBackupFailed,
+	EventPlayerJoined,
+	EventUpdateAvailable,
+	EventDiskLow,
+	EventTPSLow,
+}
+
+// Event is what gets delivered to sinks. Webhooks receive it as JSON
+// verbatim; Discord and email render Title, Message and Fields.
+type Event struct {
+	Type       string            `json:"type"`
+	ServerID   string            `json:"serverId,omitempty"`
+	ServerName string            `json:"serverName,omitempty"`
+	Title      string            `json:"title"`
+	Message    string            `json:"message"`
+	Fields     map[string]string `json:"fields,omitempty"`
+	Time       time.Time         `json:"time"`
+}
+
+func knownEvent(eventType string) bool {
+	if eventType == "*" {
+		return true
+	}
+	for _, event := range Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,339 @@
+// Package notifications delivers server events to webhooks, Discord and
+// email. Deliveries run in the background with retries; events that still
+// cannot be delivered are kept as dead letters.
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"naviger/internal/domain"
+	"naviger/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultMaxAttempts = 5
+	DefaultRetryDelay  = 2 * time.Second
+	queueSize          = 256
+	workers            = 2
+	deliveryTimeout    = 15 * time.Second
+)
+
+var ErrSinkNotFound = errors.New("notification sink not found")
+
+type Notifier struct {
+	Store       *storage.GormStore
+	Client      *http.Client
+	MaxAttempts int
+	// RetryDelay is the wait before the second attempt; it doubles after
+	// every failed attempt.
+	RetryDelay time.Duration
+
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	queue    chan delivery
+	done     chan struct{}
+	wg       sync.WaitGroup
+	once     sync.Once
+
+	mu             sync.Mutex
+	notifiedDisk   bool
+	notifiedLatest string
+}
+
+type delivery struct {
+	sink  domain.NotificationSink
+	event Event
+}
+
+// SinkInput creates or updates a sink. On update, empty Secret and
+// SMTPPassword keep the stored values.
+type SinkInput struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	Enabled      *bool    `json:"enabled"`
+	Events       []string `json:"events"`
+	URL          string   `json:"url"`
+	Secret       string   `json:"secret"`
+	SMTPHost     string   `json:"smtpHost"`
+	SMTPPort     int      `json:"smtpPort"`
+	SMTPUsername string   `json:"smtpUsername"`
+	SMTPPassword string   `json:"smtpPassword"`
+	EmailFrom    string   `json:"emailFrom"`
+	EmailTo      []string `json:"emailTo"`
+}
+
+func NewNotifier(store *storage.GormStore) *Notifier {
+	n := &Notifier{
+		Store:       store,
+		Client:      &http.Client{Timeout: deliveryTimeout},
+		MaxAttempts: DefaultMaxAttempts,
+		RetryDelay:  DefaultRetryDelay,
+		sendMail:    smtp.SendMail,
+		queue:       make(chan delivery, queueSize),
+		done:        make(chan struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		n.wg.Add(1)
+		go n.work()
+	}
+	return n
+}
+
+// Close stops the delivery workers. Deliveries waiting for a retry or still
+// queued are recorded as dead letters.
+func (n *Notifier) Close() {
+	n.once.Do(func() {
+		close(n.done)
+	})
+	n.wg.Wait()
+
+	for {
+		select {
+		case d := <-n.queue:
+			n.deadLetter(d.sink, d.event, errors.New("shutting down before delivery"), 0)
+		default:
+			return
+		}
+	}
+}
+
+// Publish queues an event for every enabled sink subscribed to its type.
+func (n *Notifier) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	sinks, err := n.Store.ListNotificationSinks()
+	if err != nil {
+		slog.Warn("Could not load notification sinks", "error", err)
+		return
+	}
+
+	for _, sink := range sinks {
+		if !sink.Enabled || !sink.Subscribed(event.Type) {
+			continue
+		}
+		select {
+		case n.queue <- delivery{sink: sink, event: event}:
+		default:
+			n.deadLetter(sink, event, errors.New("delivery queue is full"), 0)
+		}
+	}
+}
+
+// Test sends a test event to a sink straight away, without retries, and
+// returns the delivery error.
+func (n *Notifier) Test(sinkID string) error {
+	sink, err := n.Store.GetNotificationSink(sinkID)
+	if err != nil {
+		return err
+	}
+	if sink == nil {
+		return ErrSinkNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+	return n.send(ctx, *sink, Event{
+		Type:    EventTest,
+		Title:   "Test notification",
+		Message: fmt.Sprintf("Notifications from Naviger reach %q.", sink.Name),
+		Time:    time.Now(),
+	})
+}
+
+func (n *Notifier) work() {
+	defer n.wg.Done()
+	for {
+		// Once closed, what is still queued is left to Close.
+		select {
+		case <-n.done:
+			return
+		default:
+		}
+		select {
+		case d := <-n.queue:
+			n.deliver(d)
+		case <-n.done:
+			return
+		}
+	}
+}
+
+func (n *Notifier) deliver(d delivery) {
+	delay := n.RetryDelay
+	var err error
+	attempts := 0
+	for attempts < n.MaxAttempts {
+		attempts++
+		ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+		err = n.send(ctx, d.sink, d.event)
+		cancel()
+
+		var permanent permanentError
+		if err == nil || errors.As(err, &permanent) || attempts == n.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-n.done:
+			n.deadLetter(d.sink, d.event, fmt.Errorf("shutting down after: %w", err), attempts)
+			return
+		}
+		delay *= 2
+	}
+
+	if err != nil {
+		n.deadLetter(d.sink, d.event, err, attempts)
+	}
+}
+
+func (n *Notifier) deadLetter(sink domain.NotificationSink, event Event, err error, attempts int) {
+	slog.Warn("Notification could not be delivered", "sink", sink.Name, "event", event.Type, "error", err)
+
+	payload, _ := json.Marshal(event)
+	letter := &domain.DeadLetter{
+		SinkID:    sink.ID,
+		SinkName:  sink.Name,
+		EventType: event.Type,
+		Payload:   string(payload),
+		Error:     err.Error(),
+		Attempts:  attempts,
+		CreatedAt: time.Now(),
+	}
+	if err := n.Store.CreateDeadLetter(letter); err != nil {
+		slog.Warn("Could not store dead letter", "error", err)
+	}
+}
+
+func (n *Notifier) ListSinks() ([]domain.NotificationSink, error) {
+	return n.Store.ListNotificationSinks()
+}
+
+func (n *Notifier) DeadLetters(limit int) ([]domain.DeadLetter, error) {
+	return n.Store.ListDeadLetters(limit)
+}
+
+func (n *Notifier) CreateSink(input SinkInput) (*domain.NotificationSink, error) {
+	sink := &domain.NotificationSink{
+		ID:        uuid.New().String(),
+		Enabled:   true,
+		CreatedAt: time.Now(),
+	}
+	applyInput(sink, input)
+	if err := validate(sink); err != nil {
+		return nil, err
+	}
+	if err := n.Store.CreateNotificationSink(sink); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (n *Notifier) UpdateSink(id string, input SinkInput) (*domain.NotificationSink, error) {
+	sink, err := n.Store.GetNotificationSink(id)
+	if err != nil {
+		return nil, err
+	}
+	if sink == nil {
+		return nil, ErrSinkNotFound
+	}
+
+	applyInput(sink, input)
+	if err := validate(sink); err != nil {
+		return nil, err
+	}
+	if err := n.Store.UpdateNotificationSink(sink); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (n *Notifier) DeleteSink(id string) error {
+	return n.Store.DeleteNotificationSink(id)
+}
+
+func applyInput(sink *domain.NotificationSink, input SinkInput) {
+	sink.Name = strings.TrimSpace(input.Name)
+	sink.Type = input.Type
+	if input.Enabled != nil {
+		sink.Enabled = *input.Enabled
+	}
+	sink.Events = input.Events
+	if sink.Events == nil {
+		sink.Events = []string{}
+	}
+	sink.URL = strings.TrimSpace(input.URL)
+	if input.Secret != "" {
+		sink.Secret = input.Secret
+	}
+	sink.SMTPHost = strings.TrimSpace(input.SMTPHost)
+	sink.SMTPPort = input.SMTPPort
+	sink.SMTPUsername = input.SMTPUsername
+	if input.SMTPPassword != "" {
+		sink.SMTPPassword = input.SMTPPassword
+	}
+	sink.EmailFrom = strings.TrimSpace(input.EmailFrom)
+	sink.EmailTo = nil
+	for _, to := range input.EmailTo {
+		if to = strings.TrimSpace(to); to != "" {
+			sink.EmailTo = append(sink.EmailTo, to)
+		}
+	}
+}
+
+func validate(sink *domain.NotificationSink) error {
+	if sink.Name == "" {
+		return fmt.Errorf("name required")
+	}
+	for _, event := range sink.Events {
+		if !knownEvent(event) {
+			return fmt.Errorf("unknown event %q", event)
+		}
+	}
+
+	switch sink.Type {
+	case domain.SinkWebhook, domain.SinkDiscord:
+		u, err := url.Parse(sink.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url must be an http or https address")
+		}
+	case domain.SinkEmail:
+		if sink.SMTPHost == "" {
+			return fmt.Errorf("smtpHost required")
+		}
+		if sink.SMTPPort == 0 {
+			sink.SMTPPort = 587
+		}
+		if sink.SMTPPort < 1 || sink.SMTPPort > 65535 {
+			return fmt.Errorf("invalid smtpPort")
+		}
+		if _, err := mail.ParseAddress(sink.EmailFrom); err != nil {
+			return fmt.Errorf("invalid emailFrom: %w", err)
+		}
+		if len(sink.EmailTo) == 0 {
+			return fmt.Errorf("emailTo required")
+		}
+		for _, to := range sink.EmailTo {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("invalid emailTo %q: %w", to, err)
+			}
+		}
+	default:
+		return fmt.Errorf("type must be %s, %s or %s", domain.SinkWebhook, domain.SinkDiscord, domain.SinkEmail)
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package notifications
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"naviger/internal/domain"
+	"naviger/internal/storage"
+)
+
+// receiver is a local HTTP endpoint that records every request it gets and
+// fails the first failures of them.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	failures int
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+	got      chan struct{}
+}
+
+func newReceiver(failures, status int) *receiver {
+	rec := &receiver{failures: failures, status: status, got: make(chan struct{}, 16)}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, r)
+		rec.bodies = append(rec.bodies, body)
+		fail := len(rec.requests) <= rec.failures
+		rec.mu.Unlock()
+
+		if fail {
+			w.WriteHeader(rec.status)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		rec.got <- struct{}{}
+	}))
+	return rec
+}
+
+func (rec *receiver) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-rec.got:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for request %d", i+1)
+		}
+	}
+}
+
+func newTestNotifier(t *testing.T) *Notifier {
+	t.Helper()
+	store, err := storage.NewGormStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	n := NewNotifier(store)
+	n.RetryDelay = 10 * time.Millisecond
+	n.MaxAttempts = 3
+	t.Cleanup(n.Close)
+	return n
+}
+
+func createSink(t *testing.T, n *Notifier, input SinkInput) *domain.NotificationSink {
+	t.Helper()
+	sink, err := n.CreateSink(input)
+	if err != nil {
+		t.Fatalf("Failed to create sink: %v", err)
+	}
+	return sink
+}
+
+func waitForDeadLetters(t *testing.T, n *Notifier, want int) []domain.DeadLetter {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		letters, err := n.DeadLetters(10)
+		if err != nil {
+			t.Fatalf("Failed to list dead letters: %v", err)
+		}
+		if len(letters) >= want || time.Now().After(deadline) {
+			return letters
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebhookIsSigned(t *testing.T) {
+	rec := newReceiver(0, 0)
+	defer rec.Close()
+
+	n := newTestNotifier(t)
+	createSink(t, n, SinkInput{Name: "hook", Type: domain.SinkWebhook, URL: rec.URL, Secret: "s3cret"})
+
+	n.Publish(Event{Type: EventBackupSucceeded, ServerID: "srv", Title: "Backup created", Message: "done"})
+	rec.wait(t, 1)
+
+	req, body := rec.requests[0], rec.bodies[0]
+	if got, want := req.Header.Get(SignatureHeader), Sign("s3cret", body); got != want {
+		t.Errorf("Signature = %q, want %q", got, want)
+	}
+	if got := req.Header.Get(EventHeader); got != EventBackupSucceeded {
+		t.Errorf("Event header = %q, want %q", got, EventBackupSucceeded)
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("Body is not an event: %v", err)
+	}
+	if event.Type != EventBackupSucceeded || event.ServerID != "srv" || event.Time.IsZero() {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestDiscordEmbed(t *testing.T) {
+	rec := newReceiver(0, 0)
+	defer rec.Close()
+
+	n := newTestNotifier(t)
+	createSink(t, n, SinkInput{Name: "discord", Type: domain.SinkDiscord, URL: rec.URL})
+
+	n.Publish(Event{
+		Type:       EventServerCrashed,
+		ServerName: "Survival",
+		Title:      "Server crashed",
+		Message:    "Out of memory",
+		Fields:     map[string]string{"Exit code": "1"},
+	})
+	rec.wait(t, 1)
+
+	var payload discordPayload
+	if err := json.Unmarshal(rec.bodies[0], &payload); err != nil {
+		t.Fatalf("Body is not a Discord payload: %v", err)
+	}
+	if len(payload.Embeds) != 1 {
+		t.Fatalf("Got %d embeds, want 1", len(payload.Embeds))
+	}
+	embed := payload.Embeds[0]
+	if embed.Title != "Server crashed" || embed.Description != "Out of memory" || embed.Color != discordColors[EventServerCrashed] {
+		t.Errorf("Unexpected embed %+v", embed)
+	}
+	if len(embed.Fields) != 2 || embed.Fields[0].Value != "Survival" || embed.Fields[1].Name != "Exit code" {
+		t.Errorf("Unexpected fields %+v", embed.Fields)
+	}
+}
+
+func TestRetriesUntilDelivered(t *testing.T) {
+	rec := newReceiver(2, http.StatusServiceUnavailable)
+	defer rec.Close()
+
+	n := newTestNotifier(t)
+	createSink(t, n, SinkInput{Name: "hook", Type: domain.SinkWebhook, URL: rec.URL})
+
+	n.Publish(Event{Type: EventServerStarted, Title: "Server started"})
+	rec.wait(t, 3)
+
+	if letters := waitForDeadLetters(t, n, 0); len(letters) != 0 {
+		t.Errorf("Got dead letters %+v, want none", letters)
+	}
+}
+
+func TestDeadLetterAfterRetries(t *testing.T) {
+	rec := newReceiver(100, http.StatusInternalServerError)
+	defer rec.Close()
+
+	n := newTestNotifier(t)
+	sink := createSink(t, n, SinkInput{Name: "hook", Type: domain.SinkWebhook, URL: rec.URL})
+
+	n.Publish(Event{Type: EventDiskLow, Title: "Disk space low"})
+	rec.wait(t, 3)
+
+	letters := waitForDeadLetters(t, n, 1)
+	if len(letters) != 1 {
+		t.Fatalf("Got %d dead letters, want 1", len(letters))
+	}
+	if letters[0].SinkID != sink.ID || letters[0].EventType != EventDiskLow || letters[0].Attempts != 3 {
+		t.Errorf("Unexpected dead letter %+v", letters[0])
+	}
+}
+
+func TestCloseRecordsQueuedDeliveries(t *testing.T) {
+	release := make(chan struct{})
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(release) }) }
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer unblock()
+
+	n := newTestNotifier(t)
+	createSink(t, n, SinkInput{Name: "hook", Type: domain.SinkWebhook, URL: srv.URL})
+
+	// Both workers block on the first two events, the rest stay queued.
+	const events = 5
+	for i := 0; i < events; i++ {
+		n.Publish(Event{Type: EventServerStarted, Title: "Server started"})
+	}
+	time.Sleep(50 * time.Millisecond)
+	closed := make(chan struct{})
+	go func() {
+		n.Close()
+		close(closed)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	unblock()
+	<-closed
+
+	letters, err := n.DeadLetters(10)
+	if err != nil {
+		t.Fatalf("Failed to list dead letters: %v", err)
+	}
+	queued := 0
+	for _, letter := range letters {
+		if letter.Attempts == 0 {
+			queued++
+		}
+	}
+	if queued != events-workers {
+		t.Errorf("Got %d dead letters for queued deliveries, want %d", queued, events-workers)
+	}
+}
+
+func TestClientErrorsAreNotRetried(t *testing.T) {
+	rec := newReceiver(100, http.StatusNotFound)
+	defer rec.Close()
+
+	n := newTestNotifier(t)
+	createSink(t, n, SinkInput{Name: "hook", Type: domain.SinkWebhook, URL: rec.URL})
+
+	n.Publish(Event{Type: EventServerStopped, Title: "Server stopped"})
+	rec.wait(t, 1)
+
+	letters := waitForDeadLetters(t, n, 1)
+	if len(letters) != 1 || letters[0].Attempts != 1 {
+		t.Fatalf("Got dead letters %+v, want one after a single attempt", letters)
+	}
+}
+
+func TestSubscriptionsAndDisabledSinks(t *testing.T) {
+	rec := newReceiver(0, 0)
+	defer rec.Close()
+
+	n := newTestNotifier(t)
+	disabled := false
+	createSink(t, n, SinkInput{Name: "joins", Type: domain.SinkWebhook, URL: rec.URL + "/joins", Events: []string{EventPlayerJoined}})
+	createSink(t, n, SinkInput{Name: "off", Type: domain.SinkWebhook, URL: rec.URL + "/off", Enabled: &disabled})
+
+	n.Publish(Event{Type: EventServerStarted, Title: "Server started"})
+	n.Publish(Event{Type: EventPlayerJoined, Title: "Player joined"})
+	rec.wait(t, 1)
+
+	time.Sleep(50 * time.Millisecond)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.requests) != 1 || rec.requests[0].URL.Path != "/joins" {
+		t.Errorf("Got %d requests, want only the player joined event on /joins", len(rec.requests))
+	}
+}
+
+func TestTestSendReportsErrors(t *testing.T) {
+	rec := newReceiver(1, http.StatusForbidden)
+	defer rec.Close()
+
+	n := newTestNotifier(t)
+	sink := createSink(t, n, SinkInput{Name: "hook", Type: domain.SinkWebhook, URL: rec.URL})
+
+	if err := n.Test(sink.ID); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("First test send error = %v, want a 403", err)
+	}
+	if err := n.Test(sink.ID); err != nil {
+		t.Errorf("Second test send error = %v, want nil", err)
+	}
+	if err := n.Test("missing"); err != ErrSinkNotFound {
+		t.Errorf("Test of unknown sink error = %v, want ErrSinkNotFound", err)
+	}
+}
+
+func TestEmail(t *testing.T) {
+	n := newTestNotifier(t)
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	n.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, msg
+		return nil
+	}
+
+	sink := createSink(t, n, SinkInput{
+		Name:      "mail",
+		Type:      domain.SinkEmail,
+		SMTPHost:  "smtp.example.com",
+		EmailFrom: "Naviger <naviger@example.com>",
+		EmailTo:   []string{"ops@example.com", " Admin <admin@example.com> "},
+	})
+	if err := n.Test(sink.ID); err != nil {
+		t.Fatalf("Test send failed: %v", err)
+	}
+
+	if gotAddr != "smtp.example.com:587" || gotFrom != "naviger@example.com" {
+		t.Errorf("Sent via %s from %s", gotAddr, gotFrom)
+	}
+	if len(gotTo) != 2 || gotTo[1] != "admin@example.com" {
+		t.Errorf("Recipients = %v", gotTo)
+	}
+	if !strings.Contains(string(gotMsg), "Subject: [Naviger] Test notification\r\n") {
+		t.Errorf("Message has no subject:\n%s", gotMsg)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	n := newTestNotifier(t)
+	inputs := []SinkInput{
+		{Type: domain.SinkWebhook, URL: "https://example.com"},
+		{Name: "x", Type: "pager"},
+		{Name: "x", Type: domain.SinkDiscord, URL: "ftp://example.com"},
+		{Name: "x", Type: domain.SinkWebhook, URL: "https://example.com", Events: []string{"server.exploded"}},
+		{Name: "x", Type: domain.SinkEmail, SMTPHost: "smtp.example.com", EmailFrom: "a@example.com"},
+	}
+	for _, input := range inputs {
+		if _, err := n.CreateSink(input); err == nil {
+			t.Errorf("CreateSink(%+v) succeeded, want an error", input)
+		}
+	}
+}
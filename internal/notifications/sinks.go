@@ -0,0 +1,221 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"naviger/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SignatureHeader = "X-Naviger-Signature"
+	EventHeader     = "X-Naviger-Event"
+	DeliveryHeader  = "X-Naviger-Delivery"
+	userAgent       = "naviger-notifier"
+)
+
+// Discord embed colours by event type.
+var discordColors = map[string]int{
+	EventServerStarted:   0x2ECC71,
+	EventServerStopped:   0x95A5A6,
+	EventServerCrashed:   0xE74C3C,
+	EventBackupSucceeded: 0x2ECC71,
+	EventBackupFailed:    0xE74C3C,
+	EventPlayerJoined:    0x3498DB,
+	EventUpdateAvailable: 0xF1C40F,
+	EventDiskLow:         0xE67E22,
+	EventTPSLow:          0xE67E22,
+	EventTest:            0x3498DB,
+}
+
+// permanentError marks failures that retrying cannot fix, such as a webhook
+// URL that no longer exists.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+
+// Sign returns the value of the signature header for a webhook body: the
+// hex-encoded HMAC-SHA256 of the body keyed with the sink secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (n *Notifier) send(ctx context.Context, sink domain.NotificationSink, event Event) error {
+	switch sink.Type {
+	case domain.SinkWebhook:
+		return n.sendWebhook(ctx, sink, event)
+	case domain.SinkDiscord:
+		return n.sendDiscord(ctx, sink, event)
+	case domain.SinkEmail:
+		return n.sendEmail(sink, event)
+	}
+	return permanentError{fmt.Errorf("unknown sink type %q", sink.Type)}
+}
+
+func (n *Notifier) sendWebhook(ctx context.Context, sink domain.NotificationSink, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return permanentError{err}
+	}
+
+	headers := map[string]string{
+		EventHeader:    event.Type,
+		DeliveryHeader: uuid.New().String(),
+	}
+	if sink.Secret != "" {
+		headers[SignatureHeader] = Sign(sink.Secret, body)
+	}
+	return n.post(ctx, sink.URL, body, headers)
+}
+
+type discordPayload struct {
+	Username string         `json:"username"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Timestamp   string         `json:"timestamp"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      discordFooter  `json:"footer"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+func (n *Notifier) sendDiscord(ctx context.Context, sink domain.NotificationSink, event Event) error {
+	embed := discordEmbed{
+		Title:       event.Title,
+		Description: event.Message,
+		Color:       discordColors[event.Type],
+		Timestamp:   event.Time.UTC().Format(time.RFC3339),
+		Footer:      discordFooter{Text: "Naviger • " + event.Type},
+	}
+	if event.ServerName != "" {
+		embed.Fields = append(embed.Fields, discordField{Name: "Server", Value: event.ServerName, Inline: true})
+	}
+	for _, key := range sortedKeys(event.Fields) {
+		embed.Fields = append(embed.Fields, discordField{Name: key, Value: event.Fields[key], Inline: true})
+	}
+
+	body, err := json.Marshal(discordPayload{Username: "Naviger", Embeds: []discordEmbed{embed}})
+	if err != nil {
+		return permanentError{err}
+	}
+	return n.post(ctx, sink.URL, body, nil)
+}
+
+func (n *Notifier) post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusRequestTimeout {
+		return permanentError{err}
+	}
+	return err
+}
+
+func (n *Notifier) sendEmail(sink domain.NotificationSink, event Event) error {
+	var auth smtp.Auth
+	if sink.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", sink.SMTPUsername, sink.SMTPPassword, sink.SMTPHost)
+	}
+	from, err := mail.ParseAddress(sink.EmailFrom)
+	if err != nil {
+		return permanentError{err}
+	}
+	to := make([]string, 0, len(sink.EmailTo))
+	for _, recipient := range sink.EmailTo {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return permanentError{err}
+		}
+		to = append(to, address.Address)
+	}
+
+	addr := net.JoinHostPort(sink.SMTPHost, strconv.Itoa(sink.SMTPPort))
+	return n.sendMail(addr, auth, from.Address, to, composeEmail(sink, event))
+}
+
+func composeEmail(sink domain.NotificationSink, event Event) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(sink.EmailFrom))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(strings.Join(sink.EmailTo, ", ")))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue("[Naviger] "+event.Title)))
+	fmt.Fprintf(&b, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+
+	b.WriteString(event.Message + "\r\n\r\n")
+	if event.ServerName != "" {
+		fmt.Fprintf(&b, "Server: %s\r\n", event.ServerName)
+	}
+	for _, key := range sortedKeys(event.Fields) {
+		fmt.Fprintf(&b, "%s: %s\r\n", key, event.Fields[key])
+	}
+	fmt.Fprintf(&b, "Event: %s\r\nTime: %s\r\n", event.Type, event.Time.Format(time.RFC3339))
+	return []byte(b.String())
+}
+
+// headerValue keeps user-provided text from adding extra mail headers.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+func sortedKeys(fields map[string]string) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,215 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"naviger/internal/domain"
+	"naviger/internal/logevents"
+	"naviger/internal/updater"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+const (
+	UpdateCheckInterval = 6 * time.Hour
+	DiskCheckInterval   = 5 * time.Minute
+)
+
+// ObserveLine publishes server started and player joined events read from
+// the console.
+func (n *Notifier) ObserveLine(serverID, line string) {
+	if logevents.Ready(line) {
+		name := n.serverName(serverID)
+		n.Publish(Event{
+			Type:       EventServerStarted,
+			ServerID:   serverID,
+			ServerName: name,
+			Title:      "Server started",
+			Message:    fmt.Sprintf("%s is up and accepting players.", name),
+		})
+		return
+	}
+
+	event, ok := logevents.Parse(line)
+	if !ok || event.Type != logevents.Join {
+		return
+	}
+	name := n.serverName(serverID)
+	n.Publish(Event{
+		Type:       EventPlayerJoined,
+		ServerID:   serverID,
+		ServerName: name,
+		Title:      "Player joined",
+		Message:    fmt.Sprintf("%s joined %s.", event.Player, name),
+		Fields:     map[string]string{"Player": event.Player},
+	})
+}
+
+// ServerExited does nothing: whether an exit was a crash is decided by the
+// incidents manager, which reports every exit through ReportExit.
+func (n *Notifier) ServerExited(string, domain.ProcessExit) {}
+
+// ReportExit publishes a crash when the exit produced an incident and a stop
+// otherwise.
+func (n *Notifier) ReportExit(serverID string, exit domain.ProcessExit, incident *domain.Incident) {
+	name := n.serverName(serverID)
+	if incident == nil {
+		n.Publish(Event{
+			Type:       EventServerStopped,
+			ServerID:   serverID,
+			ServerName: name,
+			Title:      "Server stopped",
+			Message:    fmt.Sprintf("%s has stopped.", name),
+			Time:       exit.ExitedAt,
+		})
+		return
+	}
+
+	fields := map[string]string{
+		"Cause":     incident.Cause,
+		"Exit code": strconv.Itoa(incident.ExitCode),
+		"Incident":  incident.ID,
+	}
+	if incident.Evidence != "" {
+		fields["Evidence"] = incident.Evidence
+	}
+	n.Publish(Event{
+		Type:       EventServerCrashed,
+		ServerID:   serverID,
+		ServerName: name,
+		Title:      "Server crashed",
+		Message:    fmt.Sprintf("%s crashed. %s", name, incident.Summary),
+		Fields:     fields,
+		Time:       exit.ExitedAt,
+	})
+}
+
+// ReportBackup publishes the outcome of a backup job.
+func (n *Notifier) ReportBackup(serverID, backupName string, err error) {
+	name := n.serverName(serverID)
+	if err != nil {
+		n.Publish(Event{
+			Type:       EventBackupFailed,
+			ServerID:   serverID,
+			ServerName: name,
+			Title:      "Backup failed",
+			Message:    fmt.Sprintf("The backup of %s failed: %v", name, err),
+		})
+		return
+	}
+	n.Publish(Event{
+		Type:       EventBackupSucceeded,
+		ServerID:   serverID,
+		ServerName: name,
+		Title:      "Backup created",
+		Message:    fmt.Sprintf("%s was backed up.", name),
+		Fields:     map[string]string{"Backup": backupName},
+	})
+}
+
+// ReportLowTPS publishes that a server has been ticking slowly for a while.
+func (n *Notifier) ReportLowTPS(serverID string, tps, mspt float64, below time.Duration) {
+	name := n.serverName(serverID)
+	fields := map[string]string{
+		"TPS":   fmt.Sprintf("%.1f", tps),
+		"Since": below.Round(time.Second).String(),
+	}
+	if mspt > 0 {
+		fields["MSPT"] = fmt.Sprintf("%.1f ms", mspt)
+	}
+	n.Publish(Event{
+		Type:       EventTPSLow,
+		ServerID:   serverID,
+		ServerName: name,
+		Title:      "Server lagging",
+		Message:    fmt.Sprintf("%s has been running at %.1f TPS for %s.", name, tps, below.Round(time.Second)),
+		Fields:     fields,
+	})
+}
+
+// WatchUpdates checks for a new Naviger release until ctx is done and
+// announces each new version once.
+func (n *Notifier) WatchUpdates(ctx context.Context, interval time.Duration) {
+	check := func() {
+		info, err := updater.CheckForUpdates()
+		if err != nil || !info.UpdateAvailable {
+			return
+		}
+		n.mu.Lock()
+		seen := n.notifiedLatest == info.LatestVersion
+		n.notifiedLatest = info.LatestVersion
+		n.mu.Unlock()
+		if seen {
+			return
+		}
+		n.Publish(Event{
+			Type:    EventUpdateAvailable,
+			Title:   "Update available",
+			Message: fmt.Sprintf("Naviger %s is available (running %s).", info.LatestVersion, info.CurrentVersion),
+			Fields:  map[string]string{"Release": info.ReleaseURL},
+		})
+	}
+	watch(ctx, interval, check)
+}
+
+// WatchDisk warns once when free space on the volume holding path drops
+// below minFreeMB, and again only after it has recovered.
+func (n *Notifier) WatchDisk(ctx context.Context, path string, minFreeMB int, interval time.Duration) {
+	if minFreeMB <= 0 {
+		return
+	}
+	check := func() {
+		usage, err := disk.Usage(path)
+		if err != nil {
+			slog.Warn("Could not read disk usage", "path", path, "error", err)
+			return
+		}
+		freeMB := usage.Free / 1024 / 1024
+		low := freeMB < uint64(minFreeMB)
+
+		n.mu.Lock()
+		notify := low && !n.notifiedDisk
+		n.notifiedDisk = low
+		n.mu.Unlock()
+		if !notify {
+			return
+		}
+		n.Publish(Event{
+			Type:    EventDiskLow,
+			Title:   "Disk space low",
+			Message: fmt.Sprintf("Only %d MB free on the disk holding %s.", freeMB, path),
+			Fields: map[string]string{
+				"Free":  fmt.Sprintf("%d MB", freeMB),
+				"Used":  fmt.Sprintf("%.1f%%", usage.UsedPercent),
+				"Limit": fmt.Sprintf("%d MB", minFreeMB),
+			},
+		})
+	}
+	watch(ctx, interval, check)
+}
+
+func watch(ctx context.Context, interval time.Duration, check func()) {
+	check()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (n *Notifier) serverName(serverID string) string {
+	srv, err := n.Store.GetServerByID(serverID)
+	if err != nil || srv == nil {
+		return serverID
+	}
+	return srv.Name
+}
@@ -0,0 +1,89 @@
+package players
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	WhitelistFile     = "whitelist.json"
+	OpsFile           = "ops.json"
+	BannedPlayersFile = "banned-players.json"
+	BannedIPsFile     = "banned-ips.json"
+	banTimeLayout     = "2006-01-02 15:04:05 -0700"
+	defaultBanBy      = "Naviger"
+	defaultReason     = "Banned by an operator."
+	expiresForever    = "forever"
+	defaultOpLevel    = 4
+	maxOpLevel        = 4
+	minOpLevel        = 1
+)
+
+type WhitelistEntry struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+type OpEntry struct {
+	UUID                string `json:"uuid"`
+	Name                string `json:"name"`
+	Level               int    `json:"level"`
+	BypassesPlayerLimit bool   `json:"bypassesPlayerLimit"`
+}
+
+type BanEntry struct {
+	UUID    string `json:"uuid"`
+	Name    string `json:"name"`
+	Created string `json:"created"`
+	Source  string `json:"source"`
+	Expires string `json:"expires"`
+	Reason  string `json:"reason"`
+}
+
+type IPBanEntry struct {
+	IP      string `json:"ip"`
+	Created string `json:"created"`
+	Source  string `json:"source"`
+	Expires string `json:"expires"`
+	Reason  string `json:"reason"`
+}
+
+// readList decodes one of the server's JSON player lists. A missing or empty
+// file is an empty list.
+func readList[T any](serverDir, file string) ([]T, error) {
+	entries := []T{}
+	data, err := os.ReadFile(filepath.Join(serverDir, file))
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return entries, nil
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func writeList[T any](serverDir, file string, entries []T) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(serverDir, file), append(data, '\n'), 0644)
+}
+
+func banTimestamp() string {
+	return time.Now().Format(banTimeLayout)
+}
+
+// samePlayer matches an entry by UUID or, case-insensitively, by name.
+func samePlayer(uuid, name, target string) bool {
+	return strings.EqualFold(uuid, target) || strings.EqualFold(name, target)
+}
@@ -0,0 +1,390 @@
+package players
+
+import (
+	"errors"
+	"fmt"
+	"naviger/internal/loader"
+	"naviger/internal/properties"
+	"naviger/internal/runner"
+	"naviger/internal/storage"
+	"net"
+	"path/filepath"
+	"strings"
+)
+
+// Manager edits a server's whitelist, operator and ban lists. While the
+// server is stopped the JSON files are edited directly; while it runs the
+// server owns those files, so the equivalent console command is sent instead.
+type Manager struct {
+	Store       *storage.GormStore
+	Supervisor  *runner.Supervisor
+	ServersPath string
+	Resolver    ProfileResolver
+}
+
+func NewManager(store *storage.GormStore, supervisor *runner.Supervisor, serversPath string, resolver ProfileResolver) *Manager {
+	return &Manager{
+		Store:       store,
+		Supervisor:  supervisor,
+		ServersPath: serversPath,
+		Resolver:    resolver,
+	}
+}
+
+// Result reports how a change was applied: "file" when the JSON list was
+// edited, "command" when it was sent to the running server.
+type Result struct {
+	AppliedVia string   `json:"appliedVia"`
+	Profile    *Profile `json:"profile,omitempty"`
+}
+
+func (m *Manager) serverDir(serverID string) (string, error) {
+	srv, err := m.Store.GetServerByID(serverID)
+	if err != nil {
+		return "", err
+	}
+	if srv == nil {
+		return "", fmt.Errorf("server not found")
+	}
+	if loader.IsProxy(srv.Loader) {
+		return "", fmt.Errorf("proxies do not have player lists")
+	}
+	folderName := srv.FolderName
+	if folderName == "" {
+		folderName = srv.ID
+	}
+	return filepath.Join(m.ServersPath, folderName), nil
+}
+
+// ResolveProfile looks up the UUID the server will use for name. Offline-mode
+// servers derive it from the name; online-mode servers need a Mojang account.
+func (m *Manager) ResolveProfile(serverID, name string) (*Profile, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid player name")
+	}
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := properties.Read(dir)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(values["online-mode"], "false") {
+		return &Profile{UUID: OfflineUUID(name), Name: name}, nil
+	}
+
+	profile, err := m.Resolver.Resolve(name)
+	if errors.Is(err, ErrProfileNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	return profile, err
+}
+
+func (m *Manager) sendCommand(serverID, command string) (*Result, error) {
+	if _, err := m.serverDir(serverID); err != nil {
+		return nil, err
+	}
+	if err := m.Supervisor.SendCommand(serverID, command); err != nil {
+		return nil, err
+	}
+	return &Result{AppliedVia: "command"}, nil
+}
+
+func (m *Manager) ListWhitelist(serverID string) ([]WhitelistEntry, error) {
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	return readList[WhitelistEntry](dir, WhitelistFile)
+}
+
+func (m *Manager) AddToWhitelist(serverID, name string) (*Result, error) {
+	if m.Supervisor.IsRunning(serverID) {
+		if !namePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid player name")
+		}
+		return m.sendCommand(serverID, "whitelist add "+name)
+	}
+
+	profile, err := m.ResolveProfile(serverID, name)
+	if err != nil {
+		return nil, err
+	}
+	dir, _ := m.serverDir(serverID)
+	entries, err := readList[WhitelistEntry](dir, WhitelistFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.UUID, profile.UUID) {
+			return &Result{AppliedVia: "file", Profile: profile}, nil
+		}
+	}
+	entries = append(entries, WhitelistEntry{UUID: profile.UUID, Name: profile.Name})
+	return &Result{AppliedVia: "file", Profile: profile}, writeList(dir, WhitelistFile, entries)
+}
+
+func (m *Manager) RemoveFromWhitelist(serverID, player string) (*Result, error) {
+	if m.Supervisor.IsRunning(serverID) {
+		if !namePattern.MatchString(player) {
+			return nil, fmt.Errorf("a running server needs a player name, not a UUID")
+		}
+		return m.sendCommand(serverID, "whitelist remove "+player)
+	}
+
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readList[WhitelistEntry](dir, WhitelistFile)
+	if err != nil {
+		return nil, err
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if !samePlayer(e.UUID, e.Name, player) {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(entries) {
+		return nil, fmt.Errorf("player is not whitelisted")
+	}
+	return &Result{AppliedVia: "file"}, writeList(dir, WhitelistFile, kept)
+}
+
+func (m *Manager) ListOps(serverID string) ([]OpEntry, error) {
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	return readList[OpEntry](dir, OpsFile)
+}
+
+// AddOp makes a player an operator. A custom level or player-limit bypass
+// can only be written to ops.json, so those require the server to be stopped.
+func (m *Manager) AddOp(serverID, name string, level int, bypassesPlayerLimit bool) (*Result, error) {
+	if level == 0 {
+		level = defaultOpLevel
+	}
+	if level < minOpLevel || level > maxOpLevel {
+		return nil, fmt.Errorf("op level must be between %d and %d", minOpLevel, maxOpLevel)
+	}
+
+	if m.Supervisor.IsRunning(serverID) {
+		if level != defaultOpLevel || bypassesPlayerLimit {
+			return nil, fmt.Errorf("stop the server to set a custom op level or player-limit bypass")
+		}
+		if !namePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid player name")
+		}
+		return m.sendCommand(serverID, "op "+name)
+	}
+
+	profile, err := m.ResolveProfile(serverID, name)
+	if err != nil {
+		return nil, err
+	}
+	dir, _ := m.serverDir(serverID)
+	entries, err := readList[OpEntry](dir, OpsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := OpEntry{UUID: profile.UUID, Name: profile.Name, Level: level, BypassesPlayerLimit: bypassesPlayerLimit}
+	replaced := false
+	for i, e := range entries {
+		if strings.EqualFold(e.UUID, profile.UUID) {
+			entries[i] = entry
+			replaced = true
+		}
+	}
+	if !replaced {
+		entries = append(entries, entry)
+	}
+	return &Result{AppliedVia: "file", Profile: profile}, writeList(dir, OpsFile, entries)
+}
+
+func (m *Manager) RemoveOp(serverID, player string) (*Result, error) {
+	if m.Supervisor.IsRunning(serverID) {
+		if !namePattern.MatchString(player) {
+			return nil, fmt.Errorf("a running server needs a player name, not a UUID")
+		}
+		return m.sendCommand(serverID, "deop "+player)
+	}
+
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readList[OpEntry](dir, OpsFile)
+	if err != nil {
+		return nil, err
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if !samePlayer(e.UUID, e.Name, player) {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(entries) {
+		return nil, fmt.Errorf("player is not an operator")
+	}
+	return &Result{AppliedVia: "file"}, writeList(dir, OpsFile, kept)
+}
+
+func (m *Manager) ListBans(serverID string) ([]BanEntry, error) {
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	return readList[BanEntry](dir, BannedPlayersFile)
+}
+
+func (m *Manager) BanPlayer(serverID, name, reason string) (*Result, error) {
+	if strings.ContainsAny(reason, "\r\n") {
+		return nil, fmt.Errorf("reason must be a single line")
+	}
+
+	if m.Supervisor.IsRunning(serverID) {
+		if !namePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid player name")
+		}
+		return m.sendCommand(serverID, strings.TrimSpace("ban "+name+" "+reason))
+	}
+
+	profile, err := m.ResolveProfile(serverID, name)
+	if err != nil {
+		return nil, err
+	}
+	dir, _ := m.serverDir(serverID)
+	entries, err := readList[BanEntry](dir, BannedPlayersFile)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		reason = defaultReason
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if !strings.EqualFold(e.UUID, profile.UUID) {
+			kept = append(kept, e)
+		}
+	}
+	kept = append(kept, BanEntry{
+		UUID:    profile.UUID,
+		Name:    profile.Name,
+		Created: banTimestamp(),
+		Source:  defaultBanBy,
+		Expires: expiresForever,
+		Reason:  reason,
+	})
+	return &Result{AppliedVia: "file", Profile: profile}, writeList(dir, BannedPlayersFile, kept)
+}
+
+func (m *Manager) PardonPlayer(serverID, player string) (*Result, error) {
+	if m.Supervisor.IsRunning(serverID) {
+		if !namePattern.MatchString(player) {
+			return nil, fmt.Errorf("a running server needs a player name, not a UUID")
+		}
+		return m.sendCommand(serverID, "pardon "+player)
+	}
+
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readList[BanEntry](dir, BannedPlayersFile)
+	if err != nil {
+		return nil, err
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if !samePlayer(e.UUID, e.Name, player) {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(entries) {
+		return nil, fmt.Errorf("player is not banned")
+	}
+	return &Result{AppliedVia: "file"}, writeList(dir, BannedPlayersFile, kept)
+}
+
+func (m *Manager) ListIPBans(serverID string) ([]IPBanEntry, error) {
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	return readList[IPBanEntry](dir, BannedIPsFile)
+}
+
+func (m *Manager) BanIP(serverID, ip, reason string) (*Result, error) {
+	if net.ParseIP(ip) == nil {
+		return nil, fmt.Errorf("invalid IP address")
+	}
+	if strings.ContainsAny(reason, "\r\n") {
+		return nil, fmt.Errorf("reason must be a single line")
+	}
+
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Supervisor.IsRunning(serverID) {
+		return m.sendCommand(serverID, strings.TrimSpace("ban-ip "+ip+" "+reason))
+	}
+
+	entries, err := readList[IPBanEntry](dir, BannedIPsFile)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		reason = defaultReason
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.IP != ip {
+			kept = append(kept, e)
+		}
+	}
+	kept = append(kept, IPBanEntry{
+		IP:      ip,
+		Created: banTimestamp(),
+		Source:  defaultBanBy,
+		Expires: expiresForever,
+		Reason:  reason,
+	})
+	return &Result{AppliedVia: "file"}, writeList(dir, BannedIPsFile, kept)
+}
+
+func (m *Manager) PardonIP(serverID, ip string) (*Result, error) {
+	if net.ParseIP(ip) == nil {
+		return nil, fmt.Errorf("invalid IP address")
+	}
+
+	dir, err := m.serverDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Supervisor.IsRunning(serverID) {
+		return m.sendCommand(serverID, "pardon-ip "+ip)
+	}
+
+	entries, err := readList[IPBanEntry](dir, BannedIPsFile)
+	if err != nil {
+		return nil, err
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.IP != ip {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(entries) {
+		return nil, fmt.Errorf("IP is not banned")
+	}
+	return &Result{AppliedVia: "file"}, writeList(dir, BannedIPsFile, kept)
+}
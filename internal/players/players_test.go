@@ -0,0 +1,206 @@
+package players
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"naviger/internal/domain"
+	"naviger/internal/runner"
+	"naviger/internal/storage"
+)
+
+func TestOfflineUUID(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Notch", "b50ad385-829d-3141-a216-7e7d7539ba7f"},
+		{"jeb_", "a762f560-4fce-3236-812a-b80efff0b62b"},
+		{"Steve", "5627dd98-e6be-3c21-b8a8-e92344183641"},
+		{"Player_123", "592ac8aa-a392-3300-916e-00427a650c3b"},
+	}
+
+	for _, tt := range tests {
+		if got := OfflineUUID(tt.name); got != tt.want {
+			t.Errorf("OfflineUUID(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// vanillaOps is ops.json as a vanilla server writes it.
+const vanillaOps = `[
+  {
+    "uuid": "069a79f4-44e9-4726-a5be-fca90e38aaf5",
+    "name": "Notch",
+    "level": 4,
+    "bypassesPlayerLimit": false
+  }
+]`
+
+func TestListRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, OpsFile), []byte(vanillaOps), 0644); err != nil {
+		t.Fatalf("Failed to write ops.json: %v", err)
+	}
+
+	ops, err := readList[OpEntry](dir, OpsFile)
+	if err != nil {
+		t.Fatalf("Failed to read ops.json: %v", err)
+	}
+	want := []OpEntry{{UUID: "069a79f4-44e9-4726-a5be-fca90e38aaf5", Name: "Notch", Level: 4}}
+	if !reflect.DeepEqual(ops, want) {
+		t.Fatalf("Read %+v, want %+v", ops, want)
+	}
+
+	if err := writeList(dir, OpsFile, ops); err != nil {
+		t.Fatalf("Failed to write ops.json: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, OpsFile))
+	if strings.TrimSpace(string(data)) != vanillaOps {
+		t.Errorf("Rewritten ops.json differs:\n%s", data)
+	}
+
+	bans := []BanEntry{{UUID: OfflineUUID("Steve"), Name: "Steve", Created: banTimestamp(), Source: defaultBanBy, Expires: expiresForever, Reason: defaultReason}}
+	if err := writeList(dir, BannedPlayersFile, bans); err != nil {
+		t.Fatalf("Failed to write banned-players.json: %v", err)
+	}
+	read, err := readList[BanEntry](dir, BannedPlayersFile)
+	if err != nil || !reflect.DeepEqual(read, bans) {
+		t.Errorf("Read bans %+v, %v, want %+v", read, err, bans)
+	}
+}
+
+func TestMissingOrEmptyListIsEmpty(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, WhitelistFile), []byte("\n"), 0644)
+
+	for _, file := range []string{WhitelistFile, BannedIPsFile} {
+		entries, err := readList[WhitelistEntry](dir, file)
+		if err != nil || entries == nil || len(entries) != 0 {
+			t.Errorf("readList(%s) = %v, %v, want an empty list", file, entries, err)
+		}
+	}
+}
+
+type fakeResolver map[string]string
+
+func (f fakeResolver) Resolve(name string) (*Profile, error) {
+	for known, uuid := range f {
+		if strings.EqualFold(known, name) {
+			return &Profile{UUID: uuid, Name: known}, nil
+		}
+	}
+	return nil, ErrProfileNotFound
+}
+
+func newTestManager(t *testing.T, onlineMode bool) (*Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := storage.NewGormStore(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	serversPath := filepath.Join(dir, "servers")
+	serverDir := filepath.Join(serversPath, "survival")
+	os.MkdirAll(serverDir, 0755)
+	mode := "true"
+	if !onlineMode {
+		mode = "false"
+	}
+	os.WriteFile(filepath.Join(serverDir, "server.properties"), []byte("online-mode="+mode+"\n"), 0644)
+	if err := store.SaveServer(&domain.Server{ID: "s1", Name: "Survival", FolderName: "survival", Loader: "paper"}); err != nil {
+		t.Fatalf("Failed to save server: %v", err)
+	}
+
+	supervisor := runner.NewSupervisor(store, nil, nil, serversPath)
+	resolver := fakeResolver{"Notch": "069a79f4-44e9-4726-a5be-fca90e38aaf5"}
+	return NewManager(store, supervisor, serversPath, resolver), serverDir
+}
+
+func TestStoppedServerListsAreEdited(t *testing.T) {
+	m, _ := newTestManager(t, true)
+
+	result, err := m.AddToWhitelist("s1", "notch")
+	if err != nil {
+		t.Fatalf("AddToWhitelist: %v", err)
+	}
+	if result.AppliedVia != "file" || result.Profile.Name != "Notch" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if _, err := m.AddToWhitelist("s1", "Notch"); err != nil {
+		t.Fatalf("AddToWhitelist again: %v", err)
+	}
+	whitelist, _ := m.ListWhitelist("s1")
+	if len(whitelist) != 1 || whitelist[0].UUID != "069a79f4-44e9-4726-a5be-fca90e38aaf5" {
+		t.Errorf("Whitelist is %+v, want Notch once", whitelist)
+	}
+
+	if _, err := m.AddToWhitelist("s1", "NoSuchPlayer"); err == nil {
+		t.Error("Whitelisted a name without an account on an online-mode server")
+	}
+
+	if _, err := m.AddOp("s1", "Notch", 2, true); err != nil {
+		t.Fatalf("AddOp: %v", err)
+	}
+	ops, _ := m.ListOps("s1")
+	if len(ops) != 1 || ops[0].Level != 2 || !ops[0].BypassesPlayerLimit {
+		t.Errorf("Ops are %+v", ops)
+	}
+
+	if _, err := m.RemoveFromWhitelist("s1", "069A79F4-44E9-4726-A5BE-FCA90E38AAF5"); err != nil {
+		t.Fatalf("RemoveFromWhitelist by UUID: %v", err)
+	}
+	if _, err := m.RemoveFromWhitelist("s1", "Notch"); err == nil {
+		t.Error("Removed a player who is not whitelisted")
+	}
+}
+
+func TestOfflineModeBans(t *testing.T) {
+	m, _ := newTestManager(t, false)
+
+	if _, err := m.BanPlayer("s1", "Steve", "griefing"); err != nil {
+		t.Fatalf("BanPlayer: %v", err)
+	}
+	bans, _ := m.ListBans("s1")
+	if len(bans) != 1 || bans[0].UUID != OfflineUUID("Steve") || bans[0].Reason != "griefing" || bans[0].Expires != expiresForever {
+		t.Errorf("Bans are %+v", bans)
+	}
+
+	if _, err := m.BanPlayer("s1", "Steve", "line\nbreak"); err == nil {
+		t.Error("Accepted a reason spanning lines")
+	}
+	if _, err := m.PardonPlayer("s1", "steve"); err != nil {
+		t.Fatalf("PardonPlayer: %v", err)
+	}
+	if bans, _ := m.ListBans("s1"); len(bans) != 0 {
+		t.Errorf("Bans after pardon are %+v", bans)
+	}
+}
+
+func TestMojangResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Notch" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id":"069a79f444e94726a5befca90e38aaf5","name":"Notch"}`))
+	}))
+	defer srv.Close()
+
+	r := &MojangResolver{Client: srv.Client(), BaseURL: srv.URL + "/"}
+	profile, err := r.Resolve("Notch")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if profile.UUID != "069a79f4-44e9-4726-a5be-fca90e38aaf5" || profile.Name != "Notch" {
+		t.Errorf("Unexpected profile %+v", profile)
+	}
+	if _, err := r.Resolve("Nobody"); err != ErrProfileNotFound {
+		t.Errorf("Resolve of an unknown name returned %v, want ErrProfileNotFound", err)
+	}
+}
@@ -0,0 +1,82 @@
+package players
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+const MojangProfileURL = "https://api.mojang.com/users/profiles/minecraft/"
+
+var ErrProfileNotFound = errors.New("no Minecraft account with that name")
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,16}$`)
+
+type Profile struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+// ProfileResolver turns a player name into the account profile the server
+// will use. It is an interface so tests can substitute the Mojang API.
+type ProfileResolver interface {
+	Resolve(name string) (*Profile, error)
+}
+
+type MojangResolver struct {
+	Client  *http.Client
+	BaseURL string
+}
+
+func NewMojangResolver() *MojangResolver {
+	return &MojangResolver{
+		Client:  &http.Client{Timeout: 10 * time.Second},
+		BaseURL: MojangProfileURL,
+	}
+}
+
+func (r *MojangResolver) Resolve(name string) (*Profile, error) {
+	resp, err := r.Client.Get(r.BaseURL + url.PathEscape(name))
+	if err != nil {
+		return nil, fmt.Errorf("error contacting Mojang: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil, ErrProfileNotFound
+	default:
+		return nil, fmt.Errorf("Mojang API responded with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if len(body.ID) != 32 {
+		return nil, fmt.Errorf("unexpected profile id %q", body.ID)
+	}
+	return &Profile{UUID: dashUUID(body.ID), Name: body.Name}, nil
+}
+
+// OfflineUUID returns the UUID an offline-mode server assigns to name:
+// a version 3 UUID of "OfflinePlayer:<name>".
+func OfflineUUID(name string) string {
+	sum := md5.Sum([]byte("OfflinePlayer:" + name))
+	sum[6] = sum[6]&0x0f | 0x30
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+func dashUUID(hex string) string {
+	return fmt.Sprintf("%s-%s-%s-%s-%s", hex[0:8], hex[8:12], hex[12:16], hex[16:20], hex[20:32])
+}
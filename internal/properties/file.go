@@ -0,0 +1,350 @@
+// Package properties reads and writes Java-style .properties files, such as
+// server.properties, keeping comments, blank lines and key order intact.
+package properties
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const FileName = "server.properties"
+
+type line struct {
+	raw   string
+	key   string
+	value string
+	isKV  bool
+}
+
+// File is a parsed properties file. Untouched lines are written back
+// byte-for-byte; only lines whose value changes are re-encoded.
+type File struct {
+	lines []line
+	index map[string]int
+}
+
+func New() *File {
+	return &File{index: make(map[string]int)}
+}
+
+func Parse(r io.Reader) (*File, error) {
+	f := New()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var pending []string
+	for scanner.Scan() {
+		text := scanner.Text()
+
+		// A value ending in an odd number of backslashes continues on the
+		// next line.
+		if len(pending) > 0 || !isComment(text) && continues(text) {
+			pending = append(pending, text)
+			if continues(text) {
+				continue
+			}
+			text = strings.Join(pending, "\n")
+			pending = nil
+		}
+
+		f.appendLine(text)
+	}
+	if len(pending) > 0 {
+		f.appendLine(strings.Join(pending, "\n"))
+	}
+	return f, scanner.Err()
+}
+
+// Load reads path, returning an empty File when it does not exist.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes.NewReader(data))
+}
+
+func (f *File) appendLine(raw string) {
+	l := line{raw: raw}
+	trimmed := strings.TrimLeft(raw, " \t\f")
+	if trimmed != "" && !isComment(trimmed) {
+		l.key, l.value = splitKeyValue(trimmed)
+		l.isKV = true
+		if i, exists := f.index[l.key]; exists {
+			// Later duplicates win, as in java.util.Properties.
+			f.lines[i].isKV = false
+		}
+		f.index[l.key] = len(f.lines)
+	}
+	f.lines = append(f.lines, l)
+}
+
+func (f *File) Get(key string) (string, bool) {
+	i, ok := f.index[key]
+	if !ok {
+		return "", false
+	}
+	return f.lines[i].value, true
+}
+
+// Set updates key in place, or appends it when it is not present yet.
+func (f *File) Set(key, value string) {
+	encoded := escape(key, true) + "=" + escape(value, false)
+	if i, ok := f.index[key]; ok {
+		if f.lines[i].value == value {
+			return
+		}
+		f.lines[i] = line{raw: encoded, key: key, value: value, isKV: true}
+		return
+	}
+	f.index[key] = len(f.lines)
+	f.lines = append(f.lines, line{raw: encoded, key: key, value: value, isKV: true})
+}
+
+// Keys returns the keys in file order.
+func (f *File) Keys() []string {
+	var keys []string
+	for _, l := range f.lines {
+		if l.isKV {
+			keys = append(keys, l.key)
+		}
+	}
+	return keys
+}
+
+func (f *File) Map() map[string]string {
+	values := make(map[string]string, len(f.index))
+	for key, i := range f.index {
+		values[key] = f.lines[i].value
+	}
+	return values
+}
+
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for _, l := range f.lines {
+		n, err := io.WriteString(w, l.raw+"\n")
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Save writes the file atomically through a temporary file in the same
+// directory.
+func (f *File) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".properties-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	if _, err := f.WriteTo(writer); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Read returns the values of the server.properties in serverDir.
+func Read(serverDir string) (map[string]string, error) {
+	f, err := Load(filepath.Join(serverDir, FileName))
+	if err != nil {
+		return nil, err
+	}
+	return f.Map(), nil
+}
+
+// Update merges values into the server.properties in serverDir, creating the
+// file if needed. The file is not rewritten when nothing changes.
+func Update(serverDir string, values map[string]string) error {
+	path := filepath.Join(serverDir, FileName)
+	f, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, key := range sortedKeys(values) {
+		if current, ok := f.Get(key); ok && current == values[key] {
+			continue
+		}
+		f.Set(key, values[key])
+		changed = true
+	}
+	if !changed {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+	}
+	return f.Save(path)
+}
+
+// SetPort writes server-port into the server.properties in serverDir.
+func SetPort(serverDir string, port int) error {
+	return Update(serverDir, map[string]string{"server-port": strconv.Itoa(port)})
+}
+
+func isComment(s string) bool {
+	s = strings.TrimLeft(s, " \t\f")
+	return strings.HasPrefix(s, "#") || strings.HasPrefix(s, "!")
+}
+
+func continues(s string) bool {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitKeyValue splits a logical line at the first unescaped '=', ':' or
+// whitespace, following java.util.Properties.
+func splitKeyValue(s string) (string, string) {
+	keyEnd := len(s)
+	valueStart := len(s)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			keyEnd = i
+			j := i
+			for j < len(s) && (s[j] == ' ' || s[j] == '\t' || s[j] == '\f') {
+				j++
+			}
+			if j < len(s) && (s[j] == '=' || s[j] == ':') && (c == ' ' || c == '\t' || c == '\f') {
+				j++
+			} else if c == '=' || c == ':' {
+				j = i + 1
+			}
+			for j < len(s) && (s[j] == ' ' || s[j] == '\t' || s[j] == '\f') {
+				j++
+			}
+			valueStart = j
+			break
+		}
+	}
+	return unescape(s[:keyEnd]), unescape(s[valueStart:])
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case '\n':
+			// Line continuation: skip leading whitespace on the next line.
+			for i+1 < len(s) && (s[i+1] == ' ' || s[i+1] == '\t' || s[i+1] == '\f') {
+				i++
+			}
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// escape encodes s the way java.util.Properties.store does, using \uXXXX for
+// non-ASCII characters so the file is valid in both ISO-8859-1 and UTF-8.
+func escape(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == '=' || r == ':' || r == '#' || r == '!':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == ' ' && (isKey || i == 0):
+			b.WriteString(`\ `)
+		case r < 0x20 || r > 0x7e:
+			if r > 0xffff {
+				for _, u := range utf16Pair(r) {
+					fmt.Fprintf(&b, `\u%04X`, u)
+				}
+			} else {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func utf16Pair(r rune) []rune {
+	if r > utf8.MaxRune {
+		return []rune{0xfffd}
+	}
+	r -= 0x10000
+	return []rune{0xd800 + (r>>10)&0x3ff, 0xdc00 + r&0x3ff}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package properties
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = `#Minecraft server properties
+#Mon Jan 01 00:00:00 UTC 2024
+enable-jmx-monitoring=false
+level-type=minecraft\:normal
+motd=§aWelcome
+
+# custom comment
+max-players=20
+server-port=25565
+`
+
+func TestRoundTripKeepsCommentsAndOrder(t *testing.T) {
+	f, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var out strings.Builder
+	f.WriteTo(&out)
+	if out.String() != sample {
+		t.Errorf("Unmodified file did not round-trip:\n%s", out.String())
+	}
+
+	if v, _ := f.Get("level-type"); v != "minecraft:normal" {
+		t.Errorf("Expected unescaped level-type, got %q", v)
+	}
+	if v, _ := f.Get("motd"); v != "§aWelcome" {
+		t.Errorf("Expected unescaped motd, got %q", v)
+	}
+
+	f.Set("server-port", "25570")
+	f.Set("motd", "Héllo: world")
+	f.Set("white-list", "true")
+
+	out.Reset()
+	f.WriteTo(&out)
+	want := `#Minecraft server properties
+#Mon Jan 01 00:00:00 UTC 2024
+enable-jmx-monitoring=false
+level-type=minecraft\:normal
+motd=H\u00E9llo\: world
+
+# custom comment
+max-players=20
+server-port=25570
+white-list=true
+`
+	if out.String() != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestNormalizeValidatesAgainstSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		input   map[string]interface{}
+		want    map[string]string
+		wantErr bool
+	}{
+		{"int from json number", "1.20.4", map[string]interface{}{"max-players": float64(50)}, map[string]string{"max-players": "50"}, false},
+		{"bool from string", "1.20.4", map[string]interface{}{"online-mode": "FALSE"}, map[string]string{"online-mode": "false"}, false},
+		{"enum", "1.20.4", map[string]interface{}{"difficulty": "Hard"}, map[string]string{"difficulty": "hard"}, false},
+		{"numeric difficulty before 1.14", "1.12.2", map[string]interface{}{"difficulty": float64(3)}, map[string]string{"difficulty": "3"}, false},
+		{"named difficulty before 1.14", "1.12.2", map[string]interface{}{"difficulty": "hard"}, nil, true},
+		{"out of range", "1.20.4", map[string]interface{}{"view-distance": float64(64)}, nil, true},
+		{"unknown key passes through", "1.20.4", map[string]interface{}{"mod-setting": "x"}, map[string]string{"mod-setting": "x"}, false},
+		{"multi-line string", "1.20.4", map[string]interface{}{"motd": "a\nb"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.version, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("%s = %q, want %q", key, got[key], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package properties
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Type string
+
+const (
+	TypeString Type = "string"
+	TypeInt    Type = "int"
+	TypeBool   Type = "bool"
+	TypeEnum   Type = "enum"
+)
+
+// Field describes a known server.properties key.
+type Field struct {
+	Key         string   `json:"key"`
+	Type        Type     `json:"type"`
+	Default     string   `json:"default"`
+	Options     []string `json:"options,omitempty"`
+	Min         *int     `json:"min,omitempty"`
+	Max         *int     `json:"max,omitempty"`
+	Description string   `json:"description"`
+
+	// since and until bound the Minecraft versions the key exists in; until
+	// is the first version without it.
+	since string
+	until string
+}
+
+// Property is a schema field together with its current value.
+type Property struct {
+	Field
+	Value   interface{} `json:"value"`
+	Default interface{} `json:"default"`
+	Set     bool        `json:"set"`
+}
+
+// View is the structured form of a server.properties file. Keys the schema
+// does not know, such as ones added by mods, are returned as raw strings.
+type View struct {
+	Version    string            `json:"version"`
+	Properties []Property        `json:"properties"`
+	Extra      map[string]string `json:"extra"`
+}
+
+func intp(v int) *int { return &v }
+
+var difficulties = []string{"peaceful", "easy", "normal", "hard"}
+var gamemodes = []string{"survival", "creative", "adventure", "spectator"}
+
+var fields = []Field{
+	{Key: "motd", Type: TypeString, Default: "A Minecraft Server", Description: "Message shown in the server list"},
+	{Key: "difficulty", Type: TypeEnum, Default: "easy", Options: difficulties, Description: "World difficulty", since: "1.14"},
+	{Key: "difficulty", Type: TypeInt, Default: "1", Min: intp(0), Max: intp(3), Description: "World difficulty (0 peaceful - 3 hard)", until: "1.14"},
+	{Key: "gamemode", Type: TypeEnum, Default: "survival", Options: gamemodes, Description: "Default game mode", since: "1.14"},
+	{Key: "gamemode", Type: TypeInt, Default: "0", Min: intp(0), Max: intp(3), Description: "Default game mode (0 survival - 3 spectator)", until: "1.14"},
+	{Key: "force-gamemode", Type: TypeBool, Default: "false", Description: "Force players into the default game mode on join"},
+	{Key: "hardcore", Type: TypeBool, Default: "false", Description: "Players are banned on death"},
+	{Key: "max-players", Type: TypeInt, Default: "20", Min: intp(0), Max: intp(2147483647), Description: "Maximum number of players"},
+	{Key: "online-mode", Type: TypeBool, Default: "true", Description: "Authenticate players with Mojang"},
+	{Key: "white-list", Type: TypeBool, Default: "false", Description: "Only allow whitelisted players"},
+	{Key: "enforce-whitelist", Type: TypeBool, Default: "false", Description: "Kick non-whitelisted players when the whitelist is reloaded"},
+	{Key: "pvp", Type: TypeBool, Default: "true", Description: "Allow players to fight each other"},
+	{Key: "view-distance", Type: TypeInt, Default: "10", Min: intp(3), Max: intp(32), Description: "Chunk radius sent to clients"},
+	{Key: "simulation-distance", Type: TypeInt, Default: "10", Min: intp(3), Max: intp(32), Description: "Chunk radius that is ticked", since: "1.18"},
+	{Key: "spawn-protection", Type: TypeInt, Default: "16", Min: intp(0), Description: "Radius around spawn only operators can build in"},
+	{Key: "allow-flight", Type: TypeBool, Default: "false", Description: "Do not kick players for flying"},
+	{Key: "allow-nether", Type: TypeBool, Default: "true", Description: "Allow travel to the Nether"},
+	{Key: "spawn-monsters", Type: TypeBool, Default: "true", Description: "Spawn hostile mobs"},
+	{Key: "spawn-animals", Type: TypeBool, Default: "true", Description: "Spawn animals", until: "1.21.2"},
+	{Key: "spawn-npcs", Type: TypeBool, Default: "true", Description: "Spawn villagers", until: "1.21.2"},
+	{Key: "generate-structures", Type: TypeBool, Default: "true", Description: "Generate villages and other structures"},
+	{Key: "level-name", Type: TypeString, Default: "world", Description: "World folder name"},
+	{Key: "level-seed", Type: TypeString, Default: "", Description: "World seed"},
+	{Key: "level-type", Type: TypeString, Default: "minecraft:normal", Description: "World generator preset", since: "1.19"},
+	{Key: "level-type", Type: TypeString, Default: "default", Description: "World generator preset", until: "1.19"},
+	{Key: "max-world-size", Type: TypeInt, Default: "29999984", Min: intp(1), Max: intp(29999984), Description: "World border radius"},
+	{Key: "enable-command-block", Type: TypeBool, Default: "false", Description: "Enable command blocks"},
+	{Key: "op-permission-level", Type: TypeInt, Default: "4", Min: intp(1), Max: intp(4), Description: "Permission level of operators"},
+	{Key: "function-permission-level", Type: TypeInt, Default: "2", Min: intp(1), Max: intp(4), Description: "Permission level of functions", since: "1.14.4"},
+	{Key: "player-idle-timeout", Type: TypeInt, Default: "0", Min: intp(0), Description: "Minutes before idle players are kicked (0 disables)"},
+	{Key: "max-tick-time", Type: TypeInt, Default: "60000", Min: intp(-1), Description: "Milliseconds a tick may take before the watchdog stops the server (-1 disables)"},
+	{Key: "network-compression-threshold", Type: TypeInt, Default: "256", Min: intp(-1), Description: "Packet size above which packets are compressed (-1 disables)"},
+	{Key: "entity-broadcast-range-percentage", Type: TypeInt, Default: "100", Min: intp(10), Max: intp(1000), Description: "Entity visibility range in percent", since: "1.16"},
+	{Key: "sync-chunk-writes", Type: TypeBool, Default: "true", Description: "Write chunks synchronously", since: "1.16"},
+	{Key: "enforce-secure-profile", Type: TypeBool, Default: "true", Description: "Require signed chat from players", since: "1.19"},
+	{Key: "hide-online-players", Type: TypeBool, Default: "false", Description: "Hide the player list in the server list", since: "1.18"},
+	{Key: "resource-pack", Type: TypeString, Default: "", Description: "Resource pack URL"},
+	{Key: "resource-pack-sha1", Type: TypeString, Default: "", Description: "SHA-1 of the resource pack"},
+	{Key: "require-resource-pack", Type: TypeBool, Default: "false", Description: "Kick players who decline the resource pack", since: "1.17"},
+	{Key: "server-ip", Type: TypeString, Default: "", Description: "Address to bind to (empty for all)"},
+	{Key: "server-port", Type: TypeInt, Default: "25565", Min: intp(1), Max: intp(65535), Description: "Port, managed by Naviger"},
+	{Key: "enable-query", Type: TypeBool, Default: "false", Description: "Enable the GameSpy4 query protocol"},
+	{Key: "enable-rcon", Type: TypeBool, Default: "false", Description: "Enable remote console"},
+	{Key: "rcon.port", Type: TypeInt, Default: "25575", Min: intp(1), Max: intp(65535), Description: "Remote console port"},
+	{Key: "rcon.password", Type: TypeString, Default: "", Description: "Remote console password"},
+}
+
+// Schema returns the fields that exist in the given Minecraft version, with
+// that version's defaults. Versions that cannot be parsed, such as snapshots,
+// get the newest schema.
+func Schema(version string) []Field {
+	var result []Field
+	for _, f := range fields {
+		if f.since != "" && !versionAtLeast(version, f.since) {
+			continue
+		}
+		if f.until != "" && versionAtLeast(version, f.until) {
+			continue
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// Lookup returns the field for key in the given version's schema.
+func Lookup(version, key string) (Field, bool) {
+	for _, f := range Schema(version) {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Describe types the raw values of a server.properties file against the
+// schema of version.
+func Describe(values map[string]string, version string) View {
+	view := View{Version: version, Properties: []Property{}, Extra: map[string]string{}}
+
+	known := make(map[string]bool)
+	for _, f := range Schema(version) {
+		known[f.Key] = true
+		raw, set := values[f.Key]
+		if !set {
+			raw = f.Default
+		}
+		view.Properties = append(view.Properties, Property{
+			Field:   f,
+			Value:   typedValue(f, raw),
+			Default: typedValue(f, f.Default),
+			Set:     set,
+		})
+	}
+
+	for key, value := range values {
+		if !known[key] {
+			view.Extra[key] = value
+		}
+	}
+	return view
+}
+
+// Normalize validates input values against the schema of version and returns
+// them in their server.properties form. Unknown keys are accepted as plain
+// strings so mod-specific settings can still be edited.
+func Normalize(version string, input map[string]interface{}) (map[string]string, error) {
+	result := make(map[string]string, len(input))
+	for key, value := range input {
+		if key == "" || strings.ContainsAny(key, "\r\n") {
+			return nil, fmt.Errorf("invalid property key %q", key)
+		}
+
+		raw, err := rawValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		if f, ok := Lookup(version, key); ok {
+			if raw, err = f.Validate(raw); err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		result[key] = raw
+	}
+	return result, nil
+}
+
+// Validate checks value against the field and returns its canonical form.
+func (f Field) Validate(value string) (string, error) {
+	switch f.Type {
+	case TypeInt:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("must be an integer")
+		}
+		if f.Min != nil && n < *f.Min {
+			return "", fmt.Errorf("must be at least %d", *f.Min)
+		}
+		if f.Max != nil && n > *f.Max {
+			return "", fmt.Errorf("must be at most %d", *f.Max)
+		}
+		return strconv.Itoa(n), nil
+	case TypeBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("must be true or false")
+		}
+		return strconv.FormatBool(b), nil
+	case TypeEnum:
+		v := strings.ToLower(strings.TrimSpace(value))
+		for _, option := range f.Options {
+			if v == option {
+				return v, nil
+			}
+		}
+		return "", fmt.Errorf("must be one of %s", strings.Join(f.Options, ", "))
+	default:
+		if strings.ContainsAny(value, "\r\n") {
+			return "", fmt.Errorf("must be a single line")
+		}
+		return value, nil
+	}
+}
+
+func typedValue(f Field, raw string) interface{} {
+	switch f.Type {
+	case TypeInt:
+		if n, err := strconv.Atoi(strings.TrimSpace(raw)); err == nil {
+			return n
+		}
+	case TypeBool:
+		if b, err := strconv.ParseBool(strings.TrimSpace(raw)); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+func rawValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		if v != math.Trunc(v) {
+			return "", fmt.Errorf("must be a whole number")
+		}
+		return strconv.FormatInt(int64(v), 10), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// versionAtLeast compares dotted release versions numerically. Anything that
+// is not a plain release version counts as newer than every release.
+func versionAtLeast(version, min string) bool {
+	v, ok := parseVersion(version)
+	if !ok {
+		return true
+	}
+	m, _ := parseVersion(min)
+	for i := 0; i < len(v) || i < len(m); i++ {
+		var a, b int
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(m) {
+			b = m[i]
+		}
+		if a != b {
+			return a > b
+		}
+	}
+	return true
+}
+
+func parseVersion(version string) ([]int, bool) {
+	parts := strings.Split(version, ".")
+	nums := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, false
+		}
+		nums = append(nums, n)
+	}
+	return nums, len(nums) > 0
+}
@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"os/exec"
+
+	"naviger/internal/domain"
+	"naviger/internal/runner/strategy"
+)
+
+// Backend is how a server's command is executed. The Supervisor owns the
+// lifecycle shared by every mode (console, observers, status and exit
+// handling) and leaves launching, resource usage and cleanup to the backend
+// chosen by the server's run mode.
+type Backend interface {
+	// Command builds the command that runs the server. Its stdin, stdout and
+	// stderr are the server console.
+	Command(launch Launch) (*exec.Cmd, error)
+	// Preview returns the command line Command would run, without preparing
+	// anything for it.
+	Preview(launch Launch) ([]string, error)
+	// Started is called once the command runs and returns what tracks it
+	// until it exits.
+	Started(launch Launch, cmd *exec.Cmd) Execution
+}
+
+// Execution is a running server as its backend sees it.
+type Execution interface {
+	// Stats fills in the resource usage of the server.
+	Stats(stats *domain.ServerStats)
+	// Exited adds what the backend knows about the exit to it and releases
+	// everything held for the run.
+	Exited(exit *domain.ProcessExit)
+}
+
+// Launch describes a server start.
+type Launch struct {
+	Server      *domain.Server
+	Dir         string
+	JavaVersion int
+	Runner      strategy.ServerRunner
+	// Java is the binary of the runtime pinned to the server, or empty to
+	// use the managed runtime for JavaVersion.
+	Java string
+	// JVMArgs are the Java options from the server's JVM profile and custom
+	// arguments.
+	JVMArgs []string
+	// UID is the user the server runs as in hardened mode, otherwise 0.
+	UID int
+}
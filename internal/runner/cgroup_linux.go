@@ -0,0 +1,273 @@
+//go:build linux
+
+package runner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"naviger/internal/domain"
+)
+
+const cgroupMount = "/sys/fs/cgroup"
+
+// cgroupControllers are enabled for the server cgroups when the parent
+// offers them.
+var cgroupControllers = []string{"memory", "cpu", "pids", "io"}
+
+// cgroups puts every server in its own cgroup v2 below the daemon's cgroup.
+// The daemon needs write access there, which systemd grants with
+// Delegate=yes. Without it servers run unconfined.
+type cgroups struct {
+	root string
+	// cloneInto is set when processes can be started directly in a cgroup.
+	cloneInto bool
+	once      sync.Once
+}
+
+func newCgroups() *cgroups { return &cgroups{} }
+
+// Available reports whether servers get their own cgroup.
+func (c *cgroups) Available() bool {
+	c.once.Do(c.setup)
+	return c.root != ""
+}
+
+func (c *cgroups) setup() {
+	if _, err := os.Stat(filepath.Join(cgroupMount, "cgroup.controllers")); err != nil {
+		slog.Info("cgroup v2 is not mounted, server resource limits are disabled")
+		return
+	}
+
+	own, err := ownCgroup()
+	if err != nil {
+		slog.Warn("Could not read the daemon cgroup, server resource limits are disabled", "error", err)
+		return
+	}
+	root := filepath.Join(cgroupMount, own)
+
+	// A cgroup with processes cannot hand controllers to its children, so
+	// the daemon moves into a leaf of its own first.
+	if own != "/" {
+		leaf := filepath.Join(root, "daemon")
+		if err := os.Mkdir(leaf, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+			slog.Warn("Could not create cgroups, server resource limits are disabled", "path", root, "error", err)
+			return
+		}
+		if err := writeCgroupFile(leaf, "cgroup.procs", strconv.Itoa(os.Getpid())); err != nil {
+			slog.Warn("Could not move the daemon to its own cgroup, server resource limits are disabled", "error", err)
+			return
+		}
+	}
+
+	available, err := os.ReadFile(filepath.Join(root, "cgroup.controllers"))
+	if err != nil {
+		slog.Warn("Could not read cgroup controllers, server resource limits are disabled", "error", err)
+		return
+	}
+	var enable []string
+	for _, controller := range cgroupControllers {
+		if slices.Contains(strings.Fields(string(available)), controller) {
+			enable = append(enable, "+"+controller)
+		}
+	}
+	if err := writeCgroupFile(root, "cgroup.subtree_control", strings.Join(enable, " ")); err != nil {
+		slog.Warn("Could not enable cgroup controllers, server resource limits are disabled", "error", err)
+		return
+	}
+
+	c.root = root
+	c.cloneInto = c.probeCloneInto()
+	slog.Info("Servers run in their own cgroups", "path", root, "controllers", enable, "clone_into", c.cloneInto)
+}
+
+// probeCloneInto reports whether processes can be started directly in a
+// cgroup, which needs Linux 5.7 and a seccomp policy that allows clone3.
+func (c *cgroups) probeCloneInto() bool {
+	truePath, err := exec.LookPath("true")
+	if err != nil {
+		return false
+	}
+	dir := filepath.Join(c.root, "probe")
+	if err := os.Mkdir(dir, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+		return false
+	}
+	defer c.remove(dir)
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	cmd := exec.Command(truePath)
+	cmd.SysProcAttr = &syscall.SysProcAttr{UseCgroupFD: true, CgroupFD: int(f.Fd())}
+	return cmd.Run() == nil
+}
+
+// create makes the cgroup of a server with its limits. It returns the
+// cgroup path, or "" when the server runs unconfined.
+func (c *cgroups) create(serverID string, limits domain.ResourceLimits) string {
+	if !c.Available() {
+		return ""
+	}
+
+	dir := filepath.Join(c.root, "server-"+filepath.Base(serverID))
+	if err := os.Mkdir(dir, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+		slog.Warn("Could not create server cgroup", "server", serverID, "error", err)
+		return ""
+	}
+
+	settings := [][2]string{
+		{"memory.max", limitValue(limits.MemoryMaxMB, 1024*1024)},
+		// Kill the whole server, not a single thread, when memory runs out.
+		{"memory.oom.group", "1"},
+		{"cpu.weight", strconv.Itoa(limits.CPUWeight)},
+		{"cpu.max", cpuMax(limits.CPUQuota)},
+		{"pids.max", limitValue(limits.PidsMax, 1)},
+		{"io.weight", "default " + strconv.Itoa(limits.IOWeight)},
+	}
+	for _, setting := range settings {
+		if err := writeCgroupFile(dir, setting[0], setting[1]); err != nil {
+			slog.Warn("Could not set server cgroup limit", "server", serverID, "file", setting[0], "error", err)
+		}
+	}
+	return dir
+}
+
+// startIn makes cmd start inside the cgroup dir, so the server is limited
+// from its first instruction. It returns the opened cgroup, to close once
+// the command started, or nil when the kernel cannot do it and the process
+// is only moved by place.
+func (c *cgroups) startIn(cmd *exec.Cmd, dir string) *os.File {
+	if !c.cloneInto {
+		return nil
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(f.Fd())
+	return f
+}
+
+// place moves a process into the cgroup dir, which does nothing when it was
+// started there. On failure the cgroup is removed and false returned.
+func (c *cgroups) place(dir string, pid int) bool {
+	if err := writeCgroupFile(dir, "cgroup.procs", strconv.Itoa(pid)); err != nil {
+		slog.Warn("Could not move server into its cgroup", "path", dir, "error", err)
+		c.remove(dir)
+		return false
+	}
+	return true
+}
+
+func (c *cgroups) usage(dir string) (*domain.CgroupUsage, error) {
+	usage := &domain.CgroupUsage{}
+
+	memory, err := readCgroupFile(dir, "memory.current")
+	if err != nil {
+		return nil, err
+	}
+	usage.MemoryCurrent, _ = strconv.ParseUint(memory, 10, 64)
+	if pids, err := readCgroupFile(dir, "pids.current"); err == nil {
+		usage.PidsCurrent, _ = strconv.ParseInt(pids, 10, 64)
+	}
+
+	events := readKeyed(dir, "memory.events")
+	usage.MemoryMaxHits = events["max"]
+	usage.OOMKills = events["oom_kill"]
+
+	cpu := readKeyed(dir, "cpu.stat")
+	usage.ThrottledPeriods = cpu["nr_throttled"]
+	usage.ThrottledSeconds = float64(cpu["throttled_usec"]) / 1e6
+	return usage, nil
+}
+
+// remove deletes a server cgroup once its processes are gone. The kernel
+// can take a moment to empty it after the server exits.
+func (c *cgroups) remove(dir string) {
+	var err error
+	for range 10 {
+		if err = os.Remove(dir); err == nil || errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	slog.Warn("Could not remove server cgroup", "path", dir, "error", err)
+}
+
+// ownCgroup returns the cgroup v2 path of the daemon from /proc/self/cgroup.
+func ownCgroup() (string, error) {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return path, nil
+		}
+	}
+	return "", errors.New("no cgroup v2 entry in /proc/self/cgroup")
+}
+
+func limitValue(value, unit int) string {
+	if value < 0 {
+		return "max"
+	}
+	return strconv.FormatInt(int64(value)*int64(unit), 10)
+}
+
+// cpuMax turns a percentage of one core into a quota per 100ms period.
+func cpuMax(percent int) string {
+	const period = 100000
+	if percent <= 0 {
+		return fmt.Sprintf("max %d", period)
+	}
+	return fmt.Sprintf("%d %d", percent*period/100, period)
+}
+
+func writeCgroupFile(dir, name, value string) error {
+	return os.WriteFile(filepath.Join(dir, name), []byte(value), 0644)
+}
+
+func readCgroupFile(dir, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	return strings.TrimSpace(string(data)), err
+}
+
+// readKeyed reads a flat keyed file such as memory.events or cpu.stat.
+func readKeyed(dir, name string) map[string]int64 {
+	values := make(map[string]int64)
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return values
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			values[key] = n
+		}
+	}
+	return values
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+
+	"naviger/internal/domain"
+)
+
+// cgroups is only available on Linux; elsewhere servers run without limits
+// beyond the heap size.
+type cgroups struct{}
+
+func newCgroups() *cgroups { return &cgroups{} }
+
+func (c *cgroups) Available() bool { return false }
+
+func (c *cgroups) create(string, domain.ResourceLimits) string { return "" }
+
+func (c *cgroups) startIn(*exec.Cmd, string) *os.File { return nil }
+
+func (c *cgroups) place(string, int) bool { return false }
+
+func (c *cgroups) usage(string) (*domain.CgroupUsage, error) { return nil, errCgroupsUnavailable }
+
+func (c *cgroups) remove(string) {}
@@ -3,9 +3,180 @@
 package runner
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 )
 
-func prepareCommand(cmd *exec.Cmd) {
-	// No-op on non-Windows systems
+// serverCaps are the capabilities a non-root daemon needs in hardened mode:
+// CAP_CHOWN, CAP_DAC_OVERRIDE, CAP_FOWNER, CAP_SETGID and CAP_SETUID.
+const serverCaps = 1<<0 | 1<<1 | 1<<3 | 1<<6 | 1<<7
+
+// prepareCommand makes the server run as uid, when set, with its folder as
+// home so the JVM does not touch the daemon's.
+func prepareCommand(cmd *exec.Cmd, uid int) {
+	if uid == 0 {
+		return
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(uid)},
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, "HOME="+cmd.Dir)
+}
+
+// ownServerDir hands dir and everything in it to uid, so files written by
+// the daemon since the last start are usable by the server, and closes the
+// folder to other users. Entries the server already owns are left alone, so
+// later starts only touch what changed.
+func ownServerDir(dir string, uid int) error {
+	if uid == 0 {
+		return nil
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return err
+	}
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) == uid && int(stat.Gid) == uid {
+			return nil
+		}
+		return os.Lchown(path, uid, uid)
+	})
+}
+
+// CheckServerUsers verifies that hardened mode can work: the daemon must be
+// able to switch users and bypass file permissions, no existing account may
+// use the UID range, and the server users must be able to reach the servers
+// and runtimes folders. The private paths, when present, are first closed to
+// everyone but the daemon's user.
+func CheckServerUsers(users ServerUsers, serversPath, runtimesPath string, private ...string) error {
+	if users.FirstUID <= 0 || users.Count <= 0 {
+		return fmt.Errorf("invalid hardened UID range %d+%d", users.FirstUID, users.Count)
+	}
+	if err := checkPrivileges(); err != nil {
+		return err
+	}
+	for _, file := range []string{"/etc/passwd", "/etc/group"} {
+		if err := checkIDsUnused(file, users); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range private {
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		mode := os.FileMode(0600)
+		if info.IsDir() {
+			mode = 0700
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			return fmt.Errorf("failed to restrict %s: %w", path, err)
+		}
+	}
+
+	for _, path := range []string{serversPath, runtimesPath} {
+		if err := checkSearchable(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkPrivileges() error {
+	if os.Geteuid() == 0 {
+		return nil
+	}
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return errors.New("hardened mode needs the daemon to run as root")
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "CapEff:")
+		if !ok {
+			continue
+		}
+		caps, err := strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+		if err != nil {
+			return err
+		}
+		if caps&serverCaps != serverCaps {
+			return errors.New("hardened mode needs root or CAP_SETUID, CAP_SETGID, CAP_CHOWN, CAP_DAC_OVERRIDE and CAP_FOWNER; reinstall the service with install.sh")
+		}
+		return nil
+	}
+	return errors.New("hardened mode needs the daemon to run as root")
+}
+
+// checkIDsUnused fails when an entry of a passwd or group file has an ID in
+// the hardened range, which would let that account into a server's folder.
+func checkIDsUnused(path string, users ServerUsers) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 {
+			continue
+		}
+		id, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		if id >= users.FirstUID && id < users.FirstUID+users.Count {
+			return fmt.Errorf("%q in %s uses ID %d, inside the hardened range starting at %d; change hardened_first_uid", fields[0], path, id, users.FirstUID)
+		}
+	}
+	return scanner.Err()
+}
+
+// checkSearchable fails unless path and all its parents can be traversed by
+// other users.
+func checkSearchable(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		info, err := os.Stat(dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err == nil && info.Mode().Perm()&0001 == 0 {
+			return fmt.Errorf("server users cannot reach %s: %s is not searchable by others (chmod o+x, or move the folder)", abs, dir)
+		}
+		if dir == filepath.Dir(dir) {
+			return nil
+		}
+	}
 }
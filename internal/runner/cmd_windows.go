@@ -3,12 +3,22 @@
 package runner
 
 import (
+	"errors"
 	"os/exec"
 	"syscall"
 )
 
-func prepareCommand(cmd *exec.Cmd) {
+func prepareCommand(cmd *exec.Cmd, uid int) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		CreationFlags: 0x08000000,
 	}
 }
+
+func ownServerDir(dir string, uid int) error {
+	return nil
+}
+
+// CheckServerUsers always fails: hardened mode relies on Unix users.
+func CheckServerUsers(users ServerUsers, serversPath, runtimesPath string, private ...string) error {
+	return errors.New("hardened mode is not supported on Windows")
+}
@@ -0,0 +1,227 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"naviger/internal/domain"
+)
+
+const (
+	// containerDir is where the server folder is mounted in the container.
+	containerDir = "/server"
+	// containerStatsTTL is how long engine stats are reused, since asking
+	// the engine takes a while and stats are read by several consumers.
+	containerStatsTTL = 2 * time.Second
+)
+
+// ContainerBackend runs servers in OCI containers through a container engine
+// CLI such as docker or podman. The command built by the server's strategy
+// runs in the container with the server folder bind-mounted, the server port
+// published and the console attached through the engine's stdin and stdout.
+type ContainerBackend struct {
+	Engine string
+	// Image is the image servers run in, where {java} is replaced by the
+	// Java major version the server needs.
+	Image string
+}
+
+func NewContainerBackend(engine, image string) *ContainerBackend {
+	return &ContainerBackend{Engine: engine, Image: image}
+}
+
+func containerName(serverID string) string {
+	return "naviger-" + serverID
+}
+
+func (b *ContainerBackend) Command(launch Launch) (*exec.Cmd, error) {
+	engine, err := exec.LookPath(b.Engine)
+	if err != nil {
+		return nil, fmt.Errorf("container engine %q not found: %w", b.Engine, err)
+	}
+
+	java, err := launch.Runner.BuildCommand("java", launch.Dir, launch.JVMArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	// A container left behind by a daemon that died would hold the name.
+	_ = engineCommand(engine, "rm", "-f", containerName(launch.Server.ID)).Run()
+
+	return engineCommand(engine, b.runArgs(launch, java.Args)...), nil
+}
+
+func (b *ContainerBackend) Preview(launch Launch) ([]string, error) {
+	java, err := launch.Runner.BuildCommand("java", launch.Dir, launch.JVMArgs)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{b.Engine}, b.runArgs(launch, java.Args)...), nil
+}
+
+// runArgs builds the engine arguments that run javaArgs for launch.
+func (b *ContainerBackend) runArgs(launch Launch, javaArgs []string) []string {
+	srv := launch.Server
+	args := []string{
+		"run", "-i",
+		"--name", containerName(srv.ID),
+		"-v", launch.Dir + ":" + containerDir,
+		"-w", containerDir,
+		"-e", "HOME=" + containerDir,
+		"-p", fmt.Sprintf("%d:%d", srv.Port, srv.Port),
+	}
+
+	// Files the server writes must stay usable by the daemon, so it runs as
+	// the daemon's user or its own user in hardened mode rather than root.
+	uid, gid := os.Getuid(), os.Getgid()
+	if launch.UID != 0 {
+		uid, gid = launch.UID, launch.UID
+	}
+	if uid > 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+		if filepath.Base(b.Engine) == "podman" && launch.UID == 0 {
+			args = append(args, "--userns=keep-id")
+		}
+	}
+
+	limits := EffectiveLimits(srv)
+	if limits.MemoryMaxMB > 0 {
+		args = append(args, "--memory", fmt.Sprintf("%dm", limits.MemoryMaxMB))
+	}
+	if limits.CPUQuota > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(float64(limits.CPUQuota)/100, 'f', 2, 64))
+	}
+	if limits.CPUWeight > 0 {
+		// Shares are relative to 1024 where cgroup weights are relative to 100.
+		args = append(args, "--cpu-shares", strconv.Itoa(max(limits.CPUWeight*1024/100, 2)))
+	}
+	if limits.PidsMax > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(limits.PidsMax))
+	}
+
+	args = append(args, strings.ReplaceAll(b.Image, "{java}", strconv.Itoa(launch.JavaVersion)))
+	for _, arg := range javaArgs {
+		args = append(args, containerPath(arg, launch.Dir))
+	}
+	return args
+}
+
+// containerPath rewrites a host path to the server folder inside arg to its
+// path in the container. Forge picks its Windows argument file on Windows
+// hosts, which is swapped for the Unix one shipped next to it.
+func containerPath(arg, dir string) string {
+	i := strings.Index(arg, dir)
+	if i < 0 {
+		return arg
+	}
+	rest := filepath.ToSlash(arg[i+len(dir):])
+	if strings.HasSuffix(rest, "/win_args.txt") {
+		rest = strings.TrimSuffix(rest, "win_args.txt") + "unix_args.txt"
+	}
+	return arg[:i] + containerDir + rest
+}
+
+func (b *ContainerBackend) Started(launch Launch, cmd *exec.Cmd) Execution {
+	return &containerExecution{
+		engine: cmd.Path,
+		name:   containerName(launch.Server.ID),
+	}
+}
+
+type containerExecution struct {
+	engine  string
+	name    string
+	mu      sync.Mutex
+	cpu     float64
+	ram     uint64
+	sampled time.Time
+}
+
+func (e *containerExecution) Stats(stats *domain.ServerStats) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if time.Since(e.sampled) >= containerStatsTTL {
+		out, err := engineCommand(e.engine, "stats", "--no-stream", "--format", "{{.CPUPerc}};{{.MemUsage}}", e.name).Output()
+		if err != nil {
+			return
+		}
+		cpu, ram, err := parseEngineStats(string(out))
+		if err != nil {
+			return
+		}
+		e.cpu, e.ram, e.sampled = cpu, ram, time.Now()
+	}
+	stats.CPU = e.cpu
+	stats.RAM = e.ram
+}
+
+func (e *containerExecution) Exited(exit *domain.ProcessExit) {
+	out, err := engineCommand(e.engine, "inspect", "--format", "{{.State.OOMKilled}}", e.name).Output()
+	if err == nil && strings.TrimSpace(string(out)) == "true" {
+		exit.OOMKilled = true
+	}
+	_ = engineCommand(e.engine, "rm", "-f", e.name).Run()
+}
+
+func engineCommand(engine string, args ...string) *exec.Cmd {
+	cmd := exec.Command(engine, args...)
+	prepareCommand(cmd, 0)
+	return cmd
+}
+
+// parseEngineStats reads a "CPU%;used / limit" line as printed by docker and
+// podman stats.
+func parseEngineStats(out string) (float64, uint64, error) {
+	cpuText, memText, ok := strings.Cut(strings.TrimSpace(out), ";")
+	if !ok {
+		return 0, 0, fmt.Errorf("unexpected stats output %q", out)
+	}
+	cpu, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(cpuText), "%"), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid CPU usage %q", cpuText)
+	}
+	used, _, _ := strings.Cut(memText, "/")
+	ram, err := parseByteSize(strings.TrimSpace(used))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cpu, ram, nil
+}
+
+var byteUnits = map[string]float64{
+	"B":   1,
+	"kB":  1e3,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+}
+
+func parseByteSize(text string) (uint64, error) {
+	i := strings.IndexFunc(text, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i <= 0 {
+		return 0, fmt.Errorf("invalid size %q", text)
+	}
+	value, err := strconv.ParseFloat(text[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", text)
+	}
+	unit, ok := byteUnits[strings.TrimSpace(text[i:])]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit in %q", text)
+	}
+	return uint64(value * unit), nil
+}
@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"slices"
+	"testing"
+
+	"naviger/internal/domain"
+)
+
+func TestParseEngineStats(t *testing.T) {
+	tests := []struct {
+		out  string
+		cpu  float64
+		ram  uint64
+		fail bool
+	}{
+		{out: "12.34%;512MiB / 7.6GiB\n", cpu: 12.34, ram: 512 << 20},
+		{out: "0.00%;1.5GB / 8GB", cpu: 0, ram: 1.5e9},
+		{out: "105.2%;300kB / 1GB", cpu: 105.2, ram: 300e3},
+		{out: "--;-- / --", fail: true},
+		{out: "", fail: true},
+	}
+
+	for _, tt := range tests {
+		cpu, ram, err := parseEngineStats(tt.out)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("parseEngineStats(%q) succeeded, want an error", tt.out)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseEngineStats(%q): %v", tt.out, err)
+			continue
+		}
+		if cpu != tt.cpu || ram != tt.ram {
+			t.Errorf("parseEngineStats(%q) = %v, %d; want %v, %d", tt.out, cpu, ram, tt.cpu, tt.ram)
+		}
+	}
+}
+
+func TestContainerRunArgs(t *testing.T) {
+	backend := NewContainerBackend("docker", "eclipse-temurin:{java}-jre")
+	launch := Launch{
+		Server:      &domain.Server{ID: "abc", Port: 25570, RAM: 2048},
+		Dir:         "/srv/naviger/servers/survival",
+		JavaVersion: 21,
+		UID:         200001,
+	}
+	args := backend.runArgs(launch, []string{"java", "-Xmx2048M", "@/srv/naviger/servers/survival/libraries/net/forge/unix_args.txt", "nogui"})
+
+	for _, want := range [][]string{
+		{"--name", "naviger-abc"},
+		{"-v", "/srv/naviger/servers/survival:/server"},
+		{"-p", "25570:25570"},
+		{"--user", "200001:200001"},
+		{"--memory", "2560m"},
+	} {
+		i := slices.Index(args, want[0])
+		if i < 0 || i+1 >= len(args) || args[i+1] != want[1] {
+			t.Errorf("args %v lack %s %s", args, want[0], want[1])
+		}
+	}
+
+	image := slices.Index(args, "eclipse-temurin:21-jre")
+	if image < 0 {
+		t.Fatalf("args %v lack the image", args)
+	}
+	command := args[image+1:]
+	want := []string{"java", "-Xmx2048M", "@/server/libraries/net/forge/unix_args.txt", "nogui"}
+	if !slices.Equal(command, want) {
+		t.Errorf("command = %v, want %v", command, want)
+	}
+}
@@ -0,0 +1,32 @@
+package runner
+
+import (
+	"fmt"
+
+	"naviger/internal/domain"
+	"naviger/internal/jvmflags"
+)
+
+// JVMArgs returns the Java options srv starts with on the given Java major
+// version: the flags of its JVM profile followed by its custom arguments,
+// checked against that version.
+func JVMArgs(srv *domain.Server, java int) ([]string, error) {
+	profile, ok := jvmflags.Get(srv.JVMProfile)
+	if !ok {
+		return nil, fmt.Errorf("unknown JVM profile %q", srv.JVMProfile)
+	}
+	if java < profile.MinJava {
+		return nil, fmt.Errorf("JVM profile %s needs Java %d or newer, the server uses Java %d", profile.Name, profile.MinJava, java)
+	}
+
+	custom, err := jvmflags.ParseArgs(srv.CustomArgs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid custom arguments: %w", err)
+	}
+
+	args := append(profile.Flags(srv.RAM, java), custom...)
+	if err := jvmflags.Check(args, java); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+
+	"naviger/internal/domain"
+)
+
+const (
+	defaultCPUWeight = 100
+	defaultIOWeight  = 100
+	defaultPidsMax   = 4096
+	// minMemoryOverheadMB is the least memory allowed on top of the heap for
+	// metaspace, thread stacks, direct buffers and native libraries.
+	minMemoryOverheadMB = 512
+)
+
+var errCgroupsUnavailable = errors.New("cgroups are not available")
+
+// EffectiveLimits fills in the defaults of a server's limits: memory is the
+// heap plus a quarter of it (at least 512 MB) for off-heap use. Unlimited
+// values stay -1 and a CPUQuota of 0 means no quota.
+func EffectiveLimits(srv *domain.Server) domain.ResourceLimits {
+	limits := srv.Limits
+	if limits.MemoryMaxMB == 0 {
+		limits.MemoryMaxMB = srv.RAM + max(srv.RAM/4, minMemoryOverheadMB)
+	}
+	if limits.CPUWeight == 0 {
+		limits.CPUWeight = defaultCPUWeight
+	}
+	if limits.IOWeight == 0 {
+		limits.IOWeight = defaultIOWeight
+	}
+	if limits.PidsMax == 0 {
+		limits.PidsMax = defaultPidsMax
+	}
+	return limits
+}
+
+// ValidateLimits checks limits for a server with ram MB of heap.
+func ValidateLimits(ram int, limits domain.ResourceLimits) error {
+	if limits.MemoryMaxMB < -1 || (limits.MemoryMaxMB > 0 && limits.MemoryMaxMB <= ram) {
+		return fmt.Errorf("memoryMaxMb must be above the %d MB heap, 0 for the default or -1 for no limit", ram)
+	}
+	if limits.CPUWeight < 0 || limits.CPUWeight > 10000 {
+		return fmt.Errorf("cpuWeight must be between 1 and 10000, or 0 for the default")
+	}
+	if limits.IOWeight < 0 || limits.IOWeight > 10000 {
+		return fmt.Errorf("ioWeight must be between 1 and 10000, or 0 for the default")
+	}
+	if limits.CPUQuota < 0 {
+		return fmt.Errorf("cpuQuota must be a percentage of one core, or 0 for no quota")
+	}
+	if limits.PidsMax < -1 {
+		return fmt.Errorf("pidsMax must be positive, 0 for the default or -1 for no limit")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+
+	"naviger/internal/domain"
+	"naviger/internal/jvm"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// processBackend runs servers as child processes of the daemon with a Java
+// runtime managed on the host, each in its own cgroup where available.
+type processBackend struct {
+	jvm     *jvm.Manager
+	cgroups *cgroups
+
+	mu sync.Mutex
+	// launching holds the cgroup of each server between Command and Started.
+	launching map[string]launchCgroup
+}
+
+type launchCgroup struct {
+	dir string
+	// file is the open cgroup the process starts in, nil when it is moved
+	// there after starting.
+	file *os.File
+}
+
+func (b *processBackend) Command(launch Launch) (*exec.Cmd, error) {
+	javaPath := launch.Java
+	if javaPath == "" {
+		var err error
+		javaPath, err = b.jvm.EnsureJava(launch.JavaVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error preparing Java: %w", err)
+		}
+	}
+
+	cmd, err := launch.Runner.BuildCommand(javaPath, launch.Dir, launch.JVMArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	prepareCommand(cmd, launch.UID)
+	if dir := b.cgroups.create(launch.Server.ID, EffectiveLimits(launch.Server)); dir != "" {
+		b.setLaunching(launch.Server.ID, launchCgroup{dir: dir, file: b.cgroups.startIn(cmd, dir)})
+	}
+	return cmd, nil
+}
+
+// Preview shows the installed Java binary, or plain java when the runtime is
+// only downloaded on the next start.
+func (b *processBackend) Preview(launch Launch) ([]string, error) {
+	javaPath := launch.Java
+	if javaPath == "" {
+		var ok bool
+		if javaPath, ok = b.jvm.InstalledJava(launch.JavaVersion); !ok {
+			javaPath = "java"
+		}
+	}
+
+	cmd, err := launch.Runner.BuildCommand(javaPath, launch.Dir, launch.JVMArgs)
+	if err != nil {
+		return nil, err
+	}
+	return cmd.Args, nil
+}
+
+func (b *processBackend) Started(launch Launch, cmd *exec.Cmd) Execution {
+	run := &processExecution{cgroups: b.cgroups, pid: cmd.Process.Pid}
+	b.mu.Lock()
+	launching, ok := b.launching[launch.Server.ID]
+	delete(b.launching, launch.Server.ID)
+	b.mu.Unlock()
+	if !ok {
+		return run
+	}
+
+	if launching.file != nil {
+		launching.file.Close()
+	}
+	// Kernels that cannot start a process in a cgroup get it moved there now.
+	if b.cgroups.place(launching.dir, run.pid) {
+		run.cgroup = launching.dir
+	}
+	return run
+}
+
+// setLaunching remembers the cgroup a server is about to start in, closing
+// the one left over from a start that failed.
+func (b *processBackend) setLaunching(serverID string, cgroup launchCgroup) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.launching == nil {
+		b.launching = make(map[string]launchCgroup)
+	}
+	if previous, ok := b.launching[serverID]; ok && previous.file != nil {
+		previous.file.Close()
+	}
+	b.launching[serverID] = cgroup
+}
+
+type processExecution struct {
+	cgroups *cgroups
+	pid     int
+	// cgroup is the path of the server's cgroup, "" when it runs unconfined.
+	cgroup string
+}
+
+func (e *processExecution) Stats(stats *domain.ServerStats) {
+	if e.cgroup != "" {
+		if usage, err := e.cgroups.usage(e.cgroup); err == nil {
+			stats.Cgroup = usage
+		}
+	}
+
+	p, err := process.NewProcess(int32(e.pid))
+	if err != nil {
+		return
+	}
+	if cpu, err := p.CPUPercent(); err == nil {
+		stats.CPU = cpu
+	}
+	if mem, err := p.MemoryInfo(); err == nil {
+		stats.RAM = mem.RSS
+	}
+}
+
+func (e *processExecution) Exited(exit *domain.ProcessExit) {
+	if e.cgroup == "" {
+		return
+	}
+	if usage, err := e.cgroups.usage(e.cgroup); err == nil && usage.OOMKills > 0 {
+		exit.OOMKilled = true
+	}
+	e.cgroups.remove(e.cgroup)
+}
@@ -2,6 +2,20 @@ package strategy
 
 import "os/exec"
 
+// ServerRunner builds the command that launches a kind of server. jvmArgs
+// are the Java options, heap sizes included, that go before the runner's own
+// arguments.
 type ServerRunner interface {
-	BuildCommand(javaPath string, serverDir string, ram int, customArgs string) (*exec.Cmd, error)
+	BuildCommand(javaPath string, serverDir string, jvmArgs []string) (*exec.Cmd, error)
+}
+
+// PortConfigurer is implemented by runners whose software does not read its
+// listen port from server.properties.
+type PortConfigurer interface {
+	ConfigurePort(serverDir string, port int) error
+}
+
+// StopCommander is implemented by runners whose console does not accept "stop".
+type StopCommander interface {
+	StopCommand() string
 }
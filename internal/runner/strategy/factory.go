@@ -1,12 +1,35 @@
 package strategy
 
+import "sync"
+
+type RunnerFactory func() ServerRunner
+
+var (
+	runnersMu sync.RWMutex
+	runners   = map[string]RunnerFactory{
+		"forge":      func() ServerRunner { return &ForgeRunner{} },
+		"neoforge":   func() ServerRunner { return &ForgeRunner{} },
+		"velocity":   func() ServerRunner { return &ProxyRunner{JarName: "server.jar", Flavor: ProxyVelocity} },
+		"bungeecord": func() ServerRunner { return &ProxyRunner{JarName: "server.jar", Flavor: ProxyBungeeCord} },
+		"waterfall":  func() ServerRunner { return &ProxyRunner{JarName: "server.jar", Flavor: ProxyBungeeCord} },
+	}
+)
+
+// RegisterRunner associates a loader type with the runner used to launch it.
+// Loader types without a registered runner are started as a plain jar.
+func RegisterRunner(loaderType string, factory RunnerFactory) {
+	runnersMu.Lock()
+	defer runnersMu.Unlock()
+	runners[loaderType] = factory
+}
+
 func GetRunner(loaderType string) ServerRunner {
-	switch loaderType {
-	case "forge", "neoforge":
-		return &ForgeRunner{}
-	case "paper", "vanilla", "fabric":
-		return &VanillaRunner{JarName: "server.jar"}
-	default:
-		return &VanillaRunner{JarName: "server.jar"}
+	runnersMu.RLock()
+	factory, ok := runners[loaderType]
+	runnersMu.RUnlock()
+
+	if ok {
+		return factory()
 	}
+	return &VanillaRunner{JarName: "server.jar"}
 }
@@ -0,0 +1,108 @@
+package strategy
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+type ProxyFlavor string
+
+const (
+	ProxyVelocity   ProxyFlavor = "velocity"
+	ProxyBungeeCord ProxyFlavor = "bungeecord"
+)
+
+// DefaultVelocityConfig is the velocity.toml Velocity 3 writes on first start.
+//
+//go:embed velocity.toml
+var DefaultVelocityConfig []byte
+
+var (
+	velocityBindPattern = regexp.MustCompile(`(?m)^bind\s*=.*$`)
+	bungeeHostPattern   = regexp.MustCompile(`(?m)^(\s*-?\s*host:\s*)\S+`)
+	bungeeQueryPattern  = regexp.MustCompile(`(?m)^(\s*query_port:\s*)\d+`)
+)
+
+// ProxyRunner launches Velocity and BungeeCord-style proxies. They have no
+// server.properties, do not accept "nogui" and shut down with "end".
+type ProxyRunner struct {
+	JarName string
+	Flavor  ProxyFlavor
+}
+
+func (r *ProxyRunner) BuildCommand(javaPath string, absServerDir string, jvmArgs []string) (*exec.Cmd, error) {
+	jarPath := r.JarName
+	if jarPath == "" {
+		jarPath = "server.jar"
+	}
+
+	jarFull := filepath.Join(absServerDir, jarPath)
+	if _, err := os.Stat(jarFull); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("proxy jar not found at %s", jarFull)
+		}
+		return nil, fmt.Errorf("error accessing %s: %w", jarFull, err)
+	}
+
+	args := append(slices.Clone(jvmArgs), "-jar", jarPath)
+
+	cmd := exec.Command(javaPath, args...)
+	cmd.Dir = absServerDir
+	return cmd, nil
+}
+
+func (r *ProxyRunner) StopCommand() string {
+	return "end"
+}
+
+func (r *ProxyRunner) ConfigurePort(absServerDir string, port int) error {
+	switch r.Flavor {
+	case ProxyVelocity:
+		return configureVelocityPort(filepath.Join(absServerDir, "velocity.toml"), port)
+	default:
+		return configureBungeePort(filepath.Join(absServerDir, "config.yml"), port)
+	}
+}
+
+// configureVelocityPort rewrites the bind address of velocity.toml. A proxy
+// that has not run yet gets Velocity's default config, which it would
+// otherwise only write when the file is missing.
+func configureVelocityPort(path string, port int) error {
+	bind := fmt.Sprintf(`bind = "0.0.0.0:%d"`, port)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		data, err = DefaultVelocityConfig, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	content := string(data)
+	if velocityBindPattern.MatchString(content) {
+		content = velocityBindPattern.ReplaceAllLiteralString(content, bind)
+	} else {
+		content = bind + "\n" + content
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+func configureBungeePort(path string, port int) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		content := fmt.Sprintf("listeners:\n- host: 0.0.0.0:%d\n  query_port: %d\n", port, port)
+		return os.WriteFile(path, []byte(content), 0644)
+	}
+	if err != nil {
+		return err
+	}
+
+	content := bungeeHostPattern.ReplaceAllString(string(data), fmt.Sprintf("${1}0.0.0.0:%d", port))
+	content = bungeeQueryPattern.ReplaceAllString(content, fmt.Sprintf("${1}%d", port))
+	return os.WriteFile(path, []byte(content), 0644)
+}
@@ -5,14 +5,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
+	"slices"
 )
 
 type VanillaRunner struct {
 	JarName string
 }
 
-func (r *VanillaRunner) BuildCommand(javaPath string, absServerDir string, ram int, customArgs string) (*exec.Cmd, error) {
+func (r *VanillaRunner) BuildCommand(javaPath string, absServerDir string, jvmArgs []string) (*exec.Cmd, error) {
 	jarPath := r.JarName
 	if jarPath == "" {
 		jarPath = "server.jar"
@@ -26,16 +26,7 @@ func (r *VanillaRunner) BuildCommand(javaPath string, absServerDir string, ram i
 		return nil, fmt.Errorf("error accessing %s: %w", jarFull, err)
 	}
 
-	args := []string{
-		fmt.Sprintf("-Xmx%dM", ram),
-		"-Xms512M",
-	}
-
-	if customArgs != "" {
-		args = append(args, strings.Fields(customArgs)...)
-	}
-
-	args = append(args, "-jar", jarPath, "nogui")
+	args := append(slices.Clone(jvmArgs), "-jar", jarPath, "nogui")
 
 	cmd := exec.Command(javaPath, args...)
 	cmd.Dir = absServerDir
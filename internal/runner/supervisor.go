@@ -8,54 +8,115 @@ import (
 	"io"
 	"log/slog"
 	"naviger/internal/jvm"
+	"naviger/internal/logevents"
+	"naviger/internal/properties"
 	"naviger/internal/runner/strategy"
 	"naviger/internal/server"
 	"naviger/internal/storage"
 	"naviger/internal/ws"
 	"net"
-	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 	"sync"
+	"time"
 
 	"naviger/internal/domain"
-
-	"github.com/shirou/gopsutil/v3/process"
 )
 
+// statsInterval is how often protocol console clients receive a stats frame.
+const statsInterval = 5 * time.Second
+
 type Supervisor struct {
 	Store       *storage.GormStore
 	JVM         *jvm.Manager
 	HubManager  *ws.HubManager
 	ServersPath string
-	processes   map[string]*ActiveProcess
-	mu          sync.Mutex
+	// Users enables hardened mode, running every server as its own user.
+	Users *ServerUsers
+	// Disk provides cached folder sizes for stats; without it disk usage is
+	// reported as 0.
+	Disk *server.DiskSampler
+	// Ticks provides the measured tick rate for stats, when set.
+	Ticks     TickSource
+	cgroups   *cgroups
+	backends  map[string]Backend
+	processes map[string]*ActiveProcess
+	// starting holds the servers between the start request and their
+	// process being registered.
+	starting  map[string]bool
+	starts    map[string]int
+	observers []LineObserver
+	mu        sync.Mutex
+	// uidMu serializes UID allocation, which runs outside mu.
+	uidMu sync.Mutex
+}
+
+// LineObserver is notified of every console line a server prints and of the
+// server process exiting. Calls are made from the output readers, so
+// implementations should return quickly.
+type LineObserver interface {
+	ObserveLine(serverID, line string)
+	ServerExited(serverID string, exit domain.ProcessExit)
+}
+
+// TickSource reports the last measured ticks per second and milliseconds
+// per tick of a running server.
+type TickSource interface {
+	Tick(serverID string) (tps, mspt float64, ok bool)
 }
 
 type ActiveProcess struct {
-	Cmd    *exec.Cmd
-	Stdin  io.WriteCloser
-	Cancel context.CancelFunc
+	Cmd           *exec.Cmd
+	Stdin         io.WriteCloser
+	Cancel        context.CancelFunc
+	StopCommand   string
+	StartedAt     time.Time
+	stopRequested bool
+	run           Execution
 }
 
 func NewSupervisor(store *storage.GormStore, jvm *jvm.Manager, hubManager *ws.HubManager, serversPath string) *Supervisor {
-	return &Supervisor{
+	s := &Supervisor{
 		Store:       store,
 		JVM:         jvm,
 		HubManager:  hubManager,
 		ServersPath: serversPath,
+		cgroups:     newCgroups(),
+		backends:    make(map[string]Backend),
 		processes:   make(map[string]*ActiveProcess),
+		starting:    make(map[string]bool),
+		starts:      make(map[string]int),
 	}
+	s.backends[domain.RunModeProcess] = &processBackend{jvm: jvm, cgroups: s.cgroups}
+	return s
 }
 
-func (s *Supervisor) StartServer(serverID string) error {
+// SetBackend makes servers in the given run mode execute through backend.
+func (s *Supervisor) SetBackend(mode string, backend Backend) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.backends[mode] = backend
+}
 
-	if _, exists := s.processes[serverID]; exists {
+func (s *Supervisor) AddObserver(observer LineObserver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, observer)
+}
+
+func (s *Supervisor) StartServer(serverID string) error {
+	s.mu.Lock()
+	if _, exists := s.processes[serverID]; exists || s.starting[serverID] {
+		s.mu.Unlock()
 		return fmt.Errorf("server is already running")
 	}
+	s.starting[serverID] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.starting, serverID)
+		s.mu.Unlock()
+	}()
 
 	srv, err := s.Store.GetServerByID(serverID)
 	if err != nil {
@@ -65,16 +126,29 @@ func (s *Supervisor) StartServer(serverID string) error {
 		return fmt.Errorf("server not found")
 	}
 
-	folderName := srv.FolderName
-	if folderName == "" {
-		folderName = srv.ID
+	// Handing a large folder to the server's user can take a while, so it
+	// happens before taking s.mu; the starting guard keeps a second start of
+	// the same server out meanwhile.
+	uid, err := s.serverUID(srv)
+	if err != nil {
+		return err
+	}
+	absServerDir, err := serverDir(s.ServersPath, srv)
+	if err != nil {
+		return err
+	}
+	if err := ownServerDir(absServerDir, uid); err != nil {
+		return fmt.Errorf("failed to hand the server folder to its user: %w", err)
 	}
 
-	serverDir := filepath.Join(s.ServersPath, folderName)
-	absServerDir, err := filepath.Abs(serverDir)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backend, launch, err := s.prepareLaunch(srv)
 	if err != nil {
-		return fmt.Errorf("error getting absolute path for server: %w", err)
+		return err
 	}
+	runner := launch.Runner
 
 	if err := checkPortAvailable(srv.Port); err != nil {
 		slog.Info("Port is busy, attempting to allocate a new one", "port", srv.Port)
@@ -90,25 +164,27 @@ func (s *Supervisor) StartServer(serverID string) error {
 		slog.Info("Reassigned server to new port", "server", srv.Name, "port", newPort)
 	}
 
-	configFile := filepath.Join(absServerDir, "server.properties")
-	if err := ensurePortInProperties(configFile, srv.Port); err != nil {
-		slog.Warn("Could not update server.properties", "error", err)
+	if portConfigurer, ok := runner.(strategy.PortConfigurer); ok {
+		if err := portConfigurer.ConfigurePort(absServerDir, srv.Port); err != nil {
+			slog.Warn("Could not update proxy port configuration", "error", err)
+		}
+	} else {
+		if err := properties.SetPort(absServerDir, srv.Port); err != nil {
+			slog.Warn("Could not update server.properties", "error", err)
+		}
 	}
 
-	requiredJava := GetJavaVersionForMC(srv.Version)
-	javaPath, err := s.JVM.EnsureJava(requiredJava)
-	if err != nil {
-		return fmt.Errorf("error preparing Java: %w", err)
+	stopCommand := "stop"
+	if stopper, ok := runner.(strategy.StopCommander); ok {
+		stopCommand = stopper.StopCommand()
 	}
 
-	runner := strategy.GetRunner(srv.Loader)
-	cmd, err := runner.BuildCommand(javaPath, absServerDir, srv.RAM, srv.CustomArgs)
+	launch.UID = uid
+	cmd, err := backend.Command(launch)
 	if err != nil {
 		return err
 	}
 
-	prepareCommand(cmd)
-
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return err
@@ -125,31 +201,49 @@ func (s *Supervisor) StartServer(serverID string) error {
 	}
 
 	hub := s.HubManager.GetHub(serverID)
+	observers := append([]LineObserver(nil), s.observers...)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	go func() {
-		scanner := bufio.NewScanner(stdout)
+	// The process is only waited on once both readers reach EOF, so the last
+	// lines before an exit (usually the interesting ones after a crash) are
+	// never cut off.
+	var readers sync.WaitGroup
+	readers.Add(2)
+	readOutput := func(r io.Reader, stream string) {
+		defer readers.Done()
+		scanner := bufio.NewScanner(r)
 		for scanner.Scan() {
 			select {
 			case <-ctx.Done():
 				return
 			default:
 				text := scanner.Text()
-				hub.Broadcast([]byte(text))
+				hub.PublishLog(text, stream, logevents.Level(text))
+				for _, observer := range observers {
+					observer.ObserveLine(serverID, text)
+				}
 			}
 		}
-	}()
+	}
+
+	go readOutput(stdout, ws.StreamStdout)
+	go readOutput(stderr, ws.StreamStderr)
 
 	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
+		ticker := time.NewTicker(statsInterval)
+		defer ticker.Stop()
+		for {
 			select {
 			case <-ctx.Done():
 				return
-			default:
-				text := scanner.Text()
-				hub.Broadcast([]byte(text))
+			case <-ticker.C:
+				if !hub.HasStructuredClients() {
+					continue
+				}
+				if stats, err := s.GetServerStats(serverID); err == nil {
+					hub.PublishStats(stats)
+				}
 			}
 		}
 	}()
@@ -163,7 +257,10 @@ func (s *Supervisor) StartServer(serverID string) error {
 				if !ok {
 					return
 				}
-				_, err := stdin.Write(command)
+				_, err := stdin.Write(command.Data)
+				if command.Result != nil {
+					command.Result <- err
+				}
 				if err != nil {
 					return
 				}
@@ -176,41 +273,56 @@ func (s *Supervisor) StartServer(serverID string) error {
 		return fmt.Errorf("failed to start: %w", err)
 	}
 
-	if err := s.Store.UpdateStatus(serverID, "RUNNING"); err != nil {
-		slog.Warn("could not update status to RUNNING", "error", err)
-	}
+	s.setStatus(serverID, "RUNNING")
 
-	s.processes[serverID] = &ActiveProcess{
-		Cmd:    cmd,
-		Stdin:  stdin,
-		Cancel: cancel,
+	proc := &ActiveProcess{
+		Cmd:         cmd,
+		Stdin:       stdin,
+		Cancel:      cancel,
+		StopCommand: stopCommand,
+		StartedAt:   time.Now(),
+		run:         backend.Started(launch, cmd),
 	}
+	s.processes[serverID] = proc
+	s.starts[serverID]++
 
 	go func(id string, c *exec.Cmd, cancelFunc context.CancelFunc) {
+		readers.Wait()
 		err := c.Wait()
 		cancelFunc()
 
 		s.mu.Lock()
 		delete(s.processes, id)
+		exit := domain.ProcessExit{
+			ExitCode:      0,
+			StopRequested: proc.stopRequested,
+			StartedAt:     proc.StartedAt,
+			ExitedAt:      time.Now(),
+		}
 		s.mu.Unlock()
 
-		if s.HubManager != nil {
-			s.HubManager.RemoveHub(id)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exit.ExitCode = exitErr.ExitCode()
+		} else if err != nil {
+			exit.ExitCode = -1
 		}
 
-		if err == nil {
-			if uerr := s.Store.UpdateStatus(id, "STOPPED"); uerr != nil {
-				slog.Warn("could not update status to STOPPED", "error", uerr)
-			}
-			return
+		proc.run.Exited(&exit)
+		if exit.OOMKilled {
+			slog.Warn("Server was killed by its memory limit", "server", id)
 		}
 
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
-			_ = exitErr.ExitCode()
-			if uerr := s.Store.UpdateStatus(id, "STOPPED"); uerr != nil {
-				slog.Warn("could not update status to STOPPED", "error", uerr)
-			}
+		if err == nil || exitErr != nil {
+			s.setStatus(id, "STOPPED")
+		}
+
+		if s.HubManager != nil {
+			s.HubManager.RemoveHub(id)
+		}
+
+		for _, observer := range observers {
+			observer.ServerExited(id, exit)
 		}
 
 	}(serverID, cmd, cancel)
@@ -218,22 +330,122 @@ func (s *Supervisor) StartServer(serverID string) error {
 	return nil
 }
 
+// prepareLaunch resolves how srv starts: the backend of its run mode and the
+// launch handed to it. It must be called with s.mu held.
+func (s *Supervisor) prepareLaunch(srv *domain.Server) (Backend, Launch, error) {
+	mode := srv.RunMode
+	if mode == "" {
+		mode = domain.RunModeProcess
+	}
+	backend, ok := s.backends[mode]
+	if !ok {
+		return nil, Launch{}, fmt.Errorf("run mode %q is not available", mode)
+	}
+
+	absServerDir, err := serverDir(s.ServersPath, srv)
+	if err != nil {
+		return nil, Launch{}, err
+	}
+
+	requiredJava := RequiredJava(srv)
+	var javaPath string
+	if srv.JavaRuntime != "" {
+		pinned, err := s.JVM.Runtime(srv.JavaRuntime)
+		if err != nil {
+			return nil, Launch{}, err
+		}
+		requiredJava, javaPath = pinned.Major, pinned.Java
+	}
+
+	jvmArgs, err := JVMArgs(srv, requiredJava)
+	if err != nil {
+		return nil, Launch{}, err
+	}
+
+	return backend, Launch{
+		Server:      srv,
+		Dir:         absServerDir,
+		JavaVersion: requiredJava,
+		Runner:      strategy.GetRunner(srv.Loader),
+		Java:        javaPath,
+		JVMArgs:     jvmArgs,
+		UID:         srv.UID,
+	}, nil
+}
+
+// serverDir returns the absolute path of srv's folder.
+func serverDir(serversPath string, srv *domain.Server) (string, error) {
+	folderName := srv.FolderName
+	if folderName == "" {
+		folderName = srv.ID
+	}
+	absServerDir, err := filepath.Abs(filepath.Join(serversPath, folderName))
+	if err != nil {
+		return "", fmt.Errorf("error getting absolute path for server: %w", err)
+	}
+	return absServerDir, nil
+}
+
+// PreviewCommand returns the command line a server would start with now,
+// along with the Java version it needs.
+func (s *Supervisor) PreviewCommand(serverID string) ([]string, int, error) {
+	srv, err := s.Store.GetServerByID(serverID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if srv == nil {
+		return nil, 0, fmt.Errorf("server not found")
+	}
+
+	s.mu.Lock()
+	backend, launch, err := s.prepareLaunch(srv)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args, err := backend.Preview(launch)
+	if err != nil {
+		return nil, 0, err
+	}
+	return args, launch.JavaVersion, nil
+}
+
 func (s *Supervisor) StopServer(serverID string) error {
 	s.mu.Lock()
 	proc, exists := s.processes[serverID]
+	if exists {
+		proc.stopRequested = true
+	}
 	s.mu.Unlock()
 
 	if !exists {
 		return fmt.Errorf("server is not running")
 	}
 
-	if err := s.Store.UpdateStatus(serverID, "STOPPING"); err != nil {
-		slog.Warn("could not update status to STOPPING", "error", err)
-	}
-	_, err := io.WriteString(proc.Stdin, "stop\n")
+	s.setStatus(serverID, "STOPPING")
+	_, err := io.WriteString(proc.Stdin, proc.StopCommand+"\n")
 	return err
 }
 
+// setStatus stores a status change and announces it on the server's console
+// hub.
+func (s *Supervisor) setStatus(serverID, status string) {
+	if err := s.Store.UpdateStatus(serverID, status); err != nil {
+		slog.Warn("could not update status to "+status, "error", err)
+	}
+	if s.HubManager != nil {
+		s.HubManager.GetHub(serverID).PublishStatus(status)
+	}
+}
+
+func (s *Supervisor) IsRunning(serverID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.processes[serverID]
+	return exists
+}
+
 func (s *Supervisor) SendCommand(serverID string, cmd string) error {
 	s.mu.Lock()
 	proc, exists := s.processes[serverID]
@@ -258,42 +470,54 @@ func (s *Supervisor) GetServerStats(serverID string) (*domain.ServerStats, error
 		Disk: 0,
 	}
 
-	srv, err := s.Store.GetServerByID(serverID)
-	if err == nil && srv != nil {
-		folderName := srv.FolderName
-		if folderName == "" {
-			folderName = srv.ID
-		}
-		serverDir := filepath.Join(s.ServersPath, folderName)
-		var size int64
-		_ = filepath.Walk(serverDir, func(_ string, info os.FileInfo, err error) error {
-			if err == nil && !info.IsDir() {
-				size += info.Size()
-			}
-			return nil
-		})
-		stats.Disk = size
+	if s.Disk != nil {
+		stats.Disk = s.Disk.Size(serverID)
 	}
 
 	if !exists {
 		return stats, nil
 	}
 
-	if proc.Cmd != nil && proc.Cmd.Process != nil {
-		p, err := process.NewProcess(int32(proc.Cmd.Process.Pid))
-		if err == nil {
-			if cpu, err := p.CPUPercent(); err == nil {
-				stats.CPU = cpu
-			}
-			if mem, err := p.MemoryInfo(); err == nil {
-				stats.RAM = mem.RSS
-			}
+	if s.Ticks != nil {
+		if tps, mspt, ok := s.Ticks.Tick(serverID); ok {
+			stats.TPS, stats.MSPT = tps, mspt
 		}
 	}
 
+	proc.run.Stats(stats)
+
 	return stats, nil
 }
 
+// CgroupsAvailable reports whether servers run in their own cgroup with
+// resource limits.
+func (s *Supervisor) CgroupsAvailable() bool {
+	return s.cgroups.Available()
+}
+
+// Uptime returns how long a server process has been running, and false when
+// it is not running.
+func (s *Supervisor) Uptime(serverID string) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	proc, ok := s.processes[serverID]
+	if !ok {
+		return 0, false
+	}
+	return time.Since(proc.StartedAt), true
+}
+
+// Restarts returns how many times a server has been started again since the
+// daemon launched.
+func (s *Supervisor) Restarts(serverID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.starts[serverID] == 0 {
+		return 0
+	}
+	return s.starts[serverID] - 1
+}
+
 func (s *Supervisor) GetAllServerStats() (map[string]domain.ServerStats, error) {
 	servers, err := s.Store.ListServers()
 	if err != nil {
@@ -332,63 +556,6 @@ func (s *Supervisor) ResetRunningStates() error {
 	return nil
 }
 
-func ensurePortInProperties(path string, port int) error {
-	props := make(map[string]string)
-	var lines []string
-
-	if file, err := os.Open(path); err == nil {
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			lines = append(lines, line)
-
-			if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				val := strings.TrimSpace(parts[1])
-				props[key] = val
-			}
-		}
-		file.Close()
-	}
-
-	portStr := fmt.Sprintf("%d", port)
-	if currentVal, ok := props["server-port"]; ok && currentVal == portStr {
-		return nil
-	}
-
-	var newContent []string
-	portUpdated := false
-
-	for _, line := range lines {
-		if strings.HasPrefix(strings.TrimSpace(line), "server-port=") || strings.HasPrefix(strings.TrimSpace(line), "server-port =") {
-			newContent = append(newContent, fmt.Sprintf("server-port=%s", portStr))
-			portUpdated = true
-		} else {
-			newContent = append(newContent, line)
-		}
-	}
-
-	if !portUpdated {
-		newContent = append(newContent, fmt.Sprintf("server-port=%s", portStr))
-	}
-
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	for _, line := range newContent {
-		writer.WriteString(line + "\n")
-	}
-	return writer.Flush()
-}
-
 func checkPortAvailable(port int) error {
 	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"fmt"
+
+	"naviger/internal/domain"
+)
+
+// ServerUsers enables hardened mode: every server runs as its own system
+// user and group, allocated from the Count IDs starting at FirstUID, which
+// owns the server folder. The daemon keeps access to every folder through its
+// privileges, so the file API and backups work as before.
+type ServerUsers struct {
+	FirstUID int
+	Count    int
+}
+
+// serverUID returns the UID srv runs as, allocating the lowest free one on
+// its first start in hardened mode.
+func (s *Supervisor) serverUID(srv *domain.Server) (int, error) {
+	if s.Users == nil {
+		return 0, nil
+	}
+	if srv.UID != 0 {
+		return srv.UID, nil
+	}
+
+	s.uidMu.Lock()
+	defer s.uidMu.Unlock()
+
+	servers, err := s.Store.ListServers()
+	if err != nil {
+		return 0, err
+	}
+	used := make(map[int]bool, len(servers))
+	for _, other := range servers {
+		used[other.UID] = true
+	}
+
+	last := s.Users.FirstUID + s.Users.Count - 1
+	for uid := s.Users.FirstUID; uid <= last; uid++ {
+		if used[uid] {
+			continue
+		}
+		if err := s.Store.UpdateServerUID(srv.ID, uid); err != nil {
+			return 0, fmt.Errorf("failed to save server UID: %w", err)
+		}
+		srv.UID = uid
+		return uid, nil
+	}
+	return 0, fmt.Errorf("no free server UID left between %d and %d", s.Users.FirstUID, last)
+}
@@ -3,37 +3,79 @@ package runner
 import (
 	"strconv"
 	"strings"
+
+	"naviger/internal/domain"
+	"naviger/internal/loader"
 )
 
-// GetJavaVersionForMC
-// 1.20.5+ -> Java 21
-// 1.18+   -> Java 17
-// < 1.18  -> Java 8
+// RequiredJava is the Java major version a server needs when it has no
+// pinned runtime: the one recorded when it was created, otherwise a guess
+// from its version.
+func RequiredJava(srv *domain.Server) int {
+	if srv.JavaVersion > 0 {
+		return srv.JavaVersion
+	}
+	if loader.IsProxy(srv.Loader) {
+		return loader.ProxyJavaVersion
+	}
+	return GetJavaVersionForMC(srv.Version)
+}
+
+// GetJavaVersionForMC guesses the Java major version a Minecraft version
+// needs from its name, for when Mojang's version metadata cannot be read.
+//
+//	26.1+                  -> Java 25
+//	1.20.5+, 24w14a+       -> Java 21
+//	1.18+, 21w37a+         -> Java 17
+//	1.17, 21w19a+          -> Java 16
+//	older, alpha and beta  -> Java 8
 func GetJavaVersionForMC(mcVersion string) int {
-	parts := strings.Split(mcVersion, ".")
+	for _, prefix := range []string{"a", "b", "c", "rd-", "inf-"} {
+		if rest, ok := strings.CutPrefix(mcVersion, prefix); ok && rest != "" && rest[0] >= '0' && rest[0] <= '9' {
+			return 8
+		}
+	}
 
-	first, _ := strconv.Atoi(parts[0])
+	// Pre-releases and release candidates need what their release needs.
+	version, _, _ := strings.Cut(mcVersion, "-")
+	version, _, _ = strings.Cut(version, " ")
 
-	if first == 1 {
-		minor, _ := strconv.Atoi(parts[1])
+	if year, week, ok := snapshotWeek(version); ok {
+		switch yearWeek := year*100 + week; {
+		case yearWeek >= 2414:
+			return 21
+		case yearWeek >= 2137:
+			return 17
+		case yearWeek >= 2119:
+			return 16
+		default:
+			return 8
+		}
+	}
+
+	parts := strings.Split(version, ".")
+	first, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 21
+	}
 
-		if minor >= 20 {
-			if len(parts) > 2 {
-				patch, _ := strconv.Atoi(parts[2])
-				if minor == 20 && patch >= 5 {
-					return 21
-				}
-			}
-			if minor >= 21 {
-				return 21
-			}
+	if first == 1 && len(parts) > 1 {
+		minor, _ := strconv.Atoi(parts[1])
+		patch := 0
+		if len(parts) > 2 {
+			patch, _ = strconv.Atoi(parts[2])
 		}
 
-		if minor >= 18 {
+		switch {
+		case minor > 20 || (minor == 20 && patch >= 5):
+			return 21
+		case minor >= 18:
 			return 17
+		case minor == 17:
+			return 16
+		default:
+			return 8
 		}
-
-		return 8
 	}
 
 	if first >= 26 {
@@ -42,3 +84,21 @@ func GetJavaVersionForMC(mcVersion string) int {
 
 	return 21
 }
+
+// snapshotWeek splits a weekly snapshot name such as 24w14a into its year
+// and week.
+func snapshotWeek(version string) (int, int, bool) {
+	yearText, rest, ok := strings.Cut(version, "w")
+	if !ok || len(yearText) != 2 || len(rest) < 3 {
+		return 0, 0, false
+	}
+	year, err := strconv.Atoi(yearText)
+	if err != nil {
+		return 0, 0, false
+	}
+	week, err := strconv.Atoi(rest[:2])
+	if err != nil {
+		return 0, 0, false
+	}
+	return year, week, true
+}
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"testing"
+
+	"naviger/internal/domain"
+)
+
+func TestGetJavaVersionForMC(t *testing.T) {
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{"1.8.9", 8},
+		{"1.12.2", 8},
+		{"1.16.5", 8},
+		{"1.17", 16},
+		{"1.17.1", 16},
+		{"1.18", 17},
+		{"1.18.2", 17},
+		{"1.20.4", 17},
+		{"1.20.5", 21},
+		{"1.20.6", 21},
+		{"1.21", 21},
+		{"1.21.4", 21},
+		{"1.18-pre2", 17},
+		{"1.20.5-rc1", 21},
+		{"1.14 Pre-Release 5", 8},
+		{"20w14a", 8},
+		{"21w19a", 16},
+		{"21w37a", 17},
+		{"24w13a", 17},
+		{"24w14a", 21},
+		{"25w45a", 21},
+		{"26.1", 25},
+		{"26.1-snapshot-1", 25},
+		{"b1.7.3", 8},
+		{"a1.0.16", 8},
+		{"rd-132211", 8},
+		{"c0.30_01c", 8},
+		{"unknown", 21},
+	}
+
+	for _, tt := range tests {
+		if got := GetJavaVersionForMC(tt.version); got != tt.want {
+			t.Errorf("GetJavaVersionForMC(%q) = %d, want %d", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestRequiredJava(t *testing.T) {
+	tests := []struct {
+		name string
+		srv  domain.Server
+		want int
+	}{
+		{"recorded", domain.Server{Loader: "vanilla", Version: "1.21", JavaVersion: 22}, 22},
+		{"fallback", domain.Server{Loader: "fabric", Version: "1.17.1"}, 16},
+		{"proxy", domain.Server{Loader: "bungeecord", Version: "latest"}, 21},
+	}
+
+	for _, tt := range tests {
+		if got := RequiredJava(&tt.srv); got != tt.want {
+			t.Errorf("%s: RequiredJava = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package scheduler runs server schedules: starts, stops, restarts, console
+// commands and backups at the times of cron expressions.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"naviger/internal/backup"
+	"naviger/internal/domain"
+	"naviger/internal/runner"
+	"naviger/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// tickInterval is how often due schedules are looked for. Cron
+	// expressions have minute resolution.
+	tickInterval = 15 * time.Second
+	// stopTimeout bounds how long a restart waits for the server to stop.
+	stopTimeout = 2 * time.Minute
+)
+
+// Actions are the actions a schedule can run.
+var Actions = []string{domain.ScheduleStart, domain.ScheduleStop, domain.ScheduleRestart, domain.ScheduleCommand, domain.ScheduleBackup}
+
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// Input is what a client provides to create a schedule.
+type Input struct {
+	Cron    string `json:"cron"`
+	Action  string `json:"action"`
+	Command string `json:"command,omitempty"`
+}
+
+type Scheduler struct {
+	Store      *storage.GormStore
+	Supervisor *runner.Supervisor
+	Backups    *backup.Manager
+}
+
+func NewScheduler(store *storage.GormStore, supervisor *runner.Supervisor, backups *backup.Manager) *Scheduler {
+	return &Scheduler{Store: store, Supervisor: supervisor, Backups: backups}
+}
+
+// Validate checks a schedule's cron expression, action and command.
+func Validate(input Input) error {
+	if _, err := cron.ParseStandard(input.Cron); err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", input.Cron, err)
+	}
+	if !slices.Contains(Actions, input.Action) {
+		return fmt.Errorf("unknown action %q, expected one of %s", input.Action, strings.Join(Actions, ", "))
+	}
+	if input.Action == domain.ScheduleCommand {
+		if strings.TrimSpace(input.Command) == "" || strings.ContainsAny(input.Command, "\r\n") {
+			return fmt.Errorf("command schedules need a single-line command")
+		}
+	} else if input.Command != "" {
+		return fmt.Errorf("only command schedules take a command")
+	}
+	return nil
+}
+
+func (s *Scheduler) List(serverID string) ([]domain.Schedule, error) {
+	return s.Store.ListSchedules(serverID)
+}
+
+func (s *Scheduler) Create(serverID string, input Input) (*domain.Schedule, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+	srv, err := s.Store.GetServerByID(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if srv == nil {
+		return nil, fmt.Errorf("server not found")
+	}
+
+	schedule := &domain.Schedule{
+		ID:        uuid.New().String(),
+		ServerID:  serverID,
+		Cron:      input.Cron,
+		Action:    input.Action,
+		Command:   input.Command,
+		CreatedAt: time.Now(),
+	}
+	if err := s.Store.CreateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *Scheduler) Delete(serverID, id string) error {
+	schedules, err := s.Store.ListSchedules(serverID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(schedules, func(sc domain.Schedule) bool { return sc.ID == id }) {
+		return ErrScheduleNotFound
+	}
+	return s.Store.DeleteSchedule(serverID, id)
+}
+
+// Run runs schedules as they come due until ctx is done. Times missed while
+// the daemon was down are not caught up on.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.RunDue(last, now)
+			last = now
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunDue starts the schedules with a time in (from, to].
+func (s *Scheduler) RunDue(from, to time.Time) {
+	schedules, err := s.Store.ListAllSchedules()
+	if err != nil {
+		slog.Warn("Could not load schedules", "error", err)
+		return
+	}
+	for _, schedule := range schedules {
+		if due(schedule, from, to) {
+			go s.execute(schedule, to)
+		}
+	}
+}
+
+func due(schedule domain.Schedule, from, to time.Time) bool {
+	expr, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		return false
+	}
+	return !expr.Next(from).After(to)
+}
+
+func (s *Scheduler) execute(schedule domain.Schedule, at time.Time) {
+	err := s.runAction(schedule)
+	runErr := ""
+	if err != nil {
+		runErr = err.Error()
+		slog.Warn("Scheduled action failed", "server", schedule.ServerID, "action", schedule.Action, "error", err)
+	}
+	if err := s.Store.UpdateScheduleRun(schedule.ID, at, runErr); err != nil {
+		slog.Warn("Could not record schedule run", "schedule", schedule.ID, "error", err)
+	}
+}
+
+// runAction runs the action of a schedule. Starting a running server and
+// stopping or restarting a stopped one do nothing, so a server stopped by
+// hand is not brought back by a restart schedule.
+func (s *Scheduler) runAction(schedule domain.Schedule) error {
+	id := schedule.ServerID
+	switch schedule.Action {
+	case domain.ScheduleStart:
+		if s.Supervisor.IsRunning(id) {
+			return nil
+		}
+		return s.Supervisor.StartServer(id)
+	case domain.ScheduleStop:
+		if !s.Supervisor.IsRunning(id) {
+			return nil
+		}
+		return s.Supervisor.StopServer(id)
+	case domain.ScheduleRestart:
+		if !s.Supervisor.IsRunning(id) {
+			return nil
+		}
+		if err := s.Supervisor.StopServer(id); err != nil {
+			return err
+		}
+		deadline := time.Now().Add(stopTimeout)
+		for s.Supervisor.IsRunning(id) {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("server did not stop within %s", stopTimeout)
+			}
+			time.Sleep(time.Second)
+		}
+		return s.Supervisor.StartServer(id)
+	case domain.ScheduleCommand:
+		return s.Supervisor.SendCommand(id, schedule.Command)
+	case domain.ScheduleBackup:
+		return s.backup(id)
+	}
+	return fmt.Errorf("unknown action %q", schedule.Action)
+}
+
+// backup runs a backup job and waits for it, so its result is recorded as
+// the outcome of the schedule.
+func (s *Scheduler) backup(serverID string) error {
+	progress := make(chan domain.ProgressEvent)
+	s.Backups.StartBackupJob(serverID, "", uuid.New().String(), false, progress)
+
+	var err error
+	for event := range progress {
+		if event.Progress < 0 {
+			err = errors.New(strings.TrimPrefix(event.Message, "Error: "))
+		}
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"naviger/internal/domain"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		input Input
+		ok    bool
+	}{
+		{Input{Cron: "0 4 * * *", Action: domain.ScheduleRestart}, true},
+		{Input{Cron: "*/30 * * * *", Action: domain.ScheduleCommand, Command: "save-all"}, true},
+		{Input{Cron: "@daily", Action: domain.ScheduleBackup}, true},
+		{Input{Cron: "0 4 * *", Action: domain.ScheduleRestart}, false},
+		{Input{Cron: "0 4 * * *", Action: "reboot"}, false},
+		{Input{Cron: "0 4 * * *", Action: domain.ScheduleCommand}, false},
+		{Input{Cron: "0 4 * * *", Action: domain.ScheduleCommand, Command: "say a\nstop"}, false},
+		{Input{Cron: "0 4 * * *", Action: domain.ScheduleStop, Command: "stop"}, false},
+	}
+
+	for _, tt := range tests {
+		if err := Validate(tt.input); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v) = %v, want ok %v", tt.input, err, tt.ok)
+		}
+	}
+}
+
+func TestDue(t *testing.T) {
+	schedule := domain.Schedule{Cron: "0 4 * * *", Action: domain.ScheduleRestart}
+	at := func(clock string) time.Time {
+		parsed, _ := time.ParseInLocation("15:04:05", clock, time.Local)
+		return parsed
+	}
+
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"03:59:50", "04:00:05", true},
+		{"03:59:45", "04:00:00", true},
+		{"04:00:00", "04:00:15", false},
+		{"03:59:30", "03:59:45", false},
+	}
+	for _, tt := range tests {
+		if got := due(schedule, at(tt.from), at(tt.to)); got != tt.want {
+			t.Errorf("due(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if due(domain.Schedule{Cron: "not a cron"}, at("00:00:00"), at("23:59:59")) {
+		t.Error("an invalid cron expression was due")
+	}
+}
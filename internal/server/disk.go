@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"naviger/internal/storage"
+)
+
+// DefaultDiskSampleInterval is how often server folders are measured.
+const DefaultDiskSampleInterval = time.Minute
+
+// DiskSampler measures the size of every server folder in the background so
+// stats and metrics can report disk usage without walking the tree on each
+// request.
+type DiskSampler struct {
+	ServersPath string
+	Store       *storage.GormStore
+	Interval    time.Duration
+	sizes       map[string]int64
+	mu          sync.RWMutex
+}
+
+func NewDiskSampler(serversPath string, store *storage.GormStore) *DiskSampler {
+	return &DiskSampler{
+		ServersPath: serversPath,
+		Store:       store,
+		Interval:    DefaultDiskSampleInterval,
+		sizes:       make(map[string]int64),
+	}
+}
+
+// Run samples straight away and then every Interval until ctx is done.
+func (d *DiskSampler) Run(ctx context.Context) {
+	d.Sample()
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.Sample()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sample measures every server folder once.
+func (d *DiskSampler) Sample() {
+	servers, err := d.Store.ListServers()
+	if err != nil {
+		return
+	}
+
+	sizes := make(map[string]int64, len(servers))
+	for _, srv := range servers {
+		folderName := srv.FolderName
+		if folderName == "" {
+			folderName = srv.ID
+		}
+		sizes[srv.ID] = dirSize(filepath.Join(d.ServersPath, folderName))
+	}
+
+	d.mu.Lock()
+	d.sizes = sizes
+	d.mu.Unlock()
+}
+
+// Size returns the last measured size of a server folder in bytes, or 0
+// before the first sample.
+func (d *DiskSampler) Size(serverID string) int64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.sizes[serverID]
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -40,13 +40,19 @@ func (m *Manager) sanitizePath(serverID, requestPath string) (string, error) {
 
 	fullPath := filepath.Join(serverRoot, cleanRequestPath)
 
-	if !strings.HasPrefix(fullPath, serverRoot) {
+	if fullPath != serverRoot && !strings.HasPrefix(fullPath, serverRoot+string(os.PathSeparator)) {
 		return "", fmt.Errorf("access denied: path outside server directory")
 	}
 
 	return fullPath, nil
 }
 
+// ResolvePath returns where a path inside a server's folder is on disk,
+// refusing paths that lead out of the folder.
+func (m *Manager) ResolvePath(serverID, requestPath string) (string, error) {
+	return m.sanitizePath(serverID, requestPath)
+}
+
 func (m *Manager) ListFiles(serverID, requestPath string) ([]FileEntry, error) {
 	fullPath, err := m.sanitizePath(serverID, requestPath)
 	if err != nil {
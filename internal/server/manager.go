@@ -5,7 +5,9 @@ import (
 	"image"
 	"image/png"
 	"naviger/internal/domain"
+	"naviger/internal/jvm"
 	"naviger/internal/loader"
+	"naviger/internal/properties"
 	"naviger/internal/storage"
 	"os"
 	"path/filepath"
@@ -18,14 +20,19 @@ import (
 )
 
 type Manager struct {
-	ServersPath string
-	Store       *storage.GormStore
+	ServersPath   string
+	TemplatesPath string
+	Store         *storage.GormStore
+	// JVM provides the Java runtime for loaders that run Java while
+	// installing; without it they use java from PATH.
+	JVM *jvm.Manager
 }
 
-func NewManager(serversPath string, store *storage.GormStore) *Manager {
+func NewManager(serversPath, templatesPath string, store *storage.GormStore) *Manager {
 	return &Manager{
-		ServersPath: serversPath,
-		Store:       store,
+		ServersPath:   serversPath,
+		TemplatesPath: templatesPath,
+		Store:         store,
 	}
 }
 
@@ -40,9 +47,21 @@ func sanitizeFolderName(name string) string {
 }
 
 func (m *Manager) StartCreateServerJob(name, loaderType, version string, ram int, progressChan chan<- domain.ProgressEvent) {
+	m.runCreateJob(progressChan, func() (*domain.Server, error) {
+		return m.CreateServer(name, loaderType, version, ram, progressChan)
+	})
+}
+
+func (m *Manager) StartCreateCustomServerJob(name, version string, ram, javaVersion int, source *loader.CustomLoader, progressChan chan<- domain.ProgressEvent) {
+	m.runCreateJob(progressChan, func() (*domain.Server, error) {
+		return m.CreateServerWithLoader(name, "custom", version, ram, javaVersion, source, progressChan)
+	})
+}
+
+func (m *Manager) runCreateJob(progressChan chan<- domain.ProgressEvent, create func() (*domain.Server, error)) {
 	go func() {
 		defer close(progressChan)
-		srv, err := m.CreateServer(name, loaderType, version, ram, progressChan)
+		srv, err := create()
 		if err != nil {
 			fmt.Printf("Error creating server: %v\n", err)
 			event := domain.ProgressEvent{
@@ -64,17 +83,17 @@ func (m *Manager) StartCreateServerJob(name, loaderType, version string, ram int
 }
 
 func (m *Manager) CreateServer(name string, loaderType string, version string, ram int, progressChan chan<- domain.ProgressEvent) (*domain.Server, error) {
-	if strings.ContainsAny(name, "\\/:*?\"<>|") || strings.Contains(name, "..") {
-		return nil, fmt.Errorf("invalid server name: contains forbidden characters")
+	downloader, err := loader.GetLoader(loaderType)
+	if err != nil {
+		return nil, err
 	}
+	return m.CreateServerWithLoader(name, loaderType, version, ram, 0, downloader, progressChan)
+}
 
-	id := uuid.New().String()
-	folderName := sanitizeFolderName(name)
-	serverDir := filepath.Join(m.ServersPath, folderName)
-
-	if _, err := os.Stat(serverDir); !os.IsNotExist(err) {
-		folderName = fmt.Sprintf("%s-%s", folderName, id[:8])
-		serverDir = filepath.Join(m.ServersPath, folderName)
+func (m *Manager) CreateServerWithLoader(name string, loaderType string, version string, ram int, javaVersion int, downloader loader.ServerLoader, progressChan chan<- domain.ProgressEvent) (*domain.Server, error) {
+	id, folderName, serverDir, err := m.newServerLocation(name)
+	if err != nil {
+		return nil, err
 	}
 
 	if progressChan != nil {
@@ -86,15 +105,28 @@ func (m *Manager) CreateServer(name string, loaderType string, version string, r
 	}
 	fmt.Printf("Port allocated for '%s': %d\n", name, assignedPort)
 
-	downloader, err := loader.GetLoader(loaderType)
-	if err != nil {
-		return nil, err
-	}
-
 	if err := os.MkdirAll(serverDir, 0755); err != nil {
 		return nil, fmt.Errorf("filesystem error: %w", err)
 	}
 
+	if javaVersion == 0 {
+		// Left at 0 when offline, the version is guessed on each start.
+		if resolved, err := loader.ResolveJava(downloader, version); err == nil {
+			javaVersion = resolved
+		} else {
+			fmt.Printf("Warning: Could not resolve the Java version for %s: %v\n", version, err)
+		}
+	}
+
+	if javaRunner, ok := downloader.(loader.JavaRunner); ok && m.JVM != nil && javaVersion > 0 {
+		rt, err := m.JVM.Install(javaVersion, progressChan)
+		if err != nil {
+			os.RemoveAll(serverDir)
+			return nil, fmt.Errorf("error preparing Java %d: %w", javaVersion, err)
+		}
+		javaRunner.SetJava(rt.Java)
+	}
+
 	if err := downloader.Load(version, serverDir, progressChan); err != nil {
 		os.RemoveAll(serverDir)
 		return nil, fmt.Errorf("download error: %w", err)
@@ -103,22 +135,28 @@ func (m *Manager) CreateServer(name string, loaderType string, version string, r
 	if progressChan != nil {
 		progressChan <- domain.ProgressEvent{Message: "Configuring server..."}
 	}
-	os.WriteFile(filepath.Join(serverDir, "eula.txt"), []byte("eula=true"), 0644)
 
-	if err := UpdateServerProperties(serverDir, assignedPort); err != nil {
-		fmt.Printf("Warning: Could not write server.properties: %v\n", err)
+	// Proxies have no EULA or server.properties; their port is written to the
+	// proxy config by the runner when the server starts.
+	if !loader.IsProxy(loaderType) {
+		os.WriteFile(filepath.Join(serverDir, "eula.txt"), []byte("eula=true"), 0644)
+
+		if err := properties.SetPort(serverDir, assignedPort); err != nil {
+			fmt.Printf("Warning: Could not write server.properties: %v\n", err)
+		}
 	}
 
 	newServer := &domain.Server{
-		ID:         id,
-		Name:       name,
-		FolderName: folderName,
-		Version:    version,
-		Loader:     loaderType,
-		Port:       assignedPort,
-		RAM:        ram,
-		Status:     "STOPPED",
-		CreatedAt:  time.Now(),
+		ID:          id,
+		Name:        name,
+		FolderName:  folderName,
+		Version:     version,
+		Loader:      loaderType,
+		Port:        assignedPort,
+		RAM:         ram,
+		Status:      "STOPPED",
+		JavaVersion: javaVersion,
+		CreatedAt:   time.Now(),
 	}
 
 	if err := m.Store.SaveServer(newServer); err != nil {
@@ -129,6 +167,52 @@ func (m *Manager) CreateServer(name string, loaderType string, version string, r
 	return newServer, nil
 }
 
+// RecordJavaVersions resolves and stores the Java version of servers created
+// before it was recorded at creation. Servers it cannot resolve keep having
+// it guessed on start.
+func (m *Manager) RecordJavaVersions() {
+	servers, err := m.Store.ListServers()
+	if err != nil {
+		return
+	}
+
+	for _, srv := range servers {
+		if srv.JavaVersion > 0 {
+			continue
+		}
+		downloader, err := loader.GetLoader(srv.Loader)
+		if err != nil {
+			continue
+		}
+		javaVersion, err := loader.ResolveJava(downloader, srv.Version)
+		if err != nil {
+			fmt.Printf("Warning: Could not resolve the Java version of '%s': %v\n", srv.Name, err)
+			continue
+		}
+		if err := m.Store.UpdateServerJavaVersion(srv.ID, javaVersion); err != nil {
+			fmt.Printf("Warning: Could not record the Java version of '%s': %v\n", srv.Name, err)
+		}
+	}
+}
+
+// newServerLocation validates name and picks a fresh ID and a folder that does
+// not collide with an existing server.
+func (m *Manager) newServerLocation(name string) (id, folderName, serverDir string, err error) {
+	if strings.ContainsAny(name, "\\/:*?\"<>|") || strings.Contains(name, "..") {
+		return "", "", "", fmt.Errorf("invalid server name: contains forbidden characters")
+	}
+
+	id = uuid.New().String()
+	folderName = sanitizeFolderName(name)
+	serverDir = filepath.Join(m.ServersPath, folderName)
+
+	if _, err := os.Stat(serverDir); !os.IsNotExist(err) {
+		folderName = fmt.Sprintf("%s-%s", folderName, id[:8])
+		serverDir = filepath.Join(m.ServersPath, folderName)
+	}
+	return id, folderName, serverDir, nil
+}
+
 func (m *Manager) GetServer(id string) (*domain.Server, error) {
 	return m.Store.GetServerByID(id)
 }
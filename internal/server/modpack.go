@@ -0,0 +1,79 @@
+package server
+
+import (
+	"fmt"
+	"naviger/internal/domain"
+	"naviger/internal/loader"
+	"naviger/internal/modpack"
+	"os"
+	"path/filepath"
+)
+
+// StartImportModpackJob creates a server from a modpack archive. Either
+// archivePath points at an uploaded pack, or packURL is downloaded first. The
+// archive is removed when the job finishes.
+func (m *Manager) StartImportModpackJob(name string, ram int, archivePath, packURL string, progressChan chan<- domain.ProgressEvent) {
+	m.runCreateJob(progressChan, func() (*domain.Server, error) {
+		return m.ImportModpack(name, ram, archivePath, packURL, modpack.NewInstaller(), progressChan)
+	})
+}
+
+func (m *Manager) ImportModpack(name string, ram int, archivePath, packURL string, installer *modpack.Installer, progressChan chan<- domain.ProgressEvent) (*domain.Server, error) {
+	if archivePath == "" {
+		tmpFile, err := os.CreateTemp("", "naviger-modpack-*.zip")
+		if err != nil {
+			return nil, err
+		}
+		tmpFile.Close()
+		archivePath = tmpFile.Name()
+
+		if err := installer.Fetch(packURL, archivePath, progressChan); err != nil {
+			os.Remove(archivePath)
+			return nil, fmt.Errorf("error downloading modpack: %w", err)
+		}
+	}
+	defer os.Remove(archivePath)
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: "Reading modpack manifest..."}
+	}
+	pack, err := modpack.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	downloader, err := loader.GetLoader(pack.Loader)
+	if err != nil {
+		return nil, fmt.Errorf("modpack requires loader '%s': %w", pack.Loader, err)
+	}
+	// Packs are built against one loader build; the latest one may not load
+	// their mods.
+	if pack.LoaderVersion != "" {
+		pinner, ok := downloader.(loader.LoaderVersionPinner)
+		if !ok {
+			return nil, fmt.Errorf("modpack requires %s %s, but Naviger cannot install a specific %s version", pack.Loader, pack.LoaderVersion, pack.Loader)
+		}
+		pinner.PinLoaderVersion(pack.LoaderVersion)
+	}
+
+	if name == "" {
+		name = pack.Name
+	}
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: fmt.Sprintf("Modpack targets Minecraft %s with %s %s", pack.MinecraftVersion, pack.Loader, pack.LoaderVersion)}
+	}
+
+	srv, err := m.CreateServerWithLoader(name, pack.Loader, pack.MinecraftVersion, ram, 0, downloader, progressChan)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := installer.Install(pack, archivePath, filepath.Join(m.ServersPath, srv.FolderName), progressChan); err != nil {
+		if delErr := m.DeleteServer(srv.ID); delErr != nil {
+			fmt.Printf("Warning: Could not clean up server after failed import: %v\n", delErr)
+		}
+		return nil, fmt.Errorf("error installing modpack files: %w", err)
+	}
+
+	return srv, nil
+}
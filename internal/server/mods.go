@@ -0,0 +1,185 @@
+package server
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type ModFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	SHA1 string `json:"sha1"`
+}
+
+// ModsFolder returns the directory a loader reads plugins or mods from.
+func ModsFolder(loaderType string) (string, error) {
+	switch loaderType {
+	case "paper", "spigot", "velocity", "bungeecord", "waterfall":
+		return "plugins", nil
+	case "fabric", "forge", "neoforge", "custom":
+		return "mods", nil
+	default:
+		return "", fmt.Errorf("loader '%s' does not support mods or plugins", loaderType)
+	}
+}
+
+func (m *Manager) modsDir(serverID string) (string, error) {
+	srv, err := m.GetServer(serverID)
+	if err != nil {
+		return "", err
+	}
+	if srv == nil {
+		return "", fmt.Errorf("server not found")
+	}
+	folder, err := ModsFolder(srv.Loader)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(m.serverDir(srv), folder), nil
+}
+
+// ListMods returns the jar files in the server's mods or plugins folder.
+func (m *Manager) ListMods(serverID string) ([]ModFile, error) {
+	dir, err := m.modsDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []ModFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mods := []ModFile{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".jar") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		sum, err := fileSHA1(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, ModFile{Name: entry.Name(), Size: info.Size(), SHA1: sum})
+	}
+
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Name < mods[j].Name })
+	return mods, nil
+}
+
+// InstallMod downloads a jar into the server's mods or plugins folder. The
+// file name defaults to the last segment of the URL; when expectedSHA1 is set
+// the download is rejected unless it matches.
+func (m *Manager) InstallMod(serverID, rawURL, fileName, expectedSHA1 string) (*ModFile, error) {
+	dir, err := m.modsDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("invalid mod URL")
+	}
+	if fileName == "" {
+		fileName = path.Base(u.Path)
+	}
+	if err := validateModName(fileName); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error downloading mod: status %d", resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha1.New()
+	size, copyErr := io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	closeErr := tmp.Close()
+	if copyErr != nil {
+		return nil, copyErr
+	}
+	if closeErr != nil {
+		return nil, closeErr
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if expectedSHA1 != "" && !strings.EqualFold(sum, expectedSHA1) {
+		return nil, fmt.Errorf("sha1 mismatch for %s", fileName)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, fileName)); err != nil {
+		return nil, err
+	}
+	return &ModFile{Name: fileName, Size: size, SHA1: sum}, nil
+}
+
+func (m *Manager) DeleteMod(serverID, fileName string) error {
+	if err := validateModName(fileName); err != nil {
+		return err
+	}
+	dir, err := m.modsDir(serverID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, fileName)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("mod not found")
+		}
+		return err
+	}
+	return nil
+}
+
+func validateModName(name string) error {
+	if name == "" || name != filepath.Base(name) || strings.ContainsAny(name, "\\/:") || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid mod file name: %s", name)
+	}
+	if !strings.HasSuffix(strings.ToLower(name), ".jar") {
+		return fmt.Errorf("mod file must be a .jar: %s", name)
+	}
+	return nil
+}
+
+func fileSHA1(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha1.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
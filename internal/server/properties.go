@@ -1,65 +1,48 @@
 package server
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
+	"naviger/internal/properties"
 )
 
-func UpdateServerProperties(serverDir string, port int) error {
-	path := filepath.Join(serverDir, "server.properties")
-
-	props := make(map[string]string)
-	var order []string
-
-	if file, err := os.Open(path); err == nil {
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				val := strings.TrimSpace(parts[1])
-				props[key] = val
-				order = append(order, key)
-			}
-		}
-		file.Close()
+func (m *Manager) GetServerProperties(serverID string) (*properties.View, error) {
+	srv, err := m.GetServer(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if srv == nil {
+		return nil, fmt.Errorf("server not found")
 	}
 
-	props["server-port"] = fmt.Sprintf("%d", port)
+	values, err := properties.Read(m.serverDir(srv))
+	if err != nil {
+		return nil, err
+	}
+	view := properties.Describe(values, srv.Version)
+	return &view, nil
+}
 
-	found := false
-	for _, k := range order {
-		if k == "server-port" {
-			found = true
-			break
-		}
+// PatchServerProperties validates values against the schema of the server's
+// Minecraft version and merges them into its server.properties. The port is
+// owned by Naviger and cannot be changed here.
+func (m *Manager) PatchServerProperties(serverID string, values map[string]interface{}) (*properties.View, error) {
+	if _, ok := values["server-port"]; ok {
+		return nil, fmt.Errorf("server-port is managed by Naviger")
+	}
+	srv, err := m.GetServer(serverID)
+	if err != nil {
+		return nil, err
 	}
-	if !found {
-		order = append(order, "server-port")
+	if srv == nil {
+		return nil, fmt.Errorf("server not found")
 	}
 
-	file, err := os.Create(path)
+	normalized, err := properties.Normalize(srv.Version, values)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	writer.WriteString("# Minecraft Server Properties\n")
-	writer.WriteString(fmt.Sprintf("# Generated by Naviger\n"))
-
-	for _, key := range order {
-		val := props[key]
-		writer.WriteString(fmt.Sprintf("%s=%s\n", key, val))
+	if err := properties.Update(m.serverDir(srv), normalized); err != nil {
+		return nil, err
 	}
-	writer.Flush()
-
-	return nil
+	return m.GetServerProperties(serverID)
 }
@@ -0,0 +1,328 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"naviger/internal/domain"
+	"naviger/internal/loader"
+	"naviger/internal/properties"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var defaultWorldFolders = []string{"world", "world_nether", "world_the_end"}
+
+// templateExcludes are runtime artifacts that never belong in a template.
+var templateExcludes = []string{"logs", "crash-reports", "session.lock"}
+
+func (m *Manager) ListTemplates() ([]domain.Template, error) {
+	return m.Store.ListTemplates()
+}
+
+func (m *Manager) GetTemplate(id string) (*domain.Template, error) {
+	return m.Store.GetTemplate(id)
+}
+
+// CreateTemplate snapshots a server's files, minus its worlds, together with
+// its loader, version, RAM and custom arguments.
+func (m *Manager) CreateTemplate(serverID, name string, progressChan chan<- domain.ProgressEvent) (*domain.Template, error) {
+	srv, err := m.Store.GetServerByID(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if srv == nil {
+		return nil, fmt.Errorf("server not found")
+	}
+	if name == "" {
+		name = srv.Name
+	}
+
+	serverDir := m.serverDir(srv)
+	skip := worldFolders(serverDir)
+	for _, entry := range templateExcludes {
+		skip[entry] = true
+	}
+
+	template := &domain.Template{
+		ID:             uuid.New().String(),
+		Name:           name,
+		Loader:         srv.Loader,
+		Version:        srv.Version,
+		RAM:            srv.RAM,
+		CustomArgs:     srv.CustomArgs,
+		JavaVersion:    srv.JavaVersion,
+		SourceServerID: srv.ID,
+		CreatedAt:      time.Now(),
+	}
+
+	templateDir := filepath.Join(m.TemplatesPath, template.ID)
+	if err := copyTree(serverDir, templateDir, skip, "Copying template files", progressChan); err != nil {
+		os.RemoveAll(templateDir)
+		return nil, fmt.Errorf("error copying server files: %w", err)
+	}
+
+	if err := m.Store.CreateTemplate(template); err != nil {
+		os.RemoveAll(templateDir)
+		return nil, fmt.Errorf("DB error: %w", err)
+	}
+	return template, nil
+}
+
+func (m *Manager) DeleteTemplate(id string) error {
+	template, err := m.Store.GetTemplate(id)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		return fmt.Errorf("template not found")
+	}
+	if err := os.RemoveAll(filepath.Join(m.TemplatesPath, id)); err != nil {
+		return fmt.Errorf("error deleting template files: %w", err)
+	}
+	return m.Store.DeleteTemplate(id)
+}
+
+func (m *Manager) StartCreateFromTemplateJob(name, templateID string, ram int, progressChan chan<- domain.ProgressEvent) {
+	m.runCreateJob(progressChan, func() (*domain.Server, error) {
+		return m.CreateServerFromTemplate(name, templateID, ram, progressChan)
+	})
+}
+
+// CreateServerFromTemplate creates a server from a template's files and
+// settings. A ram of zero keeps the template's value.
+func (m *Manager) CreateServerFromTemplate(name, templateID string, ram int, progressChan chan<- domain.ProgressEvent) (*domain.Server, error) {
+	template, err := m.Store.GetTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, fmt.Errorf("template not found")
+	}
+	if ram <= 0 {
+		ram = template.RAM
+	}
+
+	settings := domain.Server{
+		Name:        name,
+		Version:     template.Version,
+		Loader:      template.Loader,
+		RAM:         ram,
+		CustomArgs:  template.CustomArgs,
+		JavaVersion: template.JavaVersion,
+	}
+	return m.createFromDirectory(settings, filepath.Join(m.TemplatesPath, template.ID), nil, progressChan)
+}
+
+func (m *Manager) StartCloneServerJob(sourceID, name string, includeWorlds bool, progressChan chan<- domain.ProgressEvent) {
+	m.runCreateJob(progressChan, func() (*domain.Server, error) {
+		return m.CloneServer(sourceID, name, includeWorlds, progressChan)
+	})
+}
+
+// CloneServer duplicates a server under a fresh ID, folder and port. Worlds
+// are only copied when includeWorlds is set, and then the source must be
+// stopped so the copy is consistent.
+func (m *Manager) CloneServer(sourceID, name string, includeWorlds bool, progressChan chan<- domain.ProgressEvent) (*domain.Server, error) {
+	source, err := m.Store.GetServerByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("server not found")
+	}
+	if includeWorlds && source.Status != "STOPPED" {
+		return nil, fmt.Errorf("server must be stopped to clone its worlds")
+	}
+	if name == "" {
+		name = source.Name + " (copy)"
+	}
+
+	sourceDir := m.serverDir(source)
+	skip := map[string]bool{"session.lock": true}
+	if !includeWorlds {
+		skip = worldFolders(sourceDir)
+	}
+
+	settings := domain.Server{
+		Name:        name,
+		Version:     source.Version,
+		Loader:      source.Loader,
+		RAM:         source.RAM,
+		CustomArgs:  source.CustomArgs,
+		JavaVersion: source.JavaVersion,
+		Limits:      source.Limits,
+		RunMode:     source.RunMode,
+		JVMProfile:  source.JVMProfile,
+		JavaRuntime: source.JavaRuntime,
+	}
+	return m.createFromDirectory(settings, sourceDir, skip, progressChan)
+}
+
+func (m *Manager) createFromDirectory(settings domain.Server, srcDir string, skip map[string]bool, progressChan chan<- domain.ProgressEvent) (*domain.Server, error) {
+	id, folderName, serverDir, err := m.newServerLocation(settings.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if progressChan != nil {
+		progressChan <- domain.ProgressEvent{Message: "Allocating port..."}
+	}
+	assignedPort, err := AllocatePort(m.Store)
+	if err != nil {
+		return nil, fmt.Errorf("error allocating port: %w", err)
+	}
+
+	if err := copyTree(srcDir, serverDir, skip, "Copying server files", progressChan); err != nil {
+		os.RemoveAll(serverDir)
+		return nil, fmt.Errorf("error copying server files: %w", err)
+	}
+
+	if !loader.IsProxy(settings.Loader) {
+		if err := properties.SetPort(serverDir, assignedPort); err != nil {
+			fmt.Printf("Warning: Could not write server.properties: %v\n", err)
+		}
+	}
+
+	settings.ID = id
+	settings.FolderName = folderName
+	settings.Port = assignedPort
+	settings.Status = "STOPPED"
+	settings.CreatedAt = time.Now()
+
+	if err := m.Store.SaveServer(&settings); err != nil {
+		os.RemoveAll(serverDir)
+		return nil, fmt.Errorf("DB error: %w", err)
+	}
+	return &settings, nil
+}
+
+func (m *Manager) serverDir(srv *domain.Server) string {
+	folderName := srv.FolderName
+	if folderName == "" {
+		folderName = srv.ID
+	}
+	return filepath.Join(m.ServersPath, folderName)
+}
+
+// worldFolders returns the top-level world directories of a server, based on
+// level-name in server.properties plus the vanilla defaults.
+func worldFolders(serverDir string) map[string]bool {
+	folders := make(map[string]bool)
+	for _, name := range defaultWorldFolders {
+		folders[name] = true
+	}
+
+	values, err := properties.Read(serverDir)
+	if err != nil {
+		return folders
+	}
+	if level := strings.TrimSpace(values["level-name"]); level != "" {
+		folders[level] = true
+		folders[level+"_nether"] = true
+		folders[level+"_the_end"] = true
+	}
+	return folders
+}
+
+// copyTree copies srcDir into destDir, skipping the named top-level entries,
+// and reports progress by bytes copied.
+func copyTree(srcDir, destDir string, skip map[string]bool, message string, progressChan chan<- domain.ProgressEvent) error {
+	if _, err := os.Stat(srcDir); err != nil {
+		return fmt.Errorf("source directory not found: %w", err)
+	}
+
+	skipped := func(relPath string) bool {
+		top := strings.SplitN(filepath.ToSlash(relPath), "/", 2)[0]
+		return skip[top]
+	}
+
+	var totalSize int64
+	filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if rel, _ := filepath.Rel(srcDir, path); rel != "." && skipped(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			totalSize += info.Size()
+		}
+		return nil
+	})
+
+	var copied int64
+	var lastProgress int
+
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return os.MkdirAll(destDir, 0755)
+		}
+		if skipped(relPath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		target := filepath.Join(destDir, relPath)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		if err := copyRegularFile(path, target, info.Mode().Perm()); err != nil {
+			return err
+		}
+
+		copied += info.Size()
+		if totalSize > 0 && progressChan != nil {
+			percentage := float64(copied) / float64(totalSize) * 100
+			if int(percentage) > lastProgress {
+				lastProgress = int(percentage)
+				progressChan <- domain.ProgressEvent{
+					Message:      fmt.Sprintf("%s... %d%%", message, lastProgress),
+					Progress:     percentage,
+					CurrentBytes: copied,
+					TotalBytes:   totalSize,
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func copyRegularFile(src, dest string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"naviger/internal/nbt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// GetLevelData returns the decoded level.dat of a world.
+func (m *Manager) GetLevelData(serverID, world string) (map[string]any, error) {
+	worldDir, err := m.findWorld(serverID, world)
+	if err != nil {
+		return nil, err
+	}
+	return nbt.ReadFile(filepath.Join(worldDir, "level.dat"))
+}
+
+// ListPlayerData returns the UUIDs of the players with saved data in a
+// world.
+func (m *Manager) ListPlayerData(serverID, world string) ([]string, error) {
+	worldDir, err := m.findWorld(serverID, world)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(worldDir, "playerdata"))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	players := []string{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".dat")
+		if _, err := uuid.Parse(name); ok && err == nil {
+			players = append(players, name)
+		}
+	}
+	sort.Strings(players)
+	return players, nil
+}
+
+// GetPlayerData returns the decoded save of a player: inventory, position,
+// health and the rest of what the server keeps while they are offline.
+func (m *Manager) GetPlayerData(serverID, world, playerUUID string) (map[string]any, error) {
+	path, err := m.playerDataPath(serverID, world, playerUUID)
+	if err != nil {
+		return nil, err
+	}
+	return nbt.ReadFile(path)
+}
+
+// PatchPlayerData merges a JSON merge patch into a player's save, see
+// nbt.Merge for how JSON values become tags. The server must be stopped, or
+// it overwrites the file when the player leaves.
+func (m *Manager) PatchPlayerData(serverID, world, playerUUID string, patch map[string]any) (map[string]any, error) {
+	path, err := m.playerDataPath(serverID, world, playerUUID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := nbt.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := nbt.Merge(data, patch); err != nil {
+		return nil, err
+	}
+	if err := nbt.WriteFile(path, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// GetChunk returns the decoded chunk at chunk coordinates x and z of a
+// dimension of a world.
+func (m *Manager) GetChunk(serverID, world, dimension string, x, z int) (map[string]any, error) {
+	dir, _, err := m.worldsDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.worldDir(dir, world); err != nil {
+		return nil, err
+	}
+	dimDir, err := dimensionDir(dir, world, dimension)
+	if err != nil {
+		return nil, err
+	}
+
+	region, err := nbt.OpenRegion(filepath.Join(dimDir, "region", nbt.RegionFileName(x, z)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("chunk not generated")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer region.Close()
+
+	chunk, err := region.ReadChunk(x, z)
+	if errors.Is(err, nbt.ErrChunkNotFound) {
+		return nil, fmt.Errorf("chunk not generated")
+	}
+	return chunk, err
+}
+
+func (m *Manager) findWorld(serverID, world string) (string, error) {
+	dir, _, err := m.worldsDir(serverID)
+	if err != nil {
+		return "", err
+	}
+	return m.worldDir(dir, world)
+}
+
+func (m *Manager) playerDataPath(serverID, world, playerUUID string) (string, error) {
+	id, err := uuid.Parse(playerUUID)
+	if err != nil {
+		return "", fmt.Errorf("invalid player UUID")
+	}
+	worldDir, err := m.findWorld(serverID, world)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(worldDir, "playerdata", id.String()+".dat")
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("no saved data for player %s", id)
+	}
+	return path, nil
+}
+
+// dimensionDir returns the folder holding the region folder of a dimension,
+// looking in the Bukkit sibling folders for the nether and the end.
+func dimensionDir(serverDir, world, dimension string) (string, error) {
+	if dimension == "" || dimension == "minecraft:overworld" {
+		return filepath.Join(serverDir, world), nil
+	}
+	for _, dim := range bukkitDimensions {
+		if dimension != dim.id {
+			continue
+		}
+		inside := filepath.Join(serverDir, world, dim.folder)
+		if _, err := os.Stat(inside); err == nil {
+			return inside, nil
+		}
+		return filepath.Join(serverDir, world+dim.suffix, dim.folder), nil
+	}
+
+	namespace, name, ok := strings.Cut(dimension, ":")
+	if !ok || namespace == "" || name == "" || strings.ContainsAny(dimension, `/\`) || strings.Contains(dimension, "..") {
+		return "", fmt.Errorf("invalid dimension %q", dimension)
+	}
+	return filepath.Join(serverDir, world, "dimensions", namespace, name), nil
+}
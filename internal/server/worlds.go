@@ -0,0 +1,495 @@
+package server
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"naviger/internal/nbt"
+	"naviger/internal/properties"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// World is a world folder of a server, recognised by its level.dat.
+type World struct {
+	Name       string      `json:"name"`
+	Active     bool        `json:"active"`
+	LevelName  string      `json:"levelName"`
+	Seed       string      `json:"seed"`
+	Version    string      `json:"version"`
+	Size       int64       `json:"size"`
+	Dimensions []Dimension `json:"dimensions"`
+}
+
+// Dimension is the part of a world holding the chunks of one dimension.
+type Dimension struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// ResetWorldOptions choose the seed a reset world is generated with. By
+// default the old seed is kept.
+type ResetWorldOptions struct {
+	Seed       string `json:"seed"`
+	RandomSeed bool   `json:"randomSeed"`
+}
+
+// Bukkit servers keep the nether and the end of a world in sibling folders,
+// each holding the usual DIM-1 or DIM1 folder.
+var bukkitDimensions = []struct{ suffix, folder, id string }{
+	{"_nether", "DIM-1", "minecraft:the_nether"},
+	{"_the_end", "DIM1", "minecraft:the_end"},
+}
+
+// overworldFolders hold the overworld's chunks, entities and points of
+// interest at the top of a world folder.
+var overworldFolders = []string{"region", "entities", "poi"}
+
+// ListWorlds returns the worlds of a server with their dimensions. The nether
+// and end folders of a Bukkit server are listed with their world.
+func (m *Manager) ListWorlds(serverID string) ([]World, error) {
+	dir, active, err := m.worldsDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	isWorld := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() && fileExists(filepath.Join(dir, entry.Name(), "level.dat")) {
+			isWorld[entry.Name()] = true
+		}
+	}
+
+	worlds := []World{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !isWorld[name] || isBukkitSibling(name, isWorld) {
+			continue
+		}
+		worlds = append(worlds, describeWorld(dir, name, name == active))
+	}
+	return worlds, nil
+}
+
+// ImportWorld extracts an uploaded world zip into a new world folder. The
+// world is found by its level.dat at any depth of the archive, and the
+// sibling nether and end folders of a Bukkit world are merged into it.
+func (m *Manager) ImportWorld(serverID, name string, archive io.ReaderAt, size int64) (*World, error) {
+	dir, active, err := m.worldsDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	folder := sanitizeFolderName(name)
+	if folder == "" || folder == "." || folder == ".." {
+		return nil, fmt.Errorf("invalid world name")
+	}
+	worldDir := filepath.Join(dir, folder)
+	if _, err := os.Stat(worldDir); err == nil {
+		return nil, fmt.Errorf("%s already exists", folder)
+	}
+
+	zr, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip file: %w", err)
+	}
+	prefix, ok := worldPrefix(zr.File)
+	if !ok {
+		return nil, fmt.Errorf("no level.dat found in the archive")
+	}
+
+	tempDir := filepath.Join(dir, "."+folder+".upload")
+	os.RemoveAll(tempDir)
+	if err := extractWorld(zr.File, prefix, tempDir); err != nil {
+		os.RemoveAll(tempDir)
+		return nil, err
+	}
+	if err := os.Rename(tempDir, worldDir); err != nil {
+		os.RemoveAll(tempDir)
+		return nil, err
+	}
+
+	world := describeWorld(dir, folder, folder == active)
+	return &world, nil
+}
+
+// GetWorld returns one world of a server.
+func (m *Manager) GetWorld(serverID, name string) (*World, error) {
+	dir, active, err := m.worldsDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.worldDir(dir, name); err != nil {
+		return nil, err
+	}
+	world := describeWorld(dir, name, name == active)
+	return &world, nil
+}
+
+// SetActiveWorld points level-name at a world, which the server loads the
+// next time it starts.
+func (m *Manager) SetActiveWorld(serverID, name string) error {
+	dir, _, err := m.worldsDir(serverID)
+	if err != nil {
+		return err
+	}
+	if _, err := m.worldDir(dir, name); err != nil {
+		return err
+	}
+	return properties.Update(dir, map[string]string{"level-name": name})
+}
+
+// ExportWorld writes a world as a zip in the vanilla layout, with the nether
+// and end of a Bukkit world moved into its DIM-1 and DIM1 folders so the zip
+// loads in single player and on any server.
+func (m *Manager) ExportWorld(serverID, name string, w io.Writer) error {
+	dir, _, err := m.worldsDir(serverID)
+	if err != nil {
+		return err
+	}
+	worldDir, err := m.worldDir(dir, name)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	if err := addTreeToZip(zw, worldDir, name); err != nil {
+		return err
+	}
+	for _, dim := range bukkitDimensions {
+		src := filepath.Join(dir, name+dim.suffix, dim.folder)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := addTreeToZip(zw, src, path.Join(name, dim.folder)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// ResetWorld deletes the active world, along with its Bukkit nether and end
+// folders, so the server generates it again on its next start. The server
+// must be stopped and backed up first.
+func (m *Manager) ResetWorld(serverID, name string, opts ResetWorldOptions) error {
+	dir, active, err := m.worldsDir(serverID)
+	if err != nil {
+		return err
+	}
+	worldDir, err := m.worldDir(dir, name)
+	if err != nil {
+		return err
+	}
+	if name != active {
+		return fmt.Errorf("only the active world can be reset")
+	}
+
+	seed := opts.Seed
+	if !opts.RandomSeed && seed == "" {
+		// An empty level-seed would give the new world a random seed.
+		seed, _, _ = readLevel(worldDir)
+	}
+
+	if err := os.RemoveAll(worldDir); err != nil {
+		return err
+	}
+	for _, dim := range bukkitDimensions {
+		if err := os.RemoveAll(filepath.Join(dir, name+dim.suffix)); err != nil {
+			return err
+		}
+	}
+	return properties.Update(dir, map[string]string{"level-seed": seed})
+}
+
+// PregenerateCommands returns the console commands that make the Chunky
+// plugin or mod generate the chunks within radius blocks of a world's spawn.
+func (m *Manager) PregenerateCommands(serverID, name string, radius int) ([]string, error) {
+	if radius <= 0 {
+		return nil, fmt.Errorf("radius must be positive")
+	}
+	dir, active, err := m.worldsDir(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.worldDir(dir, name); err != nil {
+		return nil, err
+	}
+
+	mods, err := m.ListMods(serverID)
+	if err != nil {
+		return nil, err
+	}
+	installed := false
+	for _, mod := range mods {
+		if strings.HasPrefix(strings.ToLower(mod.Name), "chunky") {
+			installed = true
+			break
+		}
+	}
+	if !installed {
+		return nil, fmt.Errorf("pregeneration needs the Chunky plugin or mod")
+	}
+
+	// Bukkit names worlds after their folder; the mods name dimensions and
+	// only load the active world.
+	target := name
+	if folder, _ := m.modsFolder(serverID); folder == "mods" {
+		if name != active {
+			return nil, fmt.Errorf("only the active world can be pregenerated with the Chunky mod")
+		}
+		target = "minecraft:overworld"
+	}
+	return []string{
+		"chunky world " + target,
+		"chunky radius " + strconv.Itoa(radius),
+		"chunky start",
+	}, nil
+}
+
+// worldsDir returns the server folder, where worlds live, and the name of
+// the world the server loads.
+func (m *Manager) worldsDir(serverID string) (string, string, error) {
+	srv, err := m.GetServer(serverID)
+	if err != nil {
+		return "", "", err
+	}
+	if srv == nil {
+		return "", "", fmt.Errorf("server not found")
+	}
+	dir := m.serverDir(srv)
+
+	active := "world"
+	if values, err := properties.Read(dir); err == nil {
+		if level := strings.TrimSpace(values["level-name"]); level != "" {
+			active = level
+		}
+	}
+	return dir, active, nil
+}
+
+func (m *Manager) worldDir(serverDir, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid world name")
+	}
+	worldDir := filepath.Join(serverDir, name)
+	if !fileExists(filepath.Join(worldDir, "level.dat")) {
+		return "", fmt.Errorf("world not found")
+	}
+	return worldDir, nil
+}
+
+func (m *Manager) modsFolder(serverID string) (string, error) {
+	srv, err := m.GetServer(serverID)
+	if err != nil || srv == nil {
+		return "", fmt.Errorf("server not found")
+	}
+	return ModsFolder(srv.Loader)
+}
+
+func isBukkitSibling(name string, isWorld map[string]bool) bool {
+	for _, dim := range bukkitDimensions {
+		if base, ok := strings.CutSuffix(name, dim.suffix); ok && isWorld[base] {
+			return true
+		}
+	}
+	return false
+}
+
+func describeWorld(serverDir, name string, active bool) World {
+	worldDir := filepath.Join(serverDir, name)
+	world := World{Name: name, Active: active, Size: dirSize(worldDir)}
+	world.Seed, world.Version, world.LevelName = readLevel(worldDir)
+
+	var overworld int64
+	for _, folder := range overworldFolders {
+		overworld += dirSize(filepath.Join(worldDir, folder))
+	}
+	world.Dimensions = append(world.Dimensions, Dimension{ID: "minecraft:overworld", Path: name, Size: overworld})
+
+	for _, dim := range bukkitDimensions {
+		for _, dimDir := range []string{path.Join(name, dim.folder), path.Join(name+dim.suffix, dim.folder)} {
+			if _, err := os.Stat(filepath.Join(serverDir, dimDir)); err != nil {
+				continue
+			}
+			size := dirSize(filepath.Join(serverDir, dimDir))
+			if dimDir != path.Join(name, dim.folder) {
+				world.Size += dirSize(filepath.Join(serverDir, name+dim.suffix))
+			}
+			world.Dimensions = append(world.Dimensions, Dimension{ID: dim.id, Path: dimDir, Size: size})
+		}
+	}
+
+	// Datapack and mod dimensions live in dimensions/<namespace>/<name>.
+	namespaces, _ := os.ReadDir(filepath.Join(worldDir, "dimensions"))
+	for _, namespace := range namespaces {
+		dims, _ := os.ReadDir(filepath.Join(worldDir, "dimensions", namespace.Name()))
+		for _, dim := range dims {
+			if !namespace.IsDir() || !dim.IsDir() {
+				continue
+			}
+			dimDir := path.Join(name, "dimensions", namespace.Name(), dim.Name())
+			world.Dimensions = append(world.Dimensions, Dimension{
+				ID:   namespace.Name() + ":" + dim.Name(),
+				Path: dimDir,
+				Size: dirSize(filepath.Join(serverDir, dimDir)),
+			})
+		}
+	}
+	return world
+}
+
+// readLevel returns the seed, game version and level name recorded in a
+// world's level.dat, leaving any it cannot read empty.
+func readLevel(worldDir string) (seed, version, levelName string) {
+	root, err := nbt.ReadFile(filepath.Join(worldDir, "level.dat"))
+	if err != nil {
+		return "", "", ""
+	}
+	// 1.16 moved the seed into WorldGenSettings.
+	for _, keys := range [][]string{{"Data", "WorldGenSettings", "seed"}, {"Data", "RandomSeed"}} {
+		if v, ok := nbt.Path(root, keys...).(int64); ok {
+			seed = strconv.FormatInt(v, 10)
+			break
+		}
+	}
+	version, _ = nbt.Path(root, "Data", "Version", "Name").(string)
+	levelName, _ = nbt.Path(root, "Data", "LevelName").(string)
+	return seed, version, levelName
+}
+
+// worldPrefix returns the folder of the shallowest level.dat in an archive.
+func worldPrefix(files []*zip.File) (string, bool) {
+	prefix, found := "", false
+	for _, f := range files {
+		name := strings.TrimPrefix(f.Name, "/")
+		if path.Base(name) != "level.dat" {
+			continue
+		}
+		dir := strings.TrimSuffix(name, "level.dat")
+		if !found || strings.Count(dir, "/") < strings.Count(prefix, "/") {
+			prefix, found = dir, true
+		}
+	}
+	return prefix, found
+}
+
+// extractWorld writes the archive entries under prefix into dest. Entries
+// of sibling Bukkit nether and end folders go into the world's DIM-1 and
+// DIM1 folders.
+func extractWorld(files []*zip.File, prefix, dest string) error {
+	siblings := map[string]string{}
+	if base := strings.TrimSuffix(prefix, "/"); base != "" {
+		for _, dim := range bukkitDimensions {
+			siblings[base+dim.suffix+"/"+dim.folder+"/"] = dim.folder + "/"
+		}
+	}
+
+	for _, f := range files {
+		name := strings.TrimPrefix(f.Name, "/")
+		rel, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			for from, to := range siblings {
+				if rest, found := strings.CutPrefix(name, from); found {
+					rel, ok = to+rest, true
+					break
+				}
+			}
+		}
+		if !ok || rel == "" || path.Base(rel) == "session.lock" {
+			continue
+		}
+
+		target := filepath.Join(dest, filepath.FromSlash(rel))
+		if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return fmt.Errorf("%s: illegal file path", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := extractZipFile(f, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(f *zip.File, target string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// addTreeToZip adds the files under src to zw below the folder prefix. The
+// session.lock file is skipped as the running server holds it.
+func addTreeToZip(zw *zip.Writer, src, prefix string) error {
+	return filepath.WalkDir(src, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || d.Name() == "session.lock" {
+			return nil
+		}
+		rel, err := filepath.Rel(src, filePath)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(prefix, filepath.ToSlash(rel))
+		header.Method = zip.Deflate
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(w, file)
+		return err
+	})
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
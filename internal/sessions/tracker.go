@@ -0,0 +1,202 @@
+// Package sessions turns player events from the console into stored play
+// sessions and summarises them per player.
+package sessions
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"naviger/internal/domain"
+	"naviger/internal/logevents"
+	"naviger/internal/storage"
+)
+
+type Tracker struct {
+	Store *storage.GormStore
+	// uuids holds the UUIDs logged during login until the matching join line
+	// arrives, keyed by server and player name.
+	uuids map[string]map[string]string
+	mu    sync.Mutex
+}
+
+type PlayerSummary struct {
+	Name            string    `json:"name"`
+	UUID            string    `json:"uuid"`
+	Online          bool      `json:"online"`
+	Sessions        int       `json:"sessions"`
+	PlaytimeSeconds int64     `json:"playtimeSeconds"`
+	FirstSeen       time.Time `json:"firstSeen"`
+	LastSeen        time.Time `json:"lastSeen"`
+	Deaths          int       `json:"deaths"`
+	Advancements    int       `json:"advancements"`
+	ChatMessages    int       `json:"chatMessages"`
+}
+
+func NewTracker(store *storage.GormStore) *Tracker {
+	return &Tracker{
+		Store: store,
+		uuids: make(map[string]map[string]string),
+	}
+}
+
+// ObserveLine records the player event on a console line, if there is one.
+func (t *Tracker) ObserveLine(serverID, line string) {
+	event, ok := logevents.Parse(line)
+	if !ok {
+		return
+	}
+	if err := t.handle(serverID, event, time.Now()); err != nil {
+		slog.Warn("Could not record player event", "server", serverID, "player", event.Player, "event", event.Type, "error", err)
+	}
+}
+
+// ServerExited ends the sessions of everyone still online when the server
+// process goes away, since no leave lines will follow.
+func (t *Tracker) ServerExited(serverID string, _ domain.ProcessExit) {
+	t.mu.Lock()
+	delete(t.uuids, serverID)
+	t.mu.Unlock()
+
+	if err := t.Store.CloseOpenPlayerSessions(serverID, time.Now()); err != nil {
+		slog.Warn("Could not close player sessions", "server", serverID, "error", err)
+	}
+}
+
+// CloseStale ends sessions left open by a daemon that did not shut down
+// cleanly. It must run before any server is started.
+func (t *Tracker) CloseStale() error {
+	return t.Store.CloseOpenPlayerSessions("", time.Now())
+}
+
+func (t *Tracker) handle(serverID string, event logevents.Event, now time.Time) error {
+	switch event.Type {
+	case logevents.UUID:
+		t.mu.Lock()
+		if t.uuids[serverID] == nil {
+			t.uuids[serverID] = make(map[string]string)
+		}
+		t.uuids[serverID][event.Player] = event.UUID
+		t.mu.Unlock()
+		return nil
+
+	case logevents.Join:
+		open, err := t.Store.GetOpenPlayerSession(serverID, event.Player)
+		if err != nil {
+			return err
+		}
+		if open != nil {
+			if err := t.Store.ClosePlayerSession(open.ID, now); err != nil {
+				return err
+			}
+		}
+
+		t.mu.Lock()
+		uuid := t.uuids[serverID][event.Player]
+		delete(t.uuids[serverID], event.Player)
+		t.mu.Unlock()
+
+		return t.Store.CreatePlayerSession(&domain.PlayerSession{
+			ServerID:   serverID,
+			PlayerName: event.Player,
+			PlayerUUID: uuid,
+			JoinedAt:   now,
+		})
+
+	case logevents.Leave:
+		open, err := t.Store.GetOpenPlayerSession(serverID, event.Player)
+		if err != nil || open == nil {
+			return err
+		}
+		return t.Store.ClosePlayerSession(open.ID, now)
+
+	case logevents.Death, logevents.Advancement, logevents.Chat:
+		open, err := t.Store.GetOpenPlayerSession(serverID, event.Player)
+		if err != nil || open == nil {
+			return err
+		}
+		counter := map[logevents.Type]string{
+			logevents.Death:       "deaths",
+			logevents.Advancement: "advancements",
+			logevents.Chat:        "chat_messages",
+		}[event.Type]
+		return t.Store.IncrementPlayerSessionCounter(open.ID, counter)
+	}
+	return nil
+}
+
+// Online returns the open session of every player currently on the server.
+func (t *Tracker) Online(serverID string) ([]domain.PlayerSession, error) {
+	return t.Store.ListOpenPlayerSessions(serverID)
+}
+
+// Summaries aggregates the stored sessions of a server per player, most
+// recently seen first.
+func (t *Tracker) Summaries(serverID string) ([]PlayerSummary, error) {
+	sessions, err := t.Store.ListPlayerSessions(serverID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*PlayerSummary)
+	var order []string
+	now := time.Now()
+	for _, session := range sessions {
+		summary, ok := byName[session.PlayerName]
+		if !ok {
+			summary = &PlayerSummary{Name: session.PlayerName, FirstSeen: session.JoinedAt}
+			byName[session.PlayerName] = summary
+			order = append(order, session.PlayerName)
+		}
+		addSession(summary, session, now)
+	}
+
+	summaries := make([]PlayerSummary, 0, len(order))
+	for _, name := range order {
+		summaries = append(summaries, *byName[name])
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].LastSeen.After(summaries[j].LastSeen)
+	})
+	return summaries, nil
+}
+
+// Summary returns the totals and the individual sessions of one player, or
+// nil when the player has never joined the server.
+func (t *Tracker) Summary(serverID, player string) (*PlayerSummary, []domain.PlayerSession, error) {
+	sessions, err := t.Store.ListPlayerSessions(serverID, player)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, nil, nil
+	}
+
+	summary := &PlayerSummary{Name: player, FirstSeen: sessions[0].JoinedAt}
+	now := time.Now()
+	for _, session := range sessions {
+		addSession(summary, session, now)
+	}
+	return summary, sessions, nil
+}
+
+func addSession(summary *PlayerSummary, session domain.PlayerSession, now time.Time) {
+	end := now
+	if session.LeftAt != nil {
+		end = *session.LeftAt
+	}
+
+	summary.Sessions++
+	summary.PlaytimeSeconds += int64(end.Sub(session.JoinedAt).Seconds())
+	summary.Deaths += session.Deaths
+	summary.Advancements += session.Advancements
+	summary.ChatMessages += session.ChatMessages
+	summary.Online = session.LeftAt == nil
+	if session.PlayerUUID != "" {
+		summary.UUID = session.PlayerUUID
+	}
+	if end.After(summary.LastSeen) {
+		summary.LastSeen = end
+	}
+}
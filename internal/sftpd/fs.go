@@ -0,0 +1,312 @@
+package sftpd
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"naviger/internal/server"
+	"naviger/internal/storage"
+
+	"github.com/pkg/sftp"
+)
+
+// fileSystem is the view of one SFTP session: a root folder with one
+// folder per server the user may access, named like the server's folder on
+// disk. Access is checked on every request so revoked permissions apply to
+// open sessions.
+type fileSystem struct {
+	userID  string
+	store   *storage.GormStore
+	manager *server.Manager
+}
+
+// target is a resolved request path. serverID is empty for the root.
+type target struct {
+	serverID string
+	root     string
+	path     string
+}
+
+func (t target) isServerRoot() bool {
+	return t.serverID != "" && t.path == t.root
+}
+
+// servers returns the folder names and IDs of the servers the user may
+// access: all of them for admins, otherwise those they may view the console
+// of, which is what the file API requires.
+func (fs *fileSystem) servers() (map[string]string, error) {
+	user, err := fs.store.GetUserByID(fs.userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, os.ErrPermission
+	}
+
+	allowed := map[string]bool{}
+	if user.Role != "admin" {
+		perms, err := fs.store.GetPermissions(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range perms {
+			if p.CanViewConsole {
+				allowed[p.ServerID] = true
+			}
+		}
+	}
+
+	servers, err := fs.store.ListServers()
+	if err != nil {
+		return nil, err
+	}
+	folders := make(map[string]string)
+	for _, srv := range servers {
+		if user.Role != "admin" && !allowed[srv.ID] {
+			continue
+		}
+		folder := srv.FolderName
+		if folder == "" {
+			folder = srv.ID
+		}
+		folders[folder] = srv.ID
+	}
+	return folders, nil
+}
+
+// resolve maps an SFTP path to a file of a server the user may access, with
+// the same rules as the file API. Symbolic links may not lead out of the
+// server folder either.
+func (fs *fileSystem) resolve(requestPath string) (target, error) {
+	clean := path.Clean("/" + requestPath)
+	if clean == "/" {
+		return target{}, nil
+	}
+	folder, rest, _ := strings.Cut(strings.TrimPrefix(clean, "/"), "/")
+
+	folders, err := fs.servers()
+	if err != nil {
+		return target{}, err
+	}
+	serverID, ok := folders[folder]
+	if !ok {
+		return target{}, os.ErrNotExist
+	}
+
+	root, err := fs.manager.ResolvePath(serverID, "/")
+	if err != nil {
+		return target{}, err
+	}
+	fullPath, err := fs.manager.ResolvePath(serverID, "/"+rest)
+	if err != nil {
+		return target{}, os.ErrPermission
+	}
+	if !insideRoot(root, fullPath) {
+		return target{}, os.ErrPermission
+	}
+	return target{serverID: serverID, root: root, path: fullPath}, nil
+}
+
+// insideRoot reports whether the deepest existing part of fullPath stays in
+// root once symbolic links are followed.
+func insideRoot(root, fullPath string) bool {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false
+	}
+	for p := fullPath; ; p = filepath.Dir(p) {
+		realPath, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			return realPath == realRoot || strings.HasPrefix(realPath, realRoot+string(os.PathSeparator))
+		}
+		if p == root || p == filepath.Dir(p) {
+			return false
+		}
+	}
+}
+
+func (fs *fileSystem) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	t, err := fs.resolve(r.Filepath)
+	if err != nil {
+		return nil, err
+	}
+	if t.serverID == "" {
+		return nil, os.ErrInvalid
+	}
+	return os.Open(t.path)
+}
+
+func (fs *fileSystem) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	t, err := fs.resolve(r.Filepath)
+	if err != nil {
+		return nil, err
+	}
+	if t.serverID == "" || t.isServerRoot() {
+		return nil, os.ErrPermission
+	}
+
+	// Appends are written at offsets like any other write, and os.File
+	// refuses WriteAt in append mode.
+	flags := os.O_WRONLY | os.O_CREATE
+	pflags := r.Pflags()
+	if pflags.Trunc {
+		flags |= os.O_TRUNC
+	}
+	if pflags.Excl {
+		flags |= os.O_EXCL
+	}
+	return os.OpenFile(t.path, flags, 0644)
+}
+
+func (fs *fileSystem) Filecmd(r *sftp.Request) error {
+	t, err := fs.resolve(r.Filepath)
+	if err != nil {
+		return err
+	}
+	// The root and the server folders themselves are fixed.
+	if t.serverID == "" || t.isServerRoot() {
+		return os.ErrPermission
+	}
+
+	switch r.Method {
+	case "Setstat":
+		return setstat(t.path, r)
+	case "Rename":
+		dest, err := fs.resolve(r.Target)
+		if err != nil {
+			return err
+		}
+		if dest.serverID == "" || dest.isServerRoot() {
+			return os.ErrPermission
+		}
+		return os.Rename(t.path, dest.path)
+	case "Rmdir":
+		info, err := os.Lstat(t.path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return sftp.ErrSSHFxFailure
+		}
+		return os.Remove(t.path)
+	case "Remove":
+		return os.Remove(t.path)
+	case "Mkdir":
+		return os.Mkdir(t.path, 0755)
+	case "Link", "Symlink":
+		// Links could point out of the server folder.
+		return sftp.ErrSSHFxOpUnsupported
+	}
+	return sftp.ErrSSHFxOpUnsupported
+}
+
+func setstat(fullPath string, r *sftp.Request) error {
+	flags := r.AttrFlags()
+	attrs := r.Attributes()
+	if flags.Size {
+		if err := os.Truncate(fullPath, int64(attrs.Size)); err != nil {
+			return err
+		}
+	}
+	if flags.Permissions {
+		if err := os.Chmod(fullPath, attrs.FileMode().Perm()); err != nil {
+			return err
+		}
+	}
+	if flags.Acmodtime {
+		if err := os.Chtimes(fullPath, attrs.AccessTime(), attrs.ModTime()); err != nil {
+			return err
+		}
+	}
+	// Owners are left alone: files belong to the daemon or the server's user.
+	return nil
+}
+
+func (fs *fileSystem) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	t, err := fs.resolve(r.Filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.Method {
+	case "List":
+		if t.serverID == "" {
+			return fs.listRoot()
+		}
+		entries, err := os.ReadDir(t.path)
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]os.FileInfo, 0, len(entries))
+		for _, entry := range entries {
+			if info, err := entry.Info(); err == nil {
+				infos = append(infos, info)
+			}
+		}
+		return listerAt(infos), nil
+	case "Stat", "Lstat":
+		if t.serverID == "" {
+			info, err := os.Stat(fs.manager.ServersPath)
+			if err != nil {
+				return nil, err
+			}
+			return listerAt{rootInfo{info}}, nil
+		}
+		stat := os.Stat
+		if r.Method == "Lstat" {
+			stat = os.Lstat
+		}
+		info, err := stat(t.path)
+		if err != nil {
+			return nil, err
+		}
+		return listerAt{info}, nil
+	}
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
+
+func (fs *fileSystem) listRoot() (sftp.ListerAt, error) {
+	folders, err := fs.servers()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(folders))
+	for folder := range folders {
+		names = append(names, folder)
+	}
+	sort.Strings(names)
+
+	infos := make([]os.FileInfo, 0, len(names))
+	for _, folder := range names {
+		if info, err := os.Stat(filepath.Join(fs.manager.ServersPath, folder)); err == nil && info.IsDir() {
+			infos = append(infos, info)
+		}
+	}
+	return listerAt(infos), nil
+}
+
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(ls []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(ls, l[offset:])
+	if n < len(ls) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// rootInfo names the root folder "/" rather than after the servers folder.
+type rootInfo struct {
+	os.FileInfo
+}
+
+func (rootInfo) Name() string {
+	return "/"
+}
@@ -0,0 +1,204 @@
+// Package sftpd serves the folders of Minecraft servers over SFTP. Users
+// sign in with their Naviger password or a registered SSH key and see one
+// folder per server they may access.
+package sftpd
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"naviger/internal/server"
+	"naviger/internal/storage"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+// userIDExtension carries the ID of the signed in user from authentication
+// to the session.
+const userIDExtension = "naviger-user-id"
+
+// failedAuthDelay slows down password guessing.
+const failedAuthDelay = time.Second
+
+// dummyHash is compared against for unknown usernames, so they take as long
+// to reject as wrong passwords and cannot be told apart by timing.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("naviger"), bcrypt.DefaultCost)
+	return hash
+})
+
+type Server struct {
+	Store   *storage.GormStore
+	Manager *server.Manager
+	config  *ssh.ServerConfig
+
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+func NewServer(store *storage.GormStore, manager *server.Manager, hostKey ssh.Signer) *Server {
+	s := &Server{Store: store, Manager: manager}
+	s.config = &ssh.ServerConfig{
+		PasswordCallback:  s.checkPassword,
+		PublicKeyCallback: s.checkPublicKey,
+		ServerVersion:     "SSH-2.0-Naviger",
+	}
+	s.config.AddHostKey(hostKey)
+	return s
+}
+
+// LoadOrGenerateHostKey reads the server's host key from path, creating an
+// Ed25519 key there on first use.
+func LoadOrGenerateHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return ssh.ParsePrivateKey(data)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ssh.MarshalPrivateKey(key, "naviger")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		return nil, err
+	}
+	return ssh.NewSignerFromKey(key)
+}
+
+// ListenAndServe accepts SFTP connections on addr until ctx is done.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve accepts SFTP connections on listener until ctx is done.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// Addr returns the address the server listens on, once it is serving.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+func (s *Server) checkPassword(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	user, err := s.Store.GetUserByUsername(meta.User())
+	if err != nil || user == nil {
+		bcrypt.CompareHashAndPassword(dummyHash(), password)
+	} else if bcrypt.CompareHashAndPassword([]byte(user.Password), password) == nil {
+		return &ssh.Permissions{Extensions: map[string]string{userIDExtension: user.ID}}, nil
+	}
+	time.Sleep(failedAuthDelay)
+	return nil, fmt.Errorf("invalid credentials for %s", meta.User())
+}
+
+func (s *Server) checkPublicKey(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	registered, err := s.Store.GetSSHKeyByFingerprint(ssh.FingerprintSHA256(key))
+	if err != nil || registered == nil {
+		return nil, fmt.Errorf("unknown key for %s", meta.User())
+	}
+	user, err := s.Store.GetUserByID(registered.UserID)
+	if err != nil || user == nil || user.Username != meta.User() {
+		return nil, fmt.Errorf("unknown key for %s", meta.User())
+	}
+	return &ssh.Permissions{Extensions: map[string]string{userIDExtension: user.ID}}, nil
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	// Clients that never finish the handshake should not hold a connection.
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+	sshConn, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	defer sshConn.Close()
+	conn.SetDeadline(time.Time{})
+	go ssh.DiscardRequests(requests)
+
+	userID := sshConn.Permissions.Extensions[userIDExtension]
+	slog.Info("SFTP session opened", "user", sshConn.User(), "remote", sshConn.RemoteAddr().String())
+	defer slog.Info("SFTP session closed", "user", sshConn.User())
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.handleSession(userID, channel, channelRequests)
+	}
+}
+
+// handleSession serves the sftp subsystem and refuses shells and commands.
+func (s *Server) handleSession(userID string, channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for req := range requests {
+		// The payload is the subsystem name as an SSH string.
+		if req.Type != "subsystem" || len(req.Payload) < 4 || string(req.Payload[4:]) != "sftp" {
+			req.Reply(false, nil)
+			continue
+		}
+		req.Reply(true, nil)
+		go ssh.DiscardRequests(requests)
+
+		handler := &fileSystem{userID: userID, store: s.Store, manager: s.Manager}
+		handlers := sftp.Handlers{FileGet: handler, FilePut: handler, FileCmd: handler, FileList: handler}
+		sftpServer := sftp.NewRequestServer(channel, handlers)
+		if err := sftpServer.Serve(); err != nil && !errors.Is(err, io.EOF) {
+			slog.Warn("SFTP session failed", "error", err)
+		}
+		sftpServer.Close()
+		return
+	}
+}
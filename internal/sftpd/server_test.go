@@ -0,0 +1,200 @@
+package sftpd
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"naviger/internal/domain"
+	"naviger/internal/server"
+	"naviger/internal/storage"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+type testEnv struct {
+	addr    string
+	servers string
+	store   *storage.GormStore
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := storage.NewGormStore(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	serversPath := filepath.Join(dir, "servers")
+	manager := server.NewManager(serversPath, filepath.Join(dir, "templates"), store)
+
+	for _, srv := range []domain.Server{
+		{ID: "id-survival", Name: "Survival", FolderName: "survival"},
+		{ID: "id-creative", Name: "Creative", FolderName: "creative"},
+		{ID: "id-survival2", Name: "Survival 2", FolderName: "survival2"},
+	} {
+		if err := store.SaveServer(&srv); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(serversPath, srv.FolderName), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(serversPath, "creative", "secret.txt"), []byte("secret"), 0644)
+	os.WriteFile(filepath.Join(serversPath, "survival2", "secret.txt"), []byte("secret"), 0644)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	if err := store.CreateUser(&domain.User{ID: "u1", Username: "alice", Password: string(hash), Role: "user"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetPermissions([]domain.Permission{{UserID: "u1", ServerID: "id-survival", CanViewConsole: true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	hostKey, err := LoadOrGenerateHostKey(filepath.Join(dir, "host_key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go NewServer(store, manager, hostKey).Serve(ctx, listener)
+
+	return &testEnv{addr: listener.Addr().String(), servers: serversPath, store: store}
+}
+
+func (e *testEnv) dial(t *testing.T, user string, auth ssh.AuthMethod) (*sftp.Client, error) {
+	t.Helper()
+	conn, err := ssh.Dial("tcp", e.addr, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	t.Cleanup(func() { client.Close(); conn.Close() })
+	return client, nil
+}
+
+func TestSFTPChroot(t *testing.T) {
+	env := newTestEnv(t)
+	client, err := env.dial(t, "alice", ssh.Password("hunter2"))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	entries, err := client.ReadDir("/")
+	if err != nil {
+		t.Fatalf("ReadDir /: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "survival" {
+		t.Fatalf("root lists %v, want only survival", entries)
+	}
+
+	f, err := client.Create("/survival/ops.txt")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	f.Write([]byte("alice"))
+	f.Close()
+	data, err := os.ReadFile(filepath.Join(env.servers, "survival", "ops.txt"))
+	if err != nil || string(data) != "alice" {
+		t.Fatalf("uploaded file = %q, %v", data, err)
+	}
+
+	if err := client.Mkdir("/survival/plugins"); err != nil {
+		t.Errorf("Mkdir: %v", err)
+	}
+	if err := client.Rename("/survival/ops.txt", "/survival/plugins/ops.txt"); err != nil {
+		t.Errorf("Rename: %v", err)
+	}
+
+	for _, path := range []string{
+		"/creative/secret.txt",
+		"/survival/../creative/secret.txt",
+		"/survival/../survival2/secret.txt",
+		"/../survival2/secret.txt",
+	} {
+		if f, err := client.Open(path); err == nil {
+			content, _ := io.ReadAll(f)
+			f.Close()
+			t.Errorf("opened %s: %q", path, content)
+		}
+	}
+
+	if err := client.Remove("/survival"); err == nil {
+		t.Error("removed the server folder")
+	}
+	if err := client.Rename("/survival/plugins/ops.txt", "/creative/ops.txt"); err == nil {
+		t.Error("moved a file into a server without permission")
+	}
+	if err := client.Symlink("/", "/survival/escape"); err == nil {
+		t.Error("created a symbolic link")
+	}
+
+	// A link made on disk, by a plugin for instance, is not followed out.
+	os.Symlink(filepath.Join(env.servers, "creative"), filepath.Join(env.servers, "survival", "link"))
+	if _, err := client.Open("/survival/link/secret.txt"); err == nil {
+		t.Error("followed a symbolic link out of the server folder")
+	}
+}
+
+func TestSFTPAuth(t *testing.T) {
+	env := newTestEnv(t)
+
+	if _, err := env.dial(t, "alice", ssh.Password("wrong")); err == nil {
+		t.Error("signed in with a wrong password")
+	}
+	if _, err := env.dial(t, "nobody", ssh.Password("hunter2")); err == nil {
+		t.Error("signed in as an unknown user")
+	}
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	signer, _ := ssh.NewSignerFromKey(key)
+	if _, err := env.dial(t, "alice", ssh.PublicKeys(signer)); err == nil {
+		t.Error("signed in with an unregistered key")
+	}
+
+	env.store.AddSSHKey(&domain.SSHKey{
+		ID:          "k1",
+		UserID:      "u1",
+		PublicKey:   string(ssh.MarshalAuthorizedKey(signer.PublicKey())),
+		Fingerprint: ssh.FingerprintSHA256(signer.PublicKey()),
+	})
+	if _, err := env.dial(t, "bob", ssh.PublicKeys(signer)); err == nil {
+		t.Error("signed in as another user with a registered key")
+	}
+	client, err := env.dial(t, "alice", ssh.PublicKeys(signer))
+	if err != nil {
+		t.Fatalf("dial with a registered key: %v", err)
+	}
+	if _, err := client.Stat("/survival"); err != nil {
+		t.Errorf("Stat: %v", err)
+	}
+
+	// Revoked permissions apply to open sessions.
+	if err := env.store.SetPermissions([]domain.Permission{{UserID: "u1", ServerID: "id-survival"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Stat("/survival"); err == nil {
+		t.Error("server still visible after its permission was revoked")
+	}
+}
@@ -0,0 +1,112 @@
+package statshistory
+
+import (
+	"fmt"
+	"time"
+
+	"naviger/internal/domain"
+)
+
+// History is the stats of one server over a time range, one point per step.
+// Steps without a point are times the server was not running.
+type History struct {
+	ServerID string    `json:"serverId"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	// Step and Resolution are in seconds. Resolution is the step of the tier
+	// the points were read from.
+	Step       int                 `json:"step"`
+	Resolution int                 `json:"resolution"`
+	Points     []domain.StatSample `json:"points"`
+}
+
+// History returns the stats of a server over the last rng, averaged into
+// buckets of step. The step is raised to the resolution of the tier that
+// covers the range when it is finer than that.
+func (r *Recorder) History(serverID string, rng, step time.Duration) (*History, error) {
+	if rng <= 0 || rng > r.MaxRange() {
+		return nil, fmt.Errorf("range must be between 0 and %s", r.MaxRange())
+	}
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+
+	tier := r.tierFor(rng, step)
+	step = max(step, tier.Step).Truncate(time.Second)
+
+	to := time.Now().UTC()
+	from := to.Add(-rng).Truncate(step)
+	samples, err := r.Store.ListStatSamples(serverID, tier.Step, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &History{
+		ServerID:   serverID,
+		From:       from,
+		To:         to,
+		Step:       int(step / time.Second),
+		Resolution: int(tier.Step / time.Second),
+		Points:     Downsample(samples, step),
+	}, nil
+}
+
+// tierFor picks the coarsest tier that still has the whole range and is no
+// coarser than step, falling back to the finest tier that has the range.
+func (r *Recorder) tierFor(rng, step time.Duration) Tier {
+	for i := len(r.Tiers) - 1; i >= 0; i-- {
+		if tier := r.Tiers[i]; tier.Keep >= rng && tier.Step <= step {
+			return tier
+		}
+	}
+	for _, tier := range r.Tiers {
+		if tier.Keep >= rng {
+			return tier
+		}
+	}
+	return r.Tiers[len(r.Tiers)-1]
+}
+
+// Downsample merges samples into buckets of step per server: CPU and RAM are
+// averaged, the peaks kept and disk usage taken from the newest sample.
+// Samples must be ordered by time; the result is too.
+func Downsample(samples []domain.StatSample, step time.Duration) []domain.StatSample {
+	type bucket struct {
+		sample domain.StatSample
+		cpu    float64
+		ram    float64
+		count  int
+	}
+
+	type key struct {
+		serverID string
+		at       int64
+	}
+
+	var buckets []*bucket
+	index := make(map[key]*bucket)
+	for _, s := range samples {
+		at := s.Time.Truncate(step)
+		k := key{s.ServerID, at.Unix()}
+		b, ok := index[k]
+		if !ok {
+			b = &bucket{sample: domain.StatSample{ServerID: s.ServerID, Time: at}}
+			index[k] = b
+			buckets = append(buckets, b)
+		}
+		b.cpu += s.CPU
+		b.ram += float64(s.RAM)
+		b.count++
+		b.sample.CPUMax = max(b.sample.CPUMax, s.CPUMax)
+		b.sample.RAMMax = max(b.sample.RAMMax, s.RAMMax)
+		b.sample.Disk = s.Disk
+	}
+
+	result := make([]domain.StatSample, 0, len(buckets))
+	for _, b := range buckets {
+		b.sample.CPU = b.cpu / float64(b.count)
+		b.sample.RAM = uint64(b.ram / float64(b.count))
+		result = append(result, b.sample)
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package statshistory
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"naviger/internal/domain"
+)
+
+func at(clock string) time.Time {
+	t, err := time.Parse(time.DateTime, "2026-10-18 "+clock)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func sample(serverID, clock string, cpu float64, ram uint64, disk int64) domain.StatSample {
+	return domain.StatSample{ServerID: serverID, Time: at(clock), CPU: cpu, CPUMax: cpu, RAM: ram, RAMMax: ram, Disk: disk}
+}
+
+func TestDownsample(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []domain.StatSample
+		step    time.Duration
+		want    []domain.StatSample
+	}{
+		{
+			name: "empty",
+			step: time.Minute,
+			want: []domain.StatSample{},
+		},
+		{
+			name: "bucket edges",
+			samples: []domain.StatSample{
+				sample("a", "12:00:00", 10, 100, 1),
+				sample("a", "12:00:59", 30, 300, 2),
+				sample("a", "12:01:00", 50, 500, 3),
+			},
+			step: time.Minute,
+			want: []domain.StatSample{
+				{ServerID: "a", Time: at("12:00:00"), CPU: 20, CPUMax: 30, RAM: 200, RAMMax: 300, Disk: 2},
+				{ServerID: "a", Time: at("12:01:00"), CPU: 50, CPUMax: 50, RAM: 500, RAMMax: 500, Disk: 3},
+			},
+		},
+		{
+			name: "interleaved servers",
+			samples: []domain.StatSample{
+				sample("a", "12:00:00", 10, 100, 1),
+				sample("b", "12:00:10", 80, 800, 8),
+				sample("a", "12:00:20", 20, 200, 2),
+				sample("b", "12:00:30", 60, 600, 9),
+				sample("b", "12:01:05", 40, 400, 10),
+			},
+			step: time.Minute,
+			want: []domain.StatSample{
+				{ServerID: "a", Time: at("12:00:00"), CPU: 15, CPUMax: 20, RAM: 150, RAMMax: 200, Disk: 2},
+				{ServerID: "b", Time: at("12:00:00"), CPU: 70, CPUMax: 80, RAM: 700, RAMMax: 800, Disk: 9},
+				{ServerID: "b", Time: at("12:01:00"), CPU: 40, CPUMax: 40, RAM: 400, RAMMax: 400, Disk: 10},
+			},
+		},
+		{
+			name: "peaks of rolled up buckets",
+			samples: []domain.StatSample{
+				{ServerID: "a", Time: at("12:00:00"), CPU: 10, CPUMax: 90, RAM: 100, RAMMax: 900},
+				{ServerID: "a", Time: at("12:01:00"), CPU: 30, CPUMax: 40, RAM: 300, RAMMax: 400},
+			},
+			step: 15 * time.Minute,
+			want: []domain.StatSample{
+				{ServerID: "a", Time: at("12:00:00"), CPU: 20, CPUMax: 90, RAM: 200, RAMMax: 900},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Downsample(tt.samples, tt.step)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Downsample mismatch\n got: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTierFor(t *testing.T) {
+	r := NewRecorder(nil, nil, 10*time.Second)
+	raw, minute, quarter := r.Tiers[0], r.Tiers[1], r.Tiers[2]
+
+	tests := []struct {
+		name string
+		rng  time.Duration
+		step time.Duration
+		want Tier
+	}{
+		{"raw step", time.Hour, 10 * time.Second, raw},
+		{"step finer than every tier", time.Hour, time.Second, raw},
+		{"range at a tier's keep", 6 * time.Hour, 10 * time.Second, raw},
+		{"coarsest tier no coarser than step", time.Hour, time.Minute, minute},
+		{"step between tiers", time.Hour, 5 * time.Minute, minute},
+		{"step coarser than every tier", time.Hour, 24 * time.Hour, quarter},
+		{"range longer than the raw keep", 24 * time.Hour, 10 * time.Second, minute},
+		{"range longer than the minute keep", 7 * 24 * time.Hour, time.Minute, quarter},
+		{"range longer than every keep", 60 * 24 * time.Hour, time.Minute, quarter},
+	}
+
+	for _, tt := range tests {
+		if got := r.tierFor(tt.rng, tt.step); got != tt.want {
+			t.Errorf("%s: tierFor(%s, %s) = %+v, want %+v", tt.name, tt.rng, tt.step, got, tt.want)
+		}
+	}
+}
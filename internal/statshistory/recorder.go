@@ -0,0 +1,166 @@
+// Package statshistory keeps a downsampled history of server resource usage
+// so dashboards can show trends instead of only the current numbers.
+package statshistory
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"naviger/internal/domain"
+	"naviger/internal/runner"
+	"naviger/internal/storage"
+)
+
+const (
+	// DefaultInterval is how often running servers are sampled.
+	DefaultInterval = 10 * time.Second
+	// MaxInterval keeps raw samples finer than the first rollup tier.
+	MaxInterval = 30 * time.Second
+)
+
+// Tier is one resolution of the history: samples Step apart, kept for Keep.
+type Tier struct {
+	Step time.Duration
+	Keep time.Duration
+}
+
+// Recorder samples the stats of running servers into the raw tier and rolls
+// each tier up into the next, coarser one once its buckets are complete.
+type Recorder struct {
+	Store      *storage.GormStore
+	Supervisor *runner.Supervisor
+	// Tiers goes from finest to coarsest; the first step is the sampling
+	// interval.
+	Tiers []Tier
+	// rolledUntil is, per tier, the end of the last bucket written.
+	rolledUntil []time.Time
+}
+
+func NewRecorder(store *storage.GormStore, supervisor *runner.Supervisor, interval time.Duration) *Recorder {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	interval = min(interval.Truncate(time.Second), MaxInterval)
+
+	tiers := []Tier{
+		{Step: interval, Keep: 6 * time.Hour},
+		{Step: time.Minute, Keep: 48 * time.Hour},
+		{Step: 15 * time.Minute, Keep: 30 * 24 * time.Hour},
+	}
+	return &Recorder{
+		Store:       store,
+		Supervisor:  supervisor,
+		Tiers:       tiers,
+		rolledUntil: make([]time.Time, len(tiers)),
+	}
+}
+
+// MaxRange is how far back the history goes.
+func (r *Recorder) MaxRange() time.Duration {
+	return r.Tiers[len(r.Tiers)-1].Keep
+}
+
+// Run samples every interval and compacts every rollup step until ctx is
+// done.
+func (r *Recorder) Run(ctx context.Context) {
+	sampleTicker := time.NewTicker(r.Tiers[0].Step)
+	defer sampleTicker.Stop()
+	compactTicker := time.NewTicker(r.Tiers[1].Step)
+	defer compactTicker.Stop()
+
+	r.Compact(time.Now())
+	for {
+		select {
+		case now := <-sampleTicker.C:
+			r.Sample(now)
+		case now := <-compactTicker.C:
+			r.Compact(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sample records the current stats of every running server.
+func (r *Recorder) Sample(now time.Time) {
+	servers, err := r.Store.ListServers()
+	if err != nil {
+		return
+	}
+
+	at := now.UTC().Truncate(time.Second)
+	samples := make([]domain.StatSample, 0, len(servers))
+	for _, srv := range servers {
+		if _, running := r.Supervisor.Uptime(srv.ID); !running {
+			continue
+		}
+		stats, err := r.Supervisor.GetServerStats(srv.ID)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, domain.StatSample{
+			ServerID: srv.ID,
+			Time:     at,
+			CPU:      stats.CPU,
+			CPUMax:   stats.CPU,
+			RAM:      stats.RAM,
+			RAMMax:   stats.RAM,
+			Disk:     stats.Disk,
+		})
+	}
+
+	if err := r.Store.AddStatSamples(r.Tiers[0].Step, samples); err != nil {
+		slog.Warn("Failed to record stats history", "error", err)
+	}
+}
+
+// Compact rolls every complete bucket up into the next tier and drops
+// samples older than their tier keeps them.
+func (r *Recorder) Compact(now time.Time) {
+	now = now.UTC()
+	for i := 1; i < len(r.Tiers); i++ {
+		if err := r.rollup(i, now); err != nil {
+			slog.Warn("Failed to roll up stats history", "step", r.Tiers[i].Step, "error", err)
+			return
+		}
+	}
+
+	for _, tier := range r.Tiers {
+		if err := r.Store.DeleteStatSamplesBefore(tier.Step, now.Add(-tier.Keep)); err != nil {
+			slog.Warn("Failed to prune stats history", "step", tier.Step, "error", err)
+		}
+	}
+}
+
+func (r *Recorder) rollup(i int, now time.Time) error {
+	tier, source := r.Tiers[i], r.Tiers[i-1]
+	end := now.Truncate(tier.Step)
+
+	start := r.rolledUntil[i]
+	if start.IsZero() {
+		// Resume after the newest bucket written before a restart.
+		latest, err := r.Store.LatestStatSampleTime(tier.Step)
+		if err != nil {
+			return err
+		}
+		if latest.IsZero() {
+			start = end.Add(-source.Keep).Truncate(tier.Step)
+		} else {
+			start = latest.Add(tier.Step)
+		}
+	}
+	if !start.Before(end) {
+		return nil
+	}
+
+	samples, err := r.Store.ListStatSamples("", source.Step, start, end)
+	if err != nil {
+		return err
+	}
+	if err := r.Store.AddStatSamples(tier.Step, Downsample(samples, tier.Step)); err != nil {
+		return err
+	}
+	r.rolledUntil[i] = end
+	return nil
+}
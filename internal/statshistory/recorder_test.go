@@ -0,0 +1,99 @@
+package statshistory
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"naviger/internal/domain"
+	"naviger/internal/storage"
+)
+
+func newTestRecorder(t *testing.T) (*Recorder, *storage.GormStore) {
+	t.Helper()
+	store, err := storage.NewGormStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	return NewRecorder(store, nil, 10*time.Second), store
+}
+
+// addRaw records a sample of each server every 10 seconds in [from, to).
+func addRaw(t *testing.T, store *storage.GormStore, from, to string, serverIDs ...string) {
+	t.Helper()
+	var samples []domain.StatSample
+	for ts := at(from); ts.Before(at(to)); ts = ts.Add(10 * time.Second) {
+		for _, id := range serverIDs {
+			samples = append(samples, domain.StatSample{ServerID: id, Time: ts, CPU: 10, CPUMax: 10, RAM: 100, RAMMax: 100})
+		}
+	}
+	if err := store.AddStatSamples(10*time.Second, samples); err != nil {
+		t.Fatalf("Failed to add samples: %v", err)
+	}
+}
+
+func minuteBuckets(t *testing.T, store *storage.GormStore, serverID string) []string {
+	t.Helper()
+	samples, err := store.ListStatSamples(serverID, time.Minute, at("00:00:00"), at("23:59:59"))
+	if err != nil {
+		t.Fatalf("Failed to list samples: %v", err)
+	}
+	buckets := []string{}
+	for _, s := range samples {
+		buckets = append(buckets, s.Time.Format(time.TimeOnly))
+	}
+	return buckets
+}
+
+func TestRollup(t *testing.T) {
+	r, store := newTestRecorder(t)
+	addRaw(t, store, "12:00:00", "12:03:30", "a", "b")
+
+	// Only complete buckets are rolled up.
+	r.Compact(at("12:03:30"))
+	for _, id := range []string{"a", "b"} {
+		if got, want := minuteBuckets(t, store, id), []string{"12:00:00", "12:01:00", "12:02:00"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: minute buckets %v, want %v", id, got, want)
+		}
+	}
+
+	// The next compaction continues where the last one stopped.
+	addRaw(t, store, "12:03:30", "12:04:10", "a")
+	r.Compact(at("12:04:10"))
+	if got, want := minuteBuckets(t, store, "a"), []string{"12:00:00", "12:01:00", "12:02:00", "12:03:00"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("minute buckets %v, want %v", got, want)
+	}
+	samples, _ := store.ListStatSamples("a", time.Minute, at("12:03:00"), at("12:04:00"))
+	if len(samples) != 1 || samples[0].CPU != 10 || samples[0].RAM != 100 {
+		t.Errorf("12:03 bucket is %+v, want one averaged sample", samples)
+	}
+
+	// A restarted recorder resumes after the newest bucket instead of writing
+	// the same ones again.
+	restarted := NewRecorder(store, nil, 10*time.Second)
+	addRaw(t, store, "12:04:10", "12:05:00", "a")
+	restarted.Compact(at("12:05:00"))
+	if got, want := minuteBuckets(t, store, "a"), []string{"12:00:00", "12:01:00", "12:02:00", "12:03:00", "12:04:00"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after restart, minute buckets %v, want %v", got, want)
+	}
+}
+
+func TestCompactPrunes(t *testing.T) {
+	r, store := newTestRecorder(t)
+	addRaw(t, store, "00:00:00", "00:01:00", "a")
+	addRaw(t, store, "12:00:00", "12:01:00", "a")
+
+	r.Compact(at("12:01:00"))
+	raw, err := store.ListStatSamples("a", 10*time.Second, at("00:00:00"), at("23:59:59"))
+	if err != nil {
+		t.Fatalf("Failed to list samples: %v", err)
+	}
+	if len(raw) != 6 || !raw[0].Time.Equal(at("12:00:00")) {
+		t.Errorf("raw samples after pruning: %+v, want the 6 from 12:00", raw)
+	}
+	// The first rollup goes back as far as raw samples are kept.
+	if got := minuteBuckets(t, store, "a"); !reflect.DeepEqual(got, []string{"12:00:00"}) {
+		t.Errorf("minute buckets %v, want only the kept samples rolled up", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"naviger/internal/domain"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/glebarez/sqlite"
@@ -15,16 +16,26 @@ import (
 )
 
 type Server struct {
-	ID         string `gorm:"primaryKey"`
-	Name       string
-	FolderName string
-	Version    string
-	Loader     string
-	Port       int
-	RAM        int
-	Status     string
-	CustomArgs string
-	CreatedAt  time.Time
+	ID          string `gorm:"primaryKey"`
+	Name        string
+	FolderName  string
+	Version     string
+	Loader      string
+	Port        int
+	RAM         int
+	Status      string
+	CustomArgs  string
+	JavaVersion int
+	MemoryMaxMB int
+	CPUWeight   int
+	CPUQuota    int
+	PidsMax     int
+	IOWeight    int
+	UID         int
+	RunMode     string
+	JVMProfile  string
+	JavaRuntime string
+	CreatedAt   time.Time
 }
 
 type Setting struct {
@@ -46,12 +57,136 @@ type Permission struct {
 	CanControlPower bool
 }
 
+type SSHKey struct {
+	ID          string `gorm:"primaryKey"`
+	UserID      string `gorm:"index"`
+	Name        string
+	PublicKey   string
+	Fingerprint string `gorm:"uniqueIndex"`
+	CreatedAt   time.Time
+}
+
 type PublicLink struct {
 	Token    string `gorm:"primaryKey"`
 	ServerID string
 	Action   string
 }
 
+type Network struct {
+	ID               string `gorm:"primaryKey"`
+	Name             string
+	ProxyID          string `gorm:"uniqueIndex"`
+	ForwardingSecret string
+	CreatedAt        time.Time
+}
+
+type NetworkServer struct {
+	NetworkID string `gorm:"primaryKey"`
+	ServerID  string `gorm:"primaryKey"`
+	Alias     string
+	Priority  int
+}
+
+type Template struct {
+	ID             string `gorm:"primaryKey"`
+	Name           string
+	Loader         string
+	Version        string
+	RAM            int
+	CustomArgs     string
+	JavaVersion    int
+	SourceServerID string
+	CreatedAt      time.Time
+}
+
+type PlayerSession struct {
+	ID           uint   `gorm:"primaryKey"`
+	ServerID     string `gorm:"index:idx_player_session"`
+	PlayerName   string `gorm:"index:idx_player_session"`
+	PlayerUUID   string
+	JoinedAt     time.Time
+	LeftAt       *time.Time
+	Deaths       int
+	Advancements int
+	ChatMessages int
+}
+
+type Incident struct {
+	ID              string `gorm:"primaryKey"`
+	ServerID        string `gorm:"index"`
+	ExitCode        int
+	Cause           string
+	Summary         string
+	Evidence        string
+	CrashReport     string
+	CrashReportText string
+	JVMErrorLog     string
+	JVMErrorLogText string
+	ConsoleTail     string
+	StartedAt       time.Time
+	CreatedAt       time.Time
+}
+
+type NotificationSink struct {
+	ID           string `gorm:"primaryKey"`
+	Name         string
+	Type         string
+	Enabled      bool
+	Events       string
+	URL          string
+	Secret       string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	EmailFrom    string
+	EmailTo      string
+	CreatedAt    time.Time
+}
+
+type DeadLetter struct {
+	ID        uint `gorm:"primaryKey"`
+	SinkID    string
+	SinkName  string
+	EventType string
+	Payload   string
+	Error     string
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// StatSample is one point of a server's stats history. Timestamp is stored
+// as Unix seconds and Resolution as the step in seconds of the tier it
+// belongs to.
+type StatSample struct {
+	ID         uint   `gorm:"primaryKey"`
+	Resolution int    `gorm:"index:idx_stat_sample"`
+	ServerID   string `gorm:"index:idx_stat_sample"`
+	Timestamp  int64  `gorm:"index:idx_stat_sample"`
+	CPU        float64
+	CPUMax     float64
+	RAM        uint64
+	RAMMax     uint64
+	Disk       int64
+}
+
+type JavaRuntime struct {
+	ID        string `gorm:"primaryKey"`
+	Home      string
+	CreatedAt time.Time
+}
+
+type Schedule struct {
+	ID        string `gorm:"primaryKey"`
+	ServerID  string `gorm:"index"`
+	Cron      string
+	Action    string
+	Command   string
+	LastRunAt time.Time
+	LastError string
+	CreatedAt time.Time
+}
+
 type GormStore struct {
 	db *gorm.DB
 }
@@ -70,7 +205,7 @@ func NewGormStore(path string) (*GormStore, error) {
 		return nil, err
 	}
 
-	err = db.AutoMigrate(&Server{}, &Setting{}, &User{}, &Permission{}, &PublicLink{})
+	err = db.AutoMigrate(&Server{}, &Setting{}, &User{}, &Permission{}, &SSHKey{}, &PublicLink{}, &Network{}, &NetworkServer{}, &Template{}, &PlayerSession{}, &Incident{}, &NotificationSink{}, &DeadLetter{}, &StatSample{}, &JavaRuntime{}, &Schedule{})
 	if err != nil {
 		return nil, fmt.Errorf("error migrating database: %w", err)
 	}
@@ -109,16 +244,26 @@ func (s *GormStore) initDefaultSettings() error {
 
 func (s *GormStore) SaveServer(srv *domain.Server) error {
 	gormServer := &Server{
-		ID:         srv.ID,
-		Name:       srv.Name,
-		FolderName: srv.FolderName,
-		Version:    srv.Version,
-		Loader:     srv.Loader,
-		Port:       srv.Port,
-		RAM:        srv.RAM,
-		Status:     srv.Status,
-		CustomArgs: srv.CustomArgs,
-		CreatedAt:  srv.CreatedAt,
+		ID:          srv.ID,
+		Name:        srv.Name,
+		FolderName:  srv.FolderName,
+		Version:     srv.Version,
+		Loader:      srv.Loader,
+		Port:        srv.Port,
+		RAM:         srv.RAM,
+		Status:      srv.Status,
+		CustomArgs:  srv.CustomArgs,
+		JavaVersion: srv.JavaVersion,
+		MemoryMaxMB: srv.Limits.MemoryMaxMB,
+		CPUWeight:   srv.Limits.CPUWeight,
+		CPUQuota:    srv.Limits.CPUQuota,
+		PidsMax:     srv.Limits.PidsMax,
+		IOWeight:    srv.Limits.IOWeight,
+		UID:         srv.UID,
+		RunMode:     srv.RunMode,
+		JVMProfile:  srv.JVMProfile,
+		JavaRuntime: srv.JavaRuntime,
+		CreatedAt:   srv.CreatedAt,
 	}
 
 	return s.db.Create(gormServer).Error
@@ -147,6 +292,36 @@ func (s *GormStore) UpdateServerPort(id string, port int) error {
 	return s.db.Model(&Server{}).Where("id = ?", id).Update("port", port).Error
 }
 
+func (s *GormStore) UpdateServerLimits(id string, limits domain.ResourceLimits) error {
+	return s.db.Model(&Server{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"memory_max_mb": limits.MemoryMaxMB,
+		"cpu_weight":    limits.CPUWeight,
+		"cpu_quota":     limits.CPUQuota,
+		"pids_max":      limits.PidsMax,
+		"io_weight":     limits.IOWeight,
+	}).Error
+}
+
+func (s *GormStore) UpdateServerUID(id string, uid int) error {
+	return s.db.Model(&Server{}).Where("id = ?", id).Update("uid", uid).Error
+}
+
+func (s *GormStore) UpdateServerRunMode(id string, mode string) error {
+	return s.db.Model(&Server{}).Where("id = ?", id).Update("run_mode", mode).Error
+}
+
+func (s *GormStore) UpdateServerJVMProfile(id string, profile string) error {
+	return s.db.Model(&Server{}).Where("id = ?", id).Update("JVMProfile", profile).Error
+}
+
+func (s *GormStore) UpdateServerJavaRuntime(id string, runtimeID string) error {
+	return s.db.Model(&Server{}).Where("id = ?", id).Update("java_runtime", runtimeID).Error
+}
+
+func (s *GormStore) UpdateServerJavaVersion(id string, javaVersion int) error {
+	return s.db.Model(&Server{}).Where("id = ?", id).Update("java_version", javaVersion).Error
+}
+
 func (s *GormStore) ListServers() ([]domain.Server, error) {
 	var gormServers []Server
 	if err := s.db.Find(&gormServers).Error; err != nil {
@@ -156,16 +331,22 @@ func (s *GormStore) ListServers() ([]domain.Server, error) {
 	var servers []domain.Server
 	for _, gs := range gormServers {
 		servers = append(servers, domain.Server{
-			ID:         gs.ID,
-			Name:       gs.Name,
-			FolderName: gs.FolderName,
-			Version:    gs.Version,
-			Loader:     gs.Loader,
-			Port:       gs.Port,
-			RAM:        gs.RAM,
-			Status:     gs.Status,
-			CustomArgs: gs.CustomArgs,
-			CreatedAt:  gs.CreatedAt,
+			ID:          gs.ID,
+			Name:        gs.Name,
+			FolderName:  gs.FolderName,
+			Version:     gs.Version,
+			Loader:      gs.Loader,
+			Port:        gs.Port,
+			RAM:         gs.RAM,
+			Status:      gs.Status,
+			CustomArgs:  gs.CustomArgs,
+			JavaVersion: gs.JavaVersion,
+			Limits:      limitsOf(gs),
+			UID:         gs.UID,
+			RunMode:     gs.RunMode,
+			JVMProfile:  gs.JVMProfile,
+			JavaRuntime: gs.JavaRuntime,
+			CreatedAt:   gs.CreatedAt,
 		})
 	}
 	return servers, nil
@@ -182,21 +363,62 @@ func (s *GormStore) GetServerByID(id string) (*domain.Server, error) {
 	}
 
 	return &domain.Server{
-		ID:         gormServer.ID,
-		Name:       gormServer.Name,
-		FolderName: gormServer.FolderName,
-		Version:    gormServer.Version,
-		Loader:     gormServer.Loader,
-		Port:       gormServer.Port,
-		RAM:        gormServer.RAM,
-		Status:     gormServer.Status,
-		CustomArgs: gormServer.CustomArgs,
-		CreatedAt:  gormServer.CreatedAt,
+		ID:          gormServer.ID,
+		Name:        gormServer.Name,
+		FolderName:  gormServer.FolderName,
+		Version:     gormServer.Version,
+		Loader:      gormServer.Loader,
+		Port:        gormServer.Port,
+		RAM:         gormServer.RAM,
+		Status:      gormServer.Status,
+		CustomArgs:  gormServer.CustomArgs,
+		JavaVersion: gormServer.JavaVersion,
+		Limits:      limitsOf(gormServer),
+		UID:         gormServer.UID,
+		RunMode:     gormServer.RunMode,
+		JVMProfile:  gormServer.JVMProfile,
+		JavaRuntime: gormServer.JavaRuntime,
+		CreatedAt:   gormServer.CreatedAt,
 	}, nil
 }
 
+func limitsOf(gs Server) domain.ResourceLimits {
+	return domain.ResourceLimits{
+		MemoryMaxMB: gs.MemoryMaxMB,
+		CPUWeight:   gs.CPUWeight,
+		CPUQuota:    gs.CPUQuota,
+		PidsMax:     gs.PidsMax,
+		IOWeight:    gs.IOWeight,
+	}
+}
+
 func (s *GormStore) DeleteServer(id string) error {
-	return s.db.Delete(&Server{}, "id = ?", id).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&Server{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&NetworkServer{}, "server_id = ?", id).Error; err != nil {
+			return err
+		}
+		// A network cannot outlive its proxy.
+		proxied := tx.Model(&Network{}).Select("id").Where("proxy_id = ?", id)
+		if err := tx.Delete(&NetworkServer{}, "network_id IN (?)", proxied).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Network{}, "proxy_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&PlayerSession{}, "server_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Incident{}, "server_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Schedule{}, "server_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&StatSample{}, "server_id = ?", id).Error
+	})
 }
 
 func (s *GormStore) UpdateStatus(id string, status string) error {
@@ -336,6 +558,9 @@ func (s *GormStore) DeleteUser(id string) error {
 		if err := tx.Delete(&Permission{}, "user_id = ?", id).Error; err != nil {
 			return err
 		}
+		if err := tx.Delete(&SSHKey{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
 		return nil
 	})
 }
@@ -344,6 +569,58 @@ func (s *GormStore) UpdatePassword(userID string, hashedPassword string) error {
 	return s.db.Model(&User{}).Where("id = ?", userID).Update("password", hashedPassword).Error
 }
 
+func (s *GormStore) AddSSHKey(key *domain.SSHKey) error {
+	return s.db.Create(&SSHKey{
+		ID:          key.ID,
+		UserID:      key.UserID,
+		Name:        key.Name,
+		PublicKey:   key.PublicKey,
+		Fingerprint: key.Fingerprint,
+		CreatedAt:   key.CreatedAt,
+	}).Error
+}
+
+func (s *GormStore) ListSSHKeys(userID string) ([]domain.SSHKey, error) {
+	var gormKeys []SSHKey
+	if err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&gormKeys).Error; err != nil {
+		return nil, err
+	}
+
+	keys := make([]domain.SSHKey, 0, len(gormKeys))
+	for _, gk := range gormKeys {
+		keys = append(keys, toDomainSSHKey(gk))
+	}
+	return keys, nil
+}
+
+func (s *GormStore) GetSSHKeyByFingerprint(fingerprint string) (*domain.SSHKey, error) {
+	var gormKey SSHKey
+	err := s.db.Where("fingerprint = ?", fingerprint).First(&gormKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	key := toDomainSSHKey(gormKey)
+	return &key, nil
+}
+
+func (s *GormStore) DeleteSSHKey(userID, id string) error {
+	return s.db.Delete(&SSHKey{}, "id = ? AND user_id = ?", id, userID).Error
+}
+
+func toDomainSSHKey(gk SSHKey) domain.SSHKey {
+	return domain.SSHKey{
+		ID:          gk.ID,
+		UserID:      gk.UserID,
+		Name:        gk.Name,
+		PublicKey:   gk.PublicKey,
+		Fingerprint: gk.Fingerprint,
+		CreatedAt:   gk.CreatedAt,
+	}
+}
+
 func (s *GormStore) SetPermissions(permissions []domain.Permission) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		if len(permissions) == 0 {
@@ -434,3 +711,607 @@ func (s *GormStore) SetLogBufferSize(size int) error {
 	}
 	return s.SetSetting("log_buffer_size", fmt.Sprintf("%d", size))
 }
+
+func (s *GormStore) CreateNetwork(network *domain.Network) error {
+	return s.db.Create(&Network{
+		ID:               network.ID,
+		Name:             network.Name,
+		ProxyID:          network.ProxyID,
+		ForwardingSecret: network.ForwardingSecret,
+		CreatedAt:        network.CreatedAt,
+	}).Error
+}
+
+func (s *GormStore) GetNetwork(id string) (*domain.Network, error) {
+	return s.findNetwork("id = ?", id)
+}
+
+func (s *GormStore) GetNetworkByProxyID(proxyID string) (*domain.Network, error) {
+	return s.findNetwork("proxy_id = ?", proxyID)
+}
+
+func (s *GormStore) GetNetworkByServerID(serverID string) (*domain.Network, error) {
+	var member NetworkServer
+	if err := s.db.Where("server_id = ?", serverID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s.GetNetwork(member.NetworkID)
+}
+
+func (s *GormStore) findNetwork(query string, arg string) (*domain.Network, error) {
+	var n Network
+	if err := s.db.Where(query, arg).First(&n).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	members, err := s.listNetworkServers(n.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Network{
+		ID:               n.ID,
+		Name:             n.Name,
+		ProxyID:          n.ProxyID,
+		ForwardingSecret: n.ForwardingSecret,
+		Servers:          members,
+		CreatedAt:        n.CreatedAt,
+	}, nil
+}
+
+func (s *GormStore) listNetworkServers(networkID string) ([]domain.NetworkServer, error) {
+	var gormMembers []NetworkServer
+	if err := s.db.Where("network_id = ?", networkID).Order("priority asc").Find(&gormMembers).Error; err != nil {
+		return nil, err
+	}
+	members := make([]domain.NetworkServer, 0, len(gormMembers))
+	for _, m := range gormMembers {
+		members = append(members, domain.NetworkServer{
+			NetworkID: m.NetworkID,
+			ServerID:  m.ServerID,
+			Alias:     m.Alias,
+			Priority:  m.Priority,
+		})
+	}
+	return members, nil
+}
+
+func (s *GormStore) ListNetworks() ([]domain.Network, error) {
+	var gormNetworks []Network
+	if err := s.db.Find(&gormNetworks).Error; err != nil {
+		return nil, err
+	}
+
+	var networks []domain.Network
+	for _, n := range gormNetworks {
+		members, err := s.listNetworkServers(n.ID)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, domain.Network{
+			ID:               n.ID,
+			Name:             n.Name,
+			ProxyID:          n.ProxyID,
+			ForwardingSecret: n.ForwardingSecret,
+			Servers:          members,
+			CreatedAt:        n.CreatedAt,
+		})
+	}
+	return networks, nil
+}
+
+func (s *GormStore) DeleteNetwork(id string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&NetworkServer{}, "network_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Network{}, "id = ?", id).Error
+	})
+}
+
+func (s *GormStore) AddNetworkServer(member *domain.NetworkServer) error {
+	return s.db.Save(&NetworkServer{
+		NetworkID: member.NetworkID,
+		ServerID:  member.ServerID,
+		Alias:     member.Alias,
+		Priority:  member.Priority,
+	}).Error
+}
+
+func (s *GormStore) RemoveNetworkServer(networkID, serverID string) error {
+	return s.db.Delete(&NetworkServer{}, "network_id = ? AND server_id = ?", networkID, serverID).Error
+}
+
+func (s *GormStore) CreateTemplate(template *domain.Template) error {
+	return s.db.Create(&Template{
+		ID:             template.ID,
+		Name:           template.Name,
+		Loader:         template.Loader,
+		Version:        template.Version,
+		RAM:            template.RAM,
+		CustomArgs:     template.CustomArgs,
+		JavaVersion:    template.JavaVersion,
+		SourceServerID: template.SourceServerID,
+		CreatedAt:      template.CreatedAt,
+	}).Error
+}
+
+func (s *GormStore) GetTemplate(id string) (*domain.Template, error) {
+	var t Template
+	if err := s.db.First(&t, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	template := toDomainTemplate(t)
+	return &template, nil
+}
+
+func (s *GormStore) ListTemplates() ([]domain.Template, error) {
+	var gormTemplates []Template
+	if err := s.db.Order("created_at asc").Find(&gormTemplates).Error; err != nil {
+		return nil, err
+	}
+
+	templates := make([]domain.Template, 0, len(gormTemplates))
+	for _, t := range gormTemplates {
+		templates = append(templates, toDomainTemplate(t))
+	}
+	return templates, nil
+}
+
+func (s *GormStore) DeleteTemplate(id string) error {
+	return s.db.Delete(&Template{}, "id = ?", id).Error
+}
+
+func toDomainTemplate(t Template) domain.Template {
+	return domain.Template{
+		ID:             t.ID,
+		Name:           t.Name,
+		Loader:         t.Loader,
+		Version:        t.Version,
+		RAM:            t.RAM,
+		CustomArgs:     t.CustomArgs,
+		JavaVersion:    t.JavaVersion,
+		SourceServerID: t.SourceServerID,
+		CreatedAt:      t.CreatedAt,
+	}
+}
+
+func (s *GormStore) CreatePlayerSession(session *domain.PlayerSession) error {
+	gormSession := PlayerSession{
+		ServerID:   session.ServerID,
+		PlayerName: session.PlayerName,
+		PlayerUUID: session.PlayerUUID,
+		JoinedAt:   session.JoinedAt,
+	}
+	if err := s.db.Create(&gormSession).Error; err != nil {
+		return err
+	}
+	session.ID = gormSession.ID
+	return nil
+}
+
+func (s *GormStore) GetOpenPlayerSession(serverID, playerName string) (*domain.PlayerSession, error) {
+	var p PlayerSession
+	result := s.db.Where("server_id = ? AND player_name = ? AND left_at IS NULL", serverID, playerName).
+		Order("joined_at desc").Limit(1).Find(&p)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	session := toDomainPlayerSession(p)
+	return &session, nil
+}
+
+func (s *GormStore) ListOpenPlayerSessions(serverID string) ([]domain.PlayerSession, error) {
+	return s.findPlayerSessions(s.db.Where("server_id = ? AND left_at IS NULL", serverID))
+}
+
+// ListPlayerSessions returns the sessions on a server, oldest first. An empty
+// playerName returns the sessions of every player.
+func (s *GormStore) ListPlayerSessions(serverID, playerName string) ([]domain.PlayerSession, error) {
+	query := s.db.Where("server_id = ?", serverID)
+	if playerName != "" {
+		query = query.Where("player_name = ?", playerName)
+	}
+	return s.findPlayerSessions(query)
+}
+
+func (s *GormStore) findPlayerSessions(query *gorm.DB) ([]domain.PlayerSession, error) {
+	var gormSessions []PlayerSession
+	if err := query.Order("joined_at asc").Find(&gormSessions).Error; err != nil {
+		return nil, err
+	}
+
+	sessions := make([]domain.PlayerSession, 0, len(gormSessions))
+	for _, p := range gormSessions {
+		sessions = append(sessions, toDomainPlayerSession(p))
+	}
+	return sessions, nil
+}
+
+func (s *GormStore) ClosePlayerSession(id uint, leftAt time.Time) error {
+	return s.db.Model(&PlayerSession{}).Where("id = ?", id).Update("left_at", leftAt).Error
+}
+
+// CloseOpenPlayerSessions ends every open session on a server, or on all
+// servers when serverID is empty.
+func (s *GormStore) CloseOpenPlayerSessions(serverID string, leftAt time.Time) error {
+	query := s.db.Model(&PlayerSession{}).Where("left_at IS NULL")
+	if serverID != "" {
+		query = query.Where("server_id = ?", serverID)
+	}
+	return query.Update("left_at", leftAt).Error
+}
+
+func (s *GormStore) IncrementPlayerSessionCounter(id uint, counter string) error {
+	switch counter {
+	case "deaths", "advancements", "chat_messages":
+	default:
+		return fmt.Errorf("unknown session counter: %s", counter)
+	}
+	return s.db.Model(&PlayerSession{}).Where("id = ?", id).
+		Update(counter, gorm.Expr(counter+" + 1")).Error
+}
+
+func toDomainPlayerSession(p PlayerSession) domain.PlayerSession {
+	return domain.PlayerSession{
+		ID:           p.ID,
+		ServerID:     p.ServerID,
+		PlayerName:   p.PlayerName,
+		PlayerUUID:   p.PlayerUUID,
+		JoinedAt:     p.JoinedAt,
+		LeftAt:       p.LeftAt,
+		Deaths:       p.Deaths,
+		Advancements: p.Advancements,
+		ChatMessages: p.ChatMessages,
+	}
+}
+
+func (s *GormStore) CreateIncident(incident *domain.Incident) error {
+	return s.db.Create(&Incident{
+		ID:              incident.ID,
+		ServerID:        incident.ServerID,
+		ExitCode:        incident.ExitCode,
+		Cause:           incident.Cause,
+		Summary:         incident.Summary,
+		Evidence:        incident.Evidence,
+		CrashReport:     incident.CrashReport,
+		CrashReportText: incident.CrashReportText,
+		JVMErrorLog:     incident.JVMErrorLog,
+		JVMErrorLogText: incident.JVMErrorLogText,
+		ConsoleTail:     strings.Join(incident.ConsoleTail, "\n"),
+		StartedAt:       incident.StartedAt,
+		CreatedAt:       incident.CreatedAt,
+	}).Error
+}
+
+func (s *GormStore) GetIncident(id string) (*domain.Incident, error) {
+	var i Incident
+	if err := s.db.First(&i, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	incident := toDomainIncident(i)
+	return &incident, nil
+}
+
+// ListIncidents returns the incidents of a server, newest first, without the
+// crash report and JVM error log contents.
+func (s *GormStore) ListIncidents(serverID string) ([]domain.Incident, error) {
+	var gormIncidents []Incident
+	if err := s.db.Omit("crash_report_text", "jvm_error_log_text").
+		Where("server_id = ?", serverID).Order("created_at desc").Find(&gormIncidents).Error; err != nil {
+		return nil, err
+	}
+
+	incidents := make([]domain.Incident, 0, len(gormIncidents))
+	for _, i := range gormIncidents {
+		incidents = append(incidents, toDomainIncident(i))
+	}
+	return incidents, nil
+}
+
+func toDomainIncident(i Incident) domain.Incident {
+	var tail []string
+	if i.ConsoleTail != "" {
+		tail = strings.Split(i.ConsoleTail, "\n")
+	}
+	return domain.Incident{
+		ID:              i.ID,
+		ServerID:        i.ServerID,
+		ExitCode:        i.ExitCode,
+		Cause:           i.Cause,
+		Summary:         i.Summary,
+		Evidence:        i.Evidence,
+		CrashReport:     i.CrashReport,
+		CrashReportText: i.CrashReportText,
+		JVMErrorLog:     i.JVMErrorLog,
+		JVMErrorLogText: i.JVMErrorLogText,
+		ConsoleTail:     tail,
+		StartedAt:       i.StartedAt,
+		CreatedAt:       i.CreatedAt,
+	}
+}
+
+func (s *GormStore) CreateNotificationSink(sink *domain.NotificationSink) error {
+	return s.db.Create(fromDomainNotificationSink(sink)).Error
+}
+
+func (s *GormStore) UpdateNotificationSink(sink *domain.NotificationSink) error {
+	return s.db.Save(fromDomainNotificationSink(sink)).Error
+}
+
+func (s *GormStore) GetNotificationSink(id string) (*domain.NotificationSink, error) {
+	var n NotificationSink
+	if err := s.db.First(&n, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sink := toDomainNotificationSink(n)
+	return &sink, nil
+}
+
+func (s *GormStore) ListNotificationSinks() ([]domain.NotificationSink, error) {
+	var gormSinks []NotificationSink
+	if err := s.db.Order("created_at asc").Find(&gormSinks).Error; err != nil {
+		return nil, err
+	}
+
+	sinks := make([]domain.NotificationSink, 0, len(gormSinks))
+	for _, n := range gormSinks {
+		sinks = append(sinks, toDomainNotificationSink(n))
+	}
+	return sinks, nil
+}
+
+func (s *GormStore) DeleteNotificationSink(id string) error {
+	return s.db.Delete(&NotificationSink{}, "id = ?", id).Error
+}
+
+func (s *GormStore) CreateDeadLetter(letter *domain.DeadLetter) error {
+	gormLetter := DeadLetter{
+		SinkID:    letter.SinkID,
+		SinkName:  letter.SinkName,
+		EventType: letter.EventType,
+		Payload:   letter.Payload,
+		Error:     letter.Error,
+		Attempts:  letter.Attempts,
+		CreatedAt: letter.CreatedAt,
+	}
+	if err := s.db.Create(&gormLetter).Error; err != nil {
+		return err
+	}
+	letter.ID = gormLetter.ID
+	return nil
+}
+
+// ListDeadLetters returns the most recent failed deliveries, newest first.
+func (s *GormStore) ListDeadLetters(limit int) ([]domain.DeadLetter, error) {
+	var gormLetters []DeadLetter
+	if err := s.db.Order("id desc").Limit(limit).Find(&gormLetters).Error; err != nil {
+		return nil, err
+	}
+
+	letters := make([]domain.DeadLetter, 0, len(gormLetters))
+	for _, l := range gormLetters {
+		letters = append(letters, domain.DeadLetter{
+			ID:        l.ID,
+			SinkID:    l.SinkID,
+			SinkName:  l.SinkName,
+			EventType: l.EventType,
+			Payload:   l.Payload,
+			Error:     l.Error,
+			Attempts:  l.Attempts,
+			CreatedAt: l.CreatedAt,
+		})
+	}
+	return letters, nil
+}
+
+func fromDomainNotificationSink(sink *domain.NotificationSink) *NotificationSink {
+	return &NotificationSink{
+		ID:           sink.ID,
+		Name:         sink.Name,
+		Type:         sink.Type,
+		Enabled:      sink.Enabled,
+		Events:       strings.Join(sink.Events, ","),
+		URL:          sink.URL,
+		Secret:       sink.Secret,
+		SMTPHost:     sink.SMTPHost,
+		SMTPPort:     sink.SMTPPort,
+		SMTPUsername: sink.SMTPUsername,
+		SMTPPassword: sink.SMTPPassword,
+		EmailFrom:    sink.EmailFrom,
+		EmailTo:      strings.Join(sink.EmailTo, ","),
+		CreatedAt:    sink.CreatedAt,
+	}
+}
+
+func toDomainNotificationSink(n NotificationSink) domain.NotificationSink {
+	return domain.NotificationSink{
+		ID:           n.ID,
+		Name:         n.Name,
+		Type:         n.Type,
+		Enabled:      n.Enabled,
+		Events:       splitList(n.Events),
+		URL:          n.URL,
+		Secret:       n.Secret,
+		SMTPHost:     n.SMTPHost,
+		SMTPPort:     n.SMTPPort,
+		SMTPUsername: n.SMTPUsername,
+		SMTPPassword: n.SMTPPassword,
+		EmailFrom:    n.EmailFrom,
+		EmailTo:      splitList(n.EmailTo),
+		CreatedAt:    n.CreatedAt,
+	}
+}
+
+func (s *GormStore) AddStatSamples(resolution time.Duration, samples []domain.StatSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	gormSamples := make([]StatSample, 0, len(samples))
+	for _, sample := range samples {
+		gormSamples = append(gormSamples, StatSample{
+			Resolution: int(resolution / time.Second),
+			ServerID:   sample.ServerID,
+			Timestamp:  sample.Time.Unix(),
+			CPU:        sample.CPU,
+			CPUMax:     sample.CPUMax,
+			RAM:        sample.RAM,
+			RAMMax:     sample.RAMMax,
+			Disk:       sample.Disk,
+		})
+	}
+	return s.db.Create(&gormSamples).Error
+}
+
+// ListStatSamples returns the samples of one resolution taken in [from, to),
+// oldest first. An empty serverID lists the samples of every server.
+func (s *GormStore) ListStatSamples(serverID string, resolution time.Duration, from, to time.Time) ([]domain.StatSample, error) {
+	query := s.db.Where("resolution = ? AND timestamp >= ? AND timestamp < ?", int(resolution/time.Second), from.Unix(), to.Unix())
+	if serverID != "" {
+		query = query.Where("server_id = ?", serverID)
+	}
+
+	var gormSamples []StatSample
+	if err := query.Order("timestamp asc").Find(&gormSamples).Error; err != nil {
+		return nil, err
+	}
+
+	samples := make([]domain.StatSample, 0, len(gormSamples))
+	for _, sample := range gormSamples {
+		samples = append(samples, domain.StatSample{
+			ServerID: sample.ServerID,
+			Time:     time.Unix(sample.Timestamp, 0).UTC(),
+			CPU:      sample.CPU,
+			CPUMax:   sample.CPUMax,
+			RAM:      sample.RAM,
+			RAMMax:   sample.RAMMax,
+			Disk:     sample.Disk,
+		})
+	}
+	return samples, nil
+}
+
+// LatestStatSampleTime returns the time of the newest sample of a resolution,
+// or the zero time when there is none.
+func (s *GormStore) LatestStatSampleTime(resolution time.Duration) (time.Time, error) {
+	var latest *int64
+	if err := s.db.Model(&StatSample{}).Where("resolution = ?", int(resolution/time.Second)).
+		Select("MAX(timestamp)").Scan(&latest).Error; err != nil {
+		return time.Time{}, err
+	}
+	if latest == nil {
+		return time.Time{}, nil
+	}
+	return time.Unix(*latest, 0).UTC(), nil
+}
+
+func (s *GormStore) DeleteStatSamplesBefore(resolution time.Duration, before time.Time) error {
+	return s.db.Where("resolution = ? AND timestamp < ?", int(resolution/time.Second), before.Unix()).
+		Delete(&StatSample{}).Error
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+func (s *GormStore) SaveJavaRuntime(runtime *domain.JavaRuntime) error {
+	return s.db.Save(&JavaRuntime{
+		ID:        runtime.ID,
+		Home:      runtime.Home,
+		CreatedAt: runtime.CreatedAt,
+	}).Error
+}
+
+func (s *GormStore) ListJavaRuntimes() ([]domain.JavaRuntime, error) {
+	var gormRuntimes []JavaRuntime
+	if err := s.db.Order("created_at").Find(&gormRuntimes).Error; err != nil {
+		return nil, err
+	}
+
+	runtimes := make([]domain.JavaRuntime, 0, len(gormRuntimes))
+	for _, gr := range gormRuntimes {
+		runtimes = append(runtimes, domain.JavaRuntime{
+			ID:        gr.ID,
+			Home:      gr.Home,
+			CreatedAt: gr.CreatedAt,
+		})
+	}
+	return runtimes, nil
+}
+
+func (s *GormStore) DeleteJavaRuntime(id string) error {
+	return s.db.Delete(&JavaRuntime{}, "id = ?", id).Error
+}
+
+func (s *GormStore) CreateSchedule(schedule *domain.Schedule) error {
+	return s.db.Create(&Schedule{
+		ID:        schedule.ID,
+		ServerID:  schedule.ServerID,
+		Cron:      schedule.Cron,
+		Action:    schedule.Action,
+		Command:   schedule.Command,
+		CreatedAt: schedule.CreatedAt,
+	}).Error
+}
+
+func (s *GormStore) ListSchedules(serverID string) ([]domain.Schedule, error) {
+	return s.findSchedules(s.db.Where("server_id = ?", serverID))
+}
+
+func (s *GormStore) ListAllSchedules() ([]domain.Schedule, error) {
+	return s.findSchedules(s.db)
+}
+
+func (s *GormStore) findSchedules(query *gorm.DB) ([]domain.Schedule, error) {
+	var gormSchedules []Schedule
+	if err := query.Order("created_at").Find(&gormSchedules).Error; err != nil {
+		return nil, err
+	}
+
+	schedules := make([]domain.Schedule, 0, len(gormSchedules))
+	for _, gs := range gormSchedules {
+		schedules = append(schedules, domain.Schedule{
+			ID:        gs.ID,
+			ServerID:  gs.ServerID,
+			Cron:      gs.Cron,
+			Action:    gs.Action,
+			Command:   gs.Command,
+			LastRunAt: gs.LastRunAt,
+			LastError: gs.LastError,
+			CreatedAt: gs.CreatedAt,
+		})
+	}
+	return schedules, nil
+}
+
+// DeleteSchedule removes a schedule of a server. Deleting a schedule that
+// does not exist is not an error.
+func (s *GormStore) DeleteSchedule(serverID, id string) error {
+	return s.db.Delete(&Schedule{}, "server_id = ? AND id = ?", serverID, id).Error
+}
+
+func (s *GormStore) UpdateScheduleRun(id string, at time.Time, runErr string) error {
+	return s.db.Model(&Schedule{}).Where("id = ?", id).
+		Updates(map[string]any{"last_run_at": at, "last_error": runErr}).Error
+}
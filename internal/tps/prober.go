@@ -0,0 +1,198 @@
+package tps
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"naviger/internal/domain"
+	"naviger/internal/logevents"
+	"naviger/internal/runner"
+	"naviger/internal/storage"
+)
+
+const (
+	DefaultInterval = 30 * time.Second
+	// replyTimeout is how long a probe waits for the complete reply before
+	// it keeps whatever it has read.
+	replyTimeout = 10 * time.Second
+)
+
+// Prober runs the probe of every started server each Interval. It watches
+// the console to know when a server is ready and to read the replies.
+type Prober struct {
+	Store      *storage.GormStore
+	Supervisor *runner.Supervisor
+	Interval   time.Duration
+	// Threshold and AlertAfter configure the low TPS alert: OnLow is called
+	// once a server has stayed below Threshold TPS for AlertAfter, and again
+	// only after it has recovered. A Threshold of 0 disables it.
+	Threshold  float64
+	AlertAfter time.Duration
+	OnLow      func(serverID string, tps, mspt float64, below time.Duration)
+	servers    map[string]*serverState
+	mu         sync.Mutex
+}
+
+type serverState struct {
+	probe    *Probe
+	pending  bool
+	sentAt   time.Time
+	reply    partial
+	latest   Reading
+	measured bool
+	lowSince time.Time
+	alerted  bool
+}
+
+func NewProber(store *storage.GormStore, supervisor *runner.Supervisor, interval time.Duration) *Prober {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Prober{
+		Store:      store,
+		Supervisor: supervisor,
+		Interval:   interval,
+		servers:    make(map[string]*serverState),
+	}
+}
+
+// ObserveLine starts probing a server once it is ready and reads probe
+// replies.
+func (p *Prober) ObserveLine(serverID, line string) {
+	if logevents.Ready(line) {
+		p.ready(serverID)
+		return
+	}
+
+	p.mu.Lock()
+	st, ok := p.servers[serverID]
+	if !ok || !st.pending {
+		p.mu.Unlock()
+		return
+	}
+	st.probe.read(line, &st.reply)
+	var alert func()
+	if st.reply.complete(st.probe) {
+		alert = p.finish(serverID, st, time.Now())
+	}
+	p.mu.Unlock()
+
+	if alert != nil {
+		alert()
+	}
+}
+
+func (p *Prober) ServerExited(serverID string, _ domain.ProcessExit) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.servers, serverID)
+}
+
+func (p *Prober) ready(serverID string) {
+	srv, err := p.Store.GetServerByID(serverID)
+	if err != nil || srv == nil {
+		return
+	}
+	probe, ok := ProbeFor(srv.Loader, srv.Version)
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.servers[serverID] = &serverState{probe: probe}
+}
+
+// Tick returns the last reading of a running server.
+func (p *Prober) Tick(serverID string) (float64, float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st, ok := p.servers[serverID]
+	if !ok || !st.measured {
+		return 0, 0, false
+	}
+	return st.latest.TPS, st.latest.MSPT, true
+}
+
+// Run probes every ready server each Interval until ctx is done.
+func (p *Prober) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			p.probeAll(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *Prober) probeAll(now time.Time) {
+	commands := make(map[string][]string)
+	var alerts []func()
+
+	p.mu.Lock()
+	for serverID, st := range p.servers {
+		if st.pending {
+			if now.Sub(st.sentAt) < replyTimeout {
+				continue
+			}
+			// Keep a reply that is only missing the tick time.
+			if st.reply.hasTPS {
+				if alert := p.finish(serverID, st, now); alert != nil {
+					alerts = append(alerts, alert)
+				}
+			}
+			st.pending = false
+		}
+		st.pending = true
+		st.sentAt = now
+		st.reply = partial{}
+		commands[serverID] = st.probe.Commands
+	}
+	p.mu.Unlock()
+
+	for _, alert := range alerts {
+		alert()
+	}
+	for serverID, cmds := range commands {
+		for _, cmd := range cmds {
+			if err := p.Supervisor.SendCommand(serverID, cmd); err != nil {
+				slog.Debug("Could not send TPS probe", "server", serverID, "error", err)
+				break
+			}
+		}
+	}
+}
+
+// finish stores the reply as the latest reading and returns the low TPS
+// alert to send, if it is due. It must be called with p.mu held.
+func (p *Prober) finish(serverID string, st *serverState, now time.Time) func() {
+	reading := st.reply.reading(now)
+	st.latest = reading
+	st.measured = true
+	st.pending = false
+
+	if p.Threshold <= 0 || p.OnLow == nil {
+		return nil
+	}
+	if reading.TPS >= p.Threshold {
+		st.lowSince = time.Time{}
+		st.alerted = false
+		return nil
+	}
+	if st.lowSince.IsZero() {
+		st.lowSince = now
+	}
+	below := now.Sub(st.lowSince)
+	if st.alerted || below < p.AlertAfter {
+		return nil
+	}
+	st.alerted = true
+
+	onLow := p.OnLow
+	return func() { onLow(serverID, reading.TPS, reading.MSPT, below) }
+}
@@ -0,0 +1,154 @@
+// Package tps measures how fast servers tick by periodically running the
+// tick rate command of their loader and reading the reply from the console.
+package tps
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"naviger/internal/logevents"
+)
+
+// Reading is one measurement of a server's tick rate. MSPT, the average
+// milliseconds per tick, is 0 when the loader does not report it.
+type Reading struct {
+	TPS  float64   `json:"tps"`
+	MSPT float64   `json:"mspt,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+// Probe is how the tick rate of one kind of server is measured: the console
+// commands to send and how to read their replies.
+type Probe struct {
+	Commands []string
+	// HasMSPT reports whether the replies include the tick time, so a
+	// reading is only complete once it has been seen.
+	HasMSPT bool
+	parse   func(text string, r *partial)
+}
+
+// partial collects the values of a reading as the reply lines arrive.
+type partial struct {
+	tps, mspt, target float64
+	hasTPS, hasMSPT   bool
+}
+
+func (p *partial) complete(probe *Probe) bool {
+	return p.hasTPS && (p.hasMSPT || !probe.HasMSPT)
+}
+
+func (p *partial) reading(at time.Time) Reading {
+	return Reading{TPS: p.tps, MSPT: p.mspt, At: at}
+}
+
+const number = `(\d+(?:[.,]\d+)?)`
+
+var (
+	colorCodePattern = regexp.MustCompile(`§[0-9a-fk-orA-FK-OR]`)
+
+	// Paper and Spigot: TPS from last 1m, 5m, 15m: 20.0, 20.0, 20.0
+	bukkitTPSPattern = regexp.MustCompile(`^TPS from last 1m, 5m, 15m: \*?` + number)
+	// Paper: ◴ 1.2/0.8/3.4, 1.1/0.7/4.0, 1.3/0.6/9.9 (avg/min/max over 5s, 10s, 1m)
+	paperMSPTPattern = regexp.MustCompile(`^(?:◴ )?` + number + `/` + number + `/` + number + `,`)
+	// Forge and NeoForge before 1.20.2: Overall : Mean tick time: 1.234 ms. Mean TPS: 20.000
+	forgeTPSPattern = regexp.MustCompile(`^Overall\s*: Mean tick time: ` + number + ` ms\. Mean TPS: ` + number)
+	// NeoForge: Overall: 20.000 TPS (1.234 ms/tick)
+	neoForgeTPSPattern = regexp.MustCompile(`^Overall\s*: ` + number + ` TPS \(` + number + ` ms/tick\)`)
+	// Vanilla 1.20.3+ "tick query":
+	//   Target tick rate: 20.0 per second.
+	//   Average time per tick: 2.3ms (Target: 50.0ms)
+	vanillaTargetPattern = regexp.MustCompile(`^Target tick rate: ` + number + ` per second`)
+	vanillaMSPTPattern   = regexp.MustCompile(`^Average time per tick: ` + number + `ms`)
+)
+
+// ProbeFor returns the probe for a loader and Minecraft version, and false
+// when the server has no tick rate command Naviger understands.
+func ProbeFor(loader, version string) (*Probe, bool) {
+	switch loader {
+	case "paper":
+		return &Probe{Commands: []string{"tps", "mspt"}, HasMSPT: true, parse: parsePaper}, true
+	case "spigot":
+		return &Probe{Commands: []string{"tps"}, parse: parsePaper}, true
+	case "forge":
+		return &Probe{Commands: []string{"forge tps"}, HasMSPT: true, parse: parseForge}, true
+	case "neoforge":
+		command := "forge tps"
+		if atLeast(version, 1, 20, 2) {
+			command = "neoforge tps"
+		}
+		return &Probe{Commands: []string{command}, HasMSPT: true, parse: parseForge}, true
+	case "vanilla", "fabric":
+		if atLeast(version, 1, 20, 3) {
+			return &Probe{Commands: []string{"tick query"}, HasMSPT: true, parse: parseVanilla}, true
+		}
+	}
+	return nil, false
+}
+
+// read takes one console line into r as part of the reply to the probe.
+func (p *Probe) read(line string, r *partial) {
+	text := colorCodePattern.ReplaceAllString(logevents.Message(line), "")
+	p.parse(strings.TrimSpace(text), r)
+}
+
+func parsePaper(text string, r *partial) {
+	if m := bukkitTPSPattern.FindStringSubmatch(text); m != nil {
+		r.tps, r.hasTPS = parseNumber(m[1]), true
+	} else if m := paperMSPTPattern.FindStringSubmatch(text); m != nil {
+		r.mspt, r.hasMSPT = parseNumber(m[1]), true
+	}
+}
+
+func parseForge(text string, r *partial) {
+	if m := forgeTPSPattern.FindStringSubmatch(text); m != nil {
+		r.mspt, r.tps = parseNumber(m[1]), parseNumber(m[2])
+		r.hasTPS, r.hasMSPT = true, true
+	} else if m := neoForgeTPSPattern.FindStringSubmatch(text); m != nil {
+		r.tps, r.mspt = parseNumber(m[1]), parseNumber(m[2])
+		r.hasTPS, r.hasMSPT = true, true
+	}
+}
+
+// parseVanilla derives TPS from the tick time: a server ticks at its target
+// rate unless ticks take longer than the time it has for them.
+func parseVanilla(text string, r *partial) {
+	if m := vanillaTargetPattern.FindStringSubmatch(text); m != nil {
+		r.target = parseNumber(m[1])
+	} else if m := vanillaMSPTPattern.FindStringSubmatch(text); m != nil {
+		r.mspt, r.hasMSPT = parseNumber(m[1]), true
+		target := r.target
+		if target <= 0 {
+			target = 20
+		}
+		r.tps, r.hasTPS = target, true
+		if r.mspt > 0 {
+			r.tps = min(target, 1000/r.mspt)
+		}
+	}
+}
+
+func parseNumber(value string) float64 {
+	n, _ := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	return n
+}
+
+// atLeast reports whether a release version such as "1.20.4" is at least
+// major.minor.patch. Snapshots and other unparsable versions are not.
+func atLeast(version string, want ...int) bool {
+	parts := strings.Split(version, ".")
+	for i, w := range want {
+		n := 0
+		if i < len(parts) {
+			var err error
+			if n, err = strconv.Atoi(parts[i]); err != nil {
+				return false
+			}
+		}
+		if n != w {
+			return n > w
+		}
+	}
+	return true
+}
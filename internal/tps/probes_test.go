@@ -0,0 +1,165 @@
+package tps
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProbeReplies(t *testing.T) {
+	tests := []struct {
+		name     string
+		loader   string
+		version  string
+		command  string
+		lines    []string
+		wantTPS  float64
+		wantMSPT float64
+	}{
+		{
+			name:    "paper",
+			loader:  "paper",
+			version: "1.21.1",
+			command: "tps",
+			lines: []string{
+				"[12:00:00 INFO]: §6TPS from last 1m, 5m, 15m: §a*20.0, §a19.8, §a19.95",
+				"[12:00:00 INFO]: §6Server tick times §e(§7avg§e/§7min§e/§7max§e)§6 from last 5s§7,§6 10s§7,§6 1m§e:",
+				"[12:00:00 INFO]: §6◴ §a12.3§7/§a4.5§7/§a40.1§7, §a11.0§7/§a4.0§7/§a41.2§7, §a10.5§7/§a3.9§7/§a48.0",
+			},
+			wantTPS:  20,
+			wantMSPT: 12.3,
+		},
+		{
+			name:    "spigot",
+			loader:  "spigot",
+			version: "1.20.1",
+			command: "tps",
+			lines:   []string{"[12:00:00 INFO]: TPS from last 1m, 5m, 15m: 18.42, 19.1, 19.7"},
+			wantTPS: 18.42,
+		},
+		{
+			name:    "forge",
+			loader:  "forge",
+			version: "1.20.1",
+			command: "forge tps",
+			lines: []string{
+				"[18Oct2026 12:00:00.123] [Server thread/INFO] [net.minecraft.server.MinecraftServer/]: Dim minecraft:overworld (minecraft:overworld): Mean tick time: 60.120 ms. Mean TPS: 16.633",
+				"[18Oct2026 12:00:00.123] [Server thread/INFO] [net.minecraft.server.MinecraftServer/]: Overall: Mean tick time: 62.500 ms. Mean TPS: 16.000",
+			},
+			wantTPS:  16,
+			wantMSPT: 62.5,
+		},
+		{
+			name:    "neoforge",
+			loader:  "neoforge",
+			version: "1.21.1",
+			command: "neoforge tps",
+			lines: []string{
+				"[18Oct2026 12:00:00.123] [Server thread/INFO] [net.minecraft.server.MinecraftServer/]: minecraft:overworld: 20.000 TPS (3.120 ms/tick)",
+				"[18Oct2026 12:00:00.123] [Server thread/INFO] [net.minecraft.server.MinecraftServer/]: Overall: 20.000 TPS (3.456 ms/tick)",
+			},
+			wantTPS:  20,
+			wantMSPT: 3.456,
+		},
+		{
+			name:    "vanilla lagging",
+			loader:  "vanilla",
+			version: "1.21.4",
+			command: "tick query",
+			lines: []string{
+				"[12:00:00] [Server thread/INFO]: The game is running normally",
+				"[12:00:00] [Server thread/INFO]: Target tick rate: 20.0 per second.",
+				"Average time per tick: 80.0ms (Target: 50.0ms)",
+			},
+			wantTPS:  12.5,
+			wantMSPT: 80,
+		},
+		{
+			name:    "fabric",
+			loader:  "fabric",
+			version: "1.20.4",
+			command: "tick query",
+			lines: []string{
+				"[12:00:00] [Server thread/INFO] (Minecraft) Target tick rate: 20.0 per second.",
+				"Average time per tick: 5,2ms (Target: 50,0ms)",
+			},
+			wantTPS:  20,
+			wantMSPT: 5.2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe, ok := ProbeFor(tt.loader, tt.version)
+			if !ok {
+				t.Fatalf("No probe for %s %s", tt.loader, tt.version)
+			}
+			if probe.Commands[0] != tt.command {
+				t.Errorf("Command = %q, want %q", probe.Commands[0], tt.command)
+			}
+
+			var reply partial
+			for _, line := range tt.lines {
+				probe.read(line, &reply)
+			}
+			if !reply.complete(probe) {
+				t.Fatalf("Reply incomplete: %+v", reply)
+			}
+			got := reply.reading(time.Now())
+			if got.TPS != tt.wantTPS || got.MSPT != tt.wantMSPT {
+				t.Errorf("Got %.3f TPS %.3f MSPT, want %.3f TPS %.3f MSPT", got.TPS, got.MSPT, tt.wantTPS, tt.wantMSPT)
+			}
+		})
+	}
+}
+
+func TestProbeFor(t *testing.T) {
+	tests := []struct {
+		loader  string
+		version string
+		want    string
+	}{
+		{"vanilla", "1.20.2", ""},
+		{"vanilla", "1.20.3", "tick query"},
+		{"fabric", "1.21", "tick query"},
+		{"vanilla", "24w10a", ""},
+		{"neoforge", "1.20.1", "forge tps"},
+		{"neoforge", "1.20.2", "neoforge tps"},
+		{"velocity", "3.3.0", ""},
+		{"custom", "", ""},
+	}
+
+	for _, tt := range tests {
+		probe, ok := ProbeFor(tt.loader, tt.version)
+		got := ""
+		if ok {
+			got = probe.Commands[0]
+		}
+		if got != tt.want {
+			t.Errorf("ProbeFor(%q, %q) = %q, want %q", tt.loader, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestLowTPSAlert(t *testing.T) {
+	var alerts []float64
+	p := NewProber(nil, nil, time.Second)
+	p.Threshold = 15
+	p.AlertAfter = time.Minute
+	p.OnLow = func(_ string, tps, _ float64, _ time.Duration) { alerts = append(alerts, tps) }
+
+	probe, _ := ProbeFor("spigot", "1.20.1")
+	st := &serverState{probe: probe}
+	start := time.Now()
+	for i, tps := range []float64{10, 11, 12, 20, 9, 8, 7, 6} {
+		st.reply = partial{tps: tps, hasTPS: true}
+		if alert := p.finish("srv", st, start.Add(time.Duration(i)*30*time.Second)); alert != nil {
+			alert()
+		}
+	}
+
+	// Low from 0s to 60s alerts at 60s; recovery at 90s re-arms; low again
+	// from 120s alerts at 180s.
+	if len(alerts) != 2 || alerts[0] != 12 || alerts[1] != 7 {
+		t.Errorf("Alerts = %v, want [12 7]", alerts)
+	}
+}
@@ -1,7 +1,10 @@
 package ws
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -14,11 +17,15 @@ const (
 
 	pingPeriod = (pongWait * 9) / 10
 
-	maxMessageSize = 512
+	maxMessageSize = 4096
+
+	commandTimeout = 5 * time.Second
 )
 
 var (
 	newline = []byte{'\n'}
+
+	errNotAccepting = errors.New("server is not accepting commands")
 )
 
 type Client struct {
@@ -26,14 +33,28 @@ type Client struct {
 
 	conn *websocket.Conn
 
+	// replay holds the history sent before live output. The hub fills and
+	// closes it before the pumps start.
 	replay chan []byte
 
 	send chan []byte
+
+	ready chan struct{}
+
+	// structured clients speak the JSON protocol. When resume is set they
+	// only want history frames after since, provided session still matches.
+	structured bool
+	resume     bool
+	since      uint64
+	session    string
 }
 
 func (c *Client) readPump() {
 	defer func() {
-		c.hub.unregister <- c
+		select {
+		case c.hub.unregister <- c:
+		case <-c.hub.stop:
+		}
 		c.conn.Close()
 	}()
 	c.conn.SetReadLimit(maxMessageSize)
@@ -48,7 +69,62 @@ func (c *Client) readPump() {
 			}
 			break
 		}
-		c.hub.Commands <- message
+		if c.structured {
+			c.handleFrame(message)
+			continue
+		}
+		select {
+		case c.hub.Commands <- Command{Data: message}:
+		case <-c.hub.stop:
+			return
+		}
+	}
+}
+
+// handleFrame runs a protocol command and answers with an ack once it has
+// been written to the server's stdin, or with an error.
+func (c *Client) handleFrame(message []byte) {
+	var in ClientFrame
+	if err := json.Unmarshal(message, &in); err != nil {
+		c.hub.replyTo(c, newFrame(FrameError, ErrorData{Message: "invalid frame: " + err.Error()}))
+		return
+	}
+	if in.Type != FrameCommand {
+		c.hub.replyTo(c, newFrame(FrameError, ErrorData{ID: in.ID, Message: fmt.Sprintf("unsupported frame type %q", in.Type)}))
+		return
+	}
+
+	command := strings.TrimRight(in.Command, "\r\n")
+	if command == "" {
+		c.hub.replyTo(c, newFrame(FrameError, ErrorData{ID: in.ID, Message: "empty command"}))
+		return
+	}
+
+	if err := c.sendCommand(command); err != nil {
+		c.hub.replyTo(c, newFrame(FrameError, ErrorData{ID: in.ID, Message: err.Error()}))
+		return
+	}
+	c.hub.replyTo(c, newFrame(FrameAck, AckData{ID: in.ID, Command: command}))
+}
+
+func (c *Client) sendCommand(command string) error {
+	result := make(chan error, 1)
+	timeout := time.NewTimer(commandTimeout)
+	defer timeout.Stop()
+
+	select {
+	case c.hub.Commands <- Command{Data: []byte(command + "\n"), Result: result}:
+	case <-timeout.C:
+		return errNotAccepting
+	case <-c.hub.stop:
+		return errNotAccepting
+	}
+
+	select {
+	case err := <-result:
+		return err
+	case <-timeout.C:
+		return errNotAccepting
 	}
 }
 
@@ -58,65 +134,22 @@ func (c *Client) writePump() {
 		ticker.Stop()
 		c.conn.Close()
 	}()
-	for {
-		for {
-			select {
-			case message, ok := <-c.replay:
-				if !ok {
-					c.replay = nil
-					break
-				}
-				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-				w, err := c.conn.NextWriter(websocket.TextMessage)
-				if err != nil {
-					return
-				}
-				w.Write(message)
-
-				for {
-					n := len(c.replay)
-					for i := 0; i < n; i++ {
-						w.Write(newline)
-						w.Write(<-c.replay)
-					}
-					n2 := len(c.send)
-					for i := 0; i < n2; i++ {
-						w.Write(newline)
-						w.Write(<-c.send)
-					}
-
-					if err := w.Close(); err != nil {
-						return
-					}
-					break
-				}
-			default:
-				goto NORMAL
-			}
+
+	for message := range c.replay {
+		if err := c.writeMessage(message, c.replay); err != nil {
+			return
 		}
+	}
 
-	NORMAL:
+	for {
 		select {
 		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
+				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
-
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write(newline)
-				w.Write(<-c.send)
-			}
-
-			if err := w.Close(); err != nil {
+			if err := c.writeMessage(message, c.send); err != nil {
 				return
 			}
 		case <-ticker.C:
@@ -127,3 +160,29 @@ func (c *Client) writePump() {
 		}
 	}
 }
+
+// writeMessage writes one websocket message. Raw clients also get whatever
+// is already queued appended as extra lines; protocol clients get exactly
+// one JSON frame per message.
+func (c *Client) writeMessage(message []byte, queue chan []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	w, err := c.conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	w.Write(message)
+
+	if !c.structured {
+		n := len(queue)
+		for i := 0; i < n; i++ {
+			next, ok := <-queue
+			if !ok {
+				break
+			}
+			w.Write(newline)
+			w.Write(next)
+		}
+	}
+
+	return w.Close()
+}
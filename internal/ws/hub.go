@@ -1,9 +1,14 @@
 package ws
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -14,21 +19,43 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// entry is a published frame together with its encodings. raw is what raw
+// clients receive; it is nil for frames they never saw before the JSON
+// protocol existed.
+type entry struct {
+	frame   Frame
+	raw     []byte
+	encoded []byte
+	history bool
+}
+
+type reply struct {
+	client *Client
+	data   []byte
+}
+
 type Hub struct {
 	clients    map[*Client]bool
-	broadcast  chan []byte
-	Commands   chan []byte
+	broadcast  chan *entry
+	Commands   chan Command
 	register   chan *Client
 	unregister chan *Client
+	replies    chan reply
 	stop       chan bool
 
-	history        [][]byte
+	session string
+	seq     uint64
+
+	history        []*entry
 	maxHistory     int
 	clearHistory   chan struct{}
 	setHistorySize chan int
 
 	snapshotRequests chan *Client
 
+	clientCount       atomic.Int32
+	structuredClients atomic.Int32
+
 	mu sync.RWMutex
 }
 
@@ -37,95 +64,86 @@ func NewHubWithHistorySize(maxHistory int) *Hub {
 		maxHistory = 0
 	}
 	h := &Hub{
-		broadcast:        make(chan []byte, 4096),
-		Commands:         make(chan []byte),
+		broadcast:        make(chan *entry, 4096),
+		Commands:         make(chan Command),
 		register:         make(chan *Client),
 		unregister:       make(chan *Client),
+		replies:          make(chan reply, 16),
 		clients:          make(map[*Client]bool),
 		stop:             make(chan bool),
+		session:          strconv.FormatInt(time.Now().UnixNano(), 36),
 		maxHistory:       maxHistory,
 		clearHistory:     make(chan struct{}, 1),
 		setHistorySize:   make(chan int, 1),
 		snapshotRequests: make(chan *Client, 8),
 	}
 	if maxHistory > 0 {
-		h.history = make([][]byte, 0, maxHistory)
+		h.history = make([]*entry, 0, maxHistory)
 	} else {
 		h.history = nil
 	}
 	return h
 }
 
+// GetHistorySnapshot returns the buffered console lines as raw text.
 func (h *Hub) GetHistorySnapshot() [][]byte {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 	if h.history == nil || len(h.history) == 0 {
 		return nil
 	}
-	copyHist := make([][]byte, len(h.history))
-	copy(copyHist, h.history)
+	copyHist := make([][]byte, 0, len(h.history))
+	for _, e := range h.history {
+		if e.raw != nil {
+			copyHist = append(copyHist, e.raw)
+		}
+	}
 	return copyHist
 }
 
+// HasStructuredClients reports whether any protocol client is connected, so
+// publishers can skip work only those clients would see.
+func (h *Hub) HasStructuredClients() bool {
+	return h.structuredClients.Load() > 0
+}
+
+// ClientCount returns how many websocket clients are connected.
+func (h *Hub) ClientCount() int {
+	return int(h.clientCount.Load())
+}
+
 func (h *Hub) Run() {
 	for {
 		select {
 		case client := <-h.register:
-			h.clients[client] = true
+			h.addClient(client)
 
 		case client := <-h.unregister:
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-				if client.replay != nil {
-					close(client.replay)
-				}
-			}
+			h.removeClient(client)
 
 		case client := <-h.snapshotRequests:
 			h.mu.RLock()
-			if h.history != nil && len(h.history) > 0 {
-				copyHist := make([][]byte, len(h.history))
-				copy(copyHist, h.history)
-				h.mu.RUnlock()
-				if client.replay == nil {
-					client.replay = make(chan []byte, len(copyHist))
-				}
-				for _, msg := range copyHist {
-					select {
-					case client.replay <- msg:
-					default:
-						client.replay <- msg
-					}
-				}
-			} else {
-				h.mu.RUnlock()
-			}
-			h.clients[client] = true
-
-		case message := <-h.broadcast:
-			msgCopy := append([]byte(nil), message...)
-			if h.maxHistory > 0 {
-				h.mu.Lock()
-				h.history = append(h.history, msgCopy)
-				if len(h.history) > h.maxHistory {
-					h.history = h.history[1:]
-				}
-				h.mu.Unlock()
+			replay := h.replayFor(client)
+			h.mu.RUnlock()
+			client.replay = make(chan []byte, len(replay))
+			for _, msg := range replay {
+				client.replay <- msg
 			}
+			close(client.replay)
+			h.addClient(client)
+			close(client.ready)
 
-			for client := range h.clients {
+		case r := <-h.replies:
+			if h.clients[r.client] {
 				select {
-				case client.send <- msgCopy:
+				case r.client.send <- r.data:
 				default:
-					close(client.send)
-					if client.replay != nil {
-						close(client.replay)
-					}
-					delete(h.clients, client)
 				}
 			}
 
+		case e := <-h.broadcast:
+			h.publish(e)
+
 		case newSize := <-h.setHistorySize:
 			if newSize <= 0 {
 				h.maxHistory = 0
@@ -135,13 +153,13 @@ func (h *Hub) Run() {
 			} else {
 				h.mu.Lock()
 				if h.history == nil {
-					h.history = make([][]byte, 0, newSize)
+					h.history = make([]*entry, 0, newSize)
 				} else {
 					if len(h.history) > newSize {
 						h.history = h.history[len(h.history)-newSize:]
 					}
 					if cap(h.history) < newSize {
-						newHist := make([][]byte, len(h.history), newSize)
+						newHist := make([]*entry, len(h.history), newSize)
 						copy(newHist, h.history)
 						h.history = newHist
 					}
@@ -156,8 +174,18 @@ func (h *Hub) Run() {
 			h.mu.Unlock()
 
 		case <-h.stop:
+			// Deliver what was published before the stop, such as the final
+			// status change, before the clients are disconnected.
+			for pending := true; pending; {
+				select {
+				case e := <-h.broadcast:
+					h.publish(e)
+				default:
+					pending = false
+				}
+			}
 			for client := range h.clients {
-				close(client.send)
+				h.removeClient(client)
 			}
 			h.mu.Lock()
 			h.history = nil
@@ -167,6 +195,102 @@ func (h *Hub) Run() {
 	}
 }
 
+func (h *Hub) addClient(client *Client) {
+	h.clients[client] = true
+	h.clientCount.Add(1)
+	if client.structured {
+		h.structuredClients.Add(1)
+	}
+}
+
+func (h *Hub) removeClient(client *Client) {
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		close(client.send)
+		h.clientCount.Add(-1)
+		if client.structured {
+			h.structuredClients.Add(-1)
+		}
+	}
+}
+
+// publish numbers and stores history frames and hands the entry to every
+// client in the encoding it asked for.
+func (h *Hub) publish(e *entry) {
+	if e.history {
+		h.seq++
+		e.frame.Seq = h.seq
+	}
+	encoded, err := json.Marshal(e.frame)
+	if err != nil {
+		return
+	}
+	e.encoded = encoded
+
+	if e.history && h.maxHistory > 0 {
+		h.mu.Lock()
+		h.history = append(h.history, e)
+		if len(h.history) > h.maxHistory {
+			h.history = h.history[1:]
+		}
+		h.mu.Unlock()
+	}
+
+	for client := range h.clients {
+		msg := e.raw
+		if client.structured {
+			msg = e.encoded
+		}
+		if msg == nil {
+			continue
+		}
+		select {
+		case client.send <- msg:
+		default:
+			h.removeClient(client)
+		}
+	}
+}
+
+// replayFor builds what a new client receives before live output: the raw
+// history for raw clients, or a hello frame followed by the history frames
+// after the client's resume point. The caller must hold h.mu.
+func (h *Hub) replayFor(client *Client) [][]byte {
+	var replay [][]byte
+	if !client.structured {
+		for _, e := range h.history {
+			if e.raw != nil {
+				replay = append(replay, e.raw)
+			}
+		}
+		return replay
+	}
+
+	hello := HelloData{Session: h.session, Seq: h.seq}
+	since := uint64(0)
+	if client.resume {
+		if client.session == h.session {
+			hello.Resumed = true
+			since = client.since
+		}
+		oldest := h.seq + 1
+		if len(h.history) > 0 {
+			oldest = h.history[0].frame.Seq
+		}
+		hello.Gap = !hello.Resumed || (since < h.seq && since+1 < oldest)
+	}
+
+	if encoded, err := json.Marshal(newFrame(FrameHello, hello)); err == nil {
+		replay = append(replay, encoded)
+	}
+	for _, e := range h.history {
+		if e.frame.Seq > since {
+			replay = append(replay, e.encoded)
+		}
+	}
+	return replay
+}
+
 func (h *Hub) Stop() {
 	close(h.stop)
 }
@@ -189,24 +313,91 @@ func (h *Hub) SetHistorySize(size int) {
 	}
 }
 
+// Broadcast publishes a raw message, such as a progress event, as a log line.
 func (h *Hub) Broadcast(message []byte) {
-	h.broadcast <- message
+	h.broadcast <- &entry{
+		frame:   newFrame(FrameLog, LogData{Line: string(message), Stream: StreamStdout}),
+		raw:     append([]byte(nil), message...),
+		history: true,
+	}
+}
+
+// PublishLog publishes a console line read from the given stream.
+func (h *Hub) PublishLog(line, stream, level string) {
+	h.broadcast <- &entry{
+		frame:   newFrame(FrameLog, LogData{Line: line, Stream: stream, Level: level}),
+		raw:     []byte(line),
+		history: true,
+	}
+}
+
+// PublishStatus publishes a server status change to protocol clients.
+func (h *Hub) PublishStatus(status string) {
+	h.broadcast <- &entry{
+		frame:   newFrame(FrameStatus, StatusData{Status: status}),
+		history: true,
+	}
+}
+
+// PublishStats publishes a resource usage sample to protocol clients. Stats
+// are not kept in the history.
+func (h *Hub) PublishStats(stats interface{}) {
+	h.broadcast <- &entry{frame: newFrame(FrameStats, stats)}
+}
+
+func (h *Hub) replyTo(client *Client, frame Frame) {
+	encoded, err := json.Marshal(frame)
+	if err != nil {
+		return
+	}
+	select {
+	case h.replies <- reply{client: client, data: encoded}:
+	case <-h.stop:
+	}
 }
 
 func (h *Hub) ServeWs(w http.ResponseWriter, r *http.Request) {
+	client := &Client{hub: h, send: make(chan []byte, 256), ready: make(chan struct{})}
+
+	query := r.URL.Query()
+	if version := query.Get("protocol"); version != "" {
+		if version != strconv.Itoa(ProtocolVersion) {
+			http.Error(w, fmt.Sprintf("unsupported protocol version %q", version), http.StatusBadRequest)
+			return
+		}
+		client.structured = true
+		if since := query.Get("since"); since != "" {
+			n, err := strconv.ParseUint(since, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid since", http.StatusBadRequest)
+				return
+			}
+			client.resume = true
+			client.since = n
+			client.session = query.Get("session")
+		}
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	client := &Client{hub: h, conn: conn, send: make(chan []byte, 256), replay: nil}
-
-	go client.writePump()
-	go client.readPump()
+	client.conn = conn
 
 	select {
 	case h.snapshotRequests <- client:
-	default:
-		go func() { h.snapshotRequests <- client }()
+	case <-h.stop:
+		conn.Close()
+		return
 	}
+	select {
+	case <-client.ready:
+	case <-h.stop:
+		conn.Close()
+		return
+	}
+
+	go client.writePump()
+	go client.readPump()
 }
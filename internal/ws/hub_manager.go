@@ -53,3 +53,14 @@ func (m *HubManager) SetDefaultHistorySize(size int) {
 		}
 	}
 }
+
+// ClientCounts returns the number of connected websocket clients per server.
+func (m *HubManager) ClientCounts() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]int, len(m.hubs))
+	for serverID, hub := range m.hubs {
+		counts[serverID] = hub.ClientCount()
+	}
+	return counts
+}
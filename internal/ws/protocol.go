@@ -0,0 +1,89 @@
+package ws
+
+import "time"
+
+// ProtocolVersion is the version of the JSON console protocol. Clients opt in
+// by connecting with ?protocol=1; without it the socket carries raw text
+// lines in both directions, as it always has.
+const ProtocolVersion = 1
+
+const (
+	FrameHello   = "hello"
+	FrameLog     = "log"
+	FrameStatus  = "status"
+	FrameStats   = "stats"
+	FrameAck     = "ack"
+	FrameError   = "error"
+	FrameCommand = "command"
+)
+
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// Frame is one message of the JSON protocol. Seq is set on frames kept in the
+// hub history (log lines and status changes), which are the ones a client
+// can resume from; acks, errors and stats ticks carry no sequence number.
+type Frame struct {
+	Version int         `json:"v"`
+	Type    string      `json:"type"`
+	Seq     uint64      `json:"seq,omitempty"`
+	Time    time.Time   `json:"time"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// HelloData is sent first on every protocol connection. Session identifies
+// the hub; sequence numbers from another session cannot be resumed. Gap is
+// set when frames after the requested sequence number were already dropped
+// from the history.
+type HelloData struct {
+	Session string `json:"session"`
+	Seq     uint64 `json:"seq"`
+	Resumed bool   `json:"resumed"`
+	Gap     bool   `json:"gap"`
+}
+
+type LogData struct {
+	Line   string `json:"line"`
+	Stream string `json:"stream"`
+	Level  string `json:"level,omitempty"`
+}
+
+type StatusData struct {
+	Status string `json:"status"`
+}
+
+type AckData struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+}
+
+type ErrorData struct {
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
+
+// ClientFrame is a message sent by a protocol client. The only type accepted
+// is "command"; the ID is echoed in the matching ack or error.
+type ClientFrame struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Command string `json:"command"`
+}
+
+// Command is console input for the server process. Result, when set,
+// receives the outcome of writing it to stdin.
+type Command struct {
+	Data   []byte
+	Result chan error
+}
+
+func newFrame(frameType string, data interface{}) Frame {
+	return Frame{
+		Version: ProtocolVersion,
+		Type:    frameType,
+		Time:    time.Now().UTC(),
+		Data:    data,
+	}
+}
@@ -29,6 +29,24 @@ func (c *Client) CreateBackup(serverID, name string) (*struct {
 	return &result, err
 }
 
+// CreateSnapshot starts a copy-on-write snapshot of a server, which is a zip
+// backup where the filesystem does not support snapshots.
+func (c *Client) CreateSnapshot(serverID, name string) error {
+	payload := map[string]interface{}{
+		"name":     name,
+		"snapshot": true,
+	}
+	return c.post(fmt.Sprintf("/servers/%s/backup", serverID), payload, nil)
+}
+
+// ConvertBackup starts writing a portable zip backup of a snapshot.
+func (c *Client) ConvertBackup(name, requestID string) error {
+	payload := map[string]string{
+		"requestId": requestID,
+	}
+	return c.post(fmt.Sprintf("/backups/%s/convert", name), payload, nil)
+}
+
 func (c *Client) DeleteBackup(name string) error {
 	return c.delete(fmt.Sprintf("/backups/%s", name))
 }
@@ -126,6 +126,24 @@ func (c *Client) put(path string, body interface{}) error {
 	return nil
 }
 
+func (c *Client) patch(path string, body interface{}) error {
+	resp, err := c.doRequest(http.MethodPatch, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		msg := strings.TrimSpace(string(bodyBytes))
+		if msg != "" {
+			return fmt.Errorf("error: %s", msg)
+		}
+		return fmt.Errorf("API error (%d)", resp.StatusCode)
+	}
+	return nil
+}
+
 func (c *Client) GetWebSocketURL(path string) (string, error) {
 	u, err := url.Parse(c.baseURL)
 	if err != nil {
@@ -0,0 +1,83 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ConsoleProtocolVersion is the console websocket protocol spoken by
+// ConsoleURL connections.
+const ConsoleProtocolVersion = 1
+
+// ConsoleFrame is one JSON frame of the console protocol. Decode Data with
+// the type matching Type: ConsoleHello, ConsoleLog, ConsoleStatus,
+// ServerStats, ConsoleAck or ConsoleError.
+type ConsoleFrame struct {
+	Version int             `json:"v"`
+	Type    string          `json:"type"`
+	Seq     uint64          `json:"seq,omitempty"`
+	Time    time.Time       `json:"time"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (f *ConsoleFrame) Decode(target interface{}) error {
+	return json.Unmarshal(f.Data, target)
+}
+
+type ConsoleHello struct {
+	Session string `json:"session"`
+	Seq     uint64 `json:"seq"`
+	Resumed bool   `json:"resumed"`
+	Gap     bool   `json:"gap"`
+}
+
+type ConsoleLog struct {
+	Line   string `json:"line"`
+	Stream string `json:"stream"`
+	Level  string `json:"level,omitempty"`
+}
+
+type ConsoleStatus struct {
+	Status string `json:"status"`
+}
+
+type ConsoleAck struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+}
+
+type ConsoleError struct {
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
+
+type ConsoleCommand struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Command string `json:"command"`
+}
+
+// NewConsoleCommand builds the frame that runs command on the server.
+func NewConsoleCommand(id, command string) ConsoleCommand {
+	return ConsoleCommand{Type: "command", ID: id, Command: command}
+}
+
+// ConsoleURL returns the websocket URL of a server console using the JSON
+// protocol. A non-empty session resumes after sequence number since.
+func (c *Client) ConsoleURL(serverID, session string, since uint64) (string, error) {
+	wsURL, err := c.GetWebSocketURL(fmt.Sprintf("/ws/servers/%s/console", serverID))
+	if err != nil {
+		return "", err
+	}
+
+	values := url.Values{}
+	values.Set("protocol", strconv.Itoa(ConsoleProtocolVersion))
+	if session != "" {
+		values.Set("session", session)
+		values.Set("since", strconv.FormatUint(since, 10))
+	}
+	return wsURL + "?" + values.Encode(), nil
+}
@@ -0,0 +1,17 @@
+package sdk
+
+import (
+	"fmt"
+)
+
+func (c *Client) ListIncidents(serverID string) ([]Incident, error) {
+	var incidents []Incident
+	err := c.get(fmt.Sprintf("/servers/%s/incidents", serverID), &incidents)
+	return incidents, err
+}
+
+func (c *Client) GetIncident(serverID, incidentID string) (*Incident, error) {
+	var incident Incident
+	err := c.get(fmt.Sprintf("/servers/%s/incidents/%s", serverID, incidentID), &incident)
+	return &incident, err
+}
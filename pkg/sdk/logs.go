@@ -0,0 +1,39 @@
+package sdk
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func (c *Client) SearchLogs(serverID string, query LogQuery) (*LogPage, error) {
+	values := url.Values{}
+	if !query.From.IsZero() {
+		values.Set("from", query.From.Format(time.RFC3339Nano))
+	}
+	if !query.To.IsZero() {
+		values.Set("to", query.To.Format(time.RFC3339Nano))
+	}
+	if query.Query != "" {
+		values.Set("q", query.Query)
+	}
+	if query.Limit > 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+	if query.Offset > 0 {
+		values.Set("offset", strconv.Itoa(query.Offset))
+	}
+	if query.Tail {
+		values.Set("tail", "true")
+	}
+
+	path := fmt.Sprintf("/servers/%s/logs", serverID)
+	if encoded := values.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+
+	var page LogPage
+	err := c.get(path, &page)
+	return &page, err
+}
@@ -0,0 +1,60 @@
+package sdk
+
+import (
+	"fmt"
+	"net/url"
+)
+
+func (c *Client) ListNetworks() ([]Network, error) {
+	var networks []Network
+	err := c.get("/networks", &networks)
+	return networks, err
+}
+
+func (c *Client) GetNetwork(id string) (*Network, error) {
+	var network Network
+	err := c.get(fmt.Sprintf("/networks/%s", id), &network)
+	return &network, err
+}
+
+func (c *Client) CreateNetwork(name, proxyID string) (*Network, error) {
+	payload := map[string]string{
+		"name":    name,
+		"proxyId": proxyID,
+	}
+	var network Network
+	err := c.post("/networks", payload, &network)
+	return &network, err
+}
+
+func (c *Client) DeleteNetwork(id string) error {
+	return c.delete(fmt.Sprintf("/networks/%s", id))
+}
+
+func (c *Client) AttachNetworkServer(networkID, serverID, alias string) ([]string, error) {
+	payload := map[string]string{
+		"serverId": serverID,
+		"alias":    alias,
+	}
+	var result NetworkWarnings
+	err := c.post(fmt.Sprintf("/networks/%s/servers", networkID), payload, &result)
+	return result.Warnings, err
+}
+
+func (c *Client) DetachNetworkServer(networkID, serverID string) error {
+	return c.delete(fmt.Sprintf("/networks/%s/servers/%s", networkID, serverID))
+}
+
+func (c *Client) ApplyNetwork(networkID string) ([]string, error) {
+	var result NetworkWarnings
+	err := c.post(fmt.Sprintf("/networks/%s/apply", networkID), nil, &result)
+	return result.Warnings, err
+}
+
+func (c *Client) StartNetwork(networkID, requestID string) error {
+	return c.post(fmt.Sprintf("/networks/%s/start?requestId=%s", networkID, url.QueryEscape(requestID)), nil, nil)
+}
+
+func (c *Client) StopNetwork(networkID, requestID string) error {
+	return c.post(fmt.Sprintf("/networks/%s/stop?requestId=%s", networkID, url.QueryEscape(requestID)), nil, nil)
+}
@@ -0,0 +1,46 @@
+package sdk
+
+import (
+	"fmt"
+)
+
+func (c *Client) ListNotificationEvents() ([]string, error) {
+	var events []string
+	err := c.get("/notifications/events", &events)
+	return events, err
+}
+
+func (c *Client) ListNotificationSinks() ([]NotificationSink, error) {
+	var sinks []NotificationSink
+	err := c.get("/notifications/sinks", &sinks)
+	return sinks, err
+}
+
+func (c *Client) CreateNotificationSink(req NotificationSinkRequest) (*NotificationSink, error) {
+	var sink NotificationSink
+	err := c.post("/notifications/sinks", req, &sink)
+	return &sink, err
+}
+
+func (c *Client) UpdateNotificationSink(id string, req NotificationSinkRequest) error {
+	return c.put(fmt.Sprintf("/notifications/sinks/%s", id), req)
+}
+
+func (c *Client) DeleteNotificationSink(id string) error {
+	return c.delete(fmt.Sprintf("/notifications/sinks/%s", id))
+}
+
+// TestNotificationSink sends a test event and returns the delivery error.
+func (c *Client) TestNotificationSink(id string) error {
+	return c.post(fmt.Sprintf("/notifications/sinks/%s/test", id), nil, nil)
+}
+
+func (c *Client) ListDeadLetters(limit int) ([]DeadLetter, error) {
+	path := "/notifications/dead-letters"
+	if limit > 0 {
+		path = fmt.Sprintf("%s?limit=%d", path, limit)
+	}
+	var letters []DeadLetter
+	err := c.get(path, &letters)
+	return letters, err
+}
@@ -0,0 +1,102 @@
+package sdk
+
+import (
+	"fmt"
+	"net/url"
+)
+
+func (c *Client) ListWhitelist(serverID string) ([]WhitelistEntry, error) {
+	var entries []WhitelistEntry
+	err := c.get(fmt.Sprintf("/servers/%s/players/whitelist", serverID), &entries)
+	return entries, err
+}
+
+func (c *Client) AddToWhitelist(serverID, name string) (*PlayerListResult, error) {
+	payload := map[string]string{"name": name}
+	var result PlayerListResult
+	err := c.post(fmt.Sprintf("/servers/%s/players/whitelist", serverID), payload, &result)
+	return &result, err
+}
+
+func (c *Client) RemoveFromWhitelist(serverID, player string) error {
+	return c.delete(fmt.Sprintf("/servers/%s/players/whitelist/%s", serverID, url.PathEscape(player)))
+}
+
+func (c *Client) ListOps(serverID string) ([]OpEntry, error) {
+	var entries []OpEntry
+	err := c.get(fmt.Sprintf("/servers/%s/players/ops", serverID), &entries)
+	return entries, err
+}
+
+func (c *Client) AddOp(serverID, name string, level int, bypassesPlayerLimit bool) (*PlayerListResult, error) {
+	payload := map[string]interface{}{
+		"name":                name,
+		"level":               level,
+		"bypassesPlayerLimit": bypassesPlayerLimit,
+	}
+	var result PlayerListResult
+	err := c.post(fmt.Sprintf("/servers/%s/players/ops", serverID), payload, &result)
+	return &result, err
+}
+
+func (c *Client) RemoveOp(serverID, player string) error {
+	return c.delete(fmt.Sprintf("/servers/%s/players/ops/%s", serverID, url.PathEscape(player)))
+}
+
+func (c *Client) ListBans(serverID string) ([]BanEntry, error) {
+	var entries []BanEntry
+	err := c.get(fmt.Sprintf("/servers/%s/players/bans", serverID), &entries)
+	return entries, err
+}
+
+func (c *Client) BanPlayer(serverID, name, reason string) (*PlayerListResult, error) {
+	payload := map[string]string{
+		"name":   name,
+		"reason": reason,
+	}
+	var result PlayerListResult
+	err := c.post(fmt.Sprintf("/servers/%s/players/bans", serverID), payload, &result)
+	return &result, err
+}
+
+func (c *Client) PardonPlayer(serverID, player string) error {
+	return c.delete(fmt.Sprintf("/servers/%s/players/bans/%s", serverID, url.PathEscape(player)))
+}
+
+func (c *Client) ListIPBans(serverID string) ([]IPBanEntry, error) {
+	var entries []IPBanEntry
+	err := c.get(fmt.Sprintf("/servers/%s/players/ip-bans", serverID), &entries)
+	return entries, err
+}
+
+func (c *Client) BanIP(serverID, ip, reason string) (*PlayerListResult, error) {
+	payload := map[string]string{
+		"ip":     ip,
+		"reason": reason,
+	}
+	var result PlayerListResult
+	err := c.post(fmt.Sprintf("/servers/%s/players/ip-bans", serverID), payload, &result)
+	return &result, err
+}
+
+func (c *Client) PardonIP(serverID, ip string) error {
+	return c.delete(fmt.Sprintf("/servers/%s/players/ip-bans/%s", serverID, url.PathEscape(ip)))
+}
+
+func (c *Client) ListOnlinePlayers(serverID string) ([]PlayerSession, error) {
+	var sessions []PlayerSession
+	err := c.get(fmt.Sprintf("/servers/%s/players/online", serverID), &sessions)
+	return sessions, err
+}
+
+func (c *Client) ListPlayerHistory(serverID string) ([]PlayerSummary, error) {
+	var summaries []PlayerSummary
+	err := c.get(fmt.Sprintf("/servers/%s/players/history", serverID), &summaries)
+	return summaries, err
+}
+
+func (c *Client) GetPlayerHistory(serverID, player string) (*PlayerHistory, error) {
+	var history PlayerHistory
+	err := c.get(fmt.Sprintf("/servers/%s/players/history/%s", serverID, url.PathEscape(player)), &history)
+	return &history, err
+}
@@ -0,0 +1,21 @@
+package sdk
+
+import (
+	"fmt"
+)
+
+func (c *Client) ListSchedules(serverID string) ([]Schedule, error) {
+	var schedules []Schedule
+	err := c.get(fmt.Sprintf("/servers/%s/schedules", serverID), &schedules)
+	return schedules, err
+}
+
+func (c *Client) CreateSchedule(serverID string, req ScheduleRequest) (*Schedule, error) {
+	var schedule Schedule
+	err := c.post(fmt.Sprintf("/servers/%s/schedules", serverID), req, &schedule)
+	return &schedule, err
+}
+
+func (c *Client) DeleteSchedule(serverID, id string) error {
+	return c.delete(fmt.Sprintf("/servers/%s/schedules/%s", serverID, id))
+}
@@ -1,6 +1,9 @@
 package sdk
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 func (c *Client) ListServers() ([]Server, error) {
 	var servers []Server
@@ -18,6 +21,102 @@ func (c *Client) CreateServer(req CreateServerRequest) error {
 	return c.post("/servers", req, nil)
 }
 
+func (c *Client) ImportModpack(req ImportModpackRequest) error {
+	return c.post("/servers/import-modpack", req, nil)
+}
+
+func (c *Client) CloneServer(id string, req CloneServerRequest) error {
+	return c.post(fmt.Sprintf("/servers/%s/clone", id), req, nil)
+}
+
+func (c *Client) UpdateServer(id string, req UpdateServerRequest) error {
+	return c.put(fmt.Sprintf("/servers/%s", id), req)
+}
+
+func (c *Client) GetServerProperties(id string) (*ServerProperties, error) {
+	var props ServerProperties
+	err := c.get(fmt.Sprintf("/servers/%s/properties", id), &props)
+	return &props, err
+}
+
+func (c *Client) UpdateServerProperties(id string, values map[string]string) error {
+	return c.patch(fmt.Sprintf("/servers/%s/properties", id), values)
+}
+
+func (c *Client) ListMods(id string) ([]ModFile, error) {
+	var mods []ModFile
+	err := c.get(fmt.Sprintf("/servers/%s/mods", id), &mods)
+	return mods, err
+}
+
+func (c *Client) InstallMod(id string, req InstallModRequest) (*ModFile, error) {
+	var mod ModFile
+	err := c.post(fmt.Sprintf("/servers/%s/mods", id), req, &mod)
+	return &mod, err
+}
+
+func (c *Client) DeleteMod(id, name string) error {
+	return c.delete(fmt.Sprintf("/servers/%s/mods/%s", id, url.PathEscape(name)))
+}
+
+func (c *Client) ListWorlds(id string) ([]World, error) {
+	var worlds []World
+	err := c.get(fmt.Sprintf("/servers/%s/worlds", id), &worlds)
+	return worlds, err
+}
+
+func (c *Client) SetActiveWorld(id, name string) error {
+	return c.put(fmt.Sprintf("/servers/%s/worlds/active", id), map[string]string{"name": name})
+}
+
+// ResetWorld backs the stopped server up and deletes its active world, and
+// returns the name of the backup.
+func (c *Client) ResetWorld(id, name string, req ResetWorldRequest) (string, error) {
+	var resp struct {
+		Backup string `json:"backup"`
+	}
+	err := c.post(fmt.Sprintf("/servers/%s/worlds/%s/reset", id, url.PathEscape(name)), req, &resp)
+	return resp.Backup, err
+}
+
+func (c *Client) PregenerateWorld(id, name string, radius int) error {
+	return c.post(fmt.Sprintf("/servers/%s/worlds/%s/pregenerate", id, url.PathEscape(name)), map[string]int{"radius": radius}, nil)
+}
+
+func (c *Client) GetLevelData(id, world string) (map[string]any, error) {
+	var data map[string]any
+	err := c.get(fmt.Sprintf("/servers/%s/worlds/%s/level", id, url.PathEscape(world)), &data)
+	return data, err
+}
+
+func (c *Client) ListPlayerData(id, world string) ([]string, error) {
+	var players []string
+	err := c.get(fmt.Sprintf("/servers/%s/worlds/%s/playerdata", id, url.PathEscape(world)), &players)
+	return players, err
+}
+
+func (c *Client) GetPlayerData(id, world, playerUUID string) (map[string]any, error) {
+	var data map[string]any
+	err := c.get(fmt.Sprintf("/servers/%s/worlds/%s/playerdata/%s", id, url.PathEscape(world), playerUUID), &data)
+	return data, err
+}
+
+// PatchPlayerData applies a JSON merge patch to an offline player's save.
+// The server must be stopped; it is backed up first.
+func (c *Client) PatchPlayerData(id, world, playerUUID string, patch map[string]any) error {
+	return c.patch(fmt.Sprintf("/servers/%s/worlds/%s/playerdata/%s", id, url.PathEscape(world), playerUUID), patch)
+}
+
+func (c *Client) GetChunk(id, world, dimension string, x, z int) (map[string]any, error) {
+	var chunk map[string]any
+	path := fmt.Sprintf("/servers/%s/worlds/%s/chunks/%d/%d", id, url.PathEscape(world), x, z)
+	if dimension != "" {
+		path += "?dimension=" + url.QueryEscape(dimension)
+	}
+	err := c.get(path, &chunk)
+	return chunk, err
+}
+
 func (c *Client) StartServer(id string) error {
 	return c.post(fmt.Sprintf("/servers/%s/start", id), nil, nil)
 }
@@ -67,3 +166,25 @@ func (c *Client) SetPortRange(start, end int) error {
 	}
 	return c.put("/settings/port-range", payload)
 }
+
+func (c *Client) GetServerLimits(id string) (*ServerLimits, error) {
+	var limits ServerLimits
+	err := c.get(fmt.Sprintf("/servers/%s/limits", id), &limits)
+	return &limits, err
+}
+
+func (c *Client) UpdateServerLimits(id string, limits ResourceLimits) error {
+	return c.put(fmt.Sprintf("/servers/%s/limits", id), limits)
+}
+
+func (c *Client) ListJVMProfiles() ([]JVMProfile, error) {
+	var profiles []JVMProfile
+	err := c.get("/jvm-profiles", &profiles)
+	return profiles, err
+}
+
+func (c *Client) PreviewServerCommand(id string) (*CommandPreview, error) {
+	var preview CommandPreview
+	err := c.get(fmt.Sprintf("/servers/%s/command", id), &preview)
+	return &preview, err
+}
@@ -0,0 +1,28 @@
+package sdk
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// GetStatsHistory returns the resource usage of a server over the last
+// rng, such as "24h" or "7d", in buckets of step. Empty values use the
+// daemon defaults.
+func (c *Client) GetStatsHistory(serverID, rng, step string) (*StatsHistory, error) {
+	values := url.Values{}
+	if rng != "" {
+		values.Set("range", rng)
+	}
+	if step != "" {
+		values.Set("step", step)
+	}
+
+	path := fmt.Sprintf("/servers/%s/stats/history", serverID)
+	if encoded := values.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+
+	var history StatsHistory
+	err := c.get(path, &history)
+	return &history, err
+}
@@ -1,5 +1,33 @@
 package sdk
 
+import "fmt"
+
 func (c *Client) RestartDaemon() error {
 	return c.post("/system/restart", nil, nil)
 }
+
+func (c *Client) ListJavaRuntimes() ([]JavaRuntime, error) {
+	var runtimes []JavaRuntime
+	err := c.get("/system/java", &runtimes)
+	return runtimes, err
+}
+
+// InstallJavaRuntime starts installing the managed runtime for a Java major
+// version. Progress is published on the requestID hub.
+func (c *Client) InstallJavaRuntime(version int, requestID string) error {
+	payload := map[string]interface{}{
+		"version":   version,
+		"requestId": requestID,
+	}
+	return c.post("/system/java", payload, nil)
+}
+
+func (c *Client) RegisterJavaRuntime(path string) (*JavaRuntime, error) {
+	var runtime JavaRuntime
+	err := c.post("/system/java/register", map[string]string{"path": path}, &runtime)
+	return &runtime, err
+}
+
+func (c *Client) DeleteJavaRuntime(id string) error {
+	return c.delete(fmt.Sprintf("/system/java/%s", id))
+}
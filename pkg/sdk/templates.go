@@ -0,0 +1,29 @@
+package sdk
+
+import "fmt"
+
+func (c *Client) ListTemplates() ([]Template, error) {
+	var templates []Template
+	err := c.get("/templates", &templates)
+	return templates, err
+}
+
+func (c *Client) GetTemplate(id string) (*Template, error) {
+	var template Template
+	err := c.get(fmt.Sprintf("/templates/%s", id), &template)
+	return &template, err
+}
+
+func (c *Client) CreateTemplate(serverID, name string) (*Template, error) {
+	payload := map[string]string{
+		"serverId": serverID,
+		"name":     name,
+	}
+	var template Template
+	err := c.post("/templates", payload, &template)
+	return &template, err
+}
+
+func (c *Client) DeleteTemplate(id string) error {
+	return c.delete(fmt.Sprintf("/templates/%s", id))
+}
@@ -1,21 +1,91 @@
 package sdk
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
 
 type Server struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Version   string    `json:"version"`
-	Loader    string    `json:"loader"`
-	Port      int       `json:"port"`
-	RAM       int       `json:"ram"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Version     string         `json:"version"`
+	Loader      string         `json:"loader"`
+	Port        int            `json:"port"`
+	RAM         int            `json:"ram"`
+	Status      string         `json:"status"`
+	CustomArgs  string         `json:"customArgs"`
+	JavaVersion int            `json:"javaVersion"`
+	Limits      ResourceLimits `json:"limits"`
+	RunMode     string         `json:"runMode"`
+	JVMProfile  string         `json:"jvmProfile"`
+	JavaRuntime string         `json:"javaRuntime"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// ResourceLimits are the cgroup limits of a server on Linux. Zero values use
+// the defaults and -1 removes a limit. CPUQuota is in percent of one core.
+type ResourceLimits struct {
+	MemoryMaxMB int `json:"memoryMaxMb"`
+	CPUWeight   int `json:"cpuWeight"`
+	CPUQuota    int `json:"cpuQuota"`
+	PidsMax     int `json:"pidsMax"`
+	IOWeight    int `json:"ioWeight"`
+}
+
+type CgroupUsage struct {
+	MemoryCurrent    uint64  `json:"memoryCurrent"`
+	MemoryMaxHits    int64   `json:"memoryMaxHits"`
+	OOMKills         int64   `json:"oomKills"`
+	ThrottledPeriods int64   `json:"throttledPeriods"`
+	ThrottledSeconds float64 `json:"throttledSeconds"`
+	PidsCurrent      int64   `json:"pidsCurrent"`
+}
+
+// ServerLimits holds the stored limits of a server, the ones applied on the
+// next start and whether the daemon host can enforce them.
+type ServerLimits struct {
+	Limits    ResourceLimits `json:"limits"`
+	Effective ResourceLimits `json:"effective"`
+	Enforced  bool           `json:"enforced"`
+	Usage     *CgroupUsage   `json:"usage,omitempty"`
+}
+
+// JVMProfile is a named set of JVM flags servers can start with.
+type JVMProfile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MinJava     int    `json:"minJava,omitempty"`
+}
+
+// CommandPreview is the command line a server would start with.
+type CommandPreview struct {
+	RunMode     string   `json:"runMode"`
+	JVMProfile  string   `json:"jvmProfile"`
+	JavaVersion int      `json:"javaVersion"`
+	Args        []string `json:"args"`
+	CommandLine string   `json:"commandLine"`
+}
+
+// JavaRuntime is a Java installation servers can run with, either managed
+// by the daemon or registered from the host.
+type JavaRuntime struct {
+	ID      string   `json:"id"`
+	Kind    string   `json:"kind"`
+	Home    string   `json:"home"`
+	Java    string   `json:"java"`
+	Major   int      `json:"major"`
+	Version string   `json:"version"`
+	Vendor  string   `json:"vendor"`
+	Size    int64    `json:"size"`
+	Servers []string `json:"servers"`
 }
 
 type BackupInfo struct {
 	Name string `json:"name"`
 	Size int64  `json:"size"`
+	// Kind is "zip" or "snapshot".
+	Kind string `json:"kind"`
 }
 
 type ProgressEvent struct {
@@ -27,9 +97,34 @@ type ProgressEvent struct {
 }
 
 type ServerStats struct {
-	CPU  float64 `json:"cpu"`
-	RAM  uint64  `json:"ram"`
-	Disk int64   `json:"disk"`
+	CPU    float64      `json:"cpu"`
+	RAM    uint64       `json:"ram"`
+	Disk   int64        `json:"disk"`
+	TPS    float64      `json:"tps,omitempty"`
+	MSPT   float64      `json:"mspt,omitempty"`
+	Cgroup *CgroupUsage `json:"cgroup,omitempty"`
+}
+
+// StatSample is ServerStats averaged over one step, with the peaks of the
+// step in CPUMax and RAMMax.
+type StatSample struct {
+	Time   time.Time `json:"time"`
+	CPU    float64   `json:"cpu"`
+	CPUMax float64   `json:"cpuMax"`
+	RAM    uint64    `json:"ram"`
+	RAMMax uint64    `json:"ramMax"`
+	Disk   int64     `json:"disk"`
+}
+
+// StatsHistory holds one point per step between From and To. Steps the
+// server was not running have no point. Step and Resolution are in seconds.
+type StatsHistory struct {
+	ServerID   string       `json:"serverId"`
+	From       time.Time    `json:"from"`
+	To         time.Time    `json:"to"`
+	Step       int          `json:"step"`
+	Resolution int          `json:"resolution"`
+	Points     []StatSample `json:"points"`
 }
 
 type UpdateInfo struct {
@@ -45,10 +140,122 @@ type PortRange struct {
 }
 
 type CreateServerRequest struct {
-	Name      string `json:"name"`
-	Version   string `json:"version"`
-	Loader    string `json:"loader"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Loader      string `json:"loader"`
+	Ram         int    `json:"ram"`
+	RequestID   string `json:"requestId"`
+	JarURL      string `json:"jarUrl,omitempty"`
+	JavaVersion int    `json:"javaVersion,omitempty"`
+	TemplateID  string `json:"templateId,omitempty"`
+}
+
+type UpdateServerRequest struct {
+	Name       *string `json:"name,omitempty"`
+	RAM        *int    `json:"ram,omitempty"`
+	CustomArgs *string `json:"customArgs,omitempty"`
+	RunMode    *string `json:"runMode,omitempty"`
+	JVMProfile *string `json:"jvmProfile,omitempty"`
+	// JavaRuntime pins a runtime by ID; an empty string unpins it.
+	JavaRuntime *string `json:"javaRuntime,omitempty"`
+}
+
+type ServerProperty struct {
+	Key         string      `json:"key"`
+	Type        string      `json:"type"`
+	Value       interface{} `json:"value"`
+	Default     interface{} `json:"default"`
+	Options     []string    `json:"options,omitempty"`
+	Min         *int        `json:"min,omitempty"`
+	Max         *int        `json:"max,omitempty"`
+	Description string      `json:"description"`
+	Set         bool        `json:"set"`
+}
+
+type ServerProperties struct {
+	Version    string            `json:"version"`
+	Properties []ServerProperty  `json:"properties"`
+	Extra      map[string]string `json:"extra"`
+}
+
+// Values returns the keys present in server.properties in their raw string
+// form, leaving out schema defaults that are not set.
+func (p *ServerProperties) Values() map[string]string {
+	values := make(map[string]string, len(p.Properties)+len(p.Extra))
+	for _, prop := range p.Properties {
+		if !prop.Set {
+			continue
+		}
+		switch v := prop.Value.(type) {
+		case string:
+			values[prop.Key] = v
+		case float64:
+			values[prop.Key] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			values[prop.Key] = fmt.Sprint(v)
+		}
+	}
+	for key, value := range p.Extra {
+		values[key] = value
+	}
+	return values
+}
+
+type ModFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	SHA1 string `json:"sha1"`
+}
+
+type World struct {
+	Name       string      `json:"name"`
+	Active     bool        `json:"active"`
+	LevelName  string      `json:"levelName"`
+	Seed       string      `json:"seed"`
+	Version    string      `json:"version"`
+	Size       int64       `json:"size"`
+	Dimensions []Dimension `json:"dimensions"`
+}
+
+type Dimension struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+type ResetWorldRequest struct {
+	Seed       string `json:"seed,omitempty"`
+	RandomSeed bool   `json:"randomSeed,omitempty"`
+}
+
+type InstallModRequest struct {
+	URL  string `json:"url"`
+	Name string `json:"name,omitempty"`
+	SHA1 string `json:"sha1,omitempty"`
+}
+
+type CloneServerRequest struct {
+	Name          string `json:"name,omitempty"`
+	IncludeWorlds bool   `json:"includeWorlds"`
+	RequestID     string `json:"requestId"`
+}
+
+type Template struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Loader         string    `json:"loader"`
+	Version        string    `json:"version"`
+	RAM            int       `json:"ram"`
+	CustomArgs     string    `json:"customArgs"`
+	JavaVersion    int       `json:"javaVersion"`
+	SourceServerID string    `json:"sourceServerId"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ImportModpackRequest struct {
+	Name      string `json:"name,omitempty"`
 	Ram       int    `json:"ram"`
+	URL       string `json:"url"`
 	RequestID string `json:"requestId"`
 }
 
@@ -59,3 +266,205 @@ type RestoreBackupRequest struct {
 	NewServerLoader  string `json:"newServerLoader,omitempty"`
 	NewServerRam     int    `json:"newServerRam,omitempty"`
 }
+
+type NetworkServer struct {
+	NetworkID string `json:"networkId"`
+	ServerID  string `json:"serverId"`
+	Alias     string `json:"alias"`
+	Priority  int    `json:"priority"`
+}
+
+type Network struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	ProxyID   string          `json:"proxyId"`
+	Servers   []NetworkServer `json:"servers"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type NetworkWarnings struct {
+	Warnings []string `json:"warnings"`
+}
+
+type WhitelistEntry struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+type OpEntry struct {
+	UUID                string `json:"uuid"`
+	Name                string `json:"name"`
+	Level               int    `json:"level"`
+	BypassesPlayerLimit bool   `json:"bypassesPlayerLimit"`
+}
+
+type BanEntry struct {
+	UUID    string `json:"uuid"`
+	Name    string `json:"name"`
+	Created string `json:"created"`
+	Source  string `json:"source"`
+	Expires string `json:"expires"`
+	Reason  string `json:"reason"`
+}
+
+type IPBanEntry struct {
+	IP      string `json:"ip"`
+	Created string `json:"created"`
+	Source  string `json:"source"`
+	Expires string `json:"expires"`
+	Reason  string `json:"reason"`
+}
+
+type PlayerProfile struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+type PlayerListResult struct {
+	AppliedVia string         `json:"appliedVia"`
+	Profile    *PlayerProfile `json:"profile,omitempty"`
+}
+
+type PlayerSession struct {
+	ID           uint       `json:"id"`
+	ServerID     string     `json:"serverId"`
+	PlayerName   string     `json:"playerName"`
+	PlayerUUID   string     `json:"playerUuid"`
+	JoinedAt     time.Time  `json:"joinedAt"`
+	LeftAt       *time.Time `json:"leftAt"`
+	Deaths       int        `json:"deaths"`
+	Advancements int        `json:"advancements"`
+	ChatMessages int        `json:"chatMessages"`
+}
+
+type PlayerSummary struct {
+	Name            string    `json:"name"`
+	UUID            string    `json:"uuid"`
+	Online          bool      `json:"online"`
+	Sessions        int       `json:"sessions"`
+	PlaytimeSeconds int64     `json:"playtimeSeconds"`
+	FirstSeen       time.Time `json:"firstSeen"`
+	LastSeen        time.Time `json:"lastSeen"`
+	Deaths          int       `json:"deaths"`
+	Advancements    int       `json:"advancements"`
+	ChatMessages    int       `json:"chatMessages"`
+}
+
+type PlayerHistory struct {
+	Summary  PlayerSummary   `json:"summary"`
+	Sessions []PlayerSession `json:"sessions"`
+}
+
+type LogLine struct {
+	Time time.Time `json:"time"`
+	Line string    `json:"line"`
+}
+
+type LogPage struct {
+	Lines  []LogLine `json:"lines"`
+	Total  int       `json:"total"`
+	Offset int       `json:"offset"`
+	Limit  int       `json:"limit"`
+}
+
+// LogQuery filters the archived console output of a server. Zero values are
+// left out of the request.
+type LogQuery struct {
+	From   time.Time
+	To     time.Time
+	Query  string
+	Limit  int
+	Offset int
+	Tail   bool
+}
+
+// Incident describes an unexpected server exit. List responses leave out the
+// crash report and JVM error log contents.
+type Incident struct {
+	ID              string    `json:"id"`
+	ServerID        string    `json:"serverId"`
+	ExitCode        int       `json:"exitCode"`
+	Cause           string    `json:"cause"`
+	Summary         string    `json:"summary"`
+	Evidence        string    `json:"evidence"`
+	CrashReport     string    `json:"crashReport"`
+	CrashReportText string    `json:"crashReportText,omitempty"`
+	JVMErrorLog     string    `json:"jvmErrorLog"`
+	JVMErrorLogText string    `json:"jvmErrorLogText,omitempty"`
+	ConsoleTail     []string  `json:"consoleTail,omitempty"`
+	StartedAt       time.Time `json:"startedAt"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// Schedule runs an action on a server at the times of a five-field cron
+// expression: start, stop, restart, command or backup.
+type Schedule struct {
+	ID        string    `json:"id"`
+	ServerID  string    `json:"serverId"`
+	Cron      string    `json:"cron"`
+	Action    string    `json:"action"`
+	Command   string    `json:"command,omitempty"`
+	LastRunAt time.Time `json:"lastRunAt,omitempty"`
+	LastError string    `json:"lastError,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ScheduleRequest struct {
+	Cron    string `json:"cron"`
+	Action  string `json:"action"`
+	Command string `json:"command,omitempty"`
+}
+
+// NotificationSink is a configured notification destination. Secrets are
+// never returned by the API.
+type NotificationSink struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	Enabled      bool      `json:"enabled"`
+	Events       []string  `json:"events"`
+	URL          string    `json:"url,omitempty"`
+	SMTPHost     string    `json:"smtpHost,omitempty"`
+	SMTPPort     int       `json:"smtpPort,omitempty"`
+	SMTPUsername string    `json:"smtpUsername,omitempty"`
+	EmailFrom    string    `json:"emailFrom,omitempty"`
+	EmailTo      []string  `json:"emailTo,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// NotificationSinkRequest creates or updates a sink. On update, an empty
+// Secret or SMTPPassword keeps the stored value.
+type NotificationSinkRequest struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	Enabled      *bool    `json:"enabled,omitempty"`
+	Events       []string `json:"events"`
+	URL          string   `json:"url,omitempty"`
+	Secret       string   `json:"secret,omitempty"`
+	SMTPHost     string   `json:"smtpHost,omitempty"`
+	SMTPPort     int      `json:"smtpPort,omitempty"`
+	SMTPUsername string   `json:"smtpUsername,omitempty"`
+	SMTPPassword string   `json:"smtpPassword,omitempty"`
+	EmailFrom    string   `json:"emailFrom,omitempty"`
+	EmailTo      []string `json:"emailTo,omitempty"`
+}
+
+type DeadLetter struct {
+	ID        uint      `json:"id"`
+	SinkID    string    `json:"sinkId"`
+	SinkName  string    `json:"sinkName"`
+	EventType string    `json:"eventType"`
+	Payload   string    `json:"payload"`
+	Error     string    `json:"error"`
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type SSHKey struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userId"`
+	Name        string    `json:"name"`
+	PublicKey   string    `json:"publicKey"`
+	Fingerprint string    `json:"fingerprint"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
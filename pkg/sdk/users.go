@@ -0,0 +1,21 @@
+package sdk
+
+import "fmt"
+
+func (c *Client) ListSSHKeys(userID string) ([]SSHKey, error) {
+	var keys []SSHKey
+	err := c.get(fmt.Sprintf("/users/%s/ssh-keys", userID), &keys)
+	return keys, err
+}
+
+// AddSSHKey registers a public key in authorized_keys format for SFTP
+// sign-in. The name defaults to the key's comment.
+func (c *Client) AddSSHKey(userID, name, publicKey string) (*SSHKey, error) {
+	var key SSHKey
+	err := c.post(fmt.Sprintf("/users/%s/ssh-keys", userID), map[string]string{"name": name, "publicKey": publicKey}, &key)
+	return &key, err
+}
+
+func (c *Client) DeleteSSHKey(userID, keyID string) error {
+	return c.delete(fmt.Sprintf("/users/%s/ssh-keys/%s", userID, keyID))
+}